@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sessionCookie is the cookie the server keeps its session in, its value is the context token
+const sessionCookie = "beegosessionID"
+
+// client calls the REST API of an OLake server
+type client struct {
+	server  string
+	project string
+	token   string
+	http    *http.Client
+}
+
+// apiResponse is the envelope of every API response
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func newClient(ctx *cliContext) *client {
+	return &client{
+		server:  strings.TrimRight(ctx.Server, "/"),
+		project: ctx.Project,
+		token:   ctx.Token,
+		http:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// login starts a session for a user and returns its token
+func (c *client) login(username, password string) (string, error) {
+	body, err := json.Marshal(map[string]string{"username": username, "password": password})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(http.MethodPost, "/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if _, err := decodeResponse(resp); err != nil {
+		return "", err
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == sessionCookie {
+			return cookie.Value, nil
+		}
+	}
+	return "", fmt.Errorf("server did not start a session, is sessionon enabled?")
+}
+
+// projectPath returns the API path of a project resource
+func (c *client) projectPath(path string) string {
+	return fmt.Sprintf("/api/v1/project/%s/%s", url.PathEscape(c.project), path)
+}
+
+// call sends a JSON body, which may be nil, and returns the data of the response
+func (c *client) call(method, path string, body interface{}) (json.RawMessage, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %s", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	return c.send(method, path, "application/json", reader)
+}
+
+// send sends a body as is and returns the data of the response
+func (c *client) send(method, path, contentType string, body io.Reader) (json.RawMessage, error) {
+	resp, err := c.do(method, path, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp)
+}
+
+// raw sends a body as is and returns the response body without decoding it,
+// for endpoints that do not wrap their response
+func (c *client) raw(method, path, contentType string, body io.Reader) ([]byte, error) {
+	resp, err := c.do(method, path, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		_, err := decodeResponse(resp)
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err)
+	}
+	return data, nil
+}
+
+func (c *client) do(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %s", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: c.token})
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %s", err)
+	}
+	return resp, nil
+}
+
+// decodeResponse returns the data of a response, or its message as an error
+func decodeResponse(resp *http.Response) (json.RawMessage, error) {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err)
+	}
+	var decoded apiResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("unexpected response (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if resp.StatusCode >= http.StatusBadRequest || !decoded.Success {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("%s (HTTP 401), run login again", decoded.Message)
+		}
+		return nil, fmt.Errorf("%s (HTTP %d)", decoded.Message, resp.StatusCode)
+	}
+	return decoded.Data, nil
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// logPollInterval is how often jobs logs --follow checks for new log lines
+const logPollInterval = 5 * time.Second
+
+var (
+	connectorColumns = []column{
+		{"ID", "id"}, {"NAME", "name"}, {"TYPE", "type"}, {"VERSION", "version"}, {"JOBS", "jobs"},
+	}
+	jobColumns = []column{
+		{"ID", "id"}, {"NAME", "name"}, {"SOURCE", "source.name"}, {"DESTINATION", "destination.name"},
+		{"FREQUENCY", "frequency"}, {"ACTIVE", "activate"}, {"LAST RUN", "last_run_state"},
+	}
+	taskColumns = []column{
+		{"TASK", "file_path"}, {"TYPE", "type"}, {"ATTEMPT", "attempt"}, {"STATUS", "status"},
+		{"STARTED", "start_time"}, {"RUNTIME", "runtime"},
+	}
+	changeColumns = []column{
+		{"ACTION", "action"}, {"KIND", "kind"}, {"NAME", "name"}, {"FIELDS", "fields"},
+	}
+)
+
+// list prints the resources of a project
+func (a *app) list(args []string, resource string, columns []column) error {
+	if _, err := a.parse(a.flags(resource+" list"), args, 0); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	data, err := c.call(http.MethodGet, c.projectPath(resource), nil)
+	if err != nil {
+		return err
+	}
+	return a.printData(data, columns)
+}
+
+// create creates a resource from a file holding its create request, the JSON
+// fields at stringFields may be written as objects
+func (a *app) create(args []string, resource string, columns []column, stringFields ...string) error {
+	flags := a.flags(resource + " create")
+	file := flags.String("file", "", "JSON or YAML file holding the request, - for stdin")
+	if _, err := a.parse(flags, args, 0); err != nil {
+		return err
+	}
+	body, err := a.readBody(*file)
+	if err != nil {
+		return err
+	}
+	if err := stringifyFields(body, stringFields...); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	data, err := c.call(http.MethodPost, c.projectPath(resource), body)
+	if err != nil {
+		return err
+	}
+	return a.printData(data, columns)
+}
+
+func (a *app) syncJob(args []string) error {
+	flags := a.flags("jobs sync")
+	overrideBlackout := flags.Bool("override-blackout", false, "run even inside a blackout window")
+	values, err := a.parse(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	path := c.projectPath(fmt.Sprintf("jobs/%s/sync", url.PathEscape(values[0])))
+	if *overrideBlackout {
+		path += "?override_blackout=true"
+	}
+	if _, err := c.call(http.MethodPost, path, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Sync of job %s triggered\n", values[0])
+	return nil
+}
+
+func (a *app) listTasks(args []string) error {
+	values, err := a.parse(a.flags("jobs tasks"), args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	data, err := c.call(http.MethodGet, c.projectPath(fmt.Sprintf("jobs/%s/tasks", url.PathEscape(values[0]))), nil)
+	if err != nil {
+		return err
+	}
+	return a.printData(data, taskColumns)
+}
+
+// jobLogs prints the logs of a run of a job, following them until the run ends when asked to
+func (a *app) jobLogs(args []string) error {
+	flags := a.flags("jobs logs")
+	task := flags.String("task", "", "task to print, as listed by jobs tasks (default latest)")
+	follow := flags.Bool("follow", false, "keep printing new lines until the task finishes")
+	values, err := a.parse(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	jobID := values[0]
+
+	printed := 0
+	for {
+		status, err := taskStatus(c, jobID, task)
+		if err != nil {
+			return err
+		}
+		entries, err := taskLogs(c, jobID, *task)
+		// logs of a run that has just started may not be written yet
+		if err != nil && (!*follow || status != "Running") {
+			return err
+		}
+		for _, entry := range entries[min(printed, len(entries)):] {
+			if err := a.printLogEntry(entry); err != nil {
+				return err
+			}
+		}
+		printed = max(printed, len(entries))
+		if !*follow || status != "Running" {
+			return nil
+		}
+		time.Sleep(logPollInterval)
+	}
+}
+
+// taskStatus returns the status of a task of a job, choosing the latest task when task is empty
+func taskStatus(c *client, jobID string, task *string) (string, error) {
+	data, err := c.call(http.MethodGet, c.projectPath(fmt.Sprintf("jobs/%s/tasks", url.PathEscape(jobID))), nil)
+	if err != nil {
+		return "", err
+	}
+	var tasks []struct {
+		FilePath  string `json:"file_path"`
+		StartTime string `json:"start_time"`
+		Status    string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return "", fmt.Errorf("invalid tasks: %s", err)
+	}
+	if *task == "" {
+		latest := ""
+		for _, t := range tasks {
+			// start times are RFC 3339 in UTC, so they sort as strings
+			if t.StartTime > latest {
+				latest, *task = t.StartTime, t.FilePath
+			}
+		}
+		if *task == "" {
+			return "", fmt.Errorf("job %s has no tasks", jobID)
+		}
+	}
+	for _, t := range tasks {
+		if t.FilePath == *task {
+			return t.Status, nil
+		}
+	}
+	return "", fmt.Errorf("task %s of job %s not found", *task, jobID)
+}
+
+func taskLogs(c *client, jobID, task string) ([]map[string]interface{}, error) {
+	path := c.projectPath(fmt.Sprintf("jobs/%s/tasks/%s/logs", url.PathEscape(jobID), url.PathEscape(task)))
+	data, err := c.call(http.MethodPost, path, map[string]string{"file_path": task})
+	if err != nil {
+		return nil, err
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid logs: %s", err)
+	}
+	return entries, nil
+}
+
+// printLogEntry prints a log entry as a line of text, or as a JSON line
+func (a *app) printLogEntry(entry map[string]interface{}) error {
+	if a.output == outputJSON {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(a.stdout, string(line))
+		return nil
+	}
+	fmt.Fprintf(a.stdout, "%s %-5s %s\n", formatValue(entry["time"]), formatValue(entry["level"]), formatValue(entry["message"]))
+	return nil
+}
+
+func (a *app) exportConfig(args []string) error {
+	flags := a.flags("config export")
+	format := flags.String("format", "yaml", "document format, yaml or json")
+	secrets := flags.String("secrets", "reference", "write secrets as references, or omit them")
+	file := flags.String("file", "", "file to write the document to (default stdout)")
+	if _, err := a.parse(flags, args, 0); err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	query := url.Values{"format": {*format}, "secrets": {*secrets}}
+	data, err := c.raw(http.MethodGet, c.projectPath("config/export?"+query.Encode()), "", nil)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		_, err = a.stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*file, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %s", *file, err)
+	}
+	return nil
+}
+
+// importConfig plans or applies a project config document
+func (a *app) importConfig(args []string, action string) error {
+	flags := a.flags("config " + action)
+	file := flags.String("file", "", "JSON or YAML project config document, - for stdin")
+	if _, err := a.parse(flags, args, 0); err != nil {
+		return err
+	}
+	doc, err := a.readFile(*file)
+	if err != nil {
+		return err
+	}
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	data, err := c.send(http.MethodPost, c.projectPath("config/"+action), "application/yaml", bytes.NewReader(doc))
+	if err != nil {
+		return err
+	}
+	if a.output == outputJSON {
+		return a.printJSON(data)
+	}
+
+	var result struct {
+		Changes        json.RawMessage `json:"changes"`
+		ScheduleErrors []string        `json:"schedule_errors"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("invalid response data: %s", err)
+	}
+	if err := a.printData(result.Changes, changeColumns); err != nil {
+		return err
+	}
+	for _, scheduleErr := range result.ScheduleErrors {
+		fmt.Fprintf(a.stderr, "warning: %s\n", scheduleErr)
+	}
+	return nil
+}
+
+func (a *app) readFile(file string) ([]byte, error) {
+	if file == "" {
+		return nil, usageError{fmt.Errorf("--file is required")}
+	}
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %s", file, err)
+	}
+	return data, nil
+}
+
+// readBody reads a JSON or YAML request body from a file
+func (a *app) readBody(file string) (map[string]interface{}, error) {
+	data, err := a.readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]interface{}
+	if err := yaml.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", file, err)
+	}
+	if body == nil {
+		return nil, fmt.Errorf("%s is empty", file)
+	}
+	return body, nil
+}
+
+// stringifyFields encodes the objects at the dotted paths of a body as JSON
+// strings, as the API takes connector and streams configs as strings
+func stringifyFields(body map[string]interface{}, paths ...string) error {
+	for _, path := range paths {
+		keys := strings.Split(path, ".")
+		parent := body
+		for _, key := range keys[:len(keys)-1] {
+			if parent, _ = parent[key].(map[string]interface{}); parent == nil {
+				break
+			}
+		}
+		if parent == nil {
+			continue
+		}
+		key := keys[len(keys)-1]
+		switch value := parent[key].(type) {
+		case map[string]interface{}, []interface{}:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %s", path, err)
+			}
+			parent[key] = string(encoded)
+		}
+	}
+	return nil
+}
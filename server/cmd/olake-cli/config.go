@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configEnv overrides the location of the CLI config file
+const configEnv = "OLAKE_CONFIG"
+
+// cliConfig is the CLI config file, holding the servers the CLI talks to
+type cliConfig struct {
+	CurrentContext string                 `yaml:"current-context"`
+	Contexts       map[string]*cliContext `yaml:"contexts"`
+}
+
+// cliContext is a server the CLI talks to and the session token used for it
+type cliContext struct {
+	Server  string `yaml:"server"`
+	Project string `yaml:"project"`
+	Token   string `yaml:"token"`
+}
+
+func configPath() (string, error) {
+	if path := os.Getenv(configEnv); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory, set %s: %s", configEnv, err)
+	}
+	return filepath.Join(home, ".olake", "config.yaml"), nil
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func loadConfig() (*cliConfig, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	config := &cliConfig{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config %s: %s", path, err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %s", path, err)
+	}
+	if config.Contexts == nil {
+		config.Contexts = map[string]*cliContext{}
+	}
+	return config, nil
+}
+
+// save writes the config file readable by the current user only, as it holds tokens
+func (c *cliConfig) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %s", err)
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %s", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config %s: %s", path, err)
+	}
+	return nil
+}
+
+// context returns the named context, or the current one when name is empty
+func (c *cliConfig) context(name string) (*cliContext, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no context selected, run login first")
+	}
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+	return ctx, nil
+}
@@ -0,0 +1,292 @@
+// Command olake-cli manages an OLake server through its REST API, so sources,
+// destinations and jobs can be scripted without the UI.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultServer  = "http://localhost:8080"
+	defaultProject = "123"
+	defaultContext = "default"
+)
+
+const usage = `Usage: olake-cli <command> [flags]
+
+Commands:
+  login                   log in and save the server as a context
+  context list            list the saved contexts
+  context use NAME        switch the current context
+  sources list            list sources
+  sources create          create a source from --file
+  destinations list       list destinations
+  destinations create     create a destination from --file
+  jobs list               list jobs
+  jobs create             create a job from --file
+  jobs sync ID            trigger a sync of a job
+  jobs tasks ID           list the sync runs of a job
+  jobs logs ID            print the logs of the latest run, or of --task
+  config export           export the project config
+  config plan             show the changes importing --file makes
+  config apply            import --file
+
+Files given with --file may be JSON or YAML, "-" reads stdin. Connector configs
+and streams configs may be written as objects.
+
+Flags shared by all commands:
+  --context NAME          context to use instead of the current one
+  --project ID            project to use instead of the context's
+  --output FORMAT         table or json (default table)
+
+Contexts are saved in ~/.olake/config.yaml, or in $OLAKE_CONFIG.
+
+Exit status is 0 on success, 2 when the command or its flags are invalid and 1
+when it fails.
+`
+
+// exit statuses
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+func main() {
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(a.main(os.Args[1:]))
+}
+
+// app holds the flags shared by all commands and the streams they use
+type app struct {
+	contextName string
+	project     string
+	output      string
+
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// usageError is an invalid command, argument or flag
+type usageError struct {
+	error
+}
+
+// main runs a command, reports its error and returns the exit status
+func (a *app) main(args []string) int {
+	err := a.run(args)
+	// on --help the flag set printed its usage already
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	fmt.Fprintf(a.stderr, "error: %s\n", err)
+	if errors.As(err, new(usageError)) {
+		return exitUsage
+	}
+	return exitFailure
+}
+
+func (a *app) run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(a.stdout, usage)
+		return nil
+	}
+	command, args := args[0], args[1:]
+	if command == "login" {
+		return a.login(args)
+	}
+
+	if len(args) == 0 {
+		return usageError{fmt.Errorf("%s needs a subcommand, see olake-cli help", command)}
+	}
+	subcommand, args := args[0], args[1:]
+	switch command + " " + subcommand {
+	case "context list":
+		return a.listContexts(args)
+	case "context use":
+		return a.useContext(args)
+	case "sources list":
+		return a.list(args, "sources", connectorColumns)
+	case "sources create":
+		return a.create(args, "sources", connectorColumns, "config")
+	case "destinations list":
+		return a.list(args, "destinations", connectorColumns)
+	case "destinations create":
+		return a.create(args, "destinations", connectorColumns, "config")
+	case "jobs list":
+		return a.list(args, "jobs", jobColumns)
+	case "jobs create":
+		return a.create(args, "jobs", jobColumns, "source.config", "destination.config", "streams_config")
+	case "jobs sync":
+		return a.syncJob(args)
+	case "jobs tasks":
+		return a.listTasks(args)
+	case "jobs logs":
+		return a.jobLogs(args)
+	case "config export":
+		return a.exportConfig(args)
+	case "config plan":
+		return a.importConfig(args, "plan")
+	case "config apply":
+		return a.importConfig(args, "apply")
+	}
+	return usageError{fmt.Errorf("unknown command %q, see olake-cli help", command+" "+subcommand)}
+}
+
+// flags returns the flag set of a command, with the shared flags registered
+func (a *app) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("olake-cli "+name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.StringVar(&a.contextName, "context", "", "context to use instead of the current one")
+	flags.StringVar(&a.project, "project", "", "project to use instead of the context's")
+	flags.StringVar(&a.output, "output", outputTable, "output format, table or json")
+	return flags
+}
+
+// parse parses flags placed before or after the positional arguments, which it returns
+func (a *app) parse(flags *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var values []string
+	for {
+		if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		} else if err != nil {
+			return nil, usageError{err}
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		values = append(values, args[0])
+		args = args[1:]
+	}
+	if len(values) != positional {
+		return nil, usageError{fmt.Errorf("expected %d arguments, got %d", positional, len(values))}
+	}
+	if a.output != outputTable && a.output != outputJSON {
+		return nil, usageError{fmt.Errorf("output must be %s or %s", outputTable, outputJSON)}
+	}
+	return values, nil
+}
+
+// client returns a client for the selected context
+func (a *app) client() (*client, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := config.context(a.contextName)
+	if err != nil {
+		return nil, err
+	}
+	c := newClient(ctx)
+	if a.project != "" {
+		c.project = a.project
+	}
+	return c, nil
+}
+
+func (a *app) login(args []string) error {
+	flags := a.flags("login")
+	server := flags.String("server", defaultServer, "server URL")
+	username := flags.String("username", "", "username")
+	password := flags.String("password", "", "password, read from $OLAKE_PASSWORD or stdin when not set")
+	token := flags.String("token", "", "session token to save instead of logging in")
+	name := flags.String("name", defaultContext, "name of the context to save")
+	if _, err := a.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	ctx := &cliContext{Server: *server, Project: a.project, Token: *token}
+	if ctx.Project == "" {
+		ctx.Project = defaultProject
+	}
+	if ctx.Token == "" {
+		if *username == "" {
+			return usageError{fmt.Errorf("--username or --token is required")}
+		}
+		if *password == "" {
+			*password = os.Getenv("OLAKE_PASSWORD")
+		}
+		if *password == "" {
+			fmt.Fprint(a.stderr, "Password: ")
+			line, err := bufio.NewReader(a.stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("failed to read password: %s", err)
+			}
+			*password = strings.TrimRight(line, "\r\n")
+		}
+		var err error
+		if ctx.Token, err = newClient(ctx).login(*username, *password); err != nil {
+			return fmt.Errorf("login failed: %s", err)
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	config.Contexts[*name] = ctx
+	config.CurrentContext = *name
+	if err := config.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Logged in to %s, saved as context %q\n", ctx.Server, *name)
+	return nil
+}
+
+func (a *app) listContexts(args []string) error {
+	if _, err := a.parse(a.flags("context list"), args, 0); err != nil {
+		return err
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// tokens are never printed
+	rows := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		ctx := config.Contexts[name]
+		rows = append(rows, map[string]interface{}{
+			"name":    name,
+			"server":  ctx.Server,
+			"project": ctx.Project,
+			"current": name == config.CurrentContext,
+		})
+	}
+	return a.printRows(rows, []column{
+		{"NAME", "name"}, {"SERVER", "server"}, {"PROJECT", "project"}, {"CURRENT", "current"},
+	})
+}
+
+func (a *app) useContext(args []string) error {
+	values, err := a.parse(a.flags("context use"), args, 1)
+	if err != nil {
+		return err
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if _, err := config.context(values[0]); err != nil {
+		return err
+	}
+	config.CurrentContext = values[0]
+	if err := config.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Switched to context %q\n", values[0])
+	return nil
+}
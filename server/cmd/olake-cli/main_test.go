@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// served is a request the test server received
+type served struct {
+	method, path, query, body, token string
+}
+
+// apiServer answers requests by path with a canned status and response
+type apiServer struct {
+	*httptest.Server
+	replies  map[string]reply
+	requests []served
+}
+
+type reply struct {
+	status int
+	body   string
+}
+
+func newAPIServer(t *testing.T, replies map[string]reply) *apiServer {
+	t.Helper()
+	s := &apiServer{replies: replies}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := served{method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, body: string(body)}
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			req.token = cookie.Value
+		}
+		s.requests = append(s.requests, req)
+		resp, ok := s.replies[r.Method+" "+r.URL.Path]
+		if !ok {
+			resp = reply{http.StatusNotFound, `{"success": false, "message": "route not found"}`}
+		}
+		if r.URL.Path == "/login" && resp.status == http.StatusOK {
+			http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "session-token"})
+		}
+		w.WriteHeader(resp.status)
+		io.WriteString(w, resp.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// last returns the last request the server received
+func (s *apiServer) last(t *testing.T) served {
+	t.Helper()
+	if len(s.requests) == 0 {
+		t.Fatal("server received no request")
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+// useServer saves a config with a context for the server and returns its path
+func useServer(t *testing.T, server string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(configEnv, path)
+	config := &cliConfig{CurrentContext: "local", Contexts: map[string]*cliContext{
+		"local": {Server: server, Project: "123", Token: "saved-token"},
+	}}
+	if err := config.save(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runCLI runs the CLI with stdin and returns its exit status and output
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	a := &app{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	code := a.main(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func ok(data string) reply {
+	return reply{http.StatusOK, `{"success": true, "message": "ok", "data": ` + data + `}`}
+}
+
+func TestExitCodes(t *testing.T) {
+	server := newAPIServer(t, map[string]reply{
+		"GET /api/v1/project/123/jobs":         ok(`[]`),
+		"GET /api/v1/project/123/sources":      {http.StatusUnauthorized, `{"success": false, "message": "Not authenticated"}`},
+		"GET /api/v1/project/123/destinations": {http.StatusBadGateway, `<html>bad gateway</html>`},
+		"POST /api/v1/project/123/jobs/7/sync": {http.StatusConflict, `{"success": false, "message": "job is already running"}`},
+	})
+	useServer(t, server.URL)
+	cases := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"help", []string{"help"}, exitOK, ""},
+		{"flag help", []string{"jobs", "list", "--help"}, exitOK, "Usage of olake-cli jobs list"},
+		{"succeeds", []string{"jobs", "list"}, exitOK, ""},
+		{"missing subcommand", []string{"jobs"}, exitUsage, "jobs needs a subcommand"},
+		{"unknown command", []string{"jobs", "delete"}, exitUsage, `unknown command "jobs delete"`},
+		{"unknown flag", []string{"jobs", "list", "--verbose"}, exitUsage, "flag provided but not defined: -verbose"},
+		{"missing argument", []string{"jobs", "sync"}, exitUsage, "expected 1 arguments, got 0"},
+		{"extra argument", []string{"jobs", "list", "7"}, exitUsage, "expected 0 arguments, got 1"},
+		{"invalid output", []string{"jobs", "list", "--output", "yaml"}, exitUsage, "output must be table or json"},
+		{"missing file", []string{"sources", "create"}, exitUsage, "--file is required"},
+		{"unknown context", []string{"jobs", "list", "--context", "prod"}, exitFailure, `context "prod" not found`},
+		{"server error", []string{"jobs", "sync", "7"}, exitFailure, "job is already running (HTTP 409)"},
+		{"logged out", []string{"sources", "list"}, exitFailure, "Not authenticated (HTTP 401), run login again"},
+		{"not an API response", []string{"destinations", "list"}, exitFailure, "unexpected response (HTTP 502): <html>bad gateway</html>"},
+		{"unreadable file", []string{"jobs", "create", "--file", filepath.Join(t.TempDir(), "job.yaml")}, exitFailure, "failed to read"},
+	}
+	for _, tc := range cases {
+		code, _, stderr := runCLI("", tc.args...)
+		if code != tc.code {
+			t.Errorf("%s: exit status %d, want %d: %s", tc.name, code, tc.code, stderr)
+		}
+		if !strings.Contains(stderr, tc.stderr) {
+			t.Errorf("%s: stderr %q does not contain %q", tc.name, stderr, tc.stderr)
+		}
+	}
+
+	// without a saved context every command asks to log in
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "config.yaml"))
+	if code, _, stderr := runCLI("", "jobs", "list"); code != exitFailure || !strings.Contains(stderr, "run login first") {
+		t.Errorf("jobs list without a context exits %d with %q", code, stderr)
+	}
+}
+
+func TestFlagParsing(t *testing.T) {
+	server := newAPIServer(t, map[string]reply{
+		"POST /api/v1/project/9/jobs/7/sync":   ok(`null`),
+		"POST /api/v1/project/123/jobs/7/sync": ok(`null`),
+	})
+	useServer(t, server.URL)
+	cases := []struct {
+		name  string
+		args  []string
+		path  string
+		query string
+	}{
+		{"flags after the argument", []string{"jobs", "sync", "7", "--override-blackout", "--project", "9"}, "/api/v1/project/9/jobs/7/sync", "override_blackout=true"},
+		{"flags before the argument", []string{"jobs", "sync", "--project=9", "7"}, "/api/v1/project/9/jobs/7/sync", ""},
+		{"project of the context", []string{"jobs", "sync", "7"}, "/api/v1/project/123/jobs/7/sync", ""},
+	}
+	for _, tc := range cases {
+		code, stdout, stderr := runCLI("", tc.args...)
+		if code != exitOK {
+			t.Fatalf("%s: exit status %d: %s", tc.name, code, stderr)
+		}
+		req := server.last(t)
+		if req.method != http.MethodPost || req.path != tc.path || req.query != tc.query || req.token != "saved-token" {
+			t.Errorf("%s: sent %+v, want POST %s?%s with the saved token", tc.name, req, tc.path, tc.query)
+		}
+		if stdout != "Sync of job 7 triggered\n" {
+			t.Errorf("%s: printed %q", tc.name, stdout)
+		}
+	}
+}
+
+func TestOutput(t *testing.T) {
+	jobs := `[
+		{"id": 1, "name": "orders", "source": {"name": "pg"}, "destination": {"name": "lake"}, "frequency": "1-days", "activate": true, "last_run_state": "completed"},
+		{"id": 12, "name": "users", "source": {"name": "mysql"}, "destination": null, "frequency": "", "activate": false}
+	]`
+	server := newAPIServer(t, map[string]reply{
+		"GET /api/v1/project/123/jobs":    ok(jobs),
+		"GET /api/v1/project/123/sources": ok(`[{"id": 3, "name": "pg", "type": "postgres", "version": "v0.1.0", "jobs": ["orders", "users"]}, {"id": 4, "name": "idle", "type": "mysql", "version": "v0.1.0", "jobs": []}]`),
+	})
+	useServer(t, server.URL)
+
+	_, stdout, _ := runCLI("", "jobs", "list")
+	want := strings.Join([]string{
+		"ID  NAME    SOURCE  DESTINATION  FREQUENCY  ACTIVE  LAST RUN",
+		"1   orders  pg      lake         1-days     true    completed",
+		"12  users   mysql   -            -          false   -",
+		"",
+	}, "\n")
+	if stdout != want {
+		t.Errorf("jobs table =\n%s\nwant\n%s", stdout, want)
+	}
+
+	_, stdout, _ = runCLI("", "sources", "list")
+	want = strings.Join([]string{
+		"ID  NAME  TYPE      VERSION  JOBS",
+		"3   pg    postgres  v0.1.0   orders,users",
+		"4   idle  mysql     v0.1.0   -",
+		"",
+	}, "\n")
+	if stdout != want {
+		t.Errorf("sources table =\n%s\nwant\n%s", stdout, want)
+	}
+
+	// json prints the data of the response as is, indented
+	_, stdout, _ = runCLI("", "jobs", "list", "--output", "json")
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil || len(got) != 2 || got[1]["name"] != "users" {
+		t.Errorf("jobs json = %s, %v", stdout, err)
+	}
+	if !strings.HasPrefix(stdout, "[\n  {\n") {
+		t.Errorf("jobs json is not indented: %q", stdout)
+	}
+}
+
+func TestCreateFromFile(t *testing.T) {
+	server := newAPIServer(t, map[string]reply{
+		"POST /api/v1/project/123/sources": ok(`{"id": 5, "name": "pg", "type": "postgres", "version": "v0.1.0"}`),
+	})
+	useServer(t, server.URL)
+	source := "name: pg\ntype: postgres\nversion: v0.1.0\nconfig:\n  host: localhost\n  port: 5432\n"
+
+	// - reads the file from stdin
+	code, stdout, stderr := runCLI(source, "sources", "create", "--file", "-")
+	if code != exitOK {
+		t.Fatalf("exit status %d: %s", code, stderr)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(server.last(t).body), &body); err != nil {
+		t.Fatal(err)
+	}
+	// the config object is sent as the JSON string the API takes
+	if body["name"] != "pg" || body["config"] != `{"host":"localhost","port":5432}` {
+		t.Errorf("sent %v, want the config as a string", body)
+	}
+	if want := "ID  NAME  TYPE      VERSION  JOBS\n5   pg    postgres  v0.1.0   -\n"; stdout != want {
+		t.Errorf("created source =\n%s\nwant\n%s", stdout, want)
+	}
+}
+
+func TestConfigPlan(t *testing.T) {
+	server := newAPIServer(t, map[string]reply{
+		"POST /api/v1/project/123/config/plan": ok(`{
+			"changes": [{"action": "update", "kind": "job", "name": "orders", "fields": ["frequency"]}],
+			"schedule_errors": ["job orders: temporal is unavailable"]
+		}`),
+	})
+	useServer(t, server.URL)
+	file := filepath.Join(t.TempDir(), "project.yaml")
+	if err := os.WriteFile(file, []byte("jobs: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI("", "config", "plan", "--file", file)
+	if code != exitOK {
+		t.Fatalf("exit status %d: %s", code, stderr)
+	}
+	if want := "ACTION  KIND  NAME    FIELDS\nupdate  job   orders  frequency\n"; stdout != want {
+		t.Errorf("plan =\n%s\nwant\n%s", stdout, want)
+	}
+	if stderr != "warning: job orders: temporal is unavailable\n" {
+		t.Errorf("schedule errors printed as %q", stderr)
+	}
+	if req := server.last(t); req.body != "jobs: []\n" {
+		t.Errorf("sent document %q, want the file as is", req.body)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	server := newAPIServer(t, map[string]reply{
+		"POST /login": ok(`{"username": "admin"}`),
+	})
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "config.yaml"))
+
+	// the password is read from stdin when neither the flag nor the environment sets it
+	t.Setenv("OLAKE_PASSWORD", "")
+	code, stdout, stderr := runCLI("secret\n", "login", "--server", server.URL, "--username", "admin", "--name", "local")
+	if code != exitOK {
+		t.Fatalf("exit status %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, `saved as context "local"`) || stderr != "Password: " {
+		t.Errorf("login printed %q and %q", stdout, stderr)
+	}
+	if body := server.last(t).body; body != `{"password":"secret","username":"admin"}` {
+		t.Errorf("login sent %s", body)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx := config.Contexts["local"]; config.CurrentContext != "local" || ctx.Token != "session-token" || ctx.Project != defaultProject {
+		t.Errorf("saved config %+v with context %+v", config, ctx)
+	}
+
+	// tokens are never printed
+	_, stdout, _ = runCLI("", "context", "list", "--output", "json")
+	if strings.Contains(stdout, "session-token") || !strings.Contains(stdout, `"current": true`) {
+		t.Errorf("context list printed %s", stdout)
+	}
+}
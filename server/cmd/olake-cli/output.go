@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// column is a table column and the dotted path of the field it shows
+type column struct {
+	header string
+	path   string
+}
+
+// printData prints response data as indented JSON, or as a table of columns
+// when data is a list of objects
+func (a *app) printData(data json.RawMessage, columns []column) error {
+	if a.output == outputJSON || columns == nil {
+		return a.printJSON(data)
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		var item map[string]interface{}
+		if err := json.Unmarshal(data, &item); err != nil {
+			return a.printJSON(data)
+		}
+		items = []map[string]interface{}{item}
+	}
+
+	writer := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	headers := make([]string, 0, len(columns))
+	for _, col := range columns {
+		headers = append(headers, col.header)
+	}
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+	for _, item := range items {
+		values := make([]string, 0, len(columns))
+		for _, col := range columns {
+			values = append(values, formatValue(lookupField(item, col.path)))
+		}
+		fmt.Fprintln(writer, strings.Join(values, "\t"))
+	}
+	return writer.Flush()
+}
+
+func (a *app) printJSON(data json.RawMessage) error {
+	if len(data) == 0 || string(data) == "null" {
+		data = json.RawMessage("[]")
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return fmt.Errorf("invalid response data: %s", err)
+	}
+	buf.WriteByte('\n')
+	_, err := a.stdout.Write(buf.Bytes())
+	return err
+}
+
+// lookupField returns the value at a dotted path of a decoded object
+func lookupField(item map[string]interface{}, path string) interface{} {
+	var value interface{} = item
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		if value == "" {
+			return "-"
+		}
+		return value
+	case float64:
+		return fmt.Sprintf("%g", value)
+	case []interface{}:
+		// lists of names are joined, other lists are counted
+		names := make([]string, 0, len(value))
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				return fmt.Sprintf("%d", len(value))
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return "-"
+		}
+		return strings.Join(names, ",")
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// printRows prints rows built by the CLI itself, like printData
+func (a *app) printRows(rows interface{}, columns []column) error {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %s", err)
+	}
+	return a.printData(data, columns)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,8 +11,12 @@ import (
 	"github.com/beego/beego/v2/core/logs"
 	"github.com/datazip/olake-frontend/server/internal/constants"
 	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/health"
 	"github.com/datazip/olake-frontend/server/internal/logger"
+	"github.com/datazip/olake-frontend/server/internal/metrics"
 	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/internal/tracing"
 )
 
 func main() {
@@ -20,9 +26,15 @@ func main() {
 	// init logger
 	logsdir, _ := config.String("logsdir")
 	logger.InitLogger(logsdir)
+	// init tracing, the worker runs on without it if the collector can not be set up
+	shutdownTracing, err := tracing.Init(context.Background(), "olake-worker")
+	if err != nil {
+		logs.Error("Failed to initialize tracing, continuing without it: %s", err)
+	}
+
 	// init database
-	postgresDB, _ := config.String("costcresdb")
-	err := database.Init(postgresDB)
+	postgresDB, _ := config.String("postgresdb")
+	err = database.Init(postgresDB)
 	if err != nil {
 		logs.Critical("Failed to initialize database: %s", err)
 		os.Exit(1)
@@ -46,6 +58,24 @@ func main() {
 		}
 	}()
 
+	// Serve Prometheus metrics and the probes, the worker has no other HTTP server
+	metricsAddr := config.DefaultString("workermetricsaddr", ":8090")
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(config.DefaultString("metricstoken", "")))
+		mux.Handle("/healthz", health.LivenessHandler())
+		mux.Handle("/readyz", health.ReadinessHandler(
+			health.Database(),
+			health.Check{Name: "temporal", Run: worker.CheckHealth},
+			health.Docker(),
+			health.ConfigDir(docker.GetDefaultConfigDir()),
+			health.EncryptionKey(),
+		))
+		if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+			logs.Error("Failed to serve metrics on %s: %s", metricsAddr, err)
+		}
+	}()
+
 	// Setup signal handling for graceful shutdown
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
@@ -56,5 +86,6 @@ func main() {
 
 	// Stop the worker
 	worker.Stop()
+	tracing.Flush(shutdownTracing)
 	logs.Info("Worker stopped. Bye Bye!")
 }
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/beego/beego/v2/core/config"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+)
+
+const commandUsage = `Usage: olake-server [command]
+
+Without a command the server starts, migrating the database first unless
+automigrate = false is set in conf/app.conf.
+
+Commands:
+  migrate status          list the migrations and when they were applied
+  migrate up              apply the pending migrations
+  migrate down [N]        roll back the last N applied migrations (default 1)
+  migrate to VERSION      apply or roll back migrations until VERSION is the
+                          latest applied one, 0 rolls back everything
+  reconcile [--apply]     compare the jobs with their Temporal schedules and
+                          report orphaned, missing, wrong_cron, wrong_action
+                          and wrong_pause schedules, --apply also repairs them
+`
+
+// runCommand runs a maintenance command instead of the server
+func runCommand(args []string) error {
+	switch args[0] {
+	case "migrate":
+		return migrate(args[1:])
+	case "reconcile":
+		return reconcile(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(commandUsage)
+		return nil
+	}
+	return fmt.Errorf("unknown command %s, see olake-server help", args[0])
+}
+
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate needs a subcommand, see olake-server help")
+	}
+	postgresDB, _ := config.String("postgresdb")
+	if err := database.Open(postgresDB); err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var done []database.Migration
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	case "up":
+		done, err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[1])
+			}
+		}
+		done, err = migrator.Down(ctx, steps)
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("migrate to needs a version")
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 || version > migrator.Latest() {
+			return fmt.Errorf("invalid version %q, must be between 0 and %d", args[1], migrator.Latest())
+		}
+		done, err = migrator.To(ctx, version)
+	default:
+		return fmt.Errorf("unknown migrate subcommand %s, see olake-server help", args[0])
+	}
+	// migrations that ran before a failing one stay applied
+	if len(done) == 0 && err == nil {
+		fmt.Println("Nothing to migrate")
+	}
+	return err
+}
+
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Unknown {
+			applied += " (newer build)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return w.Flush()
+}
+
+func reconcile(args []string) error {
+	apply := false
+	for _, arg := range args {
+		if arg != "--apply" {
+			return fmt.Errorf("unknown reconcile flag %s, see olake-server help", arg)
+		}
+		apply = true
+	}
+	postgresDB, _ := config.String("postgresdb")
+	if err := database.Open(postgresDB); err != nil {
+		return err
+	}
+	tempClient, err := temporal.NewClient()
+	if err != nil {
+		return err
+	}
+	defer tempClient.Close()
+
+	drifts, err := tempClient.CheckScheduleDrift(context.Background(), apply)
+	if err != nil {
+		return err
+	}
+	return printScheduleDrifts(os.Stdout, drifts, apply)
+}
+
+// printScheduleDrifts writes the differences reconcile found as a table, it
+// fails when some schedules could not be checked or repaired
+func printScheduleDrifts(out io.Writer, drifts []*models.ScheduleDrift, apply bool) error {
+	if len(drifts) == 0 {
+		fmt.Fprintln(out, "Schedules match the jobs")
+		return nil
+	}
+	failed := 0
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SCHEDULE\tJOB\tKIND\tEXPECTED\tACTUAL\tRESULT")
+	for _, drift := range drifts {
+		result := "reported"
+		switch {
+		case drift.Error != "":
+			result, failed = drift.Error, failed+1
+		case drift.Repaired:
+			result = "repaired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", drift.ScheduleID, driftJob(drift), drift.Kind, drift.Expected, drift.Actual, result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d schedules could not be checked or repaired", failed, len(drifts))
+	}
+	if !apply {
+		fmt.Fprintln(out, "Run olake-server reconcile --apply to repair them")
+	}
+	return nil
+}
+
+func driftJob(drift *models.ScheduleDrift) string {
+	if drift.JobName == "" {
+		return strconv.Itoa(drift.JobID)
+	}
+	return fmt.Sprintf("%d (%s)", drift.JobID, drift.JobName)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestPrintScheduleDrifts(t *testing.T) {
+	drifts := func(repaired bool, failure string) []*models.ScheduleDrift {
+		return []*models.ScheduleDrift{
+			{ScheduleID: "schedule-sync-p-1-3", ProjectID: "p-1", JobID: 3, JobName: "orders", Kind: constants.ScheduleDriftWrongPause,
+				Expected: "paused", Actual: "active", Repaired: repaired},
+			{ScheduleID: "schedule-sync-p-1-9", ProjectID: "p-1", JobID: 9, Kind: constants.ScheduleDriftOrphaned, Repaired: repaired && failure == "", Error: failure},
+		}
+	}
+	cases := []struct {
+		name    string
+		drifts  []*models.ScheduleDrift
+		apply   bool
+		wantErr bool
+		want    []string
+	}{
+		{"in sync", nil, false, false, []string{"Schedules match the jobs"}},
+		{"report", drifts(false, ""), false, false, []string{
+			"3 (orders)", "wrong_pause", "paused", "active", "reported", "orphaned", "Run olake-server reconcile --apply",
+		}},
+		{"apply", drifts(true, ""), true, false, []string{"3 (orders)", "repaired"}},
+		{"apply with a failure", drifts(true, "failed to delete schedule"), true, true, []string{"repaired", "failed to delete schedule"}},
+	}
+	for _, tc := range cases {
+		var out bytes.Buffer
+		err := printScheduleDrifts(&out, tc.drifts, tc.apply)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: error = %v, want error %t", tc.name, err, tc.wantErr)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s: output does not contain %q:\n%s", tc.name, want, out.String())
+			}
+		}
+		if tc.apply && (strings.Contains(out.String(), "reported") || strings.Contains(out.String(), "--apply")) {
+			t.Errorf("%s: applied output reports unrepaired drifts:\n%s", tc.name, out.String())
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package constants
+
+// ProjectConfigVersion is the version of the project config documents written by exports
+const ProjectConfigVersion = 1
+
+// Actions of a project config change
+const (
+	ConfigChangeCreate = "create"
+	ConfigChangeUpdate = "update"
+	ConfigChangeDelete = "delete"
+)
+
+// Kinds of resources in a project config
+const (
+	ConfigKindSource      = "source"
+	ConfigKindDestination = "destination"
+	ConfigKindJob         = "job"
+	ConfigKindSettings    = "settings"
+)
+
+// Ways secrets of connector configs are written to an export
+const (
+	// SecretsReference writes secrets as ${secret:<kind>/<name>/<path>} references to the stored value
+	SecretsReference = "reference"
+	// SecretsOmit leaves secrets out
+	SecretsOmit = "omit"
+)
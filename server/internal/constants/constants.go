@@ -37,18 +37,31 @@ func Init() {
 	checkForRequiredVariables(RequiredConfigVariable)
 
 	// init table names
+	InitTableNames(web.BConfig.RunMode)
+}
+
+// InitTableNames sets the table names of the run mode, which replaces $$ in them
+func InitTableNames(mode string) {
 	TableNameMap = map[TableType]string{
-		UserTable:        "olake-$$-user",
-		SourceTable:      "olake-$$-source",
-		DestinationTable: "olake-$$-destination",
-		JobTable:         "olake-$$-job",
-		CatalogTable:     "olake-$$-catalog",
-		SessionTable:     "session",
+		UserTable:                "olake-$$-user",
+		SourceTable:              "olake-$$-source",
+		DestinationTable:         "olake-$$-destination",
+		JobTable:                 "olake-$$-job",
+		CatalogTable:             "olake-$$-catalog",
+		SessionTable:             "session",
+		ProjectSettingsTable:     "olake-$$-project-settings",
+		JobDependencyTable:       "olake-$$-job-dependency",
+		JobStateHistoryTable:     "olake-$$-job-state-history",
+		JobTemplateTable:         "olake-$$-job-template",
+		NotificationChannelTable: "olake-$$-notification-channel",
+		JobNotificationTable:     "olake-$$-job-notification",
+		WebhookTable:             "olake-$$-webhook",
+		WebhookDeliveryTable:     "olake-$$-webhook-delivery",
+		ScheduleOperationTable:   "olake-$$-schedule-outbox",
+		MigrationTable:           "olake-$$-migrations",
 	}
-
-	// replace $$ with the environment
 	for k, v := range TableNameMap {
-		TableNameMap[k] = strings.ReplaceAll(v, "$$", web.BConfig.RunMode)
+		TableNameMap[k] = strings.ReplaceAll(v, "$$", mode)
 	}
 }
 
@@ -0,0 +1,36 @@
+package constants
+
+// Job dependency modes
+const (
+	// DependencyModeTrigger starts the job once the upstream job completes successfully
+	DependencyModeTrigger = "trigger"
+	// DependencyModeWait makes runs of the job wait for a running upstream job and
+	// skip when the upstream job did not complete successfully
+	DependencyModeWait = "wait"
+)
+
+// Reasons a job state revision was recorded
+const (
+	// StateChangeSync is a state written by a sync run
+	StateChangeSync = "sync"
+	// StateChangeCheckpoint is an intermediate state written by a running sync
+	StateChangeCheckpoint = "checkpoint"
+	// StateChangeBaseline keeps the state a job had before its first recorded change
+	StateChangeBaseline = "baseline"
+	// StateChangeReplace is a state replaced through the API
+	StateChangeReplace = "replace"
+	// StateChangeReset is a state cleared through the API
+	StateChangeReset = "reset"
+	// StateChangeStreamReset is a state with some of its streams cleared through the API
+	StateChangeStreamReset = "stream_reset"
+	// StateChangeRestore is a state restored from an earlier revision
+	StateChangeRestore = "restore"
+)
+
+// Job task types
+const (
+	// TaskTypeSync is a scheduled or manual sync run of a job
+	TaskTypeSync = "sync"
+	// TaskTypeBackfill is a one-off run of some streams of a job that leaves the job state untouched
+	TaskTypeBackfill = "backfill"
+)
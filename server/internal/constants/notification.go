@@ -0,0 +1,39 @@
+package constants
+
+// Notification channel types
+const (
+	// NotificationChannelWebhook posts the notification as JSON to a URL
+	NotificationChannelWebhook = "webhook"
+	// NotificationChannelSlack posts a message to a Slack compatible incoming webhook
+	NotificationChannelSlack = "slack"
+	// NotificationChannelEmail mails the notification over SMTP
+	NotificationChannelEmail = "email"
+)
+
+// Job events a notification subscription can be for
+const (
+	// NotificationEventFailure is a failed sync run
+	NotificationEventFailure = "failure"
+	// NotificationEventSuccess is a successful sync run
+	NotificationEventSuccess = "success"
+	// NotificationEventRecovery is a successful sync run after a failed one
+	NotificationEventRecovery = "recovery"
+	// NotificationEventLongRun is a sync run lasting longer than the run_longer_than of the subscription
+	NotificationEventLongRun = "long_run"
+	// NotificationEventNoSuccess is no successful sync run within the no_success_within of the subscription
+	NotificationEventNoSuccess = "no_success"
+	// NotificationEventSchemaDrift is a source schema that no longer matches the streams of the job
+	NotificationEventSchemaDrift = "schema_drift"
+	// NotificationEventTest is sent when testing a channel
+	NotificationEventTest = "test"
+)
+
+// NotificationEvents are the events jobs can subscribe to
+var NotificationEvents = []string{
+	NotificationEventFailure,
+	NotificationEventSuccess,
+	NotificationEventRecovery,
+	NotificationEventLongRun,
+	NotificationEventNoSuccess,
+	NotificationEventSchemaDrift,
+}
@@ -0,0 +1,27 @@
+package constants
+
+// Statuses of the operations in the schedule outbox
+const (
+	// ScheduleOperationPending is an operation that is not applied to Temporal yet
+	ScheduleOperationPending = "pending"
+	// ScheduleOperationDone is an operation applied to Temporal
+	ScheduleOperationDone = "done"
+	// ScheduleOperationFailed is an operation that ran out of attempts, the drift check repairs what it left
+	ScheduleOperationFailed = "failed"
+)
+
+// Kinds of differences between jobs and their Temporal schedules
+const (
+	// ScheduleDriftOrphaned is a schedule without a job
+	ScheduleDriftOrphaned = "orphaned"
+	// ScheduleDriftMissing is a job without a schedule
+	ScheduleDriftMissing = "missing"
+	// ScheduleDriftWrongCron is a schedule built from another frequency or schedule config than its job has
+	ScheduleDriftWrongCron = "wrong_cron"
+	// ScheduleDriftWrongPause is a paused schedule of an active job or a running schedule of an inactive one
+	ScheduleDriftWrongPause = "wrong_pause"
+	// ScheduleDriftWrongAction is a schedule starting another workflow than the job workflow, created before job workflows
+	ScheduleDriftWrongAction = "wrong_action"
+	// ScheduleDriftUnchecked is a job whose schedule could not be compared
+	ScheduleDriftUnchecked = "unchecked"
+)
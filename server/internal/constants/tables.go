@@ -10,4 +10,14 @@ const (
 	JobTable
 	CatalogTable
 	SessionTable
+	ProjectSettingsTable
+	JobDependencyTable
+	JobStateHistoryTable
+	JobTemplateTable
+	NotificationChannelTable
+	JobNotificationTable
+	WebhookTable
+	WebhookDeliveryTable
+	ScheduleOperationTable
+	MigrationTable
 )
@@ -0,0 +1,24 @@
+package constants
+
+// Kinds of rows in the trash
+const (
+	TrashKindSource              = "source"
+	TrashKindDestination         = "destination"
+	TrashKindJob                 = "job"
+	TrashKindJobTemplate         = "job_template"
+	TrashKindNotificationChannel = "notification_channel"
+	TrashKindWebhook             = "webhook"
+)
+
+// Modes of deleting a source or destination that jobs use
+const (
+	// DeleteModeReject refuses to delete it while jobs use it
+	DeleteModeReject = "reject"
+	// DeleteModeCascade moves its jobs to the trash with it and deletes their schedules
+	DeleteModeCascade = "cascade"
+	// DeleteModeDetach keeps its jobs inactive with paused schedules until they are moved to another one
+	DeleteModeDetach = "detach"
+)
+
+// DeleteModes lists the valid modes of deleting a source or destination
+var DeleteModes = []string{DeleteModeReject, DeleteModeCascade, DeleteModeDetach}
@@ -0,0 +1,38 @@
+package constants
+
+// WebhookSchemaVersion is the version of the JSON schema of webhook payloads,
+// it changes when a field is removed or changes meaning
+const WebhookSchemaVersion = "1"
+
+// Events webhooks can subscribe to
+const (
+	WebhookEventJobCreated    = "job.created"
+	WebhookEventJobUpdated    = "job.updated"
+	WebhookEventJobDeleted    = "job.deleted"
+	WebhookEventSyncStarted   = "sync.started"
+	WebhookEventSyncSucceeded = "sync.succeeded"
+	WebhookEventSyncFailed    = "sync.failed"
+	// WebhookEventStateReset is a reset of the state of a job or of some of its streams
+	WebhookEventStateReset = "job.state_reset"
+)
+
+// WebhookEvents are the events webhooks can subscribe to
+var WebhookEvents = []string{
+	WebhookEventJobCreated,
+	WebhookEventJobUpdated,
+	WebhookEventJobDeleted,
+	WebhookEventSyncStarted,
+	WebhookEventSyncSucceeded,
+	WebhookEventSyncFailed,
+	WebhookEventStateReset,
+}
+
+// Statuses of webhook deliveries
+const (
+	// WebhookDeliveryPending is a delivery that is being attempted or retried
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliverySucceeded is a delivery the endpoint accepted
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryDead is a delivery that ran out of retries, it stays in the dead-letter list until replayed
+	WebhookDeliveryDead = "dead"
+)
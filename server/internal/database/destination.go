@@ -49,7 +49,7 @@ func (r *DestinationORM) Create(destination *models.Destination) error {
 
 func (r *DestinationORM) GetAll() ([]*models.Destination, error) {
 	var destinations []*models.Destination
-	_, err := r.ormer.QueryTable(r.TableName).RelatedSel().All(&destinations)
+	_, err := alive(r.ormer, r.TableName).RelatedSel().All(&destinations)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all destinations: %s", err)
 	}
@@ -64,7 +64,7 @@ func (r *DestinationORM) GetAll() ([]*models.Destination, error) {
 
 func (r *DestinationORM) GetAllByProjectID(projectID string) ([]*models.Destination, error) {
 	var destinations []*models.Destination
-	_, err := r.ormer.QueryTable(r.TableName).Filter("project_id", projectID).RelatedSel().All(&destinations)
+	_, err := alive(r.ormer, r.TableName).Filter("project_id", projectID).RelatedSel().All(&destinations)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all destinations by project_id[%s]: %s", projectID, err)
 	}
@@ -78,8 +78,8 @@ func (r *DestinationORM) GetAllByProjectID(projectID string) ([]*models.Destinat
 }
 
 func (r *DestinationORM) GetByID(id int) (*models.Destination, error) {
-	destination := &models.Destination{ID: id}
-	err := r.ormer.Read(destination)
+	destination := &models.Destination{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).One(destination)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get destination by ID: %s", err)
 	}
@@ -106,16 +106,34 @@ func (r *DestinationORM) Update(destination *models.Destination) error {
 	return err
 }
 
-func (r *DestinationORM) Delete(id int) error {
-	destination := &models.Destination{ID: id}
-	_, err := r.ormer.Delete(destination)
-	return err
+// Delete moves a destination to the trash and handles the jobs using it as mode,
+// one of constants.DeleteModes, says. action on the schedules of the jobs is
+// recorded in the schedule outbox in the same transaction. The jobs are returned
+// as they were, with ErrConnectorInUse too.
+func (r *DestinationORM) Delete(id int, mode, action string) ([]*models.Job, []*models.ScheduleOperation, error) {
+	jobs, ops, err := deleteConnector(r.ormer, r.TableName, "dest_id", id, mode, action)
+	if err != nil {
+		return jobs, nil, fmt.Errorf("failed to delete destination[%d]: %w", id, err)
+	}
+	return jobs, ops, nil
+}
+
+// Restore takes a destination out of the trash together with the jobs trashed
+// with it whose source is not in the trash. Those jobs come back inactive and
+// action on their schedules is recorded in the schedule outbox in the same transaction.
+func (r *DestinationORM) Restore(projectID string, id int, action string) ([]*models.Job, []*models.ScheduleOperation, error) {
+	jobs, ops, err := restoreConnector(r.ormer, r.TableName, "dest_id", projectID, id,
+		func(d *models.Destination) *time.Time { return d.DeletedAt }, action)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to restore destination[%d]: %w", id, err)
+	}
+	return jobs, ops, nil
 }
 
 // GetByNameAndType retrieves destinations by name, destType, and project ID
 func (r *DestinationORM) GetByNameAndType(name, destType, projectID string) ([]*models.Destination, error) {
 	var destinations []*models.Destination
-	_, err := r.ormer.QueryTable(r.TableName).
+	_, err := alive(r.ormer, r.TableName).
 		Filter("name", name).
 		Filter("dest_type", destType).
 		Filter("project_id", projectID).
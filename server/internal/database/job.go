@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -59,16 +61,105 @@ func (r *JobORM) decryptJobSliceConfig(jobs []*models.Job) error {
 	return nil
 }
 
+// setJSONDefaults fills empty nullable jsonb columns, postgres rejects an empty string as json
+func (r *JobORM) setJSONDefaults(job *models.Job) {
+	if job.ScheduleConfig == "" {
+		job.ScheduleConfig = "{}"
+	}
+	if job.RetryPolicy == "" {
+		job.RetryPolicy = "{}"
+	}
+}
+
 // Create a new job
 func (r *JobORM) Create(job *models.Job) error {
+	r.setJSONDefaults(job)
 	_, err := r.ormer.Insert(job)
 	return err
 }
 
+// CreateScheduled creates a job and records the creation of its schedule in
+// the schedule outbox in the same transaction
+func (r *JobORM) CreateScheduled(job *models.Job, action string) (*models.ScheduleOperation, error) {
+	return r.withScheduleOperation(job, action, func(txOrm orm.TxOrmer) error {
+		r.setJSONDefaults(job)
+		_, err := txOrm.Insert(job)
+		return err
+	})
+}
+
+// UpdateScheduled updates a job and records action on its schedule in the
+// schedule outbox in the same transaction
+func (r *JobORM) UpdateScheduled(job *models.Job, action string) (*models.ScheduleOperation, error) {
+	return r.withScheduleOperation(job, action, func(txOrm orm.TxOrmer) error {
+		job.UpdatedAt = time.Now()
+		r.setJSONDefaults(job)
+		_, err := txOrm.Update(job)
+		return err
+	})
+}
+
+// DeleteScheduled moves a job to the trash and records action, the deletion
+// of its schedule, in the schedule outbox in the same transaction
+func (r *JobORM) DeleteScheduled(job *models.Job, action string) (*models.ScheduleOperation, error) {
+	var op *models.ScheduleOperation
+	err := r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		var err error
+		op, err = deleteJobWith(txOrm, job, action, time.Now())
+		return err
+	})
+	return op, err
+}
+
+// deleteJobWith is DeleteScheduled within the transaction of ormer, trashing at now
+func deleteJobWith(ormer orm.QueryExecutor, job *models.Job, action string, now time.Time) (*models.ScheduleOperation, error) {
+	if err := trashOne(ormer, constants.TableNameMap[constants.JobTable], job.ID, now); err != nil {
+		return nil, err
+	}
+	return enqueueScheduleOperation(ormer, job, action)
+}
+
+func (r *JobORM) withScheduleOperation(job *models.Job, action string, write func(txOrm orm.TxOrmer) error) (*models.ScheduleOperation, error) {
+	var op *models.ScheduleOperation
+	err := r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		if err := write(txOrm); err != nil {
+			return err
+		}
+		var err error
+		op, err = enqueueScheduleOperation(txOrm, job, action)
+		return err
+	})
+	return op, err
+}
+
+// GetAllForSchedules retrieves the jobs of all projects with the columns their schedules are built from
+func (r *JobORM) GetAllForSchedules() ([]*models.Job, error) {
+	var jobs []*models.Job
+	_, err := alive(r.ormer, r.TableName).OrderBy("id").All(&jobs, "ID", "Name", "ProjectID", "Active", "Frequency", "ScheduleConfig")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs for schedules: %s", err)
+	}
+	return jobs, nil
+}
+
+// GetForSchedule retrieves a job with the columns its schedule is built from,
+// nil when the job is in the trash or gone
+func (r *JobORM) GetForSchedule(id int) (*models.Job, error) {
+	job := &models.Job{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).One(job, "ID", "Name", "ProjectID", "Active", "Frequency", "ScheduleConfig")
+	if errors.Is(err, orm.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job[%d] for its schedule: %s", id, err)
+	}
+	return job, nil
+}
+
 // GetAll retrieves all jobs
 func (r *JobORM) GetAll() ([]*models.Job, error) {
 	var jobs []*models.Job
-	_, err := r.ormer.QueryTable(r.TableName).RelatedSel().All(&jobs)
+	_, err := alive(r.ormer, r.TableName).RelatedSel().All(&jobs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all jobs: %s", err)
 	}
@@ -88,7 +179,7 @@ func (r *JobORM) GetAllByProjectID(projectID string) ([]*models.Job, error) {
 	// Query sources in the project
 	sourceTable := constants.TableNameMap[constants.SourceTable]
 	sources := []int{}
-	_, err := r.ormer.Raw(fmt.Sprintf(`SELECT id FROM %q WHERE project_id = ?`, sourceTable), projectID).QueryRows(&sources)
+	_, err := r.ormer.Raw(fmt.Sprintf(`SELECT id FROM %q WHERE project_id = ? AND deleted_at IS NULL`, sourceTable), projectID).QueryRows(&sources)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sources for project ID %s: %s", projectID, err)
 	}
@@ -96,7 +187,7 @@ func (r *JobORM) GetAllByProjectID(projectID string) ([]*models.Job, error) {
 	// Query destinations in the project
 	destTable := constants.TableNameMap[constants.DestinationTable]
 	destinations := []int{}
-	_, err = r.ormer.Raw(fmt.Sprintf(`SELECT id FROM %q WHERE project_id = ?`, destTable), projectID).QueryRows(&destinations)
+	_, err = r.ormer.Raw(fmt.Sprintf(`SELECT id FROM %q WHERE project_id = ? AND deleted_at IS NULL`, destTable), projectID).QueryRows(&destinations)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get destinations for project ID %s: %s", projectID, err)
 	}
@@ -107,7 +198,7 @@ func (r *JobORM) GetAllByProjectID(projectID string) ([]*models.Job, error) {
 	}
 
 	// Build query
-	qs := r.ormer.QueryTable(r.TableName)
+	qs := alive(r.ormer, r.TableName)
 	// Filter by sources or destinations from the project
 	if len(sources) > 0 {
 		qs = qs.Filter("source_id__in", sources)
@@ -133,8 +224,8 @@ func (r *JobORM) GetAllByProjectID(projectID string) ([]*models.Job, error) {
 
 // GetByID retrieves a job by ID
 func (r *JobORM) GetByID(id int, decrypt bool) (*models.Job, error) {
-	job := &models.Job{ID: id}
-	err := r.ormer.Read(job)
+	job := &models.Job{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).One(job)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get job by ID: %s", err)
 	}
@@ -162,23 +253,51 @@ func (r *JobORM) GetByID(id int, decrypt bool) (*models.Job, error) {
 // Update a job
 func (r *JobORM) Update(job *models.Job) error {
 	job.UpdatedAt = time.Now()
+	r.setJSONDefaults(job)
 	_, err := r.ormer.Update(job)
 	return err
 }
 
-// Delete a job
-func (r *JobORM) Delete(id int) error {
-	job := &models.Job{ID: id}
-	_, err := r.ormer.Delete(job)
+// UpdateActive writes only the active flag of a job, a state saved since the
+// job was read is kept
+func (r *JobORM) UpdateActive(job *models.Job) error {
+	job.UpdatedAt = time.Now()
+	_, err := r.ormer.Update(job, "Active", "UpdatedAt")
 	return err
 }
 
+// Delete moves a job to the trash, its state history, dependencies and
+// notification subscriptions stay for a restore
+func (r *JobORM) Delete(id int) error {
+	return trashOne(r.ormer, r.TableName, id, time.Now())
+}
+
+// Restore takes a job out of the trash and records action on its schedule in
+// the schedule outbox in the same transaction, failing with ErrConnectorInTrash
+// while its source or destination is in the trash
+func (r *JobORM) Restore(projectID string, id int, action string) (*models.ScheduleOperation, error) {
+	job := &models.Job{}
+	if err := trashed(r.ormer, r.TableName).Filter("id", id).Filter("project_id", projectID).RelatedSel("SourceID", "DestID").One(job); err != nil {
+		return nil, fmt.Errorf("failed to restore job[%d]: %w", id, notInTrash(err))
+	}
+	if job.SourceID.DeletedAt != nil || job.DestID.DeletedAt != nil {
+		return nil, fmt.Errorf("failed to restore job[%d]: %w", id, ErrConnectorInTrash)
+	}
+	op, err := r.withScheduleOperation(job, action, func(txOrm orm.TxOrmer) error {
+		return restoreOne(trashed(txOrm, r.TableName).Filter("id", id))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore job[%d]: %w", id, err)
+	}
+	return op, nil
+}
+
 // GetBySourceID retrieves all jobs associated with a source ID
 func (r *JobORM) GetBySourceID(sourceID int) ([]*models.Job, error) {
 	var jobs []*models.Job
 	source := &models.Source{ID: sourceID}
 
-	_, err := r.ormer.QueryTable(r.TableName).
+	_, err := alive(r.ormer, r.TableName).
 		Filter("source_id", source).
 		RelatedSel().
 		All(&jobs)
@@ -199,7 +318,7 @@ func (r *JobORM) GetByDestinationID(destID int) ([]*models.Job, error) {
 	var jobs []*models.Job
 	dest := &models.Destination{ID: destID}
 
-	_, err := r.ormer.QueryTable(r.TableName).
+	_, err := alive(r.ormer, r.TableName).
 		Filter("dest_id", dest).
 		RelatedSel().
 		All(&jobs)
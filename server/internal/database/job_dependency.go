@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// JobDependencyORM handles database operations for job dependencies
+type JobDependencyORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewJobDependencyORM() *JobDependencyORM {
+	return &JobDependencyORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.JobDependencyTable],
+	}
+}
+
+// GetAllByProjectID retrieves all dependencies between jobs of a project that are not in the trash
+func (r *JobDependencyORM) GetAllByProjectID(projectID string) ([]*models.JobDependency, error) {
+	var dependencies []*models.JobDependency
+	_, err := r.betweenAliveJobs().Filter("project_id", projectID).OrderBy("id").All(&dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies for project ID %s: %s", projectID, err)
+	}
+	return dependencies, nil
+}
+
+// GetUpstreams retrieves the dependencies of a job on its upstream jobs
+func (r *JobDependencyORM) GetUpstreams(jobID int) ([]*models.JobDependency, error) {
+	var dependencies []*models.JobDependency
+	_, err := r.betweenAliveJobs().Filter("job_id", jobID).RelatedSel("UpstreamJobID").OrderBy("id").All(&dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upstream jobs of job[%d]: %s", jobID, err)
+	}
+	return dependencies, nil
+}
+
+// GetDownstreams retrieves the dependencies of other jobs on a job
+func (r *JobDependencyORM) GetDownstreams(jobID int) ([]*models.JobDependency, error) {
+	var dependencies []*models.JobDependency
+	_, err := r.betweenAliveJobs().Filter("upstream_job_id", jobID).RelatedSel("JobID").OrderBy("id").All(&dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get downstream jobs of job[%d]: %s", jobID, err)
+	}
+	return dependencies, nil
+}
+
+// betweenAliveJobs queries the dependencies whose jobs are not in the trash, the
+// dependencies of a trashed job are kept for a restore
+func (r *JobDependencyORM) betweenAliveJobs() orm.QuerySeter {
+	return alive(r.ormer, r.TableName).
+		Filter("job_id__deleted_at__isnull", true).
+		Filter("upstream_job_id__deleted_at__isnull", true)
+}
+
+// ReplaceUpstreams replaces all upstream dependencies of a job in a single transaction
+func (r *JobDependencyORM) ReplaceUpstreams(job *models.Job, dependencies []*models.JobDependency) error {
+	return r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		return replaceUpstreams(txOrm, job, dependencies)
+	})
+}
+
+// replaceUpstreams replaces the upstream dependencies of a job inside a transaction
+func replaceUpstreams(txOrm orm.TxOrmer, job *models.Job, dependencies []*models.JobDependency) error {
+	if _, err := alive(txOrm, constants.TableNameMap[constants.JobDependencyTable]).Filter("job_id", job.ID).Delete(); err != nil {
+		return fmt.Errorf("failed to delete upstream jobs of job[%d]: %s", job.ID, err)
+	}
+	for _, dependency := range dependencies {
+		dependency.JobID = job
+		dependency.ProjectID = job.ProjectID
+		if _, err := txOrm.Insert(dependency); err != nil {
+			return fmt.Errorf("failed to add upstream job[%d] to job[%d]: %s", dependency.UpstreamJobID.ID, job.ID, err)
+		}
+	}
+	return nil
+}
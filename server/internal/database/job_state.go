@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// ErrStateChanged is returned when the state of a job changed since the base revision of a new state
+var ErrStateChanged = errors.New("state of the job changed")
+
+// JobStateHistoryLimit is how many state revisions are kept per job, 0 keeps all of them
+var JobStateHistoryLimit int
+
+func init() {
+	JobStateHistoryLimit = web.AppConfig.DefaultInt("JOB_STATE_HISTORY_LIMIT", 100)
+}
+
+// JobStateHistoryORM handles database operations for job state revisions
+type JobStateHistoryORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewJobStateHistoryORM() *JobStateHistoryORM {
+	return &JobStateHistoryORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.JobStateHistoryTable],
+	}
+}
+
+// GetLatest returns the latest state revision of a job, or nil if none is recorded
+func (r *JobStateHistoryORM) GetLatest(jobID int) (*models.JobStateHistory, error) {
+	return r.latest(r.ormer, jobID)
+}
+
+func (r *JobStateHistoryORM) latest(ormer orm.QueryExecutor, jobID int) (*models.JobStateHistory, error) {
+	revision := &models.JobStateHistory{}
+	err := alive(ormer, r.TableName).Filter("job_id", jobID).RelatedSel("CreatedBy").OrderBy("-revision").Limit(1).One(revision)
+	if errors.Is(err, orm.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest state of job[%d]: %s", jobID, err)
+	}
+	return revision, nil
+}
+
+// LatestRevision returns the latest state revision number of a job, 0 if none is recorded
+func (r *JobStateHistoryORM) LatestRevision(jobID int) (int, error) {
+	latest, err := r.latest(r.ormer, jobID)
+	if err != nil || latest == nil {
+		return 0, err
+	}
+	return latest.Revision, nil
+}
+
+// stateChangedSince reports whether latest was recorded after the base revision
+// of revision by someone else: a user, or another sync run than revision's
+func stateChangedSince(latest, revision *models.JobStateHistory) bool {
+	if latest == nil || latest.Revision <= revision.BaseRevision {
+		return false
+	}
+	return revision.WorkflowID == "" || latest.WorkflowID != revision.WorkflowID
+}
+
+// numberStateRevision numbers revision, the next state of job after latest, the
+// latest recorded revision or nil. A revision of a run replacing the checkpoint of
+// the same run takes over its ID and number. It returns the baseline revision to
+// record first, keeping the state the job had before its first recorded change,
+// and ErrStateChanged when latest was recorded after the base revision of
+// revision by someone else.
+func numberStateRevision(job *models.Job, latest, revision *models.JobStateHistory) (*models.JobStateHistory, error) {
+	if stateChangedSince(latest, revision) {
+		return nil, fmt.Errorf("%w: revision %d was recorded after revision %d", ErrStateChanged, latest.Revision, revision.BaseRevision)
+	}
+	switch {
+	case latest != nil && latest.Reason == constants.StateChangeCheckpoint && revision.WorkflowID != "" && latest.WorkflowID == revision.WorkflowID:
+		// a run keeps a single revision, its latest checkpoint or its final state
+		revision.ID, revision.Revision = latest.ID, latest.Revision
+	case latest != nil:
+		revision.Revision = latest.Revision + 1
+	case job.State != "" && job.State != "{}" && job.State != revision.State:
+		revision.Revision = 2
+		return &models.JobStateHistory{JobID: job, Revision: 1, State: job.State, Reason: constants.StateChangeBaseline, Streams: "[]"}, nil
+	default:
+		revision.Revision = 1
+	}
+	return nil, nil
+}
+
+// prunedStateRevision returns the latest revision pruned once revision is
+// recorded, keeping limit revisions, 0 when none is pruned
+func prunedStateRevision(revision, limit int) int {
+	if limit <= 0 || revision <= limit {
+		return 0
+	}
+	return revision - limit
+}
+
+// SaveState sets the state of a job to the state of a new revision and records
+// the revision in a single transaction. The state the job had before its first
+// recorded change is kept as a baseline revision, a revision of a run replaces
+// the checkpoint of the same run. Only the latest JobStateHistoryLimit
+// revisions of a job are kept. The job row is locked for the transaction, the
+// state is not saved and ErrStateChanged is returned when a user or another run
+// recorded a revision after revision.BaseRevision.
+func (r *JobStateHistoryORM) SaveState(job *models.Job, revision *models.JobStateHistory) error {
+	revision.JobID = job
+	if revision.Streams == "" {
+		revision.Streams = "[]"
+	}
+
+	jobTable := constants.TableNameMap[constants.JobTable]
+	err := r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		// concurrent saves of the state of the job wait for this one
+		var lockedID int
+		err := txOrm.Raw(fmt.Sprintf(`SELECT id FROM %q WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, jobTable), job.ID).QueryRow(&lockedID)
+		if err != nil {
+			return fmt.Errorf("failed to lock job[%d]: %s", job.ID, err)
+		}
+		latest, err := r.latest(txOrm, job.ID)
+		if err != nil {
+			return err
+		}
+		baseline, err := numberStateRevision(job, latest, revision)
+		if err != nil {
+			return err
+		}
+		if baseline != nil {
+			if _, err := txOrm.Insert(baseline); err != nil {
+				return fmt.Errorf("failed to record baseline state of job[%d]: %s", job.ID, err)
+			}
+		}
+
+		_, err = alive(txOrm, jobTable).Filter("id", job.ID).Update(orm.Params{
+			"state":      revision.State,
+			"updated_at": time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update state of job[%d]: %s", job.ID, err)
+		}
+		if revision.ID != 0 {
+			if _, err := txOrm.Update(revision, "state", "reason", "updated_at"); err != nil {
+				return fmt.Errorf("failed to record state of job[%d]: %s", job.ID, err)
+			}
+			return nil
+		}
+		if _, err := txOrm.Insert(revision); err != nil {
+			return fmt.Errorf("failed to record state of job[%d]: %s", job.ID, err)
+		}
+		if pruned := prunedStateRevision(revision.Revision, JobStateHistoryLimit); pruned > 0 {
+			_, err := alive(txOrm, r.TableName).Filter("job_id", job.ID).Filter("revision__lte", pruned).Delete()
+			if err != nil {
+				return fmt.Errorf("failed to prune state history of job[%d]: %s", job.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	job.State = revision.State
+	return nil
+}
+
+// GetAllByJobID retrieves the latest state revisions of a job, newest first
+func (r *JobStateHistoryORM) GetAllByJobID(jobID, limit int) ([]*models.JobStateHistory, error) {
+	var revisions []*models.JobStateHistory
+	_, err := alive(r.ormer, r.TableName).Filter("job_id", jobID).RelatedSel("CreatedBy").OrderBy("-revision").Limit(limit).All(&revisions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state history of job[%d]: %s", jobID, err)
+	}
+	return revisions, nil
+}
+
+// GetByRevision retrieves a state revision of a job
+func (r *JobStateHistoryORM) GetByRevision(jobID, revision int) (*models.JobStateHistory, error) {
+	stateRevision := &models.JobStateHistory{}
+	err := alive(r.ormer, r.TableName).Filter("job_id", jobID).Filter("revision", revision).RelatedSel("CreatedBy").One(stateRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state revision %d of job[%d]: %s", revision, jobID, err)
+	}
+	return stateRevision, nil
+}
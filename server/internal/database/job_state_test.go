@@ -0,0 +1,105 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestStateChangedSince(t *testing.T) {
+	checkpoint := &models.JobStateHistory{Revision: 6, Reason: constants.StateChangeCheckpoint, WorkflowID: "sync-1"}
+	reset := &models.JobStateHistory{Revision: 6, Reason: constants.StateChangeReset}
+	cases := []struct {
+		name     string
+		latest   *models.JobStateHistory
+		revision *models.JobStateHistory
+		want     bool
+	}{
+		{"no revision yet", nil, &models.JobStateHistory{Reason: constants.StateChangeReplace}, false},
+		{"user edit of the latest revision", reset, &models.JobStateHistory{Reason: constants.StateChangeReplace, BaseRevision: 6}, false},
+		{"user edit after a checkpoint", checkpoint, &models.JobStateHistory{Reason: constants.StateChangeReset, BaseRevision: 5}, true},
+		{"user edit after another user edit", reset, &models.JobStateHistory{Reason: constants.StateChangeRestore, BaseRevision: 5}, true},
+		{"user edit on an empty history after a checkpoint", checkpoint, &models.JobStateHistory{Reason: constants.StateChangeReplace}, true},
+		{"sync after its own checkpoint", checkpoint, &models.JobStateHistory{Reason: constants.StateChangeSync, WorkflowID: "sync-1", BaseRevision: 5}, false},
+		{"sync after a reset", reset, &models.JobStateHistory{Reason: constants.StateChangeSync, WorkflowID: "sync-1", BaseRevision: 5}, true},
+		{"checkpoint after a reset", reset, &models.JobStateHistory{Reason: constants.StateChangeCheckpoint, WorkflowID: "sync-1", BaseRevision: 5}, true},
+		{"sync after a checkpoint of another run", checkpoint, &models.JobStateHistory{Reason: constants.StateChangeSync, WorkflowID: "sync-2", BaseRevision: 5}, true},
+	}
+	for _, tc := range cases {
+		if got := stateChangedSince(tc.latest, tc.revision); got != tc.want {
+			t.Errorf("%s: stateChangedSince = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNumberStateRevision(t *testing.T) {
+	job := &models.Job{ID: 1, State: `{"cursor": 5}`}
+	checkpoint := &models.JobStateHistory{ID: 40, Revision: 6, Reason: constants.StateChangeCheckpoint, WorkflowID: "sync-1"}
+	sync := &models.JobStateHistory{ID: 41, Revision: 7, Reason: constants.StateChangeSync, WorkflowID: "sync-1"}
+	cases := []struct {
+		name     string
+		job      *models.Job
+		latest   *models.JobStateHistory
+		revision *models.JobStateHistory
+		// want is the revision number and ID, baseline whether a baseline is recorded first
+		want     int
+		wantID   int
+		baseline bool
+		conflict bool
+	}{
+		{"first change keeps the previous state", job, nil, &models.JobStateHistory{State: "{}", Reason: constants.StateChangeReset}, 2, 0, true, false},
+		{"first change of an empty state", &models.Job{ID: 1, State: "{}"}, nil, &models.JobStateHistory{State: `{"cursor": 1}`, Reason: constants.StateChangeSync, WorkflowID: "sync-1"}, 1, 0, false, false},
+		{"first change to the same state", job, nil, &models.JobStateHistory{State: job.State, Reason: constants.StateChangeReplace}, 1, 0, false, false},
+		{"final state of a run replaces its checkpoint", job, checkpoint, &models.JobStateHistory{Reason: constants.StateChangeSync, WorkflowID: "sync-1", BaseRevision: 5}, 6, 40, false, false},
+		{"next run after a sync", job, sync, &models.JobStateHistory{Reason: constants.StateChangeCheckpoint, WorkflowID: "sync-2", BaseRevision: 7}, 8, 0, false, false},
+		{"restore of the latest revision", job, sync, &models.JobStateHistory{Reason: constants.StateChangeRestore, RestoredFrom: 3, BaseRevision: 7}, 8, 0, false, false},
+		// the restore read revision 6 as its base, a sync saved revision 7 meanwhile
+		{"restore under a concurrent sync", job, sync, &models.JobStateHistory{Reason: constants.StateChangeRestore, RestoredFrom: 3, BaseRevision: 6}, 0, 0, false, true},
+		{"replace under a concurrent checkpoint", job, checkpoint, &models.JobStateHistory{Reason: constants.StateChangeReplace, BaseRevision: 5}, 0, 0, false, true},
+		{"sync after a reset", job, &models.JobStateHistory{Revision: 8, Reason: constants.StateChangeReset}, &models.JobStateHistory{Reason: constants.StateChangeSync, WorkflowID: "sync-2", BaseRevision: 7}, 0, 0, false, true},
+	}
+	for _, tc := range cases {
+		baseline, err := numberStateRevision(tc.job, tc.latest, tc.revision)
+		if tc.conflict {
+			if !errors.Is(err, ErrStateChanged) {
+				t.Errorf("%s: error = %v, want ErrStateChanged", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if tc.revision.Revision != tc.want || tc.revision.ID != tc.wantID {
+			t.Errorf("%s: revision %d with ID %d, want %d with ID %d", tc.name, tc.revision.Revision, tc.revision.ID, tc.want, tc.wantID)
+		}
+		if (baseline != nil) != tc.baseline {
+			t.Errorf("%s: baseline = %+v, want one recorded: %t", tc.name, baseline, tc.baseline)
+		}
+		if baseline != nil && (baseline.Revision != 1 || baseline.State != tc.job.State || baseline.Reason != constants.StateChangeBaseline) {
+			t.Errorf("%s: baseline = %+v, want revision 1 with the previous state", tc.name, baseline)
+		}
+	}
+}
+
+func TestPrunedStateRevision(t *testing.T) {
+	cases := []struct {
+		revision, limit, want int
+	}{
+		{1, 100, 0},
+		{100, 100, 0},
+		// the 101st revision prunes the first one
+		{101, 100, 1},
+		{250, 100, 150},
+		{3, 1, 2},
+		// 0 keeps every revision
+		{500, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := prunedStateRevision(tc.revision, tc.limit); got != tc.want {
+			t.Errorf("prunedStateRevision(%d, %d) = %d, want %d", tc.revision, tc.limit, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// JobTemplateORM handles database operations for job templates
+type JobTemplateORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewJobTemplateORM() *JobTemplateORM {
+	return &JobTemplateORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.JobTemplateTable],
+	}
+}
+
+func (r *JobTemplateORM) Create(template *models.JobTemplate) error {
+	spec := template.Spec
+	if err := r.encryptSpec(template); err != nil {
+		return err
+	}
+	_, err := r.ormer.Insert(template)
+	template.Spec = spec
+	return err
+}
+
+// GetAllByProjectID retrieves the templates of a project with their specs decrypted
+func (r *JobTemplateORM) GetAllByProjectID(projectID string) ([]*models.JobTemplate, error) {
+	var templates []*models.JobTemplate
+	_, err := alive(r.ormer, r.TableName).Filter("project_id", projectID).RelatedSel().OrderBy("id").All(&templates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job templates for project ID %s: %s", projectID, err)
+	}
+	for _, template := range templates {
+		if err := r.decryptSpec(template); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// GetByID retrieves a template with its spec decrypted
+func (r *JobTemplateORM) GetByID(id int) (*models.JobTemplate, error) {
+	template := &models.JobTemplate{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).RelatedSel().One(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job template by id[%d]: %s", id, err)
+	}
+	if err := r.decryptSpec(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *JobTemplateORM) Update(template *models.JobTemplate) error {
+	spec := template.Spec
+	if err := r.encryptSpec(template); err != nil {
+		return err
+	}
+	template.UpdatedAt = time.Now()
+	_, err := r.ormer.Update(template)
+	template.Spec = spec
+	return err
+}
+
+// Delete moves a template to the trash
+func (r *JobTemplateORM) Delete(id int) error {
+	return trashOne(r.ormer, r.TableName, id, time.Now())
+}
+
+// Restore takes a template of a project out of the trash
+func (r *JobTemplateORM) Restore(projectID string, id int) error {
+	return restoreOne(trashed(r.ormer, r.TableName).Filter("id", id).Filter("project_id", projectID))
+}
+
+func (r *JobTemplateORM) encryptSpec(template *models.JobTemplate) error {
+	eSpec, err := utils.Encrypt(template.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt job template spec: %s", err)
+	}
+	template.Spec = eSpec
+	return nil
+}
+
+func (r *JobTemplateORM) decryptSpec(template *models.JobTemplate) error {
+	dSpec, err := utils.Decrypt(template.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt job template spec by id[%d]: %s", template.ID, err)
+	}
+	template.Spec = dSpec
+	return nil
+}
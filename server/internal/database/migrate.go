@@ -0,0 +1,309 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+)
+
+// migrationFiles holds the migrations, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Table names use $$ for the run mode.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationLockID is the Postgres advisory lock held while migrating, so
+// replicas starting together apply every migration once
+const migrationLockID = 4242_0001
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration with the time it was applied, nil when pending
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	// Unknown is set for applied migrations this build does not ship, i.e. the database was migrated by a newer build
+	Unknown bool
+}
+
+// LoadMigrations returns the migrations in version order
+func LoadMigrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, web.BConfig.RunMode)
+}
+
+func loadMigrations(files fs.FS, mode string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %s", err)
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named <version>_<name>.(up|down).sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		data, err := fs.ReadFile(files, "migrations/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %s", entry.Name(), err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		query := strings.ReplaceAll(string(data), "$$", mode)
+		if match[3] == "up" {
+			migration.Up = query
+		} else {
+			migration.Down = query
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and rolls back migrations on the default database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	table      string
+}
+
+func NewMigrator() (*Migrator, error) {
+	db, err := orm.GetDB("default")
+	if err != nil {
+		return nil, fmt.Errorf("database is not initialized: %s", err)
+	}
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, table: constants.TableNameMap[constants.MigrationTable]}, nil
+}
+
+// Latest returns the version of the newest migration of this build
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists every migration of this build and the applied ones it does not know
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if record, ok := applied[migration.Version]; ok {
+				status.AppliedAt = record.AppliedAt
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, record := range applied {
+			statuses = append(statuses, record)
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
+
+// Up applies every pending migration and returns them. Migrations applied by
+// a newer build are left alone, its schema changes are expected to be additive.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.migrate(ctx, m.Latest(), false)
+}
+
+// Down rolls back the last steps applied migrations and returns them
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, record := range applied {
+			if record.Unknown {
+				return fmt.Errorf("migration %d_%s was applied by a newer build and can only be rolled back by it", record.Version, record.Name)
+			}
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.run(ctx, conn, m.migrations[i], false); err != nil {
+				return err
+			}
+			done = append(done, m.migrations[i])
+		}
+		return nil
+	})
+	return done, err
+}
+
+// To applies the pending migrations up to version and rolls back the applied
+// ones after it, returning the migrations it ran in the order it ran them
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	return m.migrate(ctx, version, true)
+}
+
+func (m *Migrator) migrate(ctx context.Context, version int, rollback bool) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, record := range applied {
+			if !record.Unknown || record.Version <= version {
+				continue
+			}
+			if rollback {
+				return fmt.Errorf("migration %d_%s was applied by a newer build and can only be rolled back by it", record.Version, record.Name)
+			}
+			logs.Warning("Migration %d_%s was applied by a newer build", record.Version, record.Name)
+		}
+		// roll back newest first, then apply oldest first
+		for i := len(m.migrations) - 1; i >= 0 && rollback; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok && m.migrations[i].Version > version {
+				if err := m.run(ctx, conn, m.migrations[i], false); err != nil {
+					return err
+				}
+				done = append(done, m.migrations[i])
+			}
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.run(ctx, conn, migration, true); err != nil {
+					return err
+				}
+				done = append(done, migration)
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// locked runs fn on a connection holding the migration lock, after creating the tracking table
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %s", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %s", err)
+	}
+	defer func() {
+		// the lock goes with the session if unlocking fails
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			logs.Error("Failed to release migration lock: %s", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q (
+    "version" integer PRIMARY KEY,
+    "name" text NOT NULL,
+    "applied_at" timestamp with time zone NOT NULL DEFAULT now()
+)`, m.table))
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %s", err)
+	}
+	return fn(conn)
+}
+
+// applied returns the applied migrations by version, marking the ones this build does not ship
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]MigrationStatus, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(`SELECT "version", "name", "applied_at" FROM %q`, m.table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %s", err)
+	}
+	defer rows.Close()
+
+	known := map[int]bool{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+	applied := map[int]MigrationStatus{}
+	for rows.Next() {
+		var status MigrationStatus
+		var appliedAt time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %s", err)
+		}
+		status.AppliedAt = &appliedAt
+		status.Unknown = !known[status.Version]
+		applied[status.Version] = status
+	}
+	return applied, rows.Err()
+}
+
+// run applies or rolls back a migration and records it in one transaction
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	direction, query := "down", migration.Down
+	record, args := fmt.Sprintf(`DELETE FROM %q WHERE "version" = $1`, m.table), []interface{}{migration.Version}
+	if up {
+		direction, query = "up", migration.Up
+		record, args = fmt.Sprintf(`INSERT INTO %q ("version", "name") VALUES ($1, $2)`, m.table), []interface{}{migration.Version, migration.Name}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d_%s: %s", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("migration %d_%s %s failed: %s", migration.Version, migration.Name, direction, err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to record migration %d_%s: %s", migration.Version, migration.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %s", migration.Version, migration.Name, err)
+	}
+	logs.Info("Migrated %s: %d_%s", direction, migration.Version, migration.Name)
+	return nil
+}
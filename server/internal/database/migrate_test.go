@@ -0,0 +1,170 @@
+package database
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+)
+
+var (
+	createTable = regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS "([^"]+)" \((.*?)\n\);`)
+	tableColumn = regexp.MustCompile(`(?m)^\s+"(\w+)"`)
+	addColumn   = regexp.MustCompile(`ALTER TABLE "([^"]+)" ADD COLUMN (?:IF NOT EXISTS )?"(\w+)"`)
+	dropColumn  = regexp.MustCompile(`ALTER TABLE "([^"]+)" DROP COLUMN (?:IF EXISTS )?"(\w+)"`)
+	columnTag   = regexp.MustCompile(`column\((\w+)\)`)
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d_%s, want version %d, versions must have no gaps", migration.Version, migration.Name, i+1)
+		}
+		if strings.Contains(migration.Up, "$$") || strings.Contains(migration.Down, "$$") {
+			t.Errorf("migration %d_%s still holds $$", migration.Version, migration.Name)
+		}
+	}
+}
+
+// TestMigrationsMatchModels fails when a model gains or loses a column without
+// a migration doing the same, since the ORM no longer syncs the schema
+func TestMigrationsMatchModels(t *testing.T) {
+	constants.InitTableNames("$$")
+	migrations, err := loadMigrations(migrationFiles, "$$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := schemaColumns{}
+	for _, migration := range migrations {
+		schema.apply(migration.Up)
+	}
+	checkModelColumns(t, schema)
+}
+
+// TestMigrationsUpgradeReleasedSchema migrates the schema RunSyncdb created at
+// the last release before migrations, whose tables the baseline only adopts
+func TestMigrationsUpgradeReleasedSchema(t *testing.T) {
+	constants.InitTableNames("$$")
+	migrations, err := loadMigrations(migrationFiles, "$$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	released, err := os.ReadFile("testdata/released_schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releasedSchema := schemaColumns{}
+	releasedSchema.apply(string(released))
+	baseline := schemaColumns{}
+	baseline.apply(migrations[0].Up)
+	if !reflect.DeepEqual(baseline, releasedSchema) {
+		t.Errorf("migration %d_%s creates %v, want the released schema %v", migrations[0].Version, migrations[0].Name, baseline, releasedSchema)
+	}
+
+	for _, migration := range migrations {
+		releasedSchema.apply(migration.Up)
+	}
+	checkModelColumns(t, releasedSchema)
+}
+
+// schemaColumns holds the columns of every table by table name
+type schemaColumns map[string]map[string]bool
+
+// apply applies the tables and columns statements of a migration the way
+// Postgres does, IF NOT EXISTS skips tables that already exist
+func (s schemaColumns) apply(query string) {
+	for _, match := range createTable.FindAllStringSubmatch(query, -1) {
+		if _, ok := s[match[1]]; ok {
+			continue
+		}
+		s[match[1]] = map[string]bool{}
+		for _, column := range tableColumn.FindAllStringSubmatch(match[2], -1) {
+			s[match[1]][column[1]] = true
+		}
+	}
+	for _, match := range addColumn.FindAllStringSubmatch(query, -1) {
+		s[match[1]][match[2]] = true
+	}
+	for _, match := range dropColumn.FindAllStringSubmatch(query, -1) {
+		delete(s[match[1]], match[2])
+	}
+}
+
+// checkModelColumns fails for every column the models and the schema disagree on
+func checkModelColumns(t *testing.T, schema schemaColumns) {
+	t.Helper()
+	for _, model := range registeredModels() {
+		table := model.(interface{ TableName() string }).TableName()
+		columns, ok := schema[table]
+		if !ok {
+			t.Errorf("no migration creates table %s", table)
+			continue
+		}
+		modelColumns := map[string]bool{}
+		for _, column := range ormColumns(reflect.TypeOf(model).Elem()) {
+			modelColumns[column] = true
+			if !columns[column] {
+				t.Errorf("%s.%s is in the model but not in the migrations", table, column)
+			}
+		}
+		var extra []string
+		for column := range columns {
+			if !modelColumns[column] {
+				extra = append(extra, column)
+			}
+		}
+		sort.Strings(extra)
+		for _, column := range extra {
+			t.Errorf("%s.%s is in the migrations but not in the model", table, column)
+		}
+	}
+}
+
+// ormColumns returns the column names the ORM maps the fields of a model to
+func ormColumns(t reflect.Type) []string {
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("orm")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		if field.Anonymous && strings.Contains(tag, "embedded") {
+			columns = append(columns, ormColumns(field.Type)...)
+			continue
+		}
+		if strings.Contains(tag, "reverse(") || strings.Contains(tag, "rel(m2m)") {
+			continue
+		}
+		if match := columnTag.FindStringSubmatch(tag); match != nil {
+			columns = append(columns, match[1])
+			continue
+		}
+		column := snakeString(field.Name)
+		if strings.Contains(tag, "rel(fk)") || strings.Contains(tag, "rel(one)") {
+			column += "_id"
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// snakeString is the default column naming of the ORM, XxYy to xx_yy
+func snakeString(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
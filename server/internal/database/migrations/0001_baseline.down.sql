@@ -0,0 +1,8 @@
+-- Drops every table of the baseline with all of its data. The session table is
+-- shared by every run mode and stays.
+
+DROP TABLE IF EXISTS "olake-$$-catalog";
+DROP TABLE IF EXISTS "olake-$$-user";
+DROP TABLE IF EXISTS "olake-$$-job";
+DROP TABLE IF EXISTS "olake-$$-destination";
+DROP TABLE IF EXISTS "olake-$$-source";
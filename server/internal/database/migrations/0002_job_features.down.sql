@@ -0,0 +1,15 @@
+-- Drops the tables and columns of the features added after the baseline with
+-- all of their data.
+
+DROP TABLE IF EXISTS "olake-$$-webhook-delivery";
+DROP TABLE IF EXISTS "olake-$$-webhook";
+DROP TABLE IF EXISTS "olake-$$-job-notification";
+DROP TABLE IF EXISTS "olake-$$-notification-channel";
+DROP TABLE IF EXISTS "olake-$$-job-template";
+DROP TABLE IF EXISTS "olake-$$-job-state-history";
+DROP TABLE IF EXISTS "olake-$$-job-dependency";
+DROP TABLE IF EXISTS "olake-$$-project-settings";
+ALTER TABLE "olake-$$-job" DROP COLUMN IF EXISTS "retry_policy";
+ALTER TABLE "olake-$$-job" DROP COLUMN IF EXISTS "schedule_config";
+ALTER TABLE "olake-$$-destination" DROP COLUMN IF EXISTS "max_concurrent_syncs";
+ALTER TABLE "olake-$$-source" DROP COLUMN IF EXISTS "max_concurrent_syncs";
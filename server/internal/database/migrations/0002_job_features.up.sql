@@ -0,0 +1,128 @@
+-- The columns and tables of the features added after the release of the
+-- baseline: schedules, retry policies, concurrency limits, dependencies, state
+-- history, templates, notifications and webhooks. Databases synced by
+-- orm.RunSyncdb after that release may already hold some of them, so every
+-- statement is idempotent.
+
+ALTER TABLE "olake-$$-source" ADD COLUMN IF NOT EXISTS "max_concurrent_syncs" integer NOT NULL DEFAULT 0;
+ALTER TABLE "olake-$$-destination" ADD COLUMN IF NOT EXISTS "max_concurrent_syncs" integer NOT NULL DEFAULT 0;
+ALTER TABLE "olake-$$-job" ADD COLUMN IF NOT EXISTS "schedule_config" jsonb;
+ALTER TABLE "olake-$$-job" ADD COLUMN IF NOT EXISTS "retry_policy" jsonb;
+
+CREATE TABLE IF NOT EXISTS "olake-$$-project-settings" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "project_id" text NOT NULL DEFAULT '' UNIQUE,
+    "blackout_windows" jsonb,
+    "max_concurrent_syncs" integer NOT NULL DEFAULT 0,
+    "updated_by_id" integer
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-job-dependency" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "project_id" text NOT NULL DEFAULT '',
+    "job_id" integer NOT NULL,
+    "upstream_job_id" integer NOT NULL,
+    "mode" varchar(20) NOT NULL DEFAULT '',
+   UNIQUE ("job_id", "upstream_job_id")
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-job-state-history" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "job_id" integer NOT NULL,
+    "revision" integer NOT NULL DEFAULT 0,
+    "state" jsonb NOT NULL DEFAULT '{}',
+    "reason" varchar(20) NOT NULL DEFAULT '',
+    "streams" jsonb,
+    "workflow_id" varchar(255),
+    "restored_from" integer NOT NULL DEFAULT 0,
+    "created_by_id" integer,
+   UNIQUE ("job_id", "revision")
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-job-template" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "name" varchar(100) NOT NULL DEFAULT '',
+    "project_id" text NOT NULL DEFAULT '',
+    "spec" jsonb NOT NULL DEFAULT '{}',
+    "variables" jsonb NOT NULL DEFAULT '{}',
+    "created_by_id" integer,
+    "updated_by_id" integer
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-notification-channel" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "name" varchar(100) NOT NULL DEFAULT '',
+    "project_id" text NOT NULL DEFAULT '',
+    "type" varchar(20) NOT NULL DEFAULT '',
+    "config" jsonb NOT NULL DEFAULT '{}',
+    "created_by_id" integer,
+    "updated_by_id" integer
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-job-notification" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "project_id" text NOT NULL DEFAULT '',
+    "job_id" integer NOT NULL,
+    "channel_id" integer NOT NULL,
+    "events" jsonb NOT NULL DEFAULT '{}',
+    "run_longer_than" varchar(20),
+    "no_success_within" varchar(20),
+    "last_status" varchar(20),
+    "last_success_at" timestamp with time zone,
+    "long_run_workflow_id" varchar(255),
+    "no_success_notified_at" timestamp with time zone,
+    "schema_drift" varchar(64),
+   UNIQUE ("job_id", "channel_id")
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-webhook" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "name" varchar(100) NOT NULL DEFAULT '',
+    "project_id" text NOT NULL DEFAULT '',
+    "url" text NOT NULL,
+    "secret" text NOT NULL,
+    "events" jsonb NOT NULL DEFAULT '{}',
+    "active" bool NOT NULL DEFAULT FALSE,
+    "created_by_id" integer,
+    "updated_by_id" integer
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-webhook-delivery" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "project_id" text NOT NULL DEFAULT '',
+    "webhook_id" integer NOT NULL,
+    "event_id" varchar(255) NOT NULL DEFAULT '',
+    "event" varchar(50) NOT NULL DEFAULT '',
+    "payload" jsonb NOT NULL DEFAULT '{}',
+    "status" varchar(20) NOT NULL DEFAULT '',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "response_status" integer NOT NULL DEFAULT 0,
+    "last_error" text,
+    "last_attempt_at" timestamp with time zone,
+    "delivered_at" timestamp with time zone,
+   UNIQUE ("webhook_id", "event_id")
+);
@@ -0,0 +1,16 @@
+ALTER TABLE "olake-$$-webhook-delivery" DROP CONSTRAINT IF EXISTS "olake-$$-webhook-delivery_webhook_id_fkey";
+ALTER TABLE "olake-$$-job-notification" DROP CONSTRAINT IF EXISTS "olake-$$-job-notification_channel_id_fkey";
+ALTER TABLE "olake-$$-job-notification" DROP CONSTRAINT IF EXISTS "olake-$$-job-notification_job_id_fkey";
+ALTER TABLE "olake-$$-job-state-history" DROP CONSTRAINT IF EXISTS "olake-$$-job-state-history_job_id_fkey";
+ALTER TABLE "olake-$$-job-dependency" DROP CONSTRAINT IF EXISTS "olake-$$-job-dependency_upstream_job_id_fkey";
+ALTER TABLE "olake-$$-job-dependency" DROP CONSTRAINT IF EXISTS "olake-$$-job-dependency_job_id_fkey";
+
+DROP INDEX IF EXISTS "olake-$$-webhook-delivery_project_id_idx";
+DROP INDEX IF EXISTS "olake-$$-webhook_project_id_idx";
+DROP INDEX IF EXISTS "olake-$$-job-notification_channel_id_idx";
+DROP INDEX IF EXISTS "olake-$$-job-dependency_upstream_job_id_idx";
+DROP INDEX IF EXISTS "olake-$$-job_dest_id_idx";
+DROP INDEX IF EXISTS "olake-$$-job_source_id_idx";
+DROP INDEX IF EXISTS "olake-$$-job_project_id_idx";
+DROP INDEX IF EXISTS "olake-$$-destination_project_id_idx";
+DROP INDEX IF EXISTS "olake-$$-source_project_id_idx";
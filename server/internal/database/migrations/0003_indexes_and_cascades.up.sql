@@ -0,0 +1,33 @@
+-- Indexes for the lookups of every request, which are scoped by project or by
+-- the connectors of a job
+CREATE INDEX IF NOT EXISTS "olake-$$-source_project_id_idx" ON "olake-$$-source" ("project_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-destination_project_id_idx" ON "olake-$$-destination" ("project_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-job_project_id_idx" ON "olake-$$-job" ("project_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-job_source_id_idx" ON "olake-$$-job" ("source_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-job_dest_id_idx" ON "olake-$$-job" ("dest_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-job-dependency_upstream_job_id_idx" ON "olake-$$-job-dependency" ("upstream_job_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-job-notification_channel_id_idx" ON "olake-$$-job-notification" ("channel_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-webhook_project_id_idx" ON "olake-$$-webhook" ("project_id");
+CREATE INDEX IF NOT EXISTS "olake-$$-webhook-delivery_project_id_idx" ON "olake-$$-webhook-delivery" ("project_id", "id");
+
+-- Rows that only exist for a job, a channel or a webhook go with it. The ORM
+-- cascades these deletes too, the constraints cover deletes made outside it.
+-- NOT VALID skips checking rows orphaned before the constraints existed.
+ALTER TABLE "olake-$$-job-dependency"
+    ADD CONSTRAINT "olake-$$-job-dependency_job_id_fkey" FOREIGN KEY ("job_id")
+    REFERENCES "olake-$$-job" ("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "olake-$$-job-dependency"
+    ADD CONSTRAINT "olake-$$-job-dependency_upstream_job_id_fkey" FOREIGN KEY ("upstream_job_id")
+    REFERENCES "olake-$$-job" ("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "olake-$$-job-state-history"
+    ADD CONSTRAINT "olake-$$-job-state-history_job_id_fkey" FOREIGN KEY ("job_id")
+    REFERENCES "olake-$$-job" ("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "olake-$$-job-notification"
+    ADD CONSTRAINT "olake-$$-job-notification_job_id_fkey" FOREIGN KEY ("job_id")
+    REFERENCES "olake-$$-job" ("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "olake-$$-job-notification"
+    ADD CONSTRAINT "olake-$$-job-notification_channel_id_fkey" FOREIGN KEY ("channel_id")
+    REFERENCES "olake-$$-notification-channel" ("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "olake-$$-webhook-delivery"
+    ADD CONSTRAINT "olake-$$-webhook-delivery_webhook_id_fkey" FOREIGN KEY ("webhook_id")
+    REFERENCES "olake-$$-webhook" ("id") ON DELETE CASCADE NOT VALID;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "olake-$$-user_deleted_at_idx";
+DROP INDEX IF EXISTS "olake-$$-webhook_deleted_at_idx";
+DROP INDEX IF EXISTS "olake-$$-notification-channel_deleted_at_idx";
+DROP INDEX IF EXISTS "olake-$$-job-template_deleted_at_idx";
+DROP INDEX IF EXISTS "olake-$$-job_deleted_at_idx";
+DROP INDEX IF EXISTS "olake-$$-destination_deleted_at_idx";
+DROP INDEX IF EXISTS "olake-$$-source_deleted_at_idx";
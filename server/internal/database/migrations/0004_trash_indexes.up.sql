@@ -0,0 +1,9 @@
+-- Partial indexes on the trash, used by the trash listing and the retention
+-- purge without growing with the rows that are alive
+CREATE INDEX IF NOT EXISTS "olake-$$-source_deleted_at_idx" ON "olake-$$-source" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "olake-$$-destination_deleted_at_idx" ON "olake-$$-destination" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "olake-$$-job_deleted_at_idx" ON "olake-$$-job" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "olake-$$-job-template_deleted_at_idx" ON "olake-$$-job-template" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "olake-$$-notification-channel_deleted_at_idx" ON "olake-$$-notification-channel" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "olake-$$-webhook_deleted_at_idx" ON "olake-$$-webhook" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "olake-$$-user_deleted_at_idx" ON "olake-$$-user" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
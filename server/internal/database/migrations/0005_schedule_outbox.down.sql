@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "olake-$$-schedule-outbox";
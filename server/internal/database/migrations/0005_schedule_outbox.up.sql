@@ -0,0 +1,17 @@
+-- Changes of job schedules are recorded here in the transaction that changes
+-- the job and applied to Temporal afterwards, operations that fail are retried
+-- by the schedule outbox workflow of the worker
+CREATE TABLE IF NOT EXISTS "olake-$$-schedule-outbox" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "project_id" text NOT NULL DEFAULT '',
+    "job_id" integer NOT NULL DEFAULT 0,
+    "action" varchar(20) NOT NULL DEFAULT '',
+    "status" varchar(20) NOT NULL DEFAULT '',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_error" text,
+    "processed_at" timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS "olake-$$-schedule-outbox_pending_idx" ON "olake-$$-schedule-outbox" ("id") WHERE "status" = 'pending';
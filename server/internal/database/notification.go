@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// NotificationChannelORM handles database operations for notification channels
+type NotificationChannelORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewNotificationChannelORM() *NotificationChannelORM {
+	return &NotificationChannelORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.NotificationChannelTable],
+	}
+}
+
+func (r *NotificationChannelORM) Create(channel *models.NotificationChannel) error {
+	config := channel.Config
+	if err := encryptChannelConfig(channel); err != nil {
+		return err
+	}
+	_, err := r.ormer.Insert(channel)
+	channel.Config = config
+	return err
+}
+
+// GetAllByProjectID retrieves the channels of a project with their configs decrypted
+func (r *NotificationChannelORM) GetAllByProjectID(projectID string) ([]*models.NotificationChannel, error) {
+	var channels []*models.NotificationChannel
+	_, err := alive(r.ormer, r.TableName).Filter("project_id", projectID).RelatedSel().OrderBy("id").All(&channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channels for project ID %s: %s", projectID, err)
+	}
+	for _, channel := range channels {
+		if err := decryptChannelConfig(channel); err != nil {
+			return nil, err
+		}
+	}
+	return channels, nil
+}
+
+// GetByID retrieves a channel with its config decrypted
+func (r *NotificationChannelORM) GetByID(id int) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).RelatedSel().One(channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channel by id[%d]: %s", id, err)
+	}
+	if err := decryptChannelConfig(channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (r *NotificationChannelORM) Update(channel *models.NotificationChannel) error {
+	config := channel.Config
+	if err := encryptChannelConfig(channel); err != nil {
+		return err
+	}
+	channel.UpdatedAt = time.Now()
+	_, err := r.ormer.Update(channel)
+	channel.Config = config
+	return err
+}
+
+// Delete moves a channel to the trash, the job subscriptions to it are kept
+// for a restore but no longer notified
+func (r *NotificationChannelORM) Delete(id int) error {
+	if err := trashOne(r.ormer, r.TableName, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete notification channel[%d]: %s", id, err)
+	}
+	return nil
+}
+
+// Restore takes a channel of a project out of the trash
+func (r *NotificationChannelORM) Restore(projectID string, id int) error {
+	return restoreOne(trashed(r.ormer, r.TableName).Filter("id", id).Filter("project_id", projectID))
+}
+
+func encryptChannelConfig(channel *models.NotificationChannel) error {
+	eConfig, err := utils.Encrypt(channel.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt notification channel config: %s", err)
+	}
+	channel.Config = eConfig
+	return nil
+}
+
+func decryptChannelConfig(channel *models.NotificationChannel) error {
+	dConfig, err := utils.Decrypt(channel.Config)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt notification channel config by id[%d]: %s", channel.ID, err)
+	}
+	channel.Config = dConfig
+	return nil
+}
+
+// JobNotificationORM handles database operations for the notification subscriptions of jobs
+type JobNotificationORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewJobNotificationORM() *JobNotificationORM {
+	return &JobNotificationORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.JobNotificationTable],
+	}
+}
+
+// GetByJobID retrieves the subscriptions of a job to channels that are not in the trash, their channels are not loaded
+func (r *JobNotificationORM) GetByJobID(jobID int) ([]*models.JobNotification, error) {
+	var subscriptions []*models.JobNotification
+	_, err := alive(r.ormer, r.TableName).Filter("job_id", jobID).Filter("channel_id__deleted_at__isnull", true).OrderBy("id").All(&subscriptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification subscriptions of job[%d]: %s", jobID, err)
+	}
+	return subscriptions, nil
+}
+
+// GetAll retrieves the subscriptions of every job together with their jobs, skipping jobs and channels in the trash
+func (r *JobNotificationORM) GetAll() ([]*models.JobNotification, error) {
+	var subscriptions []*models.JobNotification
+	_, err := alive(r.ormer, r.TableName).
+		Filter("job_id__deleted_at__isnull", true).
+		Filter("channel_id__deleted_at__isnull", true).
+		RelatedSel("JobID").OrderBy("id").All(&subscriptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification subscriptions: %s", err)
+	}
+	return subscriptions, nil
+}
+
+// ReplaceForJob replaces the subscriptions of a job in a single transaction,
+// subscriptions of channels that stay subscribed keep what they notified
+func (r *JobNotificationORM) ReplaceForJob(job *models.Job, subscriptions []*models.JobNotification) error {
+	return r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		var existing []*models.JobNotification
+		if _, err := alive(txOrm, r.TableName).Filter("job_id", job.ID).All(&existing); err != nil {
+			return fmt.Errorf("failed to get notification subscriptions of job[%d]: %s", job.ID, err)
+		}
+		byChannel := map[int]*models.JobNotification{}
+		for _, subscription := range existing {
+			byChannel[subscription.ChannelID.ID] = subscription
+		}
+
+		for _, subscription := range subscriptions {
+			current, ok := byChannel[subscription.ChannelID.ID]
+			if !ok {
+				subscription.JobID = job
+				subscription.ProjectID = job.ProjectID
+				if _, err := txOrm.Insert(subscription); err != nil {
+					return fmt.Errorf("failed to subscribe notification channel[%d] to job[%d]: %s", subscription.ChannelID.ID, job.ID, err)
+				}
+				continue
+			}
+			delete(byChannel, subscription.ChannelID.ID)
+			current.Events = subscription.Events
+			current.RunLongerThan = subscription.RunLongerThan
+			current.NoSuccessWithin = subscription.NoSuccessWithin
+			current.UpdatedAt = time.Now()
+			if _, err := txOrm.Update(current, "Events", "RunLongerThan", "NoSuccessWithin", "UpdatedAt"); err != nil {
+				return fmt.Errorf("failed to update notification channel[%d] of job[%d]: %s", current.ChannelID.ID, job.ID, err)
+			}
+		}
+		for _, stale := range byChannel {
+			if _, err := txOrm.Delete(stale); err != nil {
+				return fmt.Errorf("failed to unsubscribe notification channel[%d] from job[%d]: %s", stale.ChannelID.ID, job.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateTracking saves what subscriptions have notified in a single transaction
+func (r *JobNotificationORM) UpdateTracking(subscriptions ...*models.JobNotification) error {
+	return r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		for _, subscription := range subscriptions {
+			_, err := txOrm.Update(subscription, "LastStatus", "LastSuccessAt", "LongRunWorkflowID", "NoSuccessNotifiedAt", "SchemaDrift")
+			if err != nil {
+				return fmt.Errorf("failed to update notification subscription[%d]: %s", subscription.ID, err)
+			}
+		}
+		return nil
+	})
+}
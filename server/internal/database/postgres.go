@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"encoding/gob"
 	"fmt"
 
@@ -13,7 +14,27 @@ import (
 	"github.com/datazip/olake-frontend/server/internal/models"
 )
 
+// Init connects to the database and migrates it to the latest schema, unless
+// automigrate is turned off in the config
 func Init(uri string) error {
+	if err := Open(uri); err != nil {
+		return err
+	}
+	if !web.AppConfig.DefaultBool("automigrate", true) {
+		return nil
+	}
+	migrator, err := NewMigrator()
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		return fmt.Errorf("failed to migrate database: %s", err)
+	}
+	return nil
+}
+
+// Open connects to the database and registers the models without touching the schema
+func Open(uri string) error {
 	// register driver
 	err := orm.RegisterDriver("postgres", orm.DRPostgres)
 	if err != nil {
@@ -36,31 +57,27 @@ func Init(uri string) error {
 
 	// register session user
 	gob.Register(constants.SessionUserID)
-	// register models in order of dependency or foreign key constraints
-	orm.RegisterModel(
+	// the schema of the models is managed by the migrations
+	orm.RegisterModel(registeredModels()...)
+	return nil
+}
+
+// registeredModels lists the models in order of dependency or foreign key constraints
+func registeredModels() []interface{} {
+	return []interface{}{
 		new(models.Source),
 		new(models.Destination),
 		new(models.Job),
 		new(models.User),
 		new(models.Catalog),
-	)
-
-	// Create tables if they do not exist
-	err = orm.RunSyncdb("default", false, true)
-	if err != nil {
-		return fmt.Errorf("failed to sync database schema: %s", err)
+		new(models.ProjectSettings),
+		new(models.JobDependency),
+		new(models.JobStateHistory),
+		new(models.JobTemplate),
+		new(models.NotificationChannel),
+		new(models.JobNotification),
+		new(models.Webhook),
+		new(models.WebhookDelivery),
+		new(models.ScheduleOperation),
 	}
-	// Add session table if sessions are enabled
-	if web.BConfig.WebConfig.Session.SessionOn {
-		_, err = orm.NewOrm().Raw(`CREATE TABLE IF NOT EXISTS session (
-    session_key VARCHAR(64) PRIMARY KEY,
-    session_data BYTEA,
-    session_expiry TIMESTAMP WITH TIME ZONE
-);`).Exec()
-
-		if err != nil {
-			return fmt.Errorf("failed to create session table: %s", err)
-		}
-	}
-	return nil
 }
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// ProjectConfigChanges are the rows written to make a project match a config
+// document. Connector configs are given decrypted. Created jobs may point at
+// created sources and destinations, which are inserted first.
+type ProjectConfigChanges struct {
+	CreateSources      []*models.Source
+	UpdateSources      []*models.Source
+	DeleteSources      []*models.Source
+	CreateDestinations []*models.Destination
+	UpdateDestinations []*models.Destination
+	DeleteDestinations []*models.Destination
+	CreateJobs         []*models.Job
+	UpdateJobs         []*models.Job
+	DeleteJobs         []*models.Job
+	// Upstreams replace the upstream dependencies of created and updated jobs
+	Upstreams []*JobUpstreams
+	// Settings are saved when set
+	Settings *models.ProjectSettings
+	// ScheduleActions are recorded in the schedule outbox once the jobs are written
+	ScheduleActions []*JobScheduleAction
+	// DeleteAction is recorded in the schedule outbox for every job the import
+	// deletes, on its own or with its source or destination
+	DeleteAction string
+}
+
+// JobScheduleAction is an action on the schedule of a created, updated or deleted job
+type JobScheduleAction struct {
+	Job    *models.Job
+	Action string
+}
+
+// JobUpstreams are the upstream dependencies a job of a config import ends up with
+type JobUpstreams struct {
+	Job          *models.Job
+	Dependencies []*models.JobDependency
+}
+
+// ProjectConfigORM writes project config imports
+type ProjectConfigORM struct {
+	ormer  orm.Ormer
+	jobORM *JobORM
+}
+
+func NewProjectConfigORM() *ProjectConfigORM {
+	return &ProjectConfigORM{
+		ormer:  orm.NewOrm(),
+		jobORM: NewJobORM(),
+	}
+}
+
+// Apply writes all changes in a single transaction together with the schedule
+// operations they need. Deleted rows move to the trash together, as deleting a
+// connector in cascade mode does, so that restoring a connector restores its
+// deleted jobs.
+func (r *ProjectConfigORM) Apply(changes *ProjectConfigChanges) ([]*models.ScheduleOperation, error) {
+	var ops []*models.ScheduleOperation
+	err := r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		now := time.Now()
+		for _, source := range changes.CreateSources {
+			if err := withEncryptedConfig(&source.Config, func() error { _, err := txOrm.Insert(source); return err }); err != nil {
+				return fmt.Errorf("failed to create source %s: %s", source.Name, err)
+			}
+		}
+		for _, source := range changes.UpdateSources {
+			source.UpdatedAt = now
+			if err := withEncryptedConfig(&source.Config, func() error { _, err := txOrm.Update(source); return err }); err != nil {
+				return fmt.Errorf("failed to update source %s: %s", source.Name, err)
+			}
+		}
+		for _, dest := range changes.CreateDestinations {
+			if err := withEncryptedConfig(&dest.Config, func() error { _, err := txOrm.Insert(dest); return err }); err != nil {
+				return fmt.Errorf("failed to create destination %s: %s", dest.Name, err)
+			}
+		}
+		for _, dest := range changes.UpdateDestinations {
+			dest.UpdatedAt = now
+			if err := withEncryptedConfig(&dest.Config, func() error { _, err := txOrm.Update(dest); return err }); err != nil {
+				return fmt.Errorf("failed to update destination %s: %s", dest.Name, err)
+			}
+		}
+
+		for _, job := range changes.CreateJobs {
+			r.jobORM.setJSONDefaults(job)
+			if _, err := txOrm.Insert(job); err != nil {
+				return fmt.Errorf("failed to create job %s: %s", job.Name, err)
+			}
+		}
+		for _, job := range changes.UpdateJobs {
+			r.jobORM.setJSONDefaults(job)
+			job.UpdatedAt = now
+			if _, err := txOrm.Update(job); err != nil {
+				return fmt.Errorf("failed to update job %s: %s", job.Name, err)
+			}
+		}
+		for _, upstreams := range changes.Upstreams {
+			if err := replaceUpstreams(txOrm, upstreams.Job, upstreams.Dependencies); err != nil {
+				return fmt.Errorf("failed to update dependencies of job %s: %s", upstreams.Job.Name, err)
+			}
+		}
+		if changes.Settings != nil {
+			if err := saveProjectSettings(txOrm, changes.Settings); err != nil {
+				return fmt.Errorf("failed to save project settings: %s", err)
+			}
+		}
+		// the jobs of a deleted connector are left out of the document, so they go
+		// to the trash with it and come back when it is restored
+		deleted := map[int]bool{}
+		deleteConnectorJobs := func(kind, name, table, column string, id int) error {
+			jobs, connectorOps, err := deleteConnectorWith(txOrm, table, column, id, constants.DeleteModeCascade, changes.DeleteAction, now)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s %s: %s", kind, name, err)
+			}
+			for _, job := range jobs {
+				deleted[job.ID] = true
+			}
+			ops = append(ops, connectorOps...)
+			return nil
+		}
+		for _, dest := range changes.DeleteDestinations {
+			if err := deleteConnectorJobs("destination", dest.Name, constants.TableNameMap[constants.DestinationTable], "dest_id", dest.ID); err != nil {
+				return err
+			}
+		}
+		for _, source := range changes.DeleteSources {
+			if err := deleteConnectorJobs("source", source.Name, constants.TableNameMap[constants.SourceTable], "source_id", source.ID); err != nil {
+				return err
+			}
+		}
+		for _, job := range changes.DeleteJobs {
+			if deleted[job.ID] {
+				continue
+			}
+			op, err := deleteJobWith(txOrm, job, changes.DeleteAction, now)
+			if err != nil {
+				return fmt.Errorf("failed to delete job %s: %s", job.Name, err)
+			}
+			ops = append(ops, op)
+		}
+		for _, scheduled := range changes.ScheduleActions {
+			op, err := enqueueScheduleOperation(txOrm, scheduled.Job, scheduled.Action)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, op)
+		}
+		return nil
+	})
+	return ops, err
+}
+
+// withEncryptedConfig encrypts a config for the duration of write, leaving it decrypted afterwards
+func withEncryptedConfig(config *string, write func() error) error {
+	plaintext := *config
+	eConfig, err := utils.Encrypt(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt config: %s", err)
+	}
+	*config = eConfig
+	defer func() { *config = plaintext }()
+	return write()
+}
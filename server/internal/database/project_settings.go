@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// ProjectSettingsORM handles database operations for project settings
+type ProjectSettingsORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewProjectSettingsORM() *ProjectSettingsORM {
+	return &ProjectSettingsORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.ProjectSettingsTable],
+	}
+}
+
+// GetByProjectID returns the settings of a project, or empty settings if none are saved yet
+func (r *ProjectSettingsORM) GetByProjectID(projectID string) (*models.ProjectSettings, error) {
+	settings := &models.ProjectSettings{}
+	err := alive(r.ormer, r.TableName).Filter("project_id", projectID).RelatedSel().One(settings)
+	if errors.Is(err, orm.ErrNoRows) {
+		return &models.ProjectSettings{ProjectID: projectID, BlackoutWindows: "[]"}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings for project[%s]: %s", projectID, err)
+	}
+	if settings.BlackoutWindows == "" {
+		settings.BlackoutWindows = "[]"
+	}
+	return settings, nil
+}
+
+// Save creates or updates the settings of a project
+func (r *ProjectSettingsORM) Save(settings *models.ProjectSettings) error {
+	return saveProjectSettings(r.ormer, settings)
+}
+
+// SaveScheduled saves the settings of a project and records action on the
+// schedules of all its jobs in the schedule outbox in the same transaction,
+// project blackout windows are part of every job schedule
+func (r *ProjectSettingsORM) SaveScheduled(settings *models.ProjectSettings, action string) ([]*models.ScheduleOperation, error) {
+	var ops []*models.ScheduleOperation
+	err := r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		if err := saveProjectSettings(txOrm, settings); err != nil {
+			return err
+		}
+		var err error
+		ops, err = enqueueProjectScheduleOperations(txOrm, settings.ProjectID, action)
+		return err
+	})
+	return ops, err
+}
+
+// enqueueProjectScheduleOperations records action on the schedules of all jobs of a project
+func enqueueProjectScheduleOperations(ormer orm.QueryExecutor, projectID, action string) ([]*models.ScheduleOperation, error) {
+	var jobs []*models.Job
+	if _, err := alive(ormer, constants.TableNameMap[constants.JobTable]).Filter("project_id", projectID).OrderBy("id").All(&jobs, "ID", "ProjectID"); err != nil {
+		return nil, fmt.Errorf("failed to get jobs of project[%s]: %s", projectID, err)
+	}
+	return enqueueScheduleOperations(ormer, jobs, action)
+}
+
+func saveProjectSettings(ormer orm.DML, settings *models.ProjectSettings) error {
+	if settings.BlackoutWindows == "" {
+		settings.BlackoutWindows = "[]"
+	}
+	if settings.ID == 0 {
+		_, err := ormer.Insert(settings)
+		return err
+	}
+	settings.UpdatedAt = time.Now()
+	_, err := ormer.Update(settings)
+	return err
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// ScheduleOutboxORM handles the operations of the schedule outbox
+type ScheduleOutboxORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewScheduleOutboxORM() *ScheduleOutboxORM {
+	return &ScheduleOutboxORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.ScheduleOperationTable],
+	}
+}
+
+// enqueueScheduleOperation records an action on the schedule of a job, within
+// the transaction of ormer when it is one
+func enqueueScheduleOperation(ormer orm.QueryExecutor, job *models.Job, action string) (*models.ScheduleOperation, error) {
+	op := &models.ScheduleOperation{
+		ProjectID: job.ProjectID,
+		JobID:     job.ID,
+		Action:    action,
+		Status:    constants.ScheduleOperationPending,
+	}
+	if _, err := ormer.Insert(op); err != nil {
+		return nil, fmt.Errorf("failed to record %s of the schedule of job[%d]: %s", action, job.ID, err)
+	}
+	return op, nil
+}
+
+// enqueueScheduleOperations records action on the schedules of jobs
+func enqueueScheduleOperations(ormer orm.QueryExecutor, jobs []*models.Job, action string) ([]*models.ScheduleOperation, error) {
+	ops := make([]*models.ScheduleOperation, 0, len(jobs))
+	for _, job := range jobs {
+		op, err := enqueueScheduleOperation(ormer, job, action)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// Enqueue records action on the schedules of jobs in one transaction
+func (r *ScheduleOutboxORM) Enqueue(jobs []*models.Job, action string) ([]*models.ScheduleOperation, error) {
+	var ops []*models.ScheduleOperation
+	err := r.ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		var err error
+		ops, err = enqueueScheduleOperations(txOrm, jobs, action)
+		return err
+	})
+	return ops, err
+}
+
+// GetPending returns up to limit pending operations recorded before a time, oldest first
+func (r *ScheduleOutboxORM) GetPending(before time.Time, limit int) ([]*models.ScheduleOperation, error) {
+	var ops []*models.ScheduleOperation
+	_, err := alive(r.ormer, r.TableName).
+		Filter("status", constants.ScheduleOperationPending).
+		Filter("created_at__lt", before).
+		OrderBy("id").
+		Limit(limit).
+		All(&ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending schedule operations: %s", err)
+	}
+	return ops, nil
+}
+
+// CountPending returns the number of pending operations
+func (r *ScheduleOutboxORM) CountPending() (int64, error) {
+	return alive(r.ormer, r.TableName).Filter("status", constants.ScheduleOperationPending).Count()
+}
+
+// Complete marks operations as applied
+func (r *ScheduleOutboxORM) Complete(ops []*models.ScheduleOperation) error {
+	now := time.Now()
+	for _, op := range ops {
+		_, err := alive(r.ormer, r.TableName).Filter("id", op.ID).Filter("status", constants.ScheduleOperationPending).Update(orm.Params{
+			"status":       constants.ScheduleOperationDone,
+			"attempts":     op.Attempts + 1,
+			"last_error":   "",
+			"processed_at": now,
+			"updated_at":   now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to complete schedule operation[%d]: %s", op.ID, err)
+		}
+	}
+	return nil
+}
+
+// Fail records a failed attempt of operations, operations that used up
+// maxAttempts are marked failed and not retried
+func (r *ScheduleOutboxORM) Fail(ops []*models.ScheduleOperation, cause error, maxAttempts int) error {
+	now := time.Now()
+	for _, op := range ops {
+		params := orm.Params{"attempts": op.Attempts + 1, "last_error": cause.Error(), "updated_at": now}
+		if op.Attempts+1 >= maxAttempts {
+			params["status"] = constants.ScheduleOperationFailed
+			params["processed_at"] = now
+		}
+		if _, err := alive(r.ormer, r.TableName).Filter("id", op.ID).Filter("status", constants.ScheduleOperationPending).Update(params); err != nil {
+			return fmt.Errorf("failed to record the failure of schedule operation[%d]: %s", op.ID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteProcessedBefore deletes the done and failed operations processed before a time
+func (r *ScheduleOutboxORM) DeleteProcessedBefore(before time.Time) (int64, error) {
+	count, err := alive(r.ormer, r.TableName).
+		Filter("status__in", constants.ScheduleOperationDone, constants.ScheduleOperationFailed).
+		Filter("processed_at__lt", before).
+		Delete()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete processed schedule operations: %s", err)
+	}
+	return count, nil
+}
@@ -49,7 +49,7 @@ func (r *SourceORM) Create(source *models.Source) error {
 
 func (r *SourceORM) GetAll() ([]*models.Source, error) {
 	var sources []*models.Source
-	_, err := r.ormer.QueryTable(r.TableName).RelatedSel().All(&sources)
+	_, err := alive(r.ormer, r.TableName).RelatedSel().All(&sources)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all sources: %s", err)
 	}
@@ -63,8 +63,8 @@ func (r *SourceORM) GetAll() ([]*models.Source, error) {
 }
 
 func (r *SourceORM) GetByID(id int) (*models.Source, error) {
-	source := &models.Source{ID: id}
-	err := r.ormer.Read(source)
+	source := &models.Source{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).One(source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get source by id[%d]: %s", id, err)
 	}
@@ -91,16 +91,34 @@ func (r *SourceORM) Update(source *models.Source) error {
 	return err
 }
 
-func (r *SourceORM) Delete(id int) error {
-	source := &models.Source{ID: id}
-	_, err := r.ormer.Delete(source)
-	return err
+// Delete moves a source to the trash and handles the jobs using it as mode,
+// one of constants.DeleteModes, says. action on the schedules of the jobs is
+// recorded in the schedule outbox in the same transaction. The jobs are returned
+// as they were, with ErrConnectorInUse too.
+func (r *SourceORM) Delete(id int, mode, action string) ([]*models.Job, []*models.ScheduleOperation, error) {
+	jobs, ops, err := deleteConnector(r.ormer, r.TableName, "source_id", id, mode, action)
+	if err != nil {
+		return jobs, nil, fmt.Errorf("failed to delete source[%d]: %w", id, err)
+	}
+	return jobs, ops, nil
+}
+
+// Restore takes a source out of the trash together with the jobs trashed
+// with it whose destination is not in the trash. Those jobs come back inactive and
+// action on their schedules is recorded in the schedule outbox in the same transaction.
+func (r *SourceORM) Restore(projectID string, id int, action string) ([]*models.Job, []*models.ScheduleOperation, error) {
+	jobs, ops, err := restoreConnector(r.ormer, r.TableName, "source_id", projectID, id,
+		func(s *models.Source) *time.Time { return s.DeletedAt }, action)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to restore source[%d]: %w", id, err)
+	}
+	return jobs, ops, nil
 }
 
 // GetByNameAndType retrieves sources by name, type, and project ID
 func (r *SourceORM) GetByNameAndType(name, sourceType, projectIDStr string) ([]*models.Source, error) {
 	var sources []*models.Source
-	_, err := r.ormer.QueryTable(r.TableName).
+	_, err := alive(r.ormer, r.TableName).
 		Filter("name", name).
 		Filter("type", sourceType).
 		Filter("project_id", projectIDStr).
@@ -116,3 +134,19 @@ func (r *SourceORM) GetByNameAndType(name, sourceType, projectIDStr string) ([]*
 
 	return sources, nil
 }
+
+// GetAllByProjectID retrieves all sources of a project
+func (r *SourceORM) GetAllByProjectID(projectID string) ([]*models.Source, error) {
+	var sources []*models.Source
+	_, err := alive(r.ormer, r.TableName).Filter("project_id", projectID).OrderBy("id").All(&sources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all sources by project_id[%s]: %s", projectID, err)
+	}
+
+	// Decrypt config after reading
+	if err := r.decryptSourceSliceConfigs(sources); err != nil {
+		return nil, fmt.Errorf("failed to decrypt source config: %s", err)
+	}
+
+	return sources, nil
+}
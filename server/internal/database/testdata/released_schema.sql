@@ -0,0 +1,74 @@
+-- The schema orm.RunSyncdb and the session provider created at the last release
+-- before migrations, generated with `orm sqlall` from the models of that release.
+
+CREATE TABLE IF NOT EXISTS "olake-$$-source" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "name" text NOT NULL DEFAULT '',
+    "project_id" text NOT NULL DEFAULT '',
+    "config" jsonb NOT NULL DEFAULT '{}',
+    "version" text NOT NULL DEFAULT '',
+    "created_by_id" integer NOT NULL,
+    "updated_by_id" integer NOT NULL,
+    "type" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-destination" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "name" text NOT NULL DEFAULT '',
+    "project_id" text NOT NULL DEFAULT '',
+    "dest_type" text NOT NULL DEFAULT '',
+    "version" text NOT NULL DEFAULT '',
+    "config" jsonb NOT NULL DEFAULT '{}',
+    "created_by_id" integer NOT NULL,
+    "updated_by_id" integer NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-job" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "name" varchar(100) NOT NULL DEFAULT '',
+    "source_id" integer NOT NULL,
+    "dest_id" integer NOT NULL,
+    "active" bool NOT NULL DEFAULT FALSE,
+    "frequency" text NOT NULL DEFAULT '',
+    "streams_config" jsonb NOT NULL DEFAULT '{}',
+    "state" jsonb NOT NULL DEFAULT '{}',
+    "created_by_id" integer NOT NULL,
+    "updated_by_id" integer NOT NULL,
+    "project_id" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-user" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "username" varchar(100) NOT NULL DEFAULT '' UNIQUE,
+    "password" varchar(100) NOT NULL DEFAULT '',
+    "email" varchar(100) NOT NULL DEFAULT '' UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS "olake-$$-catalog" (
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    "deleted_at" timestamp with time zone,
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "type" varchar(50) NOT NULL DEFAULT '',
+    "name" varchar(100) NOT NULL DEFAULT '',
+    "specs" jsonb NOT NULL DEFAULT '{}',
+    "version" text NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "session" (
+    "session_key" varchar(64) PRIMARY KEY,
+    "session_data" bytea,
+    "session_expiry" timestamp with time zone
+);
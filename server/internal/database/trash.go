@@ -0,0 +1,311 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// Deleting a source, destination, job, job template, notification channel,
+// webhook or user moves its row to the trash by setting deleted_at. Every query
+// of the package goes through alive, so trashed rows are invisible until they
+// are restored or purged once TrashRetentionDays have passed.
+
+// TrashRetentionDays is how long rows stay in the trash before they are purged, 0 keeps them forever
+var TrashRetentionDays int
+
+var (
+	// ErrNotInTrash is returned when restoring a row that is not in the trash
+	ErrNotInTrash = errors.New("not in the trash")
+	// ErrConnectorInTrash is returned when restoring a job whose source or destination is in the trash
+	ErrConnectorInTrash = errors.New("source or destination of the job is in the trash")
+	// ErrConnectorInUse is returned when deleting a source or destination used by jobs with DeleteModeReject
+	ErrConnectorInUse = errors.New("source or destination is used by jobs")
+)
+
+func init() {
+	TrashRetentionDays = web.AppConfig.DefaultInt("TRASH_RETENTION_DAYS", 30)
+}
+
+// alive returns a query on the rows of a table that are not in the trash
+func alive(ormer orm.QueryExecutor, table string) orm.QuerySeter {
+	return ormer.QueryTable(table).Filter("deleted_at__isnull", true)
+}
+
+// trashed returns a query on the rows of a table that are in the trash
+func trashed(ormer orm.QueryExecutor, table string) orm.QuerySeter {
+	return ormer.QueryTable(table).Filter("deleted_at__isnull", false)
+}
+
+// moveToTrash trashes the rows of a query at deletedAt, rows trashed together
+// share the time so that restoring the row they went with restores them too
+func moveToTrash(qs orm.QuerySeter, deletedAt time.Time) (int64, error) {
+	return qs.Update(orm.Params{"deleted_at": deletedAt, "updated_at": deletedAt})
+}
+
+// restoreFromTrash takes the rows of a query out of the trash
+func restoreFromTrash(qs orm.QuerySeter) (int64, error) {
+	return qs.Update(orm.Params{"deleted_at": nil, "updated_at": time.Now()})
+}
+
+// trashOne trashes a single row, failing with orm.ErrNoRows if it is not alive
+func trashOne(ormer orm.QueryExecutor, table string, id int, deletedAt time.Time) error {
+	count, err := moveToTrash(alive(ormer, table).Filter("id", id), deletedAt)
+	if err == nil && count == 0 {
+		err = orm.ErrNoRows
+	}
+	return err
+}
+
+// restoreOne restores the row of a query on the trash, failing with ErrNotInTrash if there is none
+func restoreOne(qs orm.QuerySeter) error {
+	count, err := restoreFromTrash(qs)
+	if err == nil && count == 0 {
+		err = ErrNotInTrash
+	}
+	return err
+}
+
+// notInTrash turns the error of reading a trashed row that does not exist into ErrNotInTrash
+func notInTrash(err error) error {
+	if errors.Is(err, orm.ErrNoRows) {
+		return ErrNotInTrash
+	}
+	return err
+}
+
+// deleteConnector moves the source or destination whose id is in column,
+// source_id or dest_id, to the trash and handles its jobs as mode says,
+// recording action on the schedule of every job in the schedule outbox in the
+// same transaction. The jobs are returned as they were before the delete, with
+// ErrConnectorInUse too.
+func deleteConnector(ormer orm.Ormer, table, column string, id int, mode, action string) ([]*models.Job, []*models.ScheduleOperation, error) {
+	var jobs []*models.Job
+	var ops []*models.ScheduleOperation
+	err := ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		var err error
+		jobs, ops, err = deleteConnectorWith(txOrm, table, column, id, mode, action, time.Now())
+		return err
+	})
+	return jobs, ops, err
+}
+
+// deleteConnectorWith is deleteConnector within the transaction of ormer,
+// trashing at now
+func deleteConnectorWith(ormer orm.QueryExecutor, table, column string, id int, mode, action string, now time.Time) ([]*models.Job, []*models.ScheduleOperation, error) {
+	if err := trashOne(ormer, table, id, now); err != nil {
+		return nil, nil, err
+	}
+	var jobs []*models.Job
+	jobsQuery := alive(ormer, constants.TableNameMap[constants.JobTable]).Filter(column, id)
+	if _, err := jobsQuery.All(&jobs); err != nil {
+		return nil, nil, fmt.Errorf("failed to get jobs: %s", err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil, nil
+	}
+	update, err := connectorJobsUpdate(mode, now)
+	if err != nil {
+		return jobs, nil, err
+	}
+	if _, err := jobsQuery.Update(update); err != nil {
+		return jobs, nil, fmt.Errorf("failed to update jobs: %s", err)
+	}
+	ops, err := enqueueScheduleOperations(ormer, jobs, action)
+	return jobs, ops, err
+}
+
+// connectorJobsUpdate returns the update of the jobs of a source or destination
+// deleted with mode, DeleteModeReject fails with ErrConnectorInUse
+func connectorJobsUpdate(mode string, now time.Time) (orm.Params, error) {
+	switch mode {
+	case constants.DeleteModeReject:
+		return nil, ErrConnectorInUse
+	case constants.DeleteModeCascade:
+		// trashed together so that restoring the connector restores the jobs
+		return orm.Params{"active": false, "deleted_at": now, "updated_at": now}, nil
+	case constants.DeleteModeDetach:
+		return orm.Params{"active": false, "updated_at": now}, nil
+	default:
+		return nil, fmt.Errorf("unknown delete mode %q", mode)
+	}
+}
+
+// restoreConnector takes the source or destination T whose id is in column out
+// of the trash together with the jobs trashed with it whose other connector is
+// not in the trash. Those jobs come back inactive and action on their schedules
+// is recorded in the schedule outbox in the same transaction.
+func restoreConnector[T any](ormer orm.Ormer, table, column, projectID string, id int, deletedAt func(*T) *time.Time, action string) ([]*models.Job, []*models.ScheduleOperation, error) {
+	var jobs []*models.Job
+	var ops []*models.ScheduleOperation
+	err := ormer.DoTx(func(_ context.Context, txOrm orm.TxOrmer) error {
+		connector := new(T)
+		if err := trashed(txOrm, table).Filter("id", id).Filter("project_id", projectID).One(connector, "ID", "DeletedAt"); err != nil {
+			return notInTrash(err)
+		}
+		if err := restoreOne(trashed(txOrm, table).Filter("id", id)); err != nil {
+			return err
+		}
+		var err error
+		if jobs, err = restoreJobsWith(txOrm, column, id, *deletedAt(connector)); err != nil || len(jobs) == 0 {
+			return err
+		}
+		ops, err = enqueueScheduleOperations(txOrm, jobs, action)
+		return err
+	})
+	return jobs, ops, err
+}
+
+// restoreJobsWith restores the jobs trashed together with the source or
+// destination whose id is in column, jobs whose other connector is still in
+// the trash stay there. The connector configs of the jobs are not decrypted.
+func restoreJobsWith(ormer orm.QueryExecutor, column string, id int, deletedAt time.Time) ([]*models.Job, error) {
+	jobTable := constants.TableNameMap[constants.JobTable]
+	var jobs []*models.Job
+	if _, err := trashed(ormer, jobTable).Filter(column, id).Filter("deleted_at", deletedAt).RelatedSel("SourceID", "DestID").All(&jobs); err != nil {
+		return nil, fmt.Errorf("failed to get jobs trashed with %s[%d]: %s", column, id, err)
+	}
+	restored := jobs[:0]
+	ids := []int{}
+	for _, job := range jobs {
+		if job.SourceID.DeletedAt == nil && job.DestID.DeletedAt == nil {
+			job.Active = false
+			restored = append(restored, job)
+			ids = append(ids, job.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return restored, nil
+	}
+	if _, err := trashed(ormer, jobTable).Filter("id__in", ids).Update(orm.Params{"deleted_at": nil, "active": false, "updated_at": time.Now()}); err != nil {
+		return nil, fmt.Errorf("failed to restore jobs trashed with %s[%d]: %s", column, id, err)
+	}
+	return restored, nil
+}
+
+// TrashORM lists and purges the trash
+type TrashORM struct {
+	ormer orm.Ormer
+}
+
+func NewTrashORM() *TrashORM {
+	return &TrashORM{ormer: orm.NewOrm()}
+}
+
+// GetAllByProjectID lists the trashed rows of a project, most recently deleted
+// first. Users belong to no project and are not listed.
+func (r *TrashORM) GetAllByProjectID(projectID string) ([]*models.TrashItem, error) {
+	var items []*models.TrashItem
+	var err error
+	add := func(kindItems []*models.TrashItem, kindErr error) {
+		items = append(items, kindItems...)
+		if err == nil {
+			err = kindErr
+		}
+	}
+	add(trashItems(r.ormer, constants.TrashKindSource, constants.SourceTable, projectID,
+		func(s *models.Source) (int, string, *time.Time) { return s.ID, s.Name, s.DeletedAt }))
+	add(trashItems(r.ormer, constants.TrashKindDestination, constants.DestinationTable, projectID,
+		func(d *models.Destination) (int, string, *time.Time) { return d.ID, d.Name, d.DeletedAt }))
+	add(trashItems(r.ormer, constants.TrashKindJob, constants.JobTable, projectID,
+		func(j *models.Job) (int, string, *time.Time) { return j.ID, j.Name, j.DeletedAt }))
+	add(trashItems(r.ormer, constants.TrashKindJobTemplate, constants.JobTemplateTable, projectID,
+		func(t *models.JobTemplate) (int, string, *time.Time) { return t.ID, t.Name, t.DeletedAt }))
+	add(trashItems(r.ormer, constants.TrashKindNotificationChannel, constants.NotificationChannelTable, projectID,
+		func(n *models.NotificationChannel) (int, string, *time.Time) { return n.ID, n.Name, n.DeletedAt }))
+	add(trashItems(r.ormer, constants.TrashKindWebhook, constants.WebhookTable, projectID,
+		func(w *models.Webhook) (int, string, *time.Time) { return w.ID, w.Name, w.DeletedAt }))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash of project[%s]: %s", projectID, err)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// trashItems lists the trashed rows of a project in the table of T
+func trashItems[T any](ormer orm.QueryExecutor, kind string, table constants.TableType, projectID string, fields func(*T) (int, string, *time.Time)) ([]*models.TrashItem, error) {
+	var rows []*T
+	if _, err := trashed(ormer, constants.TableNameMap[table]).Filter("project_id", projectID).All(&rows, "ID", "Name", "DeletedAt"); err != nil {
+		return nil, err
+	}
+	items := make([]*models.TrashItem, 0, len(rows))
+	for _, row := range rows {
+		id, name, deletedAt := fields(row)
+		items = append(items, &models.TrashItem{Kind: kind, ID: id, Name: name, DeletedAt: *deletedAt, PurgeAt: PurgeTime(*deletedAt)})
+	}
+	return items, nil
+}
+
+// PurgeTime returns when a row trashed at deletedAt is purged, nil if the trash is kept forever
+func PurgeTime(deletedAt time.Time) *time.Time {
+	if TrashRetentionDays <= 0 {
+		return nil
+	}
+	purgeAt := deletedAt.AddDate(0, 0, TrashRetentionDays)
+	return &purgeAt
+}
+
+// trashReference is a column referencing the rows of a trashable table
+type trashReference struct {
+	table  constants.TableType
+	column string
+}
+
+// purgeOrder lists the trashable tables in the order they are purged, with the
+// references that keep a trashed row from being purged. Jobs go first so that
+// the connectors trashed with them are no longer referenced, the rows that
+// only exist for a job, channel or webhook are removed by the cascades of
+// their foreign keys.
+var purgeOrder = []struct {
+	table      constants.TableType
+	references []trashReference
+}{
+	{table: constants.JobTable},
+	{table: constants.JobTemplateTable},
+	{table: constants.NotificationChannelTable},
+	{table: constants.WebhookTable},
+	{table: constants.SourceTable, references: []trashReference{{constants.JobTable, "source_id"}}},
+	{table: constants.DestinationTable, references: []trashReference{{constants.JobTable, "dest_id"}}},
+	{table: constants.UserTable, references: []trashReference{
+		{constants.SourceTable, "created_by_id"}, {constants.SourceTable, "updated_by_id"},
+		{constants.DestinationTable, "created_by_id"}, {constants.DestinationTable, "updated_by_id"},
+		{constants.JobTable, "created_by_id"}, {constants.JobTable, "updated_by_id"},
+		{constants.ProjectSettingsTable, "updated_by_id"},
+		{constants.JobStateHistoryTable, "created_by_id"},
+		{constants.JobTemplateTable, "created_by_id"}, {constants.JobTemplateTable, "updated_by_id"},
+		{constants.NotificationChannelTable, "created_by_id"}, {constants.NotificationChannelTable, "updated_by_id"},
+		{constants.WebhookTable, "created_by_id"}, {constants.WebhookTable, "updated_by_id"},
+	}},
+}
+
+// Purge deletes the rows trashed before a time and returns how many were
+// deleted. Rows that are still referenced, e.g. a user who created a source,
+// stay in the trash. Tables are purged one by one, a failure keeps what the
+// tables before it purged.
+func (r *TrashORM) Purge(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for _, step := range purgeOrder {
+		table := constants.TableNameMap[step.table]
+		var query strings.Builder
+		fmt.Fprintf(&query, `DELETE FROM %q t WHERE t."deleted_at" < ?`, table)
+		for _, ref := range step.references {
+			fmt.Fprintf(&query, ` AND NOT EXISTS (SELECT 1 FROM %q r WHERE r.%q = t."id")`, constants.TableNameMap[ref.table], ref.column)
+		}
+		result, err := r.ormer.RawWithCtx(ctx, query.String(), before).Exec()
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge trash of %s: %s", table, err)
+		}
+		count, _ := result.RowsAffected()
+		purged += count
+	}
+	return purged, nil
+}
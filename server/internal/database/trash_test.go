@@ -0,0 +1,70 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+)
+
+var (
+	unscopedQuery = regexp.MustCompile(`\.(QueryTable|Read|ReadOrCreate)\(`)
+	rawSelect     = regexp.MustCompile("(?s)Raw(?:WithCtx)?\\([^`]*`(SELECT[^`]*)`")
+)
+
+// TestQueriesSkipTrash fails when a query of the package can see trashed rows,
+// every table is read through alive or trashed and raw selects filter deleted_at
+func TestQueriesSkipTrash(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file == "trash.go" || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, line := range strings.Split(string(content), "\n") {
+			if match := unscopedQuery.FindString(line); match != "" {
+				t.Errorf("%s:%d calls %s, query through alive or trashed instead", file, i+1, strings.TrimSuffix(match, "("))
+			}
+		}
+		for _, match := range rawSelect.FindAllStringSubmatch(string(content), -1) {
+			if !strings.Contains(match[1], "deleted_at IS NULL") {
+				t.Errorf("%s: raw query %q does not filter deleted_at", file, match[1])
+			}
+		}
+	}
+}
+
+func TestConnectorJobsUpdate(t *testing.T) {
+	now := time.Now()
+	if _, err := connectorJobsUpdate(constants.DeleteModeReject, now); !errors.Is(err, ErrConnectorInUse) {
+		t.Errorf("reject = %v, want ErrConnectorInUse", err)
+	}
+	update, err := connectorJobsUpdate(constants.DeleteModeCascade, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update["active"] != false || update["deleted_at"] != now {
+		t.Errorf("cascade updates %v, want the jobs deactivated and trashed", update)
+	}
+	update, err = connectorJobsUpdate(constants.DeleteModeDetach, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, trashed := update["deleted_at"]; trashed || update["active"] != false {
+		t.Errorf("detach updates %v, want the jobs deactivated and kept", update)
+	}
+	if _, err := connectorJobsUpdate("purge", now); err == nil {
+		t.Error("unknown mode purge updates the jobs")
+	}
+}
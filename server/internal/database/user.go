@@ -26,7 +26,7 @@ func NewUserORM() *UserORM {
 
 func (r *UserORM) FindByUsername(username string) (*models.User, error) {
 	var user models.User
-	err := r.ormer.QueryTable(r.TableName).Filter("username", username).One(&user)
+	err := alive(r.ormer, r.TableName).Filter("username", username).One(&user)
 	return &user, err
 }
 
@@ -35,10 +35,14 @@ func (r *UserORM) ComparePassword(hashedPassword, plainPassword string) error {
 }
 
 func (r *UserORM) Create(user *models.User) error {
-	exists := r.ormer.QueryTable(r.TableName).Filter("username", user.Username).Exist()
+	exists := alive(r.ormer, r.TableName).Filter("username", user.Username).Exist()
 	if exists {
 		return fmt.Errorf("username already exists")
 	}
+	// usernames stay taken while their users are in the trash
+	if trashed(r.ormer, r.TableName).Filter("username", user.Username).Exist() {
+		return fmt.Errorf("username belongs to a deleted user, restore the user instead")
+	}
 
 	_, err := r.ormer.Insert(user)
 	return err
@@ -46,13 +50,13 @@ func (r *UserORM) Create(user *models.User) error {
 
 func (r *UserORM) GetAll() ([]*models.User, error) {
 	var users []*models.User
-	_, err := r.ormer.QueryTable(r.TableName).All(&users)
+	_, err := alive(r.ormer, r.TableName).All(&users)
 	return users, err
 }
 
 func (r *UserORM) GetByID(id int) (*models.User, error) {
-	user := &models.User{ID: id}
-	err := r.ormer.Read(user)
+	user := &models.User{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).One(user)
 	return user, err
 }
 
@@ -62,8 +66,12 @@ func (r *UserORM) Update(user *models.User) error {
 	return err
 }
 
+// Delete moves a user to the trash, the user can no longer log in
 func (r *UserORM) Delete(id int) error {
-	user := &models.User{ID: id}
-	_, err := r.ormer.Delete(user)
-	return err
+	return trashOne(r.ormer, r.TableName, id, time.Now())
+}
+
+// Restore takes a user out of the trash
+func (r *UserORM) Restore(id int) error {
+	return restoreOne(trashed(r.ormer, r.TableName).Filter("id", id))
 }
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// WebhookORM handles database operations for webhooks
+type WebhookORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewWebhookORM() *WebhookORM {
+	return &WebhookORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.WebhookTable],
+	}
+}
+
+func (r *WebhookORM) Create(webhook *models.Webhook) error {
+	secret := webhook.Secret
+	if err := encryptWebhookSecret(webhook); err != nil {
+		return err
+	}
+	_, err := r.ormer.Insert(webhook)
+	webhook.Secret = secret
+	return err
+}
+
+// GetAllByProjectID retrieves the webhooks of a project with their secrets decrypted
+func (r *WebhookORM) GetAllByProjectID(projectID string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	_, err := alive(r.ormer, r.TableName).Filter("project_id", projectID).RelatedSel().OrderBy("id").All(&webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks for project ID %s: %s", projectID, err)
+	}
+	for _, webhook := range webhooks {
+		if err := decryptWebhookSecret(webhook); err != nil {
+			return nil, err
+		}
+	}
+	return webhooks, nil
+}
+
+// GetSubscribed retrieves the active webhooks of a project subscribed to an event
+func (r *WebhookORM) GetSubscribed(projectID, event string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	_, err := alive(r.ormer, r.TableName).Filter("project_id", projectID).Filter("active", true).OrderBy("id").All(&webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks for project ID %s: %s", projectID, err)
+	}
+	subscribed := webhooks[:0]
+	for _, webhook := range webhooks {
+		var events []string
+		if err := json.Unmarshal([]byte(webhook.Events), &events); err == nil && slices.Contains(events, event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	return subscribed, nil
+}
+
+// GetByID retrieves a webhook with its secret decrypted
+func (r *WebhookORM) GetByID(id int) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	err := alive(r.ormer, r.TableName).Filter("id", id).RelatedSel().One(webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook by id[%d]: %s", id, err)
+	}
+	if err := decryptWebhookSecret(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhookORM) Update(webhook *models.Webhook) error {
+	secret := webhook.Secret
+	if err := encryptWebhookSecret(webhook); err != nil {
+		return err
+	}
+	webhook.UpdatedAt = time.Now()
+	_, err := r.ormer.Update(webhook)
+	webhook.Secret = secret
+	return err
+}
+
+// Delete moves a webhook to the trash, its delivery log is kept until it is purged
+func (r *WebhookORM) Delete(id int) error {
+	if err := trashOne(r.ormer, r.TableName, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete webhook[%d]: %s", id, err)
+	}
+	return nil
+}
+
+// Restore takes a webhook of a project out of the trash
+func (r *WebhookORM) Restore(projectID string, id int) error {
+	return restoreOne(trashed(r.ormer, r.TableName).Filter("id", id).Filter("project_id", projectID))
+}
+
+func encryptWebhookSecret(webhook *models.Webhook) error {
+	eSecret, err := utils.Encrypt(webhook.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt webhook secret: %s", err)
+	}
+	webhook.Secret = eSecret
+	return nil
+}
+
+func decryptWebhookSecret(webhook *models.Webhook) error {
+	dSecret, err := utils.Decrypt(webhook.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt webhook secret by id[%d]: %s", webhook.ID, err)
+	}
+	webhook.Secret = dSecret
+	return nil
+}
+
+// WebhookDeliveryORM handles database operations for the delivery log of webhooks
+type WebhookDeliveryORM struct {
+	ormer     orm.Ormer
+	TableName string
+}
+
+func NewWebhookDeliveryORM() *WebhookDeliveryORM {
+	return &WebhookDeliveryORM{
+		ormer:     orm.NewOrm(),
+		TableName: constants.TableNameMap[constants.WebhookDeliveryTable],
+	}
+}
+
+// WebhookDeliveryFilter narrows down the delivery log, zero values match everything
+type WebhookDeliveryFilter struct {
+	WebhookID int
+	Status    string
+	Event     string
+	Limit     int
+}
+
+// GetOrCreate records the delivery of an event to a webhook, a delivery that
+// was already recorded for the event is returned as is
+func (r *WebhookDeliveryORM) GetOrCreate(delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	existing := &models.WebhookDelivery{}
+	err := alive(r.ormer, r.TableName).Filter("webhook_id", delivery.WebhookID.ID).Filter("event_id", delivery.EventID).One(existing)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, orm.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get delivery of event %s: %s", delivery.EventID, err)
+	}
+	if _, err := r.ormer.Insert(delivery); err != nil {
+		return nil, fmt.Errorf("failed to record delivery of event %s to webhook[%d]: %s", delivery.EventID, delivery.WebhookID.ID, err)
+	}
+	return delivery, nil
+}
+
+// GetByID retrieves a delivery, its webhook is not loaded
+func (r *WebhookDeliveryORM) GetByID(id int) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	if err := alive(r.ormer, r.TableName).Filter("id", id).One(delivery); err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery by id[%d]: %s", id, err)
+	}
+	return delivery, nil
+}
+
+// GetAllByProjectID retrieves the newest deliveries of a project matching the
+// filter, leaving out the deliveries of webhooks in the trash
+func (r *WebhookDeliveryORM) GetAllByProjectID(projectID string, filter WebhookDeliveryFilter) ([]*models.WebhookDelivery, error) {
+	query := alive(r.ormer, r.TableName).Filter("project_id", projectID).Filter("webhook_id__deleted_at__isnull", true)
+	if filter.WebhookID != 0 {
+		query = query.Filter("webhook_id", filter.WebhookID)
+	}
+	if filter.Status != "" {
+		query = query.Filter("status", filter.Status)
+	}
+	if filter.Event != "" {
+		query = query.Filter("event", filter.Event)
+	}
+	var deliveries []*models.WebhookDelivery
+	if _, err := query.OrderBy("-id").Limit(filter.Limit).All(&deliveries); err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries for project ID %s: %s", projectID, err)
+	}
+	return deliveries, nil
+}
+
+// UpdateAttempt saves the outcome of a delivery attempt
+func (r *WebhookDeliveryORM) UpdateAttempt(delivery *models.WebhookDelivery) error {
+	_, err := r.ormer.Update(delivery, "Status", "Attempts", "ResponseStatus", "LastError", "LastAttemptAt", "DeliveredAt", "UpdatedAt")
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery[%d]: %s", delivery.ID, err)
+	}
+	return nil
+}
+
+// UpdateStatus moves a delivery to a status, e.g. to the dead-letter list or back to pending for a replay
+func (r *WebhookDeliveryORM) UpdateStatus(id int, status string) error {
+	_, err := alive(r.ormer, r.TableName).Filter("id", id).Update(orm.Params{"status": status, "updated_at": time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to update status of webhook delivery[%d]: %s", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// CheckpointInterval is how often the state file of a running sync is checked for changes
+const CheckpointInterval = 30 * time.Second
+
+// Checkpoint is an intermediate state written by a running sync and saved to the job
+type Checkpoint struct {
+	Revision int
+	State    string
+	SavedAt  time.Time
+}
+
+// watchState saves every new state the connector writes to statePath as a
+// checkpoint of the job, so a later run resumes from it if this one fails.
+// Checkpoints stop once a user or another run changed the state after baseRevision.
+// report is called on every check with the latest checkpoint, nil before the
+// first one, so callers can use it as a heartbeat. The returned function stops
+// watching and waits for a running check to finish.
+func watchState(ctx context.Context, job *models.Job, workflowID string, baseRevision int, statePath string, report func(*Checkpoint)) func() {
+	// a copy, the state of the job is saved by the caller once the sync completes
+	checkpointJob := *job
+	lastModified := time.Time{}
+	if info, err := os.Stat(statePath); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(CheckpointInterval)
+		defer ticker.Stop()
+
+		var checkpoint *Checkpoint
+		var stateChanged bool
+		lastState := strings.TrimSpace(job.State)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if info, err := os.Stat(statePath); !stateChanged && err == nil && info.ModTime().After(lastModified) {
+				data, err := os.ReadFile(statePath)
+				state := strings.TrimSpace(string(data))
+				// the connector may be halfway through writing the file, check again next time
+				if err == nil && state != "" && utils.ValidateJobState(state) == nil {
+					lastModified = info.ModTime()
+					if state != lastState {
+						revision := &models.JobStateHistory{State: state, Reason: constants.StateChangeCheckpoint, WorkflowID: workflowID, BaseRevision: baseRevision}
+						err := database.NewJobStateHistoryORM().SaveState(&checkpointJob, revision)
+						if errors.Is(err, database.ErrStateChanged) {
+							stateChanged = true
+							logs.Warn("Stopped checkpoints of job[%d]: %s", job.ID, err)
+						} else if err != nil {
+							logs.Error("Failed to save checkpoint of job[%d]: %s", job.ID, err)
+						} else {
+							lastState = state
+							checkpoint = &Checkpoint{Revision: revision.Revision, State: state, SavedAt: time.Now()}
+							logs.Info("Saved checkpoint revision %d of job[%d]", revision.Revision, job.ID)
+						}
+					}
+				}
+			}
+			if report != nil {
+				report(checkpoint)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Error classes of failed docker commands, used as the error type of Temporal
+// application errors so retry policies can refer to them
+const (
+	ErrorClassNetwork        = "network"
+	ErrorClassRateLimit      = "rate_limit"
+	ErrorClassOutOfMemory    = "out_of_memory"
+	ErrorClassDocker         = "docker"
+	ErrorClassImage          = "image"
+	ErrorClassAuthentication = "authentication"
+	ErrorClassConfiguration  = "configuration"
+	ErrorClassUnknown        = "unknown"
+)
+
+// ErrorClasses lists every error class a failed docker command can be classified as
+var ErrorClasses = []string{
+	ErrorClassNetwork,
+	ErrorClassRateLimit,
+	ErrorClassOutOfMemory,
+	ErrorClassDocker,
+	ErrorClassImage,
+	ErrorClassAuthentication,
+	ErrorClassConfiguration,
+	ErrorClassUnknown,
+}
+
+// maxClassifiedLines is how many trailing output lines are searched for error patterns
+const maxClassifiedLines = 50
+
+// errorPattern maps connector log messages to an error class
+type errorPattern struct {
+	class     string
+	retryable bool
+	pattern   *regexp.Regexp
+}
+
+// errorPatterns are checked in order, fatal classes come first so that e.g. an
+// authentication failure reported through a reset connection stays fatal. The
+// image class comes before authentication, which would match "pull access denied".
+var errorPatterns = []errorPattern{
+	{ErrorClassImage, false, regexp.MustCompile(`(?i)manifest unknown|pull access denied|repository does not exist|no such image`)},
+	{ErrorClassAuthentication, false, regexp.MustCompile(`(?i)authentication failed|password authentication|invalid credentials|access denied|unauthori[sz]ed|invalid api key|permission denied for (?:database|schema|relation|table|user|role)|(?:insufficient|missing) (?:privileges|permissions)`)},
+	{ErrorClassConfiguration, false, regexp.MustCompile(`(?i)invalid config|failed to parse|failed to unmarshal|unknown field|validation failed|missing required|\b(?:database|schema|relation|table|collection|bucket|catalog|namespace|topic)\s+["']?[\w.$-]+["']?\s+does not exist`)},
+	{ErrorClassRateLimit, true, regexp.MustCompile(`(?i)rate limit|too many requests|throttl|quota exceeded`)},
+	{ErrorClassOutOfMemory, true, regexp.MustCompile(`(?i)out of memory|oomkilled|cannot allocate memory`)},
+	// a local "permission denied", e.g. on the docker socket or a mounted config dir, is no credential problem
+	{ErrorClassDocker, true, regexp.MustCompile(`(?i)cannot connect to the docker daemon|error response from daemon|permission denied while trying to connect to the docker daemon`)},
+	{ErrorClassNetwork, true, regexp.MustCompile(`(?i)connection refused|connection reset|i/o timeout|no such host|broken pipe|network is unreachable|timed out|tls handshake|unexpected eof`)},
+}
+
+// CommandError is returned when a docker command exits with a non-zero status
+type CommandError struct {
+	ExitCode  int
+	Class     string
+	Retryable bool
+	// Message is the last error logged by the connector
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("docker command failed with exit status %d", e.ExitCode)
+	}
+	return fmt.Sprintf("docker command failed with exit status %d: %s", e.ExitCode, e.Message)
+}
+
+// ClassifyCommandError classifies a failed docker command by its exit code and
+// the log patterns in its output
+func ClassifyCommandError(exitCode int, output []byte) *CommandError {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) > maxClassifiedLines {
+		lines = lines[len(lines)-maxClassifiedLines:]
+	}
+	cmdErr := &CommandError{ExitCode: exitCode, Message: lastErrorMessage(lines)}
+
+	switch exitCode {
+	case 126, 127:
+		// the container command could not be run at all
+		cmdErr.Class, cmdErr.Retryable = ErrorClassConfiguration, false
+		return cmdErr
+	case 137:
+		// killed, usually by the OOM killer
+		cmdErr.Class, cmdErr.Retryable = ErrorClassOutOfMemory, true
+		return cmdErr
+	}
+
+	tail := strings.Join(lines, "\n")
+	for _, p := range errorPatterns {
+		if p.pattern.MatchString(tail) {
+			cmdErr.Class, cmdErr.Retryable = p.class, p.retryable
+			return cmdErr
+		}
+	}
+
+	if exitCode == 125 {
+		// docker itself failed before running the container
+		cmdErr.Class, cmdErr.Retryable = ErrorClassDocker, true
+		return cmdErr
+	}
+	cmdErr.Class, cmdErr.Retryable = ErrorClassUnknown, true
+	return cmdErr
+}
+
+// ClassifyPullError classifies a failed pull of a connector image. Pulls that
+// failed on the network, a rate limit or the daemon keep their retryable class,
+// any other failure is an image error.
+func ClassifyPullError(exitCode int, image string, output []byte) *CommandError {
+	cmdErr := ClassifyCommandError(exitCode, output)
+	switch cmdErr.Class {
+	case ErrorClassNetwork, ErrorClassRateLimit, ErrorClassDocker:
+	default:
+		cmdErr.Class, cmdErr.Retryable = ErrorClassImage, false
+	}
+	cmdErr.Message = fmt.Sprintf("failed to pull image %s: %s", image, cmdErr.Message)
+	return cmdErr
+}
+
+// lastErrorMessage returns the message of the last error or fatal log line,
+// falling back to the last output line
+func lastErrorMessage(lines []string) string {
+	var last string
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if last == "" {
+			last = line
+		}
+		start := strings.Index(line, "{")
+		if start == -1 {
+			continue
+		}
+		var entry struct {
+			Level   string `json:"level"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(line[start:]), &entry); err != nil {
+			continue
+		}
+		if entry.Level == "error" || entry.Level == "fatal" {
+			last = entry.Message
+			break
+		}
+	}
+	if len(last) > 500 {
+		last = last[:500] + "..."
+	}
+	return last
+}
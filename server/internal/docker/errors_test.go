@@ -0,0 +1,96 @@
+package docker
+
+import "testing"
+
+func TestClassifyCommandError(t *testing.T) {
+	cases := []struct {
+		name      string
+		exitCode  int
+		output    string
+		class     string
+		retryable bool
+	}{
+		{"command not found", 127, "exec: olake: not found", ErrorClassConfiguration, false},
+		{"killed", 137, "", ErrorClassOutOfMemory, true},
+		{"pull access denied", 125, "Error response from daemon: pull access denied for olakego/source-foo, repository does not exist or may require 'docker login'", ErrorClassImage, false},
+		{"manifest unknown", 125, "Error response from daemon: manifest for olakego/source-postgres:v9 not found: manifest unknown", ErrorClassImage, false},
+		{"password authentication", 1, `{"level":"error","message":"pq: password authentication failed for user \"olake\""}`, ErrorClassAuthentication, false},
+		{"access denied", 1, "Error 1045: Access denied for user 'olake'@'10.0.0.1'", ErrorClassAuthentication, false},
+		{"missing grant", 1, "pq: permission denied for table orders", ErrorClassAuthentication, false},
+		{"docker socket permission", 1, "permission denied while trying to connect to the Docker daemon socket at unix:///var/run/docker.sock", ErrorClassDocker, true},
+		{"config dir permission", 1, "open /mnt/config/state.json: permission denied", ErrorClassUnknown, true},
+		{"authentication over a reset connection", 1, "authentication failed: connection reset by peer", ErrorClassAuthentication, false},
+		{"missing database", 1, `pq: database "analytics" does not exist`, ErrorClassConfiguration, false},
+		{"missing table", 1, "Table 'shop.orders' does not exist", ErrorClassConfiguration, false},
+		{"invalid config", 1, "failed to unmarshal config: unknown field \"hosts\"", ErrorClassConfiguration, false},
+		{"log line mentioning a missing file", 1, "checkpoint file does not exist, starting from scratch\nread tcp 10.0.0.2:5432: i/o timeout", ErrorClassNetwork, true},
+		{"rate limit", 1, "429 Too Many Requests", ErrorClassRateLimit, true},
+		{"out of memory", 1, "fatal error: runtime: out of memory", ErrorClassOutOfMemory, true},
+		{"docker daemon", 1, "Cannot connect to the Docker daemon at unix:///var/run/docker.sock", ErrorClassDocker, true},
+		{"network", 1, "dial tcp 10.0.0.2:5432: connect: connection refused", ErrorClassNetwork, true},
+		{"docker failed before the container", 125, "docker: invalid reference format", ErrorClassDocker, true},
+		{"unknown", 2, "panic: something else", ErrorClassUnknown, true},
+	}
+	for _, tc := range cases {
+		cmdErr := ClassifyCommandError(tc.exitCode, []byte(tc.output))
+		if cmdErr.Class != tc.class || cmdErr.Retryable != tc.retryable {
+			t.Errorf("%s: class = %s, retryable = %v, want %s, %v", tc.name, cmdErr.Class, cmdErr.Retryable, tc.class, tc.retryable)
+		}
+		if cmdErr.ExitCode != tc.exitCode {
+			t.Errorf("%s: exit code = %d, want %d", tc.name, cmdErr.ExitCode, tc.exitCode)
+		}
+	}
+}
+
+func TestClassifyCommandErrorTail(t *testing.T) {
+	// only the last maxClassifiedLines lines are searched
+	output := "password authentication failed"
+	for i := 0; i < maxClassifiedLines; i++ {
+		output += "\nsyncing"
+	}
+	if cmdErr := ClassifyCommandError(1, []byte(output)); cmdErr.Class != ErrorClassUnknown {
+		t.Errorf("class = %s, want %s for an error before the tail", cmdErr.Class, ErrorClassUnknown)
+	}
+}
+
+func TestLastErrorMessage(t *testing.T) {
+	lines := []string{
+		`2026-01-01T00:00:00Z {"level":"error","message":"first"}`,
+		`2026-01-01T00:00:01Z {"level":"error","message":"sync failed"}`,
+		`2026-01-01T00:00:02Z {"level":"info","message":"cleaning up"}`,
+		"",
+	}
+	if got := lastErrorMessage(lines); got != "sync failed" {
+		t.Errorf("lastErrorMessage = %q, want the last error", got)
+	}
+	if got := lastErrorMessage([]string{"plain output", "last line", " "}); got != "last line" {
+		t.Errorf("lastErrorMessage = %q, want the last line without errors", got)
+	}
+}
+
+func TestClassifyPullError(t *testing.T) {
+	cases := []struct {
+		name      string
+		output    string
+		class     string
+		retryable bool
+	}{
+		{"missing tag", "Error response from daemon: manifest for olakego/source-postgres:latest not found: manifest unknown", ErrorClassImage, false},
+		{"private repository", "Error response from daemon: unauthorized: authentication required", ErrorClassImage, false},
+		{"invalid reference", "invalid reference format", ErrorClassImage, false},
+		{"registry rate limit", "toomanyrequests: You have reached your pull rate limit", ErrorClassRateLimit, true},
+		{"registry unreachable", "Get \"https://registry-1.docker.io/v2/\": dial tcp: lookup registry-1.docker.io: no such host", ErrorClassNetwork, true},
+		{"daemon unreachable", "Cannot connect to the Docker daemon at unix:///var/run/docker.sock", ErrorClassDocker, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ClassifyPullError(1, "olakego/source-postgres:latest", []byte(tc.output))
+			if err.Class != tc.class || err.Retryable != tc.retryable {
+				t.Errorf("ClassifyPullError() = %s (retryable %t), want %s (retryable %t)", err.Class, err.Retryable, tc.class, tc.retryable)
+			}
+			if want := "failed to pull image olakego/source-postgres:latest: " + tc.output; err.Message != want {
+				t.Errorf("message = %q, want %q", err.Message, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+const (
+	// DefaultPreviewRowLimit is the row limit per stream of a preview that sets none
+	DefaultPreviewRowLimit = 100
+	// MaxPreviewRowLimit bounds the row limit per stream a preview may ask for
+	MaxPreviewRowLimit = 10000
+	// DefaultPreviewSampleSize is the number of sample rows per stream of a preview that sets none
+	DefaultPreviewSampleSize = 10
+	// PreviewTimeout stops a preview that has not reached the row limit of every stream
+	PreviewTimeout = 5 * time.Minute
+	// previewCheckInterval is how often the sink of a running preview is checked for the row limit
+	previewCheckInterval = 5 * time.Second
+	// previewSinkDir is where the connector writes a preview, relative to the work directory
+	previewSinkDir = "sink"
+)
+
+// previewWriterConfig writes a preview as parquet files into the work directory instead of the real destination
+var previewWriterConfig = fmt.Sprintf(`{"type":"PARQUET","writer":{"local_path":"/mnt/config/%s"}}`, previewSinkDir)
+
+// RunPreview reads the selected streams of a streams config from scratch into
+// a local parquet sink, stopping the connector once every stream has at least
+// rowLimit rows or PreviewTimeout passed. It returns up to sampleSize rows of
+// each stream along with the column types of the catalog.
+func (r *Runner) RunPreview(ctx context.Context, sourceType, version, config, streamsConfig, workflowID string, rowLimit, sampleSize int) (*models.SyncPreviewResponse, error) {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID)))
+	workDir, err := r.setupWorkDirectory(hash)
+	if err != nil {
+		return nil, err
+	}
+	logs.Info("working directory path %s\n", workDir)
+
+	streams, err := utils.SelectedStreams(streamsConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("no streams selected")
+	}
+	previewStreams, err := utils.SelectStreams(streamsConfig, streams, "")
+	if err != nil {
+		return nil, err
+	}
+	columns, err := utils.StreamColumns(streamsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := []FileConfig{
+		{Name: "config.json", Data: config},
+		{Name: "streams.json", Data: previewStreams},
+		{Name: "writer.json", Data: previewWriterConfig},
+		{Name: "state.json", Data: "{}"},
+	}
+	if err := r.writeConfigFiles(workDir, configs); err != nil {
+		return nil, err
+	}
+	sinkDir := filepath.Join(workDir, previewSinkDir)
+
+	// the container is named so it can be stopped once there are enough rows,
+	// killing the docker client would leave it running
+	containerName := fmt.Sprintf("olake-preview-%s", hash[:16])
+	runCtx, cancel := context.WithTimeout(ctx, PreviewTimeout)
+	defer cancel()
+	done := make(chan struct{})
+	stopped := make(chan bool, 1)
+	go func() {
+		ticker := time.NewTicker(previewCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				stopped <- false
+				return
+			case <-runCtx.Done():
+			case <-ticker.C:
+				if !reachedRowLimit(sinkDir, streams, int64(rowLimit)) {
+					continue
+				}
+			}
+			stopContainer(containerName)
+			stopped <- true
+			return
+		}
+	}()
+
+	_, err = r.executeDockerCommand(ctx, []string{"--rm", "--name", containerName}, "config", Sync, sourceType, version,
+		filepath.Join(workDir, "config.json"), syncArgs...)
+	close(done)
+	stoppedEarly := <-stopped
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil && !stoppedEarly {
+		return nil, err
+	}
+
+	resp := &models.SyncPreviewResponse{WorkflowID: workflowID}
+	for _, stream := range streams {
+		preview := models.StreamPreview{
+			Stream:     stream,
+			Columns:    columns[stream],
+			Complete:   !stoppedEarly,
+			SampleRows: []map[string]interface{}{},
+		}
+		for _, path := range streamFiles(sinkDir, stream) {
+			numRows, rows, err := readParquetFile(path, sampleSize-len(preview.SampleRows))
+			if err != nil {
+				logs.Warn("Skipping preview file %s: %s", path, err)
+				continue
+			}
+			preview.RowCountEstimate += numRows
+			preview.SampleRows = append(preview.SampleRows, rows...)
+		}
+		resp.Streams = append(resp.Streams, preview)
+	}
+	return resp, nil
+}
+
+// reachedRowLimit reports whether every stream has at least rowLimit rows in the sink
+func reachedRowLimit(sinkDir string, streams []string, rowLimit int64) bool {
+	for _, stream := range streams {
+		var count int64
+		for _, path := range streamFiles(sinkDir, stream) {
+			// files the connector is still writing cannot be opened yet
+			if numRows, _, err := readParquetFile(path, 0); err == nil {
+				count += numRows
+			}
+		}
+		if count < rowLimit {
+			return false
+		}
+	}
+	return true
+}
+
+// streamFiles lists the parquet files of a "namespace.name" stream in the sink,
+// which the connector writes below a <namespace>/<name> directory
+func streamFiles(sinkDir, stream string) []string {
+	namespace, name, found := strings.Cut(stream, ".")
+	if !found {
+		namespace, name = "", stream
+	}
+
+	var files []string
+	_ = filepath.WalkDir(sinkDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".parquet" {
+			return nil
+		}
+		rel, err := filepath.Rel(sinkDir, filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		for i := range parts {
+			if parts[i] == name && (namespace == "" || (i > 0 && parts[i-1] == namespace)) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+// readParquetFile returns the row count and up to limit rows of a parquet file
+func readParquetFile(path string, limit int) (int64, []map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	parquetFile, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var rows []map[string]interface{}
+	reader := parquet.NewReader(parquetFile)
+	defer reader.Close()
+	for len(rows) < limit {
+		row := map[string]interface{}{}
+		if err := reader.Read(&row); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return parquetFile.NumRows(), rows, nil
+}
+
+// stopContainer stops a running container, a missing container is not an error
+func stopContainer(name string) {
+	output, err := exec.Command("docker", "stop", name).CombinedOutput()
+	if err != nil && !strings.Contains(string(output), "No such container") {
+		logs.Error("Failed to stop container %s: %s: %s", name, err, string(output))
+	}
+}
@@ -4,16 +4,23 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/beego/beego/v2/core/logs"
 	"github.com/datazip/olake-frontend/server/internal/constants"
 	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/metrics"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/tracing"
 	"github.com/datazip/olake-frontend/server/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Constants
@@ -23,6 +30,13 @@ const (
 	DefaultConfigDir       = "/tmp/olake-config"
 )
 
+// syncArgs point the sync command at the config files of a work directory
+var syncArgs = []string{
+	"--catalog", "/mnt/config/streams.json",
+	"--destination", "/mnt/config/writer.json",
+	"--state", "/mnt/config/state.json",
+}
+
 // Command represents a Docker command type
 type Command string
 
@@ -31,6 +45,8 @@ const (
 	Spec     Command = "spec"
 	Check    Command = "check"
 	Sync     Command = "sync"
+	// Pull is the docker pull run before commands of latest images
+	Pull Command = "pull"
 )
 
 // File configuration for different operations
@@ -90,23 +106,42 @@ func (r *Runner) GetDockerImageName(sourceType, version string) string {
 
 // ExecuteDockerCommand executes a Docker command with the given parameters
 func (r *Runner) ExecuteDockerCommand(ctx context.Context, flag string, command Command, sourceType, version, configPath string, additionalArgs ...string) ([]byte, error) {
+	return r.executeDockerCommand(ctx, nil, flag, command, sourceType, version, configPath, additionalArgs...)
+}
+
+// executeDockerCommand executes a Docker command, runArgs are passed to docker run before the image
+func (r *Runner) executeDockerCommand(ctx context.Context, runArgs []string, flag string, command Command, sourceType, version, configPath string, additionalArgs ...string) (output []byte, err error) {
+	ctx, span := tracing.Start(ctx, "docker."+string(command), tracing.Connector(sourceType, version)...)
+	defer func() { tracing.End(span, err) }()
+
 	outputDir := filepath.Dir(configPath)
 	if err := utils.CreateDirectory(outputDir, DefaultDirPermissions); err != nil {
 		return nil, err
 	}
+	if version == "latest" {
+		if err := r.pullImage(ctx, r.GetDockerImageName(sourceType, version)); err != nil {
+			return nil, err
+		}
+	}
 
-	dockerArgs := r.buildDockerArgs(flag, command, sourceType, version, configPath, outputDir, additionalArgs...)
+	// the connector continues the trace of the command
+	for _, env := range tracing.ContainerEnv(ctx) {
+		runArgs = append(runArgs, "-e", env)
+	}
+	dockerArgs := r.buildDockerArgs(runArgs, flag, command, sourceType, version, configPath, outputDir, additionalArgs...)
 
 	logs.Info("Running Docker command: docker %s\n", strings.Join(dockerArgs, " "))
 
+	start := time.Now()
 	dockerCmd := exec.CommandContext(ctx, "docker", dockerArgs...)
-	output, err := dockerCmd.CombinedOutput()
+	output, err = dockerCmd.CombinedOutput()
+	recordDockerCommand(ctx, command, start, dockerCmd)
 
 	logs.Info("Docker command output: %s\n", string(output))
 
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("docker command failed with exit status %d", exitErr.ExitCode())
+		if exitErr, ok := err.(*exec.ExitError); ok && ctx.Err() == nil {
+			return nil, ClassifyCommandError(exitErr.ExitCode(), output)
 		}
 		return nil, err
 	}
@@ -114,14 +149,50 @@ func (r *Runner) ExecuteDockerCommand(ctx context.Context, flag string, command
 	return output, nil
 }
 
-// buildDockerArgs constructs Docker command arguments
-func (r *Runner) buildDockerArgs(flag string, command Command, sourceType, version, configPath, outputDir string, additionalArgs ...string) []string {
-	hostOutputDir := r.getHostOutputDir(outputDir)
-	dockerArgs := []string{"run"}
+// pullImage pulls the latest build of a connector image, traced apart from the
+// command so slow pulls are told apart from slow connectors
+func (r *Runner) pullImage(ctx context.Context, image string) (err error) {
+	ctx, span := tracing.Start(ctx, "docker.pull", attribute.String("olake.image", image))
+	defer func() { tracing.End(span, err) }()
 
-	if version == "latest" {
-		dockerArgs = append(dockerArgs, "--pull=always")
+	start := time.Now()
+	pullCmd := exec.CommandContext(ctx, "docker", "pull", "--quiet", image)
+	output, err := pullCmd.CombinedOutput()
+	recordDockerCommand(ctx, Pull, start, pullCmd)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && ctx.Err() == nil {
+			return ClassifyPullError(exitErr.ExitCode(), image, output)
+		}
+		return fmt.Errorf("failed to pull image %s: %s: %s", image, err, strings.TrimSpace(string(output)))
 	}
+	return nil
+}
+
+// ServerVersion returns the version of the Docker daemon, failing when the
+// daemon can not be reached through the docker CLI
+func ServerVersion(ctx context.Context) (string, error) {
+	output, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker daemon is unreachable: %s: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// recordDockerCommand records the duration and exit code of a finished Docker command
+func recordDockerCommand(ctx context.Context, command Command, start time.Time, cmd *exec.Cmd) {
+	exitCode := -1
+	// a command killed because ctx is done did not exit on its own
+	if cmd.ProcessState != nil && ctx.Err() == nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	metrics.DockerCommandDuration.WithLabelValues(string(command)).Observe(metrics.Since(start))
+	metrics.DockerCommandExits.WithLabelValues(string(command), strconv.Itoa(exitCode)).Inc()
+}
+
+// buildDockerArgs constructs Docker command arguments
+func (r *Runner) buildDockerArgs(runArgs []string, flag string, command Command, sourceType, version, configPath, outputDir string, additionalArgs ...string) []string {
+	hostOutputDir := r.getHostOutputDir(outputDir)
+	dockerArgs := append([]string{"run"}, runArgs...)
 
 	dockerArgs = append(dockerArgs,
 		"-v", fmt.Sprintf("%s:/mnt/config", hostOutputDir),
@@ -218,14 +289,23 @@ func (r *Runner) GetCatalog(ctx context.Context, sourceType, version, config, wo
 	return utils.ParseJSONFile(catalogPath)
 }
 
-// RunSync runs the sync command to transfer data from source to destination
-func (r *Runner) RunSync(ctx context.Context, jobID int, workflowID string) (map[string]interface{}, error) {
+// RunSync runs the sync command to transfer data from source to destination.
+// States written while the sync runs are saved as checkpoints and passed to
+// report, see watchState.
+func (r *Runner) RunSync(ctx context.Context, jobID int, workflowID string, report func(*Checkpoint)) (map[string]interface{}, error) {
 	// Generate unique directory name
 	workDir, err := r.setupWorkDirectory(fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID))))
 	if err != nil {
 		return nil, err
 	}
 	logs.Info("working directory path %s\n", workDir)
+	// the revision is read before the job, so a state saved in between is kept
+	// over the state of this run rather than lost
+	stateORM := database.NewJobStateHistoryORM()
+	baseRevision, err := stateORM.LatestRevision(jobID)
+	if err != nil {
+		return nil, err
+	}
 	// Get current job state
 	jobORM := database.NewJobORM()
 	job, err := jobORM.GetByID(jobID, false)
@@ -249,10 +329,9 @@ func (r *Runner) RunSync(ctx context.Context, jobID int, workflowID string) (map
 	statePath := filepath.Join(workDir, "state.json")
 
 	// Execute sync command
-	_, err = r.ExecuteDockerCommand(ctx, "config", Sync, job.SourceID.Type, job.SourceID.Version, configPath,
-		"--catalog", "/mnt/config/streams.json",
-		"--destination", "/mnt/config/writer.json",
-		"--state", "/mnt/config/state.json")
+	stopWatching := watchState(ctx, job, workflowID, baseRevision, statePath, report)
+	err = r.runSyncCommand(ctx, job, configPath)
+	stopWatching()
 	if err != nil {
 		return nil, err
 	}
@@ -262,13 +341,76 @@ func (r *Runner) RunSync(ctx context.Context, jobID int, workflowID string) (map
 		return nil, err
 	}
 
-	// Update job state if we have valid result
+	// Update job state if we have valid result, keeping the run that produced it
 	if stateJSON, err := json.Marshal(result); err == nil {
-		job.State = string(stateJSON)
-		job.Active = true
-		if err := jobORM.Update(job); err != nil {
+		err := stateORM.SaveState(job, &models.JobStateHistory{
+			State:        string(stateJSON),
+			Reason:       constants.StateChangeSync,
+			WorkflowID:   workflowID,
+			BaseRevision: baseRevision,
+		})
+		if errors.Is(err, database.ErrStateChanged) {
+			// the state was reset or replaced while the sync ran, the next run starts from it
+			logs.Warn("Keeping the state of job[%d] changed during sync %s: %s", job.ID, workflowID, err)
+		} else if err != nil {
 			return nil, err
 		}
+		if !job.Active {
+			// job still holds the state this run started from
+			job.Active = true
+			if err := jobORM.UpdateActive(job); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return result, nil
 }
+
+// RunBackfill runs a one-off sync of some streams of a job from scratch, from a
+// copy of the job state that is discarded afterwards. filter, when not empty,
+// limits the rows read from each of the streams.
+func (r *Runner) RunBackfill(ctx context.Context, jobID int, workflowID string, streams []string, filter string) (map[string]interface{}, error) {
+	workDir, err := r.setupWorkDirectory(fmt.Sprintf("%x", sha256.Sum256([]byte(workflowID))))
+	if err != nil {
+		return nil, err
+	}
+	logs.Info("working directory path %s\n", workDir)
+	job, err := database.NewJobORM().GetByID(jobID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	streamsConfig, err := utils.SelectStreams(job.StreamsConfig, streams, filter)
+	if err != nil {
+		return nil, err
+	}
+	state, err := utils.WithoutStreams(job.State, streams)
+	if err != nil {
+		return nil, err
+	}
+	configs := []FileConfig{
+		{Name: "config.json", Data: job.SourceID.Config},
+		{Name: "streams.json", Data: streamsConfig},
+		{Name: "writer.json", Data: job.DestID.Config},
+		{Name: "state.json", Data: state},
+	}
+	if err := r.writeConfigFiles(workDir, configs); err != nil {
+		return nil, err
+	}
+
+	if err := r.runSyncCommand(ctx, job, filepath.Join(workDir, "config.json")); err != nil {
+		return nil, err
+	}
+	return utils.ParseJSONFile(filepath.Join(workDir, "state.json"))
+}
+
+// runSyncCommand runs the sync command on the config files of a work directory
+// and records the records it reports, a failed sync may have synced some
+func (r *Runner) runSyncCommand(ctx context.Context, job *models.Job, configPath string) error {
+	workDir := filepath.Dir(configPath)
+	// the stats of an earlier attempt in the same work directory are already recorded
+	_ = os.Remove(filepath.Join(workDir, statsFile))
+	_, err := r.ExecuteDockerCommand(ctx, "config", Sync, job.SourceID.Type, job.SourceID.Version, configPath, syncArgs...)
+	recordSyncStats(job.ID, workDir)
+	return err
+}
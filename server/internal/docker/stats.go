@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/datazip/olake-frontend/server/internal/metrics"
+)
+
+// statsFile is where the connector reports the progress of a sync, next to its config
+const statsFile = "stats.json"
+
+// SyncStats are the fields of the stats file of a sync that are recorded, the
+// connector also writes its memory, speed and elapsed and remaining time as text
+type SyncStats struct {
+	// SyncedRecords is the total of records the sync read across all streams
+	SyncedRecords int64 `json:"Synced Records"`
+}
+
+// readSyncStats reads the stats file in workDir, it is missing when the sync failed before writing it
+func readSyncStats(workDir string) (*SyncStats, error) {
+	data, err := os.ReadFile(filepath.Join(workDir, statsFile))
+	if err != nil {
+		return nil, err
+	}
+	var stats SyncStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// recordSyncStats adds the records of the stats file in workDir to the sync metrics of a job
+func recordSyncStats(jobID int, workDir string) {
+	stats, err := readSyncStats(workDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logs.Warning("Failed to read sync stats of job[%d]: %s", jobID, err)
+		}
+		return
+	}
+	metrics.SyncRecords.WithLabelValues(metrics.JobLabel(jobID)).Add(float64(stats.SyncedRecords))
+}
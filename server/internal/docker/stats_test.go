@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordSyncStats(t *testing.T) {
+	workDir := t.TempDir()
+	// the stats file as the connector writes it
+	stats := `{"Estimated Remaining Time": "0.00 s", "Memory": "41 mb", "Seconds Elapsed": "12.00", "Speed": "1000.00 rps", "Synced Records": 12000}`
+	if err := os.WriteFile(filepath.Join(workDir, statsFile), []byte(stats), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	counter := metrics.SyncRecords.WithLabelValues(metrics.JobLabel(41))
+	before := testutil.ToFloat64(counter)
+	recordSyncStats(41, workDir)
+	if got := testutil.ToFloat64(counter) - before; got != 12000 {
+		t.Errorf("recorded %v records, want 12000", got)
+	}
+
+	// syncs that failed before writing stats record nothing
+	recordSyncStats(41, t.TempDir())
+	if got := testutil.ToFloat64(counter) - before; got != 12000 {
+		t.Errorf("recorded %v records after a sync without stats, want 12000", got)
+	}
+}
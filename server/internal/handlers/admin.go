@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// requireAdmin responds with 403 unless the session user is listed in
+// adminusers of conf/app.conf, it reports whether the request may go on
+func requireAdmin(c *web.Controller) bool {
+	userID, ok := c.GetSession(constants.SessionUserID).(int)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusForbidden, "Admin access required")
+		return false
+	}
+	user, err := database.NewUserORM().GetByID(userID)
+	if err != nil {
+		logs.Error("Failed to get user[%d]: %s", userID, err)
+		utils.ErrorResponse(c, http.StatusForbidden, "Admin access required")
+		return false
+	}
+	if !isAdmin(web.AppConfig.DefaultString("adminusers", ""), user.Username) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Admin access required")
+		return false
+	}
+	return true
+}
+
+// isAdmin reports whether a username is in a comma separated list of admins
+func isAdmin(admins, username string) bool {
+	for _, admin := range strings.Split(admins, ",") {
+		if admin = strings.TrimSpace(admin); admin != "" && admin == username {
+			return true
+		}
+	}
+	return false
+}
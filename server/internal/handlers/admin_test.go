@@ -0,0 +1,22 @@
+package handlers
+
+import "testing"
+
+func TestIsAdmin(t *testing.T) {
+	cases := []struct {
+		admins   string
+		username string
+		want     bool
+	}{
+		{"admin", "admin", true},
+		{"ops, admin ", "admin", true},
+		{"ops,admin", "adm", false},
+		{"", "admin", false},
+		{",", "", false},
+	}
+	for _, tc := range cases {
+		if got := isAdmin(tc.admins, tc.username); got != tc.want {
+			t.Errorf("isAdmin(%q, %q) = %t, want %t", tc.admins, tc.username, got, tc.want)
+		}
+	}
+}
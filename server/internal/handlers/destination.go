@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -45,13 +44,14 @@ func (c *DestHandler) GetAllDestinations() {
 	destItems := make([]models.DestinationDataItem, 0, len(destinations))
 	for _, dest := range destinations {
 		item := models.DestinationDataItem{
-			ID:        dest.ID,
-			Name:      dest.Name,
-			Type:      dest.DestType,
-			Version:   dest.Version,
-			Config:    dest.Config,
-			CreatedAt: dest.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: dest.UpdatedAt.Format(time.RFC3339),
+			ID:                 dest.ID,
+			Name:               dest.Name,
+			Type:               dest.DestType,
+			Version:            dest.Version,
+			Config:             dest.Config,
+			CreatedAt:          dest.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:          dest.UpdatedAt.Format(time.RFC3339),
+			MaxConcurrentSyncs: dest.MaxConcurrentSyncs,
 		}
 
 		setUsernames(&item.CreatedBy, &item.UpdatedBy, dest.CreatedBy, dest.UpdatedBy)
@@ -79,14 +79,19 @@ func (c *DestHandler) CreateDestination() {
 		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
 		return
 	}
+	if req.MaxConcurrentSyncs < 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "max_concurrent_syncs must not be negative")
+		return
+	}
 
 	// Convert request to Destination model
 	destination := &models.Destination{
-		Name:      req.Name,
-		DestType:  req.Type,
-		Version:   req.Version,
-		Config:    req.Config,
-		ProjectID: projectIDStr,
+		Name:               req.Name,
+		DestType:           req.Type,
+		Version:            req.Version,
+		Config:             req.Config,
+		ProjectID:          projectIDStr,
+		MaxConcurrentSyncs: req.MaxConcurrentSyncs,
 	}
 
 	// Set created by if user is logged in
@@ -115,6 +120,10 @@ func (c *DestHandler) UpdateDestination() {
 		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
 		return
 	}
+	if req.MaxConcurrentSyncs < 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "max_concurrent_syncs must not be negative")
+		return
+	}
 
 	// Get existing destination
 	existingDest, err := c.destORM.GetByID(id)
@@ -128,6 +137,7 @@ func (c *DestHandler) UpdateDestination() {
 	existingDest.DestType = req.Type
 	existingDest.Version = req.Version
 	existingDest.Config = req.Config
+	existingDest.MaxConcurrentSyncs = req.MaxConcurrentSyncs
 	existingDest.UpdatedAt = time.Now()
 
 	// Update user who made changes
@@ -147,33 +157,57 @@ func (c *DestHandler) UpdateDestination() {
 
 // @router /project/:projectid/destinations/:id [delete]
 func (c *DestHandler) DeleteDestination() {
-	// Get destination ID from path
 	id := GetIDFromPath(&c.Controller)
-	// Get the name for the response
+	if id == 0 {
+		return
+	}
+	mode, ok := deleteMode(&c.Controller)
+	if !ok {
+		return
+	}
 	dest, err := c.destORM.GetByID(id)
 	if err != nil {
 		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Destination not found")
 		return
 	}
-	jobs, err := c.jobORM.GetByDestinationID(id)
+
+	// the schedule changes commit with the delete in the schedule outbox, which
+	// keeps retrying them so that no schedule keeps firing for a deleted destination
+	ctx := c.Ctx.Request.Context()
+	jobs, ops, err := c.destORM.Delete(id, mode, string(connectorDeleteAction(mode)))
 	if err != nil {
-		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to get source by id")
+		deleteErrorResponse(&c.Controller, "Destination", jobs, err)
+		return
 	}
-	for _, job := range jobs {
-		job.Active = false
-		if err := c.jobORM.Update(job); err != nil {
-			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to deactivate jobs using this destination")
-			return
-		}
+	applyScheduleOperations(ctx, c.tempClient, ops...)
+	emitDeleteModeEvents(ctx, c.tempClient, mode, jobs)
+
+	resp := &models.DeleteDestinationResponse{Name: dest.Name}
+	if len(jobs) > 0 {
+		resp.Mode, resp.Jobs = mode, jobNames(jobs)
 	}
-	if err := c.destORM.Delete(id); err != nil {
-		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to delete destination")
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/destinations/:id/restore [post]
+func (c *DestHandler) RestoreDestination() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
 		return
 	}
-
-	utils.SuccessResponse(&c.Controller, &models.DeleteDestinationResponse{
-		Name: dest.Name,
-	})
+	// jobs deleted with the destination come back inactive with paused schedules
+	jobs, ops, err := c.destORM.Restore(c.Ctx.Input.Param(":projectid"), id, string(temporal.ActionCreate))
+	if err != nil {
+		restoreErrorResponse(&c.Controller, "Destination", err)
+		return
+	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, ops...)
+	dest, err := c.destORM.GetByID(id)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get restored destination: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(dest.Name, jobs))
 }
 
 // @router /project/:projectid/destinations/test [post]
@@ -200,7 +234,7 @@ func (c *DestHandler) TestConnection() {
 		return
 	}
 	// TODO: use context provided by request
-	result, err := c.tempClient.TestConnection(context.Background(), "destination", "postgres", "latest", encryptedConfig)
+	result, err := c.tempClient.TestConnection(c.Ctx.Request.Context(), "destination", "postgres", "latest", encryptedConfig)
 	if result == nil {
 		result = map[string]interface{}{
 			"message": err.Error(),
@@ -210,7 +244,7 @@ func (c *DestHandler) TestConnection() {
 	utils.SuccessResponse(&c.Controller, result)
 }
 
-// @router /destinations/:id/jobs [get]
+// @router /project/:projectid/destinations/:id/jobs [get]
 func (c *DestHandler) GetDestinationJobs() {
 	id := GetIDFromPath(&c.Controller)
 	// Check if destination exists
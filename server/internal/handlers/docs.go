@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/openapi"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// swaggerUIVersion is the swagger-ui-dist release the docs page loads
+const swaggerUIVersion = "5.17.14"
+
+var swaggerUIPage = fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>OLake Server API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@%[1]s/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@%[1]s/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui", withCredentials: true });
+    };
+  </script>
+</body>
+</html>
+`, swaggerUIVersion)
+
+// the routes do not change once the server runs, so the document is built once
+var (
+	openAPISpecOnce sync.Once
+	openAPISpec     []byte
+	openAPISpecErr  error
+)
+
+type DocsHandler struct {
+	web.Controller
+}
+
+// @router /api/v1/openapi.json [get]
+func (c *DocsHandler) GetOpenAPISpec() {
+	openAPISpecOnce.Do(func() {
+		openAPISpec, openAPISpecErr = json.Marshal(openapi.Build(openapi.RegisteredRoutes()))
+	})
+	if openAPISpecErr != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to build OpenAPI document: %s", openAPISpecErr))
+		return
+	}
+	c.Ctx.Output.Header("Content-Type", "application/json")
+	_ = c.Ctx.Output.Body(openAPISpec)
+}
+
+// @router /api/v1/docs [get]
+func (c *DocsHandler) GetSwaggerUI() {
+	c.Ctx.Output.Header("Content-Type", "text/html; charset=utf-8")
+	_ = c.Ctx.Output.Body([]byte(swaggerUIPage))
+}
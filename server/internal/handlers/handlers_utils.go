@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -74,7 +73,7 @@ func buildJobDataItems(jobs []*models.Job, err error, projectIDStr, contextType
 func setJobWorkflowInfo(jobInfo *models.JobDataItem, jobID int, projectIDStr string, tempClient *temporal.Client, controller *web.Controller) bool {
 	query := fmt.Sprintf("WorkflowId between 'sync-%s-%d' and 'sync-%s-%d-~'", projectIDStr, jobID, projectIDStr, jobID)
 
-	resp, err := tempClient.ListWorkflow(context.Background(), &workflowservice.ListWorkflowExecutionsRequest{
+	resp, err := tempClient.ListWorkflow(controller.Ctx.Request.Context(), &workflowservice.ListWorkflowExecutionsRequest{
 		Query:    query,
 		PageSize: 1,
 	})
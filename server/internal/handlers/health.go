@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/beego/beego/v2/core/config"
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+	"github.com/spf13/viper"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/health"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// HealthHandler serves the probes of the server. The server never runs
+// containers, so unlike the worker its readiness does not depend on Docker.
+type HealthHandler struct {
+	web.Controller
+}
+
+var (
+	probeClientMu sync.Mutex
+	probeClient   *temporal.Client
+)
+
+// sharedTemporalClient returns the Temporal client the probes share so that
+// frequent probes do not dial Temporal every time, a failed dial is retried
+// by the next probe
+func sharedTemporalClient() (*temporal.Client, error) {
+	probeClientMu.Lock()
+	defer probeClientMu.Unlock()
+	if probeClient != nil {
+		return probeClient, nil
+	}
+	tempClient, err := temporal.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	probeClient = tempClient
+	return probeClient, nil
+}
+
+// @router /healthz [get]
+func (c *HealthHandler) Liveness() {
+	utils.SuccessResponse(&c.Controller, map[string]string{"status": "ok"})
+}
+
+// @router /readyz [get]
+func (c *HealthHandler) Readiness() {
+	tempClient, dialErr := sharedTemporalClient()
+	report := health.Run(c.Ctx.Request.Context(), serverChecks(tempClient, dialErr)...)
+	if !report.Ready {
+		utils.RespondJSON(&c.Controller, http.StatusServiceUnavailable, false, "Server is not ready", report)
+		return
+	}
+	utils.SuccessResponse(&c.Controller, report)
+}
+
+// @router /diagnostics [get]
+func (c *HealthHandler) GetDiagnostics() {
+	if !requireAdmin(&c.Controller) {
+		return
+	}
+	ctx := c.Ctx.Request.Context()
+	tempClient, dialErr := sharedTemporalClient()
+
+	resp := models.DiagnosticsResponse{
+		Build:          viper.GetString("BUILD"),
+		CommitSHA:      viper.GetString("COMMITSHA"),
+		ReleaseChannel: viper.GetString("RELEASE_CHANNEL"),
+		GoVersion:      runtime.Version(),
+		Config:         configSummary(),
+		Readiness:      health.Run(ctx, serverChecks(tempClient, dialErr)...),
+		TaskQueue:      models.TaskQueueResponse{Name: temporal.TaskQueue, Pollers: []models.TaskQueuePoller{}},
+	}
+	if dialErr != nil {
+		resp.TaskQueue.Error = dialErr.Error()
+	} else if pollers, err := tempClient.TaskQueuePollers(ctx); err != nil {
+		resp.TaskQueue.Error = err.Error()
+	} else {
+		resp.TaskQueue.Pollers = pollers
+	}
+	if pending, err := database.NewScheduleOutboxORM().CountPending(); err != nil {
+		logs.Warn("Failed to count pending schedule operations: %s", err)
+	} else {
+		resp.PendingScheduleOperations = pending
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// serverChecks are the readiness checks of the server, dialErr fails the
+// Temporal check when the client could not be created
+func serverChecks(tempClient *temporal.Client, dialErr error) []health.Check {
+	return []health.Check{
+		health.Database(),
+		{Name: "temporal", Run: func(ctx context.Context) error {
+			if dialErr != nil {
+				return dialErr
+			}
+			return tempClient.CheckHealth(ctx)
+		}},
+		health.ConfigDir(docker.GetDefaultConfigDir()),
+		health.EncryptionKey(),
+	}
+}
+
+// configSummary lists the settings that explain how the server is wired, with credentials redacted
+func configSummary() map[string]string {
+	logsDir, _ := config.String("logsdir")
+	postgresDB, _ := config.String("postgresdb")
+	return map[string]string{
+		"run_mode":         web.BConfig.RunMode,
+		"http_port":        strconv.Itoa(web.BConfig.Listen.HTTPPort),
+		"session":          strconv.FormatBool(web.BConfig.WebConfig.Session.SessionOn),
+		"postgresdb":       redactURL(postgresDB),
+		"temporal_address": temporal.TemporalAddress,
+		"logs_dir":         logsDir,
+		"config_dir":       docker.GetDefaultConfigDir(),
+		"persistent_dir":   os.Getenv("PERSISTENT_DIR"),
+		"encryption":       utils.EncryptionMode(),
+	}
+}
+
+// redactURL hides the password of a connection URL, values that are not URLs are hidden entirely
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if value == "" {
+			return ""
+		}
+		return "xxxxx"
+	}
+	return u.Redacted()
+}
@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -25,10 +25,16 @@ import (
 
 type JobHandler struct {
 	web.Controller
-	jobORM     *database.JobORM
-	sourceORM  *database.SourceORM
-	destORM    *database.DestinationORM
-	tempClient *temporal.Client
+	jobORM          *database.JobORM
+	sourceORM       *database.SourceORM
+	destORM         *database.DestinationORM
+	settingsORM     *database.ProjectSettingsORM
+	depORM          *database.JobDependencyORM
+	stateORM        jobStateStore
+	templateORM     *database.JobTemplateORM
+	channelORM      *database.NotificationChannelORM
+	notificationORM *database.JobNotificationORM
+	tempClient      *temporal.Client
 }
 
 // Prepare initializes the ORM instances
@@ -36,6 +42,12 @@ func (c *JobHandler) Prepare() {
 	c.jobORM = database.NewJobORM()
 	c.sourceORM = database.NewSourceORM()
 	c.destORM = database.NewDestinationORM()
+	c.settingsORM = database.NewProjectSettingsORM()
+	c.depORM = database.NewJobDependencyORM()
+	c.stateORM = database.NewJobStateHistoryORM()
+	c.templateORM = database.NewJobTemplateORM()
+	c.channelORM = database.NewNotificationChannelORM()
+	c.notificationORM = database.NewJobNotificationORM()
 	var err error
 	c.tempClient, err = temporal.NewClient()
 	if err != nil {
@@ -66,6 +78,13 @@ func (c *JobHandler) GetAllJobs() {
 			Activate:      job.Active,
 		}
 
+		if scheduleConfig, err := temporal.ParseScheduleConfig(job.ScheduleConfig); err == nil {
+			jobResp.ScheduleConfig = scheduleConfig
+		}
+		if retryPolicy, err := temporal.ParseRetryPolicy(job.RetryPolicy); err == nil {
+			jobResp.RetryPolicy = retryPolicy
+		}
+
 		// Set source and destination details
 		if job.SourceID != nil {
 			jobResp.Source = models.JobSourceConfig{
@@ -96,7 +115,7 @@ func (c *JobHandler) GetAllJobs() {
 		// Get workflow information if Temporal client is available
 		if c.tempClient != nil {
 			query := fmt.Sprintf("WorkflowId between 'sync-%s-%d' and 'sync-%s-%d-~'", projectIDStr, job.ID, projectIDStr, job.ID)
-			if resp, err := c.tempClient.ListWorkflow(context.Background(), &workflowservice.ListWorkflowExecutionsRequest{
+			if resp, err := c.tempClient.ListWorkflow(c.Ctx.Request.Context(), &workflowservice.ListWorkflowExecutionsRequest{
 				Query:    query,
 				PageSize: 1,
 			}); err != nil {
@@ -124,61 +143,27 @@ func (c *JobHandler) CreateJob() {
 		return
 	}
 
-	// Find or create source
-	source, err := c.getOrCreateSource(req.Source, projectIDStr)
+	projectWindows, err := temporal.LoadProjectBlackoutWindows(projectIDStr)
 	if err != nil {
-		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to process source: %s", err))
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
 		return
 	}
-
-	// Find or create destination
-	dest, err := c.getOrCreateDestination(req.Destination, projectIDStr)
+	scheduleConfig, err := validateJobSchedule(req.Frequency, req.ScheduleConfig, projectWindows)
 	if err != nil {
-		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to process destination: %s", err))
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid schedule: %s", err))
 		return
 	}
-
-	// Create job model
-	job := &models.Job{
-		Name:          req.Name,
-		SourceID:      source,
-		DestID:        dest,
-		Active:        true,
-		Frequency:     req.Frequency,
-		StreamsConfig: req.StreamsConfig,
-		State:         "{}",
-		ProjectID:     projectIDStr,
-	}
-	// Set user information
-	userID := c.GetSession(constants.SessionUserID)
-	if userID != nil {
-		user := &models.User{ID: userID.(int)}
-		job.CreatedBy = user
-		job.UpdatedBy = user
+	retryPolicy, err := validateJobRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid retry policy: %s", err))
+		return
 	}
 
-	// Create job in database
-	if err := c.jobORM.Create(job); err != nil {
+	if _, err := c.createJob(&req, scheduleConfig, retryPolicy, projectIDStr); err != nil {
 		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to create job: %s", err))
 		return
 	}
 
-	if c.tempClient != nil {
-		fmt.Println("Using Temporal workflow for sync job")
-		_, err = c.tempClient.ManageSync(
-			c.Ctx.Request.Context(),
-			job.ProjectID,
-			job.ID,
-			job.Frequency,
-			temporal.ActionCreate,
-		)
-		if err != nil {
-			fmt.Printf("Temporal workflow execution failed: %v", err)
-		} else {
-			fmt.Println("Successfully executed sync job via Temporal")
-		}
-	}
-
 	utils.SuccessResponse(&c.Controller, req)
 }
 
@@ -195,6 +180,22 @@ func (c *JobHandler) UpdateJob() {
 		return
 	}
 
+	projectWindows, err := temporal.LoadProjectBlackoutWindows(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
+		return
+	}
+	scheduleConfig, err := validateJobSchedule(req.Frequency, req.ScheduleConfig, projectWindows)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid schedule: %s", err))
+		return
+	}
+	retryPolicy, err := validateJobRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid retry policy: %s", err))
+		return
+	}
+
 	// Get existing job
 	existingJob, err := c.jobORM.GetByID(id, true)
 	if err != nil {
@@ -222,6 +223,8 @@ func (c *JobHandler) UpdateJob() {
 	existingJob.DestID = dest
 	existingJob.Active = req.Activate
 	existingJob.Frequency = req.Frequency
+	existingJob.ScheduleConfig = scheduleConfig
+	existingJob.RetryPolicy = retryPolicy
 	existingJob.StreamsConfig = req.StreamsConfig
 	existingJob.UpdatedAt = time.Now()
 	existingJob.ProjectID = projectIDStr
@@ -233,24 +236,14 @@ func (c *JobHandler) UpdateJob() {
 		existingJob.UpdatedBy = user
 	}
 
-	// Update job in database
-	if err := c.jobORM.Update(existingJob); err != nil {
+	// Update job in database together with the update of its schedule
+	op, err := c.jobORM.UpdateScheduled(existingJob, string(temporal.ActionUpdate))
+	if err != nil {
 		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to update job")
 		return
 	}
-	if c.tempClient != nil {
-		logs.Info("Using Temporal workflow for sync job")
-		_, err = c.tempClient.ManageSync(
-			c.Ctx.Request.Context(),
-			existingJob.ProjectID,
-			existingJob.ID,
-			existingJob.Frequency,
-			temporal.ActionUpdate,
-		)
-		if err != nil {
-			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Temporal workflow execution failed: %s", err))
-		}
-	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, op)
+	emitJobEvent(c.Ctx.Request.Context(), c.tempClient, constants.WebhookEventJobUpdated, existingJob)
 
 	utils.SuccessResponse(&c.Controller, req)
 }
@@ -272,29 +265,41 @@ func (c *JobHandler) DeleteJob() {
 	}
 
 	jobName := job.Name
-	if c.tempClient != nil {
-		logs.Info("Using Temporal workflow for delete job schedule")
-		_, err = c.tempClient.ManageSync(
-			c.Ctx.Request.Context(),
-			job.ProjectID,
-			job.ID,
-			job.Frequency,
-			temporal.ActionDelete,
-		)
-		if err != nil {
-			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Temporal workflow execution failed for delete job schedule: %s", err))
-		}
-	}
-	// Delete job
-	if err := c.jobORM.Delete(id); err != nil {
+	// Move the job to the trash together with the deletion of its schedule, its
+	// state history, notification subscriptions and dependencies stay for a restore
+	op, err := c.jobORM.DeleteScheduled(job, string(temporal.ActionDelete))
+	if err != nil {
 		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to delete job")
 		return
 	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, op)
+	emitJobEvent(c.Ctx.Request.Context(), c.tempClient, constants.WebhookEventJobDeleted, job)
 	utils.SuccessResponse(&c.Controller, models.DeleteDestinationResponse{
 		Name: jobName,
 	})
 }
 
+// @router /project/:projectid/jobs/:id/restore [post]
+func (c *JobHandler) RestoreJob() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	// deleting the job deleted its schedule, the outbox creates it again
+	op, err := c.jobORM.Restore(c.Ctx.Input.Param(":projectid"), id, string(temporal.ActionCreate))
+	if err != nil {
+		restoreErrorResponse(&c.Controller, "Job", err)
+		return
+	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, op)
+	job, err := c.jobORM.GetByID(id, true)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get restored job: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(job.Name, nil))
+}
+
 // @router /project/:projectid/jobs/:id/sync [post]
 func (c *JobHandler) SyncJob() {
 	idStr := c.Ctx.Input.Param(":id")
@@ -303,6 +308,13 @@ func (c *JobHandler) SyncJob() {
 		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid job ID")
 		return
 	}
+	// manual runs may explicitly ignore blackout windows
+	overrideBlackout, err := c.GetBool("override_blackout", false)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid override_blackout value")
+		return
+	}
+
 	// Check if job exists
 	job, err := c.jobORM.GetByID(id, true)
 	if err != nil {
@@ -315,18 +327,29 @@ func (c *JobHandler) SyncJob() {
 		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Job must have both source and destination configured")
 		return
 	}
+	if msg := detachedJobMessage(job); msg != "" {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, msg)
+		return
+	}
 
+	action := temporal.ActionTrigger
+	if overrideBlackout {
+		action = temporal.ActionForceTrigger
+	}
 	if c.tempClient != nil {
 		logs.Info("Using Temporal workflow for sync job")
 		_, err = c.tempClient.ManageSync(
 			c.Ctx.Request.Context(),
-			job.ProjectID,
-			job.ID,
-			job.Frequency,
-			temporal.ActionTrigger,
+			job,
+			action,
 		)
+		if errors.Is(err, temporal.ErrBlackoutWindow) {
+			utils.ErrorResponse(&c.Controller, http.StatusConflict, fmt.Sprintf("%s, use override_blackout=true to run anyway", err))
+			return
+		}
 		if err != nil {
 			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Temporal workflow execution failed: %s", err))
+			return
 		}
 	}
 	utils.SuccessResponse(&c.Controller, nil)
@@ -349,23 +372,14 @@ func (c *JobHandler) ActivateJob() {
 		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Job not found")
 		return
 	}
+	if msg := detachedJobMessage(job); req.Activate && msg != "" {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, msg)
+		return
+	}
 	action := temporal.ActionUnpause
 	if !req.Activate {
 		action = temporal.ActionPause
 	}
-	if c.tempClient != nil {
-		logs.Info("Using Temporal workflow for activate job schedule")
-		_, err = c.tempClient.ManageSync(
-			c.Ctx.Request.Context(),
-			job.ProjectID,
-			job.ID,
-			job.Frequency,
-			action,
-		)
-		if err != nil {
-			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Temporal workflow execution failed for activate job schedule: %s", err))
-		}
-	}
 	// Update activation status
 	job.Active = req.Activate
 	job.UpdatedAt = time.Now()
@@ -377,15 +391,68 @@ func (c *JobHandler) ActivateJob() {
 		job.UpdatedBy = user
 	}
 
-	// Update job in database
-	if err := c.jobORM.Update(job); err != nil {
+	// Update job in database together with the pause or unpause of its schedule
+	op, err := c.jobORM.UpdateScheduled(job, string(action))
+	if err != nil {
 		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to update job activation status")
 		return
 	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, op)
+	emitJobEvent(c.Ctx.Request.Context(), c.tempClient, constants.WebhookEventJobUpdated, job)
 
 	utils.SuccessResponse(&c.Controller, req)
 }
 
+// detachedJobMessage explains why a job whose source or destination was
+// deleted with mode detach can not run, "" for other jobs
+func detachedJobMessage(job *models.Job) string {
+	switch {
+	case job.SourceID != nil && job.SourceID.DeletedAt != nil:
+		return "Source of the job is in the trash, restore it or move the job to another source first"
+	case job.DestID != nil && job.DestID.DeletedAt != nil:
+		return "Destination of the job is in the trash, restore it or move the job to another destination first"
+	}
+	return ""
+}
+
+// @router /project/:projectid/jobs/schedule/preview [post]
+func (c *JobHandler) PreviewSchedule() {
+	var req models.SchedulePreviewRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if req.Count == 0 {
+		req.Count = temporal.DefaultSchedulePreviewCount
+	}
+
+	settings, err := c.settingsORM.GetByProjectID(c.Ctx.Input.Param(":projectid"))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
+		return
+	}
+	projectWindows, err := temporal.ParseBlackoutWindows(settings.BlackoutWindows)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fireTimes, location, err := temporal.PreviewSchedule(req.Frequency, req.ScheduleConfig, projectWindows, time.Now(), req.Count)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid schedule: %s", err))
+		return
+	}
+
+	nextRuns := make([]string, 0, len(fireTimes))
+	for _, fireTime := range fireTimes {
+		nextRuns = append(nextRuns, fireTime.In(location).Format(time.RFC3339))
+	}
+	utils.SuccessResponse(&c.Controller, models.SchedulePreviewResponse{
+		TimeZone: location.String(),
+		NextRuns: nextRuns,
+	})
+}
+
 // @router /project/:projectid/jobs/:id/tasks [get]
 func (c *JobHandler) GetJobTasks() {
 	idStr := c.Ctx.Input.Param(":id")
@@ -404,9 +471,11 @@ func (c *JobHandler) GetJobTasks() {
 	}
 	var tasks []models.JobTask
 	// Construct a query for workflows related to this project and job
-	query := fmt.Sprintf("WorkflowId between 'sync-%s-%d' and 'sync-%s-%d-~'", projectIDStr, job.ID, projectIDStr, job.ID)
+	syncID := temporal.SyncWorkflowID(projectIDStr, job.ID)
+	backfillID := temporal.BackfillWorkflowID(projectIDStr, job.ID)
+	query := fmt.Sprintf("(WorkflowId between '%s' and '%s-~') OR (WorkflowId between '%s' and '%s-~')", syncID, syncID, backfillID, backfillID)
 	// List workflows using the direct query
-	resp, err := c.tempClient.ListWorkflow(context.Background(), &workflowservice.ListWorkflowExecutionsRequest{
+	resp, err := c.tempClient.ListWorkflow(c.Ctx.Request.Context(), &workflowservice.ListWorkflowExecutionsRequest{
 		Query: query,
 	})
 	if err != nil {
@@ -421,11 +490,17 @@ func (c *JobHandler) GetJobTasks() {
 		} else {
 			runTime = time.Since(startTime).Round(time.Second).String()
 		}
+		taskType := constants.TaskTypeSync
+		if strings.HasPrefix(execution.Execution.WorkflowId, backfillID) {
+			taskType = constants.TaskTypeBackfill
+		}
 		tasks = append(tasks, models.JobTask{
 			Runtime:   runTime,
 			StartTime: startTime.Format(time.RFC3339),
 			Status:    execution.Status.String(),
 			FilePath:  execution.Execution.WorkflowId,
+			Attempt:   temporal.SyncAttempt(execution.Execution.WorkflowId),
+			Type:      taskType,
 		})
 	}
 
@@ -524,6 +599,88 @@ func (c *JobHandler) GetTaskLogs() {
 
 // Helper methods
 
+// validateJobSchedule validates the job schedule against the blackout windows of
+// its project and returns the schedule config to store on the job
+func validateJobSchedule(frequency string, config *models.ScheduleConfig, projectWindows []models.BlackoutWindow) (string, error) {
+	if err := temporal.ValidateSchedule(frequency, config, projectWindows, time.Now()); err != nil {
+		return "", err
+	}
+	if config == nil {
+		return "{}", nil
+	}
+	scheduleConfig, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(scheduleConfig), nil
+}
+
+// validateJobRetryPolicy validates the job retry policy and returns the retry policy to store on the job
+func validateJobRetryPolicy(policy *models.RetryPolicy) (string, error) {
+	if _, err := temporal.BuildSyncRetryPolicy(policy); err != nil {
+		return "", err
+	}
+	if policy == nil {
+		return "{}", nil
+	}
+	retryPolicy, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(retryPolicy), nil
+}
+
+// createJob finds or creates the source and destination of a validated job
+// request, then creates the job and its Temporal schedule
+func (c *JobHandler) createJob(req *models.CreateJobRequest, scheduleConfig, retryPolicy, projectIDStr string) (*models.Job, error) {
+	// Find or create source
+	source, err := c.getOrCreateSource(req.Source, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process source: %s", err)
+	}
+
+	// Find or create destination
+	dest, err := c.getOrCreateDestination(req.Destination, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process destination: %s", err)
+	}
+
+	// Create job model
+	var user *models.User
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		user = &models.User{ID: userID.(int)}
+	}
+	job := newJob(req, scheduleConfig, retryPolicy, projectIDStr, source, dest, user)
+
+	// Create job in database together with the creation of its schedule
+	op, err := c.jobORM.CreateScheduled(job, string(temporal.ActionCreate))
+	if err != nil {
+		return nil, err
+	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, op)
+	emitJobEvent(c.Ctx.Request.Context(), c.tempClient, constants.WebhookEventJobCreated, job)
+
+	return job, nil
+}
+
+// newJob builds an active job with an empty state from a validated create job request
+func newJob(req *models.CreateJobRequest, scheduleConfig, retryPolicy, projectIDStr string, source *models.Source, dest *models.Destination, user *models.User) *models.Job {
+	return &models.Job{
+		Name:           req.Name,
+		SourceID:       source,
+		DestID:         dest,
+		Active:         true,
+		Frequency:      req.Frequency,
+		ScheduleConfig: scheduleConfig,
+		RetryPolicy:    retryPolicy,
+		StreamsConfig:  req.StreamsConfig,
+		State:          "{}",
+		ProjectID:      projectIDStr,
+		CreatedBy:      user,
+		UpdatedBy:      user,
+	}
+}
+
 // getOrCreateSource finds or creates a source based on the provided config
 func (c *JobHandler) getOrCreateSource(config models.JobSourceConfig, projectIDStr string) (*models.Source, error) {
 	// Try to find an existing source matching the criteria
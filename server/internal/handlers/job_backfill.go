@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// filterColumnPattern matches the column names a connector stream filter accepts
+var filterColumnPattern = regexp.MustCompile(`^\w+$`)
+
+// @router /project/:projectid/jobs/:id/backfill [post]
+func (c *JobHandler) BackfillJob() {
+	var req models.BackfillRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if len(req.Streams) == 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "At least one stream is required")
+		return
+	}
+	filter, err := backfillFilter(req.TimeRange)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	// like manual syncs, backfills may explicitly ignore blackout windows
+	overrideBlackout, err := c.GetBool("override_blackout", false)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid override_blackout value")
+		return
+	}
+
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+	if _, err := utils.SelectStreams(job.StreamsConfig, req.Streams, filter); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if c.tempClient == nil {
+		utils.ErrorResponse(&c.Controller, http.StatusServiceUnavailable, "Cannot start backfill, Temporal is unavailable")
+		return
+	}
+
+	workflowID, err := c.tempClient.StartBackfill(c.Ctx.Request.Context(), job, req.Streams, filter, overrideBlackout)
+	if errors.Is(err, temporal.ErrBlackoutWindow) {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, fmt.Sprintf("%s, use override_blackout=true to run anyway", err))
+		return
+	}
+	if errors.Is(err, temporal.ErrBackfillStarted) {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, fmt.Sprintf("%s, retry to start another backfill", err))
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Temporal workflow execution failed: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, models.BackfillResponse{WorkflowID: workflowID})
+}
+
+// backfillFilter validates a backfill time range and turns it into a stream filter
+func backfillFilter(timeRange *models.BackfillTimeRange) (string, error) {
+	if timeRange == nil {
+		return "", nil
+	}
+	if !filterColumnPattern.MatchString(timeRange.Column) {
+		return "", fmt.Errorf("time_range.column must be a column name")
+	}
+	if timeRange.From == "" && timeRange.To == "" {
+		return "", fmt.Errorf("time_range needs a from or to bound")
+	}
+	var from, to time.Time
+	var err error
+	if timeRange.From != "" {
+		if from, err = time.Parse(time.RFC3339, timeRange.From); err != nil {
+			return "", fmt.Errorf("time_range.from must be an RFC3339 timestamp")
+		}
+	}
+	if timeRange.To != "" {
+		if to, err = time.Parse(time.RFC3339, timeRange.To); err != nil {
+			return "", fmt.Errorf("time_range.to must be an RFC3339 timestamp")
+		}
+	}
+	if timeRange.From != "" && timeRange.To != "" && !from.Before(to) {
+		return "", fmt.Errorf("time_range.from must be before time_range.to")
+	}
+	return utils.TimeRangeFilter(timeRange.Column, from, to), nil
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// @router /project/:projectid/jobs/:id/clone [post]
+func (c *JobHandler) CloneJob() {
+	var req models.JobCloneRequest
+	if len(c.Ctx.Input.RequestBody) > 0 {
+		if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+	}
+
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	// decrypted, the connector configs are passed on as in a create job request
+	job, err := c.jobORM.GetByID(id, true)
+	if err != nil || job.ProjectID != projectIDStr {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	createReq, err := cloneJobRequest(job, &req)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	projectWindows, err := temporal.LoadProjectBlackoutWindows(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
+		return
+	}
+	scheduleConfig, err := validateJobSchedule(createReq.Frequency, createReq.ScheduleConfig, projectWindows)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid schedule: %s", err))
+		return
+	}
+	retryPolicy, err := validateJobRetryPolicy(createReq.RetryPolicy)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid retry policy: %s", err))
+		return
+	}
+
+	clone, err := c.createJob(createReq, scheduleConfig, retryPolicy, projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to clone job: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, models.CreatedJobResponse{ID: clone.ID, Name: clone.Name})
+}
+
+// cloneJobRequest builds the create job request of a copy of a job with the overrides of req
+func cloneJobRequest(job *models.Job, req *models.JobCloneRequest) (*models.CreateJobRequest, error) {
+	scheduleConfig, err := temporal.ParseScheduleConfig(job.ScheduleConfig)
+	if err != nil {
+		return nil, err
+	}
+	retryPolicy, err := temporal.ParseRetryPolicy(job.RetryPolicy)
+	if err != nil {
+		return nil, err
+	}
+	createReq := &models.CreateJobRequest{
+		Name: fmt.Sprintf("%s (copy)", job.Name),
+		Source: models.JobSourceConfig{
+			Name:    job.SourceID.Name,
+			Type:    job.SourceID.Type,
+			Version: job.SourceID.Version,
+			Config:  job.SourceID.Config,
+		},
+		Destination: models.JobDestinationConfig{
+			Name:    job.DestID.Name,
+			Type:    job.DestID.DestType,
+			Version: job.DestID.Version,
+			Config:  job.DestID.Config,
+		},
+		Frequency:      job.Frequency,
+		ScheduleConfig: scheduleConfig,
+		RetryPolicy:    retryPolicy,
+		StreamsConfig:  job.StreamsConfig,
+	}
+
+	if req.Name != "" {
+		createReq.Name = req.Name
+	}
+	if createReq.Source, err = overrideConnector("source", createReq.Source, req.Source); err != nil {
+		return nil, err
+	}
+	if createReq.Destination, err = overrideConnector("destination", createReq.Destination, req.Destination); err != nil {
+		return nil, err
+	}
+	if req.Frequency != "" {
+		createReq.Frequency = req.Frequency
+	}
+	if req.ScheduleConfig != nil {
+		createReq.ScheduleConfig = req.ScheduleConfig
+	}
+	if req.RetryPolicy != nil {
+		createReq.RetryPolicy = req.RetryPolicy
+	}
+	if req.StreamsConfig != "" {
+		createReq.StreamsConfig = req.StreamsConfig
+	}
+	return createReq, nil
+}
+
+// overrideConnector applies the set fields of override to a connector of the
+// cloned job. Connectors are found by name and type and updated in place, so a
+// new config or version needs a new name to leave the cloned job untouched.
+func overrideConnector(kind string, connector models.ConnectorConfig, override *models.ConnectorConfig) (models.ConnectorConfig, error) {
+	if override == nil {
+		return connector, nil
+	}
+	merged := connector
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+	if override.Type != "" {
+		merged.Type = override.Type
+	}
+	if override.Version != "" {
+		merged.Version = override.Version
+	}
+	if override.Config != "" {
+		merged.Config = override.Config
+	}
+	if merged.Name == connector.Name && merged.Type == connector.Type && (merged.Version != connector.Version || merged.Config != connector.Config) {
+		return connector, fmt.Errorf("%s config and version can only be overridden along with a new %s name, the %s %q is shared with the cloned job", kind, kind, kind, connector.Name)
+	}
+	return merged, nil
+}
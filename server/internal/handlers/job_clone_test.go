@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func testClonedJob() *models.Job {
+	return &models.Job{
+		Name:           "orders",
+		SourceID:       &models.Source{ID: 1, Name: "pg", Type: "postgres", Version: "v1", Config: `{"host": "a"}`},
+		DestID:         &models.Destination{ID: 2, Name: "lake", DestType: "iceberg", Version: "v1", Config: `{"bucket": "lake"}`},
+		Frequency:      "1-days",
+		ScheduleConfig: `{"time_zone": "UTC"}`,
+		RetryPolicy:    `{"maximum_attempts": 2}`,
+		StreamsConfig:  `{"selected_streams": {}}`,
+		State:          `{"cursor": 1}`,
+		ProjectID:      "p1",
+	}
+}
+
+func TestCloneJobRequest(t *testing.T) {
+	job := testClonedJob()
+	req, err := cloneJobRequest(job, &models.JobCloneRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "orders (copy)" || req.Frequency != "1-days" || req.StreamsConfig != job.StreamsConfig {
+		t.Errorf("clone = %+v", req)
+	}
+	if req.Source != (models.JobSourceConfig{Name: "pg", Type: "postgres", Version: "v1", Config: `{"host": "a"}`}) {
+		t.Errorf("clone source = %+v", req.Source)
+	}
+	if req.Destination != (models.JobDestinationConfig{Name: "lake", Type: "iceberg", Version: "v1", Config: `{"bucket": "lake"}`}) {
+		t.Errorf("clone destination = %+v", req.Destination)
+	}
+	if req.ScheduleConfig == nil || req.ScheduleConfig.TimeZone != "UTC" || req.RetryPolicy == nil || req.RetryPolicy.MaximumAttempts != 2 {
+		t.Errorf("clone schedule config = %+v, retry policy = %+v", req.ScheduleConfig, req.RetryPolicy)
+	}
+
+	// the clone starts with an empty state
+	clone := newJob(req, "{}", "{}", job.ProjectID, job.SourceID, job.DestID, nil)
+	if clone.State != "{}" || !clone.Active || clone.ID != 0 {
+		t.Errorf("cloned job = %+v", clone)
+	}
+}
+
+func TestCloneJobRequestOverrides(t *testing.T) {
+	req, err := cloneJobRequest(testClonedJob(), &models.JobCloneRequest{
+		Name:           "orders-eu",
+		Source:         &models.JobSourceConfig{Name: "pg-eu", Config: `{"host": "b"}`},
+		Destination:    &models.JobDestinationConfig{Version: "v1"},
+		Frequency:      "2-hours",
+		ScheduleConfig: &models.ScheduleConfig{TimeZone: "Europe/Berlin"},
+		RetryPolicy:    &models.RetryPolicy{MaximumAttempts: 5},
+		StreamsConfig:  `{"selected_streams": {"public": []}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "orders-eu" || req.Frequency != "2-hours" || req.StreamsConfig != `{"selected_streams": {"public": []}}` {
+		t.Errorf("clone = %+v", req)
+	}
+	if req.Source != (models.JobSourceConfig{Name: "pg-eu", Type: "postgres", Version: "v1", Config: `{"host": "b"}`}) {
+		t.Errorf("clone source = %+v", req.Source)
+	}
+	if req.Destination.Name != "lake" {
+		t.Errorf("clone destination = %+v", req.Destination)
+	}
+	if req.ScheduleConfig.TimeZone != "Europe/Berlin" || req.RetryPolicy.MaximumAttempts != 5 {
+		t.Errorf("clone schedule config = %+v, retry policy = %+v", req.ScheduleConfig, req.RetryPolicy)
+	}
+}
+
+func TestCloneJobRequestSharedConnector(t *testing.T) {
+	cases := []*models.JobCloneRequest{
+		{Source: &models.JobSourceConfig{Config: `{"host": "b"}`}},
+		{Source: &models.JobSourceConfig{Version: "v2"}},
+		{Destination: &models.JobDestinationConfig{Name: "lake", Config: `{"bucket": "other"}`}},
+	}
+	for _, req := range cases {
+		if _, err := cloneJobRequest(testClonedJob(), req); err == nil {
+			t.Errorf("clone %+v changes a connector shared with the cloned job", req)
+		}
+	}
+
+	job := testClonedJob()
+	job.ScheduleConfig = "{"
+	if _, err := cloneJobRequest(job, &models.JobCloneRequest{}); err == nil {
+		t.Error("clone of a job with an invalid schedule config is accepted")
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// @router /project/:projectid/jobs/:id/dependencies [get]
+func (c *JobHandler) GetJobDependencies() {
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+
+	resp, err := c.buildJobDependenciesResponse(job)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/jobs/:id/dependencies [put]
+func (c *JobHandler) UpdateJobDependencies() {
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	var req models.JobDependenciesRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+
+	dependencies := make([]*models.JobDependency, 0, len(req.Upstreams))
+	seen := map[int]bool{}
+	for _, upstream := range req.Upstreams {
+		if upstream.Mode == "" {
+			upstream.Mode = constants.DependencyModeTrigger
+		}
+		if upstream.Mode != constants.DependencyModeTrigger && upstream.Mode != constants.DependencyModeWait {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid dependency mode %q, must be %s or %s", upstream.Mode, constants.DependencyModeTrigger, constants.DependencyModeWait))
+			return
+		}
+		if upstream.JobID == job.ID {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "A job cannot depend on itself")
+			return
+		}
+		if seen[upstream.JobID] {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Upstream job %d is listed more than once", upstream.JobID))
+			return
+		}
+		seen[upstream.JobID] = true
+
+		upstreamJob, err := c.jobORM.GetByID(upstream.JobID, false)
+		if err != nil || upstreamJob.ProjectID != projectIDStr {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Upstream job %d not found in project", upstream.JobID))
+			return
+		}
+		dependencies = append(dependencies, &models.JobDependency{UpstreamJobID: upstreamJob, Mode: upstream.Mode})
+	}
+
+	// check the project graph with the new upstreams of the job in place of the old ones
+	existing, err := c.depORM.GetAllByProjectID(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	graph := map[int][]int{}
+	for _, dependency := range existing {
+		if dependency.JobID.ID != job.ID {
+			graph[dependency.UpstreamJobID.ID] = append(graph[dependency.UpstreamJobID.ID], dependency.JobID.ID)
+		}
+	}
+	for _, dependency := range dependencies {
+		graph[dependency.UpstreamJobID.ID] = append(graph[dependency.UpstreamJobID.ID], job.ID)
+	}
+	if cycle := utils.FindCycle(graph); cycle != nil {
+		path := make([]string, 0, len(cycle))
+		for _, jobID := range cycle {
+			path = append(path, fmt.Sprintf("job %d", jobID))
+		}
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Dependencies would create a cycle: %s", strings.Join(path, " -> ")))
+		return
+	}
+
+	if err := c.depORM.ReplaceUpstreams(job, dependencies); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to update job dependencies: %s", err))
+		return
+	}
+
+	resp, err := c.buildJobDependenciesResponse(job)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/jobs/dag [get]
+func (c *JobHandler) GetJobDAG() {
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	jobs, err := c.jobORM.GetAllByProjectID(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to retrieve jobs by project ID")
+		return
+	}
+	dependencies, err := c.depORM.GetAllByProjectID(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	graph := map[int][]int{}
+	edges := make([]models.JobDAGEdge, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		graph[dependency.UpstreamJobID.ID] = append(graph[dependency.UpstreamJobID.ID], dependency.JobID.ID)
+		edges = append(edges, models.JobDAGEdge{
+			UpstreamJobID: dependency.UpstreamJobID.ID,
+			JobID:         dependency.JobID.ID,
+			Mode:          dependency.Mode,
+		})
+	}
+	levels := utils.GraphLevels(graph)
+
+	nodes := make([]models.JobDAGNode, 0, len(jobs))
+	for _, job := range jobs {
+		node := models.JobDAGNode{
+			JobID:    job.ID,
+			Name:     job.Name,
+			Activate: job.Active,
+			Level:    levels[job.ID],
+		}
+		if c.tempClient != nil {
+			if latest, err := c.tempClient.GetLatestSyncRun(c.Ctx.Request.Context(), projectIDStr, job.ID); err != nil {
+				logs.Error("Failed to get latest sync run of job %d: %s", job.ID, err)
+			} else if latest != nil {
+				node.LastRunTime = latest.StartTime.AsTime().Format(time.RFC3339)
+				node.LastRunState = latest.Status.String()
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	utils.SuccessResponse(&c.Controller, models.JobDAGResponse{
+		Nodes: nodes,
+		Edges: edges,
+	})
+}
+
+func (c *JobHandler) buildJobDependenciesResponse(job *models.Job) (*models.JobDependenciesResponse, error) {
+	upstreams, err := c.depORM.GetUpstreams(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	downstreams, err := c.depORM.GetDownstreams(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.JobDependenciesResponse{
+		JobID:       job.ID,
+		Upstreams:   make([]models.JobDependencyConfig, 0, len(upstreams)),
+		Downstreams: make([]models.JobDependencyConfig, 0, len(downstreams)),
+	}
+	for _, dependency := range upstreams {
+		resp.Upstreams = append(resp.Upstreams, models.JobDependencyConfig{JobID: dependency.UpstreamJobID.ID, Mode: dependency.Mode})
+	}
+	for _, dependency := range downstreams {
+		resp.Downstreams = append(resp.Downstreams, models.JobDependencyConfig{JobID: dependency.JobID.ID, Mode: dependency.Mode})
+	}
+	return resp, nil
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// @router /project/:projectid/jobs/preview [post]
+func (c *JobHandler) PreviewSync() {
+	var req models.SyncPreviewRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if req.RowLimit == 0 {
+		req.RowLimit = docker.DefaultPreviewRowLimit
+	}
+	if req.SampleSize == 0 {
+		req.SampleSize = docker.DefaultPreviewSampleSize
+	}
+	if req.RowLimit < 0 || req.RowLimit > docker.MaxPreviewRowLimit {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("row_limit must be between 1 and %d", docker.MaxPreviewRowLimit))
+		return
+	}
+	if req.SampleSize < 0 || req.SampleSize > req.RowLimit {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "sample_size must be between 1 and row_limit")
+		return
+	}
+	streams, err := utils.SelectedStreams(req.StreamsConfig)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(streams) == 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "At least one stream must be selected")
+		return
+	}
+	if c.tempClient == nil {
+		utils.ErrorResponse(&c.Controller, http.StatusServiceUnavailable, "Cannot run preview, Temporal is unavailable")
+		return
+	}
+
+	encryptedConfig, err := utils.Encrypt(req.Source.Config)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to encrypt config")
+		return
+	}
+	resp, err := c.tempClient.PreviewSync(c.Ctx.Request.Context(), req.Source.Type, req.Source.Version, encryptedConfig,
+		req.StreamsConfig, req.RowLimit, req.SampleSize)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to run preview: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// jobStateStore is the state history of jobs, a *database.JobStateHistoryORM
+type jobStateStore interface {
+	GetLatest(jobID int) (*models.JobStateHistory, error)
+	LatestRevision(jobID int) (int, error)
+	SaveState(job *models.Job, revision *models.JobStateHistory) error
+	GetAllByJobID(jobID, limit int) ([]*models.JobStateHistory, error)
+	GetByRevision(jobID, revision int) (*models.JobStateHistory, error)
+}
+
+// @router /project/:projectid/jobs/:id/state [get]
+func (c *JobHandler) GetJobState() {
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+
+	resp, err := c.buildJobStateResponse(job)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/jobs/:id/state [put]
+func (c *JobHandler) UpdateJobState() {
+	var req models.JobStateRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if err := utils.ValidateJobState(req.State); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid state: %s", err))
+		return
+	}
+
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+	baseRevision, ok := c.checkNoRunningSync(job)
+	if !ok {
+		return
+	}
+	c.saveJobState(job, &models.JobStateHistory{State: req.State, Reason: constants.StateChangeReplace, BaseRevision: baseRevision})
+}
+
+// @router /project/:projectid/jobs/:id/state/reset [post]
+func (c *JobHandler) ResetJobState() {
+	var req models.JobStateResetRequest
+	if len(c.Ctx.Input.RequestBody) > 0 {
+		if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+	}
+
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+	baseRevision, ok := c.checkNoRunningSync(job)
+	if !ok {
+		return
+	}
+
+	if len(req.Streams) == 0 {
+		if c.saveJobState(job, &models.JobStateHistory{State: "{}", Reason: constants.StateChangeReset, BaseRevision: baseRevision}) {
+			emitJobEvent(c.Ctx.Request.Context(), c.tempClient, constants.WebhookEventStateReset, job)
+		}
+		return
+	}
+	state, err := utils.ResetStreams(job.State, req.Streams)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Failed to reset streams: %s", err))
+		return
+	}
+	streams, err := json.Marshal(req.Streams)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to encode streams: %s", err))
+		return
+	}
+	if c.saveJobState(job, &models.JobStateHistory{State: state, Reason: constants.StateChangeStreamReset, Streams: string(streams), BaseRevision: baseRevision}) {
+		emitJobEvent(c.Ctx.Request.Context(), c.tempClient, constants.WebhookEventStateReset, job, req.Streams...)
+	}
+}
+
+// @router /project/:projectid/jobs/:id/states [get]
+func (c *JobHandler) GetJobStateHistory() {
+	limit, err := c.GetInt("limit", 50)
+	if err != nil || limit < 1 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid limit value")
+		return
+	}
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+	c.respondJobStateHistory(job, limit)
+}
+
+// respondJobStateHistory responds with the latest limit state revisions of a job, newest first
+func (c *JobHandler) respondJobStateHistory(job *models.Job, limit int) {
+	revisions, err := c.stateORM.GetAllByJobID(job.ID, limit)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := make([]models.JobStateRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		resp = append(resp, buildJobStateRevisionResponse(revision))
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/jobs/:id/states/:rev/restore [post]
+func (c *JobHandler) RestoreJobState() {
+	rev, err := strconv.Atoi(c.Ctx.Input.Param(":rev"))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+	revision, err := c.stateORM.GetByRevision(job.ID, rev)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "State revision not found")
+		return
+	}
+	baseRevision, ok := c.checkNoRunningSync(job)
+	if !ok {
+		return
+	}
+	c.saveJobState(job, restoredJobState(revision, baseRevision))
+}
+
+// restoredJobState is the revision restoring the state of revision on top of baseRevision
+func restoredJobState(revision *models.JobStateHistory, baseRevision int) *models.JobStateHistory {
+	return &models.JobStateHistory{State: revision.State, Reason: constants.StateChangeRestore, RestoredFrom: revision.Revision, BaseRevision: baseRevision}
+}
+
+// getProjectJob loads the job of the request path, responding with 404 if it is not in the project
+func (c *JobHandler) getProjectJob() (*models.Job, bool) {
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return nil, false
+	}
+	job, err := c.jobORM.GetByID(id, false)
+	if err != nil || job.ProjectID != projectIDStr {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Job not found")
+		return nil, false
+	}
+	return job, true
+}
+
+// checkNoRunningSync responds with 409 while a sync of the job is running, a
+// running sync would overwrite the state once it completes. It returns the
+// latest state revision read before the check, the base revision of the new
+// state: a sync starting after the check either sees the new state or records
+// a later revision, which makes saving the new state fail with 409.
+func (c *JobHandler) checkNoRunningSync(job *models.Job) (int, bool) {
+	if c.tempClient == nil {
+		utils.ErrorResponse(&c.Controller, http.StatusServiceUnavailable, "Cannot check for running syncs, Temporal is unavailable")
+		return 0, false
+	}
+	baseRevision, err := c.stateORM.LatestRevision(job.ID)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return 0, false
+	}
+	running, err := c.tempClient.IsSyncRunning(c.Ctx.Request.Context(), job.ProjectID, job.ID)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to check for running syncs: %s", err))
+		return 0, false
+	}
+	if running {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, "A sync of the job is running, retry once it completes")
+		return 0, false
+	}
+	return baseRevision, true
+}
+
+// saveJobState saves a new state revision of a job and responds with the state, it reports whether the state was saved
+func (c *JobHandler) saveJobState(job *models.Job, revision *models.JobStateHistory) bool {
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		revision.CreatedBy = &models.User{ID: userID.(int)}
+	}
+	if err := c.stateORM.SaveState(job, revision); errors.Is(err, database.ErrStateChanged) {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, "The state of the job changed, a sync may have started, retry once it completes")
+		return false
+	} else if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to update job state: %s", err))
+		return false
+	}
+
+	resp, err := c.buildJobStateResponse(job)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return true
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+	return true
+}
+
+func (c *JobHandler) buildJobStateResponse(job *models.Job) (*models.JobStateResponse, error) {
+	resp := &models.JobStateResponse{
+		JobID: job.ID,
+		State: job.State,
+	}
+	latest, err := c.stateORM.GetLatest(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		resp.Revision = latest.Revision
+		resp.UpdatedAt = latest.CreatedAt.Format(time.RFC3339)
+		if latest.CreatedBy != nil {
+			resp.UpdatedBy = latest.CreatedBy.Username
+		}
+	}
+	return resp, nil
+}
+
+func buildJobStateRevisionResponse(revision *models.JobStateHistory) models.JobStateRevisionResponse {
+	resp := models.JobStateRevisionResponse{
+		Revision:     revision.Revision,
+		State:        revision.State,
+		Reason:       revision.Reason,
+		WorkflowID:   revision.WorkflowID,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt.Format(time.RFC3339),
+	}
+	if err := json.Unmarshal([]byte(revision.Streams), &resp.Streams); err != nil {
+		logs.Error("Failed to decode streams of state revision %d: %s", revision.Revision, err)
+	}
+	if revision.CreatedBy != nil {
+		resp.CreatedBy = revision.CreatedBy.Username
+	}
+	return resp
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/context"
+	"github.com/beego/beego/v2/server/web/session"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// memoryStateStore keeps the state history of one job the way
+// JobStateHistoryORM does, pruning it to limit revisions
+type memoryStateStore struct {
+	revisions []*models.JobStateHistory
+	limit     int
+	// concurrent runs once, before the next save, like a save that takes the job lock first
+	concurrent func()
+}
+
+func (s *memoryStateStore) GetLatest(int) (*models.JobStateHistory, error) {
+	if len(s.revisions) == 0 {
+		return nil, nil
+	}
+	return s.revisions[len(s.revisions)-1], nil
+}
+
+func (s *memoryStateStore) LatestRevision(jobID int) (int, error) {
+	latest, _ := s.GetLatest(jobID)
+	if latest == nil {
+		return 0, nil
+	}
+	return latest.Revision, nil
+}
+
+func (s *memoryStateStore) SaveState(job *models.Job, revision *models.JobStateHistory) error {
+	if concurrent := s.concurrent; concurrent != nil {
+		s.concurrent = nil
+		concurrent()
+	}
+	latest, _ := s.LatestRevision(job.ID)
+	if latest > revision.BaseRevision {
+		return fmt.Errorf("%w: revision %d was recorded after revision %d", database.ErrStateChanged, latest, revision.BaseRevision)
+	}
+	revision.JobID, revision.Revision = job, latest+1
+	s.revisions = append(s.revisions, revision)
+	if s.limit > 0 && len(s.revisions) > s.limit {
+		s.revisions = s.revisions[len(s.revisions)-s.limit:]
+	}
+	job.State = revision.State
+	return nil
+}
+
+func (s *memoryStateStore) GetAllByJobID(_, limit int) ([]*models.JobStateHistory, error) {
+	var revisions []*models.JobStateHistory
+	for i := len(s.revisions) - 1; i >= 0 && len(revisions) < limit; i-- {
+		revisions = append(revisions, s.revisions[i])
+	}
+	return revisions, nil
+}
+
+func (s *memoryStateStore) GetByRevision(jobID, revision int) (*models.JobStateHistory, error) {
+	for _, stateRevision := range s.revisions {
+		if stateRevision.Revision == revision {
+			return stateRevision, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get state revision %d of job[%d]", revision, jobID)
+}
+
+// userSession is the session of a signed in user
+type userSession struct {
+	session.Store
+	userID int
+}
+
+func (s userSession) Get(_ stdcontext.Context, key interface{}) interface{} {
+	if key == constants.SessionUserID {
+		return s.userID
+	}
+	return nil
+}
+
+// newStateHandler returns a job handler on store recording its response
+func newStateHandler(store jobStateStore) (*JobHandler, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	ctx := context.NewContext()
+	ctx.Reset(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	c := &JobHandler{stateORM: store}
+	c.Init(ctx, "JobHandler", "", nil)
+	c.CruSession = userSession{userID: 7}
+	return c, rec
+}
+
+// decodeResponse checks the status of a response and decodes its data into data
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder, status int, data interface{}) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body)
+	}
+	resp := models.JSONResponse{Data: data}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stateHistory saves states as sync revisions of a job
+func stateHistory(t *testing.T, store *memoryStateStore, job *models.Job, states ...string) {
+	t.Helper()
+	for i, state := range states {
+		base, _ := store.LatestRevision(job.ID)
+		revision := &models.JobStateHistory{State: state, Reason: constants.StateChangeSync, WorkflowID: fmt.Sprintf("sync-%d", i), BaseRevision: base}
+		if err := store.SaveState(job, revision); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRestoreJobState(t *testing.T) {
+	store := &memoryStateStore{}
+	job := &models.Job{ID: 1}
+	stateHistory(t, store, job, `{"cursor": 1}`, `{"cursor": 2}`)
+
+	revision, err := store.GetByRevision(job.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := store.LatestRevision(job.ID)
+	c, rec := newStateHandler(store)
+	if !c.saveJobState(job, restoredJobState(revision, base)) {
+		t.Fatal("restore was not saved")
+	}
+	var resp models.JobStateResponse
+	decodeResponse(t, rec, http.StatusOK, &resp)
+	if resp.State != `{"cursor": 1}` || resp.Revision != 3 {
+		t.Errorf("restored state %s at revision %d, want revision 1 as revision 3", resp.State, resp.Revision)
+	}
+	if latest, _ := store.GetLatest(job.ID); latest.Reason != constants.StateChangeRestore || latest.RestoredFrom != 1 || latest.CreatedBy == nil || latest.CreatedBy.ID != 7 {
+		t.Errorf("latest revision = %+v, want a restore of revision 1 by user 7", latest)
+	}
+}
+
+// TestRestoreJobStateConflict restores on top of a revision read before a sync
+// saved its state, the restore fails rather than overwrite the sync state
+func TestRestoreJobStateConflict(t *testing.T) {
+	store := &memoryStateStore{}
+	job := &models.Job{ID: 1}
+	stateHistory(t, store, job, `{"cursor": 1}`, `{"cursor": 2}`)
+
+	revision, _ := store.GetByRevision(job.ID, 1)
+	base, _ := store.LatestRevision(job.ID)
+	store.concurrent = func() { stateHistory(t, store, job, `{"cursor": 3}`) }
+	c, rec := newStateHandler(store)
+	if c.saveJobState(job, restoredJobState(revision, base)) {
+		t.Fatal("restore was saved over a concurrent sync")
+	}
+	decodeResponse(t, rec, http.StatusConflict, nil)
+	if latest, _ := store.GetLatest(job.ID); latest.Revision != 3 || latest.Reason != constants.StateChangeSync || job.State != `{"cursor": 3}` {
+		t.Errorf("latest revision = %+v with job state %s, want the state of the sync", latest, job.State)
+	}
+
+	// retried on top of the sync revision, the restore goes through
+	base, _ = store.LatestRevision(job.ID)
+	c, rec = newStateHandler(store)
+	c.saveJobState(job, restoredJobState(revision, base))
+	var resp models.JobStateResponse
+	decodeResponse(t, rec, http.StatusOK, &resp)
+	if resp.Revision != 4 || resp.State != `{"cursor": 1}` {
+		t.Errorf("retried restore saved %s at revision %d, want revision 1 as revision 4", resp.State, resp.Revision)
+	}
+}
+
+func TestJobStateHistory(t *testing.T) {
+	store := &memoryStateStore{limit: 3}
+	job := &models.Job{ID: 1}
+	stateHistory(t, store, job, `{"cursor": 1}`, `{"cursor": 2}`, `{"cursor": 3}`, `{"cursor": 4}`, `{"cursor": 5}`)
+
+	cases := []struct {
+		limit int
+		want  []int
+	}{
+		// the two oldest revisions were pruned
+		{50, []int{5, 4, 3}},
+		{2, []int{5, 4}},
+	}
+	for _, tc := range cases {
+		c, rec := newStateHandler(store)
+		c.respondJobStateHistory(job, tc.limit)
+		var resp []models.JobStateRevisionResponse
+		decodeResponse(t, rec, http.StatusOK, &resp)
+		var got []int
+		for _, revision := range resp {
+			got = append(got, revision.Revision)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("limit %d: revisions = %v, want %v", tc.limit, got, tc.want)
+		}
+		if resp[0].State != `{"cursor": 5}` || resp[0].WorkflowID != "sync-4" || resp[0].Reason != constants.StateChangeSync {
+			t.Errorf("limit %d: latest revision = %+v", tc.limit, resp[0])
+		}
+	}
+
+	// a pruned revision can not be restored
+	if _, err := store.GetByRevision(job.ID, 1); err == nil {
+		t.Error("pruned revision 1 is still in the history")
+	}
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// maxTemplateVariableSets bounds the jobs a single instantiation creates
+const maxTemplateVariableSets = 100
+
+// @router /project/:projectid/job-templates [get]
+func (c *JobHandler) GetJobTemplates() {
+	templates, err := c.templateORM.GetAllByProjectID(c.Ctx.Input.Param(":projectid"))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to retrieve job templates")
+		return
+	}
+	resp := make([]models.JobTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		resp = append(resp, buildJobTemplateResponse(template))
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/job-templates [post]
+func (c *JobHandler) CreateJobTemplate() {
+	var req models.JobTemplateRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	template := &models.JobTemplate{ProjectID: c.Ctx.Input.Param(":projectid")}
+	if err := setJobTemplateSpec(template, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		user := &models.User{ID: userID.(int)}
+		template.CreatedBy = user
+		template.UpdatedBy = user
+	}
+
+	if err := c.templateORM.Create(template); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to create job template: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildJobTemplateResponse(template))
+}
+
+// @router /project/:projectid/job-templates/:id [put]
+func (c *JobHandler) UpdateJobTemplate() {
+	var req models.JobTemplateRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	template, ok := c.getProjectJobTemplate()
+	if !ok {
+		return
+	}
+	if err := setJobTemplateSpec(template, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		template.UpdatedBy = &models.User{ID: userID.(int)}
+	}
+
+	// jobs created from the template earlier are left as they are
+	if err := c.templateORM.Update(template); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to update job template: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildJobTemplateResponse(template))
+}
+
+// @router /project/:projectid/job-templates/:id [delete]
+func (c *JobHandler) DeleteJobTemplate() {
+	template, ok := c.getProjectJobTemplate()
+	if !ok {
+		return
+	}
+	if err := c.templateORM.Delete(template.ID); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to delete job template")
+		return
+	}
+	utils.SuccessResponse(&c.Controller, map[string]interface{}{
+		"name": template.Name,
+	})
+}
+
+// @router /project/:projectid/job-templates/:id/restore [post]
+func (c *JobHandler) RestoreJobTemplate() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	if err := c.templateORM.Restore(c.Ctx.Input.Param(":projectid"), id); err != nil {
+		restoreErrorResponse(&c.Controller, "Job template", err)
+		return
+	}
+	template, ok := c.getProjectJobTemplate()
+	if !ok {
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(template.Name, nil))
+}
+
+// @router /project/:projectid/job-templates/:id/instantiate [post]
+func (c *JobHandler) InstantiateJobTemplate() {
+	var req models.JobTemplateInstantiateRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if len(req.VariableSets) == 0 || len(req.VariableSets) > maxTemplateVariableSets {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("variable_sets must hold between 1 and %d sets", maxTemplateVariableSets))
+		return
+	}
+	template, ok := c.getProjectJobTemplate()
+	if !ok {
+		return
+	}
+
+	projectWindows, err := temporal.LoadProjectBlackoutWindows(template.ProjectID)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
+		return
+	}
+
+	// every set is checked before any job is created
+	type instance struct {
+		req            *models.CreateJobRequest
+		scheduleConfig string
+		retryPolicy    string
+	}
+	instances := make([]instance, 0, len(req.VariableSets))
+	sources := map[string]string{}
+	destinations := map[string]string{}
+	for i, variables := range req.VariableSets {
+		createReq, err := renderJobTemplate(template, variables)
+		if err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Variable set %d: %s", i, err))
+			return
+		}
+		scheduleConfig, err := validateJobSchedule(createReq.Frequency, createReq.ScheduleConfig, projectWindows)
+		if err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Variable set %d: invalid schedule: %s", i, err))
+			return
+		}
+		retryPolicy, err := validateJobRetryPolicy(createReq.RetryPolicy)
+		if err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Variable set %d: invalid retry policy: %s", i, err))
+			return
+		}
+		// connectors are found by name and type, sets sharing one must agree on its config
+		if err := claimConnector(sources, "source", createReq.Source); err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Variable set %d: %s", i, err))
+			return
+		}
+		if err := claimConnector(destinations, "destination", createReq.Destination); err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Variable set %d: %s", i, err))
+			return
+		}
+		instances = append(instances, instance{req: createReq, scheduleConfig: scheduleConfig, retryPolicy: retryPolicy})
+	}
+
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	resp := make([]models.JobTemplateInstanceResponse, 0, len(instances))
+	for i, instance := range instances {
+		result := models.JobTemplateInstanceResponse{Variables: req.VariableSets[i]}
+		job, err := c.createJob(instance.req, instance.scheduleConfig, instance.retryPolicy, projectIDStr)
+		if err != nil {
+			logs.Error("Failed to create job for variable set %d of job template[%d]: %s", i, template.ID, err)
+			result.Error = err.Error()
+		} else {
+			result.Job = &models.CreatedJobResponse{ID: job.ID, Name: job.Name}
+		}
+		resp = append(resp, result)
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// getProjectJobTemplate loads the job template of the request path, responding with 404 if it is not in the project
+func (c *JobHandler) getProjectJobTemplate() (*models.JobTemplate, bool) {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return nil, false
+	}
+	template, err := c.templateORM.GetByID(id)
+	if err != nil || template.ProjectID != c.Ctx.Input.Param(":projectid") {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Job template not found")
+		return nil, false
+	}
+	return template, true
+}
+
+// setJobTemplateSpec validates a template request and stores it on the template
+func setJobTemplateSpec(template *models.JobTemplate, req *models.JobTemplateRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if req.Job.Name == "" {
+		return fmt.Errorf("job.name is required")
+	}
+	spec, err := json.Marshal(req.Job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %s", err)
+	}
+	variables, err := json.Marshal(utils.TemplateVariables(string(spec)))
+	if err != nil {
+		return fmt.Errorf("failed to encode variables: %s", err)
+	}
+	template.Name = req.Name
+	template.Spec = string(spec)
+	template.Variables = string(variables)
+	return nil
+}
+
+// renderJobTemplate builds the create job request of a template for a set of variables
+func renderJobTemplate(template *models.JobTemplate, variables map[string]string) (*models.CreateJobRequest, error) {
+	var known []string
+	if err := json.Unmarshal([]byte(template.Variables), &known); err != nil {
+		return nil, fmt.Errorf("invalid template variables: %s", err)
+	}
+	for name := range variables {
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("unknown variable %q", name)
+		}
+	}
+
+	spec, err := utils.RenderTemplate(template.Spec, variables)
+	if err != nil {
+		return nil, err
+	}
+	var req models.CreateJobRequest
+	if err := json.Unmarshal([]byte(spec), &req); err != nil {
+		return nil, fmt.Errorf("invalid rendered job: %s", err)
+	}
+	return &req, nil
+}
+
+// claimConnector records the config of a connector used by a template instance,
+// failing if another instance uses the same connector with another config
+func claimConnector(claimed map[string]string, kind string, connector models.ConnectorConfig) error {
+	key := fmt.Sprintf("%s/%s", connector.Type, connector.Name)
+	config := connector.Version + "\x00" + connector.Config
+	if existing, ok := claimed[key]; ok && existing != config {
+		return fmt.Errorf("%s %q is rendered with different configs, add a variable to its name", kind, connector.Name)
+	}
+	claimed[key] = config
+	return nil
+}
+
+func buildJobTemplateResponse(template *models.JobTemplate) models.JobTemplateResponse {
+	resp := models.JobTemplateResponse{
+		ID:        template.ID,
+		Name:      template.Name,
+		Variables: []string{},
+		CreatedAt: template.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: template.UpdatedAt.Format(time.RFC3339),
+	}
+	if err := json.Unmarshal([]byte(template.Spec), &resp.Job); err != nil {
+		logs.Error("Failed to decode spec of job template[%d]: %s", template.ID, err)
+	}
+	if err := json.Unmarshal([]byte(template.Variables), &resp.Variables); err != nil {
+		logs.Error("Failed to decode variables of job template[%d]: %s", template.ID, err)
+	}
+	setUsernames(&resp.CreatedBy, &resp.UpdatedBy, template.CreatedBy, template.UpdatedBy)
+	return resp
+}
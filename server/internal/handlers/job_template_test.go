@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func testJobTemplate(t *testing.T) *models.JobTemplate {
+	t.Helper()
+	template := &models.JobTemplate{ProjectID: "p1"}
+	err := setJobTemplateSpec(template, &models.JobTemplateRequest{
+		Name: "orders",
+		Job: models.CreateJobRequest{
+			Name:          "{{db}}-sync",
+			Source:        models.JobSourceConfig{Name: "pg-{{db}}", Type: "postgres", Version: "v0.1.0", Config: `{"host": "{{host}}", "database": "{{db}}"}`},
+			Destination:   models.JobDestinationConfig{Name: "lake", Type: "iceberg", Version: "v0.1.0", Config: `{"bucket": "lake"}`},
+			Frequency:     "1-days",
+			RetryPolicy:   &models.RetryPolicy{MaximumAttempts: 3},
+			StreamsConfig: `{"selected_streams": {"{{db}}": []}}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+func TestSetJobTemplateSpec(t *testing.T) {
+	template := testJobTemplate(t)
+	if template.Variables != `["db","host"]` {
+		t.Errorf("variables = %s, want [\"db\",\"host\"]", template.Variables)
+	}
+	if err := setJobTemplateSpec(&models.JobTemplate{}, &models.JobTemplateRequest{Name: "x"}); err == nil {
+		t.Error("template without a job name is accepted")
+	}
+}
+
+func TestRenderJobTemplate(t *testing.T) {
+	template := testJobTemplate(t)
+	req, err := renderJobTemplate(template, map[string]string{"db": "orders", "host": `db"1`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "orders-sync" || req.Source.Name != "pg-orders" || req.Destination.Name != "lake" {
+		t.Errorf("rendered names = %q, %q, %q", req.Name, req.Source.Name, req.Destination.Name)
+	}
+	if req.Source.Config != `{"database":"orders","host":"db\"1"}` {
+		t.Errorf("rendered source config = %s", req.Source.Config)
+	}
+	if req.StreamsConfig != `{"selected_streams":{"orders":[]}}` {
+		t.Errorf("rendered streams config = %s", req.StreamsConfig)
+	}
+	if req.RetryPolicy == nil || req.RetryPolicy.MaximumAttempts != 3 {
+		t.Errorf("rendered retry policy = %+v", req.RetryPolicy)
+	}
+
+	cases := []struct {
+		name      string
+		variables map[string]string
+		want      string
+	}{
+		{"missing variable", map[string]string{"db": "orders"}, `no value for variable "host"`},
+		{"unknown variable", map[string]string{"db": "orders", "host": "h", "port": "5432"}, `unknown variable "port"`},
+	}
+	for _, tc := range cases {
+		if _, err := renderJobTemplate(template, tc.variables); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestClaimConnector(t *testing.T) {
+	claimed := map[string]string{}
+	pg := models.ConnectorConfig{Name: "pg", Type: "postgres", Version: "v1", Config: `{"host": "a"}`}
+	if err := claimConnector(claimed, "source", pg); err != nil {
+		t.Fatal(err)
+	}
+	if err := claimConnector(claimed, "source", pg); err != nil {
+		t.Errorf("claiming the same connector twice: %s", err)
+	}
+	other := pg
+	other.Type = "mysql"
+	other.Config = `{"host": "b"}`
+	if err := claimConnector(claimed, "source", other); err != nil {
+		t.Errorf("claiming a connector of another type: %s", err)
+	}
+	changed := pg
+	changed.Config = `{"host": "b"}`
+	if err := claimConnector(claimed, "source", changed); err == nil {
+		t.Error("claiming a connector with another config is accepted")
+	}
+	changed = pg
+	changed.Version = "v2"
+	if err := claimConnector(claimed, "source", changed); err == nil {
+		t.Error("claiming a connector with another version is accepted")
+	}
+}
+
+func TestNewJobFromTemplate(t *testing.T) {
+	req, err := renderJobTemplate(testJobTemplate(t), map[string]string{"db": "orders", "host": "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduleConfig, err := validateJobSchedule(req.Frequency, req.ScheduleConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retryPolicy, err := validateJobRetryPolicy(req.RetryPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &models.Source{ID: 1}
+	dest := &models.Destination{ID: 2}
+	user := &models.User{ID: 3}
+	job := newJob(req, scheduleConfig, retryPolicy, "p1", source, dest, user)
+	if job.Name != "orders-sync" || job.Frequency != "1-days" || job.ProjectID != "p1" || !job.Active || job.State != "{}" {
+		t.Errorf("job = %+v", job)
+	}
+	if job.SourceID != source || job.DestID != dest || job.CreatedBy != user || job.UpdatedBy != user {
+		t.Errorf("job connectors or users = %v, %v, %v, %v", job.SourceID, job.DestID, job.CreatedBy, job.UpdatedBy)
+	}
+	if job.ScheduleConfig != "{}" || job.RetryPolicy != `{"maximum_attempts":3}` || job.StreamsConfig != req.StreamsConfig {
+		t.Errorf("job configs = %s, %s, %s", job.ScheduleConfig, job.RetryPolicy, job.StreamsConfig)
+	}
+
+	if job := newJob(req, "{}", "{}", "p1", source, dest, nil); job.CreatedBy != nil || job.UpdatedBy != nil {
+		t.Errorf("job without a session user has users %v, %v", job.CreatedBy, job.UpdatedBy)
+	}
+}
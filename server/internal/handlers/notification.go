@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/notify"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// @router /project/:projectid/notification-channels [get]
+func (c *ProjectHandler) GetNotificationChannels() {
+	channels, err := c.channelORM.GetAllByProjectID(c.Ctx.Input.Param(":projectid"))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to retrieve notification channels")
+		return
+	}
+	resp := make([]models.NotificationChannelResponse, 0, len(channels))
+	for _, channel := range channels {
+		resp = append(resp, buildNotificationChannelResponse(channel))
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/notification-channels [post]
+func (c *ProjectHandler) CreateNotificationChannel() {
+	var req models.NotificationChannelRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	channel := &models.NotificationChannel{ProjectID: c.Ctx.Input.Param(":projectid")}
+	if err := setNotificationChannel(channel, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		user := &models.User{ID: userID.(int)}
+		channel.CreatedBy = user
+		channel.UpdatedBy = user
+	}
+
+	if err := c.channelORM.Create(channel); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to create notification channel: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildNotificationChannelResponse(channel))
+}
+
+// @router /project/:projectid/notification-channels/:id [put]
+func (c *ProjectHandler) UpdateNotificationChannel() {
+	var req models.NotificationChannelRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	channel, ok := c.getProjectNotificationChannel()
+	if !ok {
+		return
+	}
+	if err := setNotificationChannel(channel, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		channel.UpdatedBy = &models.User{ID: userID.(int)}
+	}
+
+	if err := c.channelORM.Update(channel); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to update notification channel: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildNotificationChannelResponse(channel))
+}
+
+// @router /project/:projectid/notification-channels/:id [delete]
+func (c *ProjectHandler) DeleteNotificationChannel() {
+	channel, ok := c.getProjectNotificationChannel()
+	if !ok {
+		return
+	}
+	// jobs subscribed to the channel are no longer notified until it is restored
+	if err := c.channelORM.Delete(channel.ID); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to delete notification channel")
+		return
+	}
+	utils.SuccessResponse(&c.Controller, map[string]interface{}{
+		"name": channel.Name,
+	})
+}
+
+// @router /project/:projectid/notification-channels/:id/restore [post]
+func (c *ProjectHandler) RestoreNotificationChannel() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	// the job subscriptions to the channel were kept and notify again
+	if err := c.channelORM.Restore(c.Ctx.Input.Param(":projectid"), id); err != nil {
+		restoreErrorResponse(&c.Controller, "Notification channel", err)
+		return
+	}
+	channel, ok := c.getProjectNotificationChannel()
+	if !ok {
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(channel.Name, nil))
+}
+
+// @router /project/:projectid/notification-channels/:id/test [post]
+func (c *ProjectHandler) TestNotificationChannel() {
+	channel, ok := c.getProjectNotificationChannel()
+	if !ok {
+		return
+	}
+	notification := &notify.Notification{
+		Event:     constants.NotificationEventTest,
+		ProjectID: channel.ProjectID,
+		JobName:   channel.Name,
+		Message:   fmt.Sprintf("Test notification of channel %s.", channel.Name),
+		Time:      time.Now().UTC(),
+	}
+	if err := notify.Send(c.Ctx.Request.Context(), channel, notification); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadGateway, fmt.Sprintf("Failed to send test notification: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, map[string]interface{}{
+		"name": channel.Name,
+	})
+}
+
+// getProjectNotificationChannel loads the channel of the request path, responding with 404 if it is not in the project
+func (c *ProjectHandler) getProjectNotificationChannel() (*models.NotificationChannel, bool) {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return nil, false
+	}
+	channel, err := c.channelORM.GetByID(id)
+	if err != nil || channel.ProjectID != c.Ctx.Input.Param(":projectid") {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Notification channel not found")
+		return nil, false
+	}
+	return channel, true
+}
+
+// setNotificationChannel validates a channel request and stores it on the channel
+func setNotificationChannel(channel *models.NotificationChannel, req *models.NotificationChannelRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := notify.ValidateConfig(req.Type, &req.Config); err != nil {
+		return err
+	}
+	config, err := json.Marshal(req.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %s", err)
+	}
+	channel.Name = req.Name
+	channel.Type = req.Type
+	channel.Config = string(config)
+	return nil
+}
+
+func buildNotificationChannelResponse(channel *models.NotificationChannel) models.NotificationChannelResponse {
+	resp := models.NotificationChannelResponse{
+		ID:        channel.ID,
+		Name:      channel.Name,
+		Type:      channel.Type,
+		CreatedAt: channel.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: channel.UpdatedAt.Format(time.RFC3339),
+	}
+	if err := json.Unmarshal([]byte(channel.Config), &resp.Config); err != nil {
+		logs.Error("Failed to decode config of notification channel[%d]: %s", channel.ID, err)
+	}
+	setUsernames(&resp.CreatedBy, &resp.UpdatedBy, channel.CreatedBy, channel.UpdatedBy)
+	return resp
+}
+
+// @router /project/:projectid/jobs/:id/notifications [get]
+func (c *JobHandler) GetJobNotifications() {
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+	resp, err := c.buildJobNotificationsResponse(job)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/jobs/:id/notifications [put]
+func (c *JobHandler) UpdateJobNotifications() {
+	var req models.JobNotificationsRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	job, ok := c.getProjectJob()
+	if !ok {
+		return
+	}
+
+	subscriptions := make([]*models.JobNotification, 0, len(req.Subscriptions))
+	seen := map[int]bool{}
+	for _, config := range req.Subscriptions {
+		if seen[config.ChannelID] {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Notification channel %d is listed more than once", config.ChannelID))
+			return
+		}
+		seen[config.ChannelID] = true
+		if err := validateJobNotification(&config); err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Notification channel %d: %s", config.ChannelID, err))
+			return
+		}
+		channel, err := c.channelORM.GetByID(config.ChannelID)
+		if err != nil || channel.ProjectID != job.ProjectID {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Notification channel %d not found in project", config.ChannelID))
+			return
+		}
+		events, err := json.Marshal(config.Events)
+		if err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid events")
+			return
+		}
+		subscriptions = append(subscriptions, &models.JobNotification{
+			ChannelID:       channel,
+			Events:          string(events),
+			RunLongerThan:   config.RunLongerThan,
+			NoSuccessWithin: config.NoSuccessWithin,
+		})
+	}
+
+	if err := c.notificationORM.ReplaceForJob(job, subscriptions); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to update job notifications: %s", err))
+		return
+	}
+	resp, err := c.buildJobNotificationsResponse(job)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// validateJobNotification checks the events of a subscription and the thresholds they need
+func validateJobNotification(config *models.JobNotificationConfig) error {
+	if len(config.Events) == 0 {
+		return fmt.Errorf("events must list at least one event")
+	}
+	for _, event := range config.Events {
+		if !slices.Contains(constants.NotificationEvents, event) {
+			return fmt.Errorf("unknown event %q, must be one of %v", event, constants.NotificationEvents)
+		}
+	}
+	thresholds := []struct {
+		name, value, event string
+	}{
+		{"run_longer_than", config.RunLongerThan, constants.NotificationEventLongRun},
+		{"no_success_within", config.NoSuccessWithin, constants.NotificationEventNoSuccess},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value == "" {
+			if slices.Contains(config.Events, threshold.event) {
+				return fmt.Errorf("%s is required for the %s event", threshold.name, threshold.event)
+			}
+			continue
+		}
+		if duration, err := time.ParseDuration(threshold.value); err != nil || duration < time.Minute {
+			return fmt.Errorf("%s must be a duration of at least 1m", threshold.name)
+		}
+	}
+	return nil
+}
+
+func (c *JobHandler) buildJobNotificationsResponse(job *models.Job) (*models.JobNotificationsResponse, error) {
+	subscriptions, err := c.notificationORM.GetByJobID(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.JobNotificationsResponse{
+		JobID:         job.ID,
+		Subscriptions: make([]models.JobNotificationConfig, 0, len(subscriptions)),
+	}
+	for _, subscription := range subscriptions {
+		config := models.JobNotificationConfig{
+			ChannelID:       subscription.ChannelID.ID,
+			Events:          []string{},
+			RunLongerThan:   subscription.RunLongerThan,
+			NoSuccessWithin: subscription.NoSuccessWithin,
+		}
+		if err := json.Unmarshal([]byte(subscription.Events), &config.Events); err != nil {
+			logs.Error("Failed to decode events of notification subscription[%d]: %s", subscription.ID, err)
+		}
+		resp.Subscriptions = append(resp.Subscriptions, config)
+	}
+	return resp, nil
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+type ProjectHandler struct {
+	web.Controller
+	settingsORM *database.ProjectSettingsORM
+	jobORM      *database.JobORM
+	depORM      *database.JobDependencyORM
+	sourceORM   *database.SourceORM
+	destORM     *database.DestinationORM
+	configORM   *database.ProjectConfigORM
+	channelORM  *database.NotificationChannelORM
+	webhookORM  *database.WebhookORM
+	deliveryORM *database.WebhookDeliveryORM
+	trashORM    *database.TrashORM
+	tempClient  *temporal.Client
+}
+
+// Prepare initializes the ORM instances
+func (c *ProjectHandler) Prepare() {
+	c.settingsORM = database.NewProjectSettingsORM()
+	c.jobORM = database.NewJobORM()
+	c.depORM = database.NewJobDependencyORM()
+	c.sourceORM = database.NewSourceORM()
+	c.destORM = database.NewDestinationORM()
+	c.configORM = database.NewProjectConfigORM()
+	c.channelORM = database.NewNotificationChannelORM()
+	c.webhookORM = database.NewWebhookORM()
+	c.deliveryORM = database.NewWebhookDeliveryORM()
+	c.trashORM = database.NewTrashORM()
+	var err error
+	c.tempClient, err = temporal.NewClient()
+	if err != nil {
+		logs.Error("Failed to create Temporal client: %v", err)
+	}
+}
+
+// @router /project/:projectid/settings [get]
+func (c *ProjectHandler) GetProjectSettings() {
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	settings, err := c.settingsORM.GetByProjectID(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
+		return
+	}
+
+	resp, err := buildProjectSettingsResponse(settings)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/settings [put]
+func (c *ProjectHandler) UpdateProjectSettings() {
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	var req models.ProjectSettingsRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if err := temporal.ValidateBlackoutWindows(req.BlackoutWindows); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.MaxConcurrentSyncs < 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "max_concurrent_syncs must not be negative")
+		return
+	}
+	blackedOutJobs, err := c.blackedOutJobs(projectIDStr, req.BlackoutWindows)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get jobs: %s", err))
+		return
+	}
+	if len(blackedOutJobs) > 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Blackout windows leave no run for jobs: %s", strings.Join(blackedOutJobs, ", ")))
+		return
+	}
+
+	settings, err := c.settingsORM.GetByProjectID(projectIDStr)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get project settings: %s", err))
+		return
+	}
+	blackoutWindows, err := json.Marshal(req.BlackoutWindows)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid blackout windows")
+		return
+	}
+	settings.BlackoutWindows = string(blackoutWindows)
+	settings.MaxConcurrentSyncs = req.MaxConcurrentSyncs
+
+	userID := c.GetSession(constants.SessionUserID)
+	if userID != nil {
+		settings.UpdatedBy = &models.User{ID: userID.(int)}
+	}
+
+	ops, err := c.settingsORM.SaveScheduled(settings, string(temporal.ActionUpdate))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to save project settings: %s", err))
+		return
+	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, ops...)
+
+	resp, err := buildProjectSettingsResponse(settings)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/concurrency [get]
+func (c *ProjectHandler) GetSyncConcurrency() {
+	projectIDStr := c.Ctx.Input.Param(":projectid")
+	if c.tempClient == nil {
+		utils.ErrorResponse(&c.Controller, http.StatusServiceUnavailable, "Temporal client is not available")
+		return
+	}
+	state, err := c.tempClient.GetConcurrencyState(c.Ctx.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := models.ConcurrencyResponse{
+		Running: buildConcurrencySlots(state.Holders, projectIDStr),
+		Queued:  buildConcurrencySlots(state.Queue, projectIDStr),
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// blackedOutJobs returns the jobs of the project that would never fire outside of the windows
+func (c *ProjectHandler) blackedOutJobs(projectIDStr string, windows []models.BlackoutWindow) ([]string, error) {
+	jobs, err := c.jobORM.GetAllByProjectID(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+	var blackedOut []string
+	for _, job := range jobs {
+		config, err := temporal.ParseScheduleConfig(job.ScheduleConfig)
+		if err != nil {
+			continue
+		}
+		if err := temporal.ValidateSchedule(job.Frequency, config, windows, time.Now()); errors.Is(err, temporal.ErrScheduleBlackedOut) {
+			blackedOut = append(blackedOut, job.Name)
+		}
+	}
+	return blackedOut, nil
+}
+
+// buildConcurrencySlots lists the requests of the project's syncs
+func buildConcurrencySlots(requests []temporal.ConcurrencyRequest, projectIDStr string) []models.ConcurrencySlot {
+	slots := make([]models.ConcurrencySlot, 0, len(requests))
+	for _, request := range requests {
+		if request.ProjectID != projectIDStr {
+			continue
+		}
+		slot := models.ConcurrencySlot{
+			WorkflowID:  request.WorkflowID,
+			JobID:       request.JobID,
+			RequestedAt: request.RequestedAt.Format(time.RFC3339),
+		}
+		for _, scope := range request.Scopes {
+			slot.Scopes = append(slot.Scopes, fmt.Sprintf("%s (limit %d)", scope.Key, scope.Limit))
+		}
+		if !request.GrantedAt.IsZero() {
+			slot.GrantedAt = request.GrantedAt.Format(time.RFC3339)
+		}
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func buildProjectSettingsResponse(settings *models.ProjectSettings) (*models.ProjectSettingsResponse, error) {
+	blackoutWindows, err := temporal.ParseBlackoutWindows(settings.BlackoutWindows)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.ProjectSettingsResponse{
+		ProjectID:          settings.ProjectID,
+		BlackoutWindows:    blackoutWindows,
+		MaxConcurrentSyncs: settings.MaxConcurrentSyncs,
+	}
+	if settings.ID != 0 {
+		resp.UpdatedAt = settings.UpdatedAt.Format(time.RFC3339)
+	}
+	if settings.UpdatedBy != nil {
+		resp.UpdatedBy = settings.UpdatedBy.Username
+	}
+	return resp, nil
+}
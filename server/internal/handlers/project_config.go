@@ -0,0 +1,746 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// projectSnapshot is the current sources, destinations, jobs and settings of a project, with decrypted configs
+type projectSnapshot struct {
+	projectID    string
+	sources      []*models.Source
+	destinations []*models.Destination
+	jobs         []*models.Job
+	dependencies []*models.JobDependency
+	settings     *models.ProjectSettings
+	// blackoutWindows are the parsed blackout windows of settings
+	blackoutWindows []models.BlackoutWindow
+}
+
+// @router /project/:projectid/config/export [get]
+func (c *ProjectHandler) ExportProjectConfig() {
+	format := c.GetString("format", "yaml")
+	if format != "yaml" && format != "json" {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "format must be yaml or json")
+		return
+	}
+	secrets := c.GetString("secrets", constants.SecretsReference)
+	if secrets != constants.SecretsReference && secrets != constants.SecretsOmit {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("secrets must be %s or %s", constants.SecretsReference, constants.SecretsOmit))
+		return
+	}
+
+	snapshot, err := c.loadProjectSnapshot()
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	doc, err := exportProjectConfig(snapshot, secrets == constants.SecretsOmit)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to export project config: %s", err))
+		return
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err == nil && format == "yaml" {
+		data, err = utils.JSONToYAML(data)
+	}
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to encode project config: %s", err))
+		return
+	}
+
+	// the document is returned as is so it can be committed without unwrapping
+	c.Ctx.Output.Header("Content-Type", fmt.Sprintf("application/%s", format))
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"project-%s.%s\"", snapshot.projectID, format))
+	_ = c.Ctx.Output.Body(data)
+}
+
+// @router /project/:projectid/config/plan [post]
+func (c *ProjectHandler) PlanProjectConfig() {
+	_, plan, ok := c.planProjectConfigRequest()
+	if !ok {
+		return
+	}
+	utils.SuccessResponse(&c.Controller, models.ProjectConfigPlanResponse{Changes: plan})
+}
+
+// @router /project/:projectid/config/apply [post]
+func (c *ProjectHandler) ApplyProjectConfig() {
+	changes, plan, ok := c.planProjectConfigRequest()
+	if !ok {
+		return
+	}
+	ops, err := c.configORM.Apply(changes)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to apply project config: %s", err))
+		return
+	}
+	resp := models.ProjectConfigApplyResponse{Changes: plan}
+	if err := applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, ops...); err != nil {
+		resp.ScheduleErrors = []string{fmt.Sprintf("%s, the schedule outbox retries the schedule changes", err)}
+	}
+	c.emitJobEvents(changes)
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// planProjectConfigRequest parses the YAML or JSON config document of the
+// request and compares it with the project, responding on failure
+func (c *ProjectHandler) planProjectConfigRequest() (*database.ProjectConfigChanges, []models.ConfigChange, bool) {
+	data, err := utils.YAMLToJSON(c.Ctx.Input.RequestBody)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid request format: %s", err))
+		return nil, nil, false
+	}
+	var doc models.ProjectConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid project config: %s", err))
+		return nil, nil, false
+	}
+
+	snapshot, err := c.loadProjectSnapshot()
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	var user *models.User
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		user = &models.User{ID: userID.(int)}
+	}
+	changes, plan, err := planProjectConfig(&doc, snapshot, user)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid project config: %s", err))
+		return nil, nil, false
+	}
+	return changes, plan, true
+}
+
+func (c *ProjectHandler) loadProjectSnapshot() (*projectSnapshot, error) {
+	snapshot := &projectSnapshot{projectID: c.Ctx.Input.Param(":projectid")}
+	var err error
+	if snapshot.sources, err = c.sourceORM.GetAllByProjectID(snapshot.projectID); err != nil {
+		return nil, err
+	}
+	if snapshot.destinations, err = c.destORM.GetAllByProjectID(snapshot.projectID); err != nil {
+		return nil, err
+	}
+	if snapshot.jobs, err = c.jobORM.GetAllByProjectID(snapshot.projectID); err != nil {
+		return nil, err
+	}
+	if snapshot.dependencies, err = c.depORM.GetAllByProjectID(snapshot.projectID); err != nil {
+		return nil, err
+	}
+	if snapshot.settings, err = c.settingsORM.GetByProjectID(snapshot.projectID); err != nil {
+		return nil, err
+	}
+	if snapshot.blackoutWindows, err = temporal.ParseBlackoutWindows(snapshot.settings.BlackoutWindows); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// planScheduleActions returns the actions on the schedules of the jobs an
+// import creates or changes. planned are all jobs of the project after
+// the import, project blackout windows are part of every one of their schedules.
+func planScheduleActions(changes *database.ProjectConfigChanges, plan []models.ConfigChange, planned []*models.Job) []*database.JobScheduleAction {
+	var blackoutChanged bool
+	updatedFields := map[string][]string{}
+	for _, change := range plan {
+		switch {
+		case change.Kind == constants.ConfigKindSettings:
+			blackoutChanged = slices.Contains(change.Fields, "blackout_windows")
+		case change.Kind == constants.ConfigKindJob && change.Action == constants.ConfigChangeUpdate:
+			updatedFields[change.Name] = change.Fields
+		}
+	}
+
+	var actions []*database.JobScheduleAction
+	add := func(job *models.Job, action temporal.SyncAction) {
+		actions = append(actions, &database.JobScheduleAction{Job: job, Action: string(action)})
+	}
+	for _, job := range planned {
+		if job.ID == 0 {
+			// created paused when the job is inactive
+			add(job, temporal.ActionCreate)
+			continue
+		}
+		fields := updatedFields[job.Name]
+		if blackoutChanged || slices.Contains(fields, "frequency") || slices.Contains(fields, "schedule_config") {
+			add(job, temporal.ActionUpdate)
+		}
+		if slices.Contains(fields, "active") {
+			if job.Active {
+				add(job, temporal.ActionUnpause)
+			} else {
+				add(job, temporal.ActionPause)
+			}
+		}
+	}
+	return actions
+}
+
+// emitJobEvents sends the job changes of an applied config to the webhooks of the project
+func (c *ProjectHandler) emitJobEvents(changes *database.ProjectConfigChanges) {
+	ctx := c.Ctx.Request.Context()
+	for _, job := range changes.CreateJobs {
+		emitJobEvent(ctx, c.tempClient, constants.WebhookEventJobCreated, job)
+	}
+	for _, job := range changes.UpdateJobs {
+		emitJobEvent(ctx, c.tempClient, constants.WebhookEventJobUpdated, job)
+	}
+	for _, job := range changes.DeleteJobs {
+		emitJobEvent(ctx, c.tempClient, constants.WebhookEventJobDeleted, job)
+	}
+}
+
+// exportProjectConfig builds the config document of a project, sorted by name
+func exportProjectConfig(snapshot *projectSnapshot, omitSecrets bool) (*models.ProjectConfig, error) {
+	doc := &models.ProjectConfig{
+		Version:      constants.ProjectConfigVersion,
+		Sources:      []models.ConnectorDefinition{},
+		Destinations: []models.ConnectorDefinition{},
+		Jobs:         []models.JobDefinition{},
+	}
+	for _, source := range snapshot.sources {
+		def, err := connectorDefinition(source.Name, source.Type, source.Version, source.MaxConcurrentSyncs, source.Config)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %s", source.Name, err)
+		}
+		utils.HideSecrets(def.Config, constants.ConfigKindSource, source.Name, omitSecrets)
+		doc.Sources = append(doc.Sources, *def)
+	}
+	for _, dest := range snapshot.destinations {
+		def, err := connectorDefinition(dest.Name, dest.DestType, dest.Version, dest.MaxConcurrentSyncs, dest.Config)
+		if err != nil {
+			return nil, fmt.Errorf("destination %s: %s", dest.Name, err)
+		}
+		utils.HideSecrets(def.Config, constants.ConfigKindDestination, dest.Name, omitSecrets)
+		doc.Destinations = append(doc.Destinations, *def)
+	}
+	upstreams := jobUpstreams(snapshot.dependencies, jobNamesByID(snapshot.jobs))
+	for _, job := range snapshot.jobs {
+		def, err := jobDefinition(job)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %s", job.Name, err)
+		}
+		def.Upstreams = upstreams[job.ID]
+		doc.Jobs = append(doc.Jobs, *def)
+	}
+	doc.Settings = &models.ProjectSettingsDefinition{
+		BlackoutWindows:    snapshot.blackoutWindows,
+		MaxConcurrentSyncs: snapshot.settings.MaxConcurrentSyncs,
+	}
+	if doc.Settings.BlackoutWindows == nil {
+		doc.Settings.BlackoutWindows = []models.BlackoutWindow{}
+	}
+
+	sort.SliceStable(doc.Sources, func(i, j int) bool { return doc.Sources[i].Name < doc.Sources[j].Name })
+	sort.SliceStable(doc.Destinations, func(i, j int) bool { return doc.Destinations[i].Name < doc.Destinations[j].Name })
+	sort.SliceStable(doc.Jobs, func(i, j int) bool { return doc.Jobs[i].Name < doc.Jobs[j].Name })
+	return doc, nil
+}
+
+// jobUpstreams returns the upstream declarations of each job by job ID, sorted by upstream name
+func jobUpstreams(dependencies []*models.JobDependency, jobNames map[int]string) map[int][]models.JobUpstreamDefinition {
+	upstreams := map[int][]models.JobUpstreamDefinition{}
+	for _, dependency := range dependencies {
+		name, ok := jobNames[dependency.UpstreamJobID.ID]
+		if !ok {
+			continue
+		}
+		jobID := dependency.JobID.ID
+		upstreams[jobID] = append(upstreams[jobID], models.JobUpstreamDefinition{Job: name, Mode: dependency.Mode})
+	}
+	for _, defs := range upstreams {
+		sort.Slice(defs, func(i, j int) bool { return defs[i].Job < defs[j].Job })
+	}
+	return upstreams
+}
+
+func connectorDefinition(name, connectorType, version string, maxConcurrentSyncs int, config string) (*models.ConnectorDefinition, error) {
+	decoded, err := decodeJSONObject(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %s", err)
+	}
+	return &models.ConnectorDefinition{
+		Name:               name,
+		Type:               connectorType,
+		Version:            version,
+		MaxConcurrentSyncs: maxConcurrentSyncs,
+		Config:             decoded,
+	}, nil
+}
+
+func jobDefinition(job *models.Job) (*models.JobDefinition, error) {
+	if job.SourceID == nil || job.DestID == nil {
+		return nil, fmt.Errorf("source or destination not found")
+	}
+	streamsConfig, err := decodeJSONObject(job.StreamsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid streams config: %s", err)
+	}
+	active := job.Active
+	def := &models.JobDefinition{
+		Name:          job.Name,
+		Source:        job.SourceID.Name,
+		Destination:   job.DestID.Name,
+		Active:        &active,
+		Frequency:     job.Frequency,
+		StreamsConfig: streamsConfig,
+	}
+	if !jsonEqual(job.ScheduleConfig, "{}") {
+		if def.ScheduleConfig, err = temporal.ParseScheduleConfig(job.ScheduleConfig); err != nil {
+			return nil, err
+		}
+	}
+	if !jsonEqual(job.RetryPolicy, "{}") {
+		if def.RetryPolicy, err = temporal.ParseRetryPolicy(job.RetryPolicy); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// planProjectConfig compares a config document with a project and returns the
+// changes that make the project match it. Sources, destinations and jobs
+// missing from the document are deleted.
+func planProjectConfig(doc *models.ProjectConfig, snapshot *projectSnapshot, user *models.User) (*database.ProjectConfigChanges, []models.ConfigChange, error) {
+	if doc.Version != constants.ProjectConfigVersion {
+		return nil, nil, fmt.Errorf("unsupported version %d, expected %d", doc.Version, constants.ProjectConfigVersion)
+	}
+
+	// secret references resolve against the configs stored before the import
+	stored := map[string]*models.ConnectorDefinition{}
+	currentSources := map[string]*models.Source{}
+	for _, source := range snapshot.sources {
+		if _, ok := currentSources[source.Name]; ok {
+			return nil, nil, fmt.Errorf("the project has several sources named %q, rename them before importing", source.Name)
+		}
+		currentSources[source.Name] = source
+		def, err := connectorDefinition(source.Name, source.Type, source.Version, source.MaxConcurrentSyncs, source.Config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("source %q: %s", source.Name, err)
+		}
+		stored[constants.ConfigKindSource+"/"+source.Name] = def
+	}
+	currentDestinations := map[string]*models.Destination{}
+	for _, dest := range snapshot.destinations {
+		if _, ok := currentDestinations[dest.Name]; ok {
+			return nil, nil, fmt.Errorf("the project has several destinations named %q, rename them before importing", dest.Name)
+		}
+		currentDestinations[dest.Name] = dest
+		def, err := connectorDefinition(dest.Name, dest.DestType, dest.Version, dest.MaxConcurrentSyncs, dest.Config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("destination %q: %s", dest.Name, err)
+		}
+		stored[constants.ConfigKindDestination+"/"+dest.Name] = def
+	}
+	currentJobs := map[string]*models.Job{}
+	for _, job := range snapshot.jobs {
+		if _, ok := currentJobs[job.Name]; ok {
+			return nil, nil, fmt.Errorf("the project has several jobs named %q, rename them before importing", job.Name)
+		}
+		currentJobs[job.Name] = job
+	}
+	lookup := func(ref utils.SecretReference) (interface{}, error) {
+		def, ok := stored[ref.Kind+"/"+ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s %q does not exist", ref.Kind, ref.Name)
+		}
+		value, ok := utils.LookupPath(def.Config, ref.Path)
+		if !ok {
+			return nil, fmt.Errorf("%s %q has no %s", ref.Kind, ref.Name, ref.Path)
+		}
+		return value, nil
+	}
+
+	changes := &database.ProjectConfigChanges{}
+	var plan []models.ConfigChange
+
+	sources := map[string]*models.Source{}
+	for i := range doc.Sources {
+		def := &doc.Sources[i]
+		if def.Name == "" || def.Type == "" {
+			return nil, nil, fmt.Errorf("sources need a name and type")
+		}
+		if _, ok := sources[def.Name]; ok {
+			return nil, nil, fmt.Errorf("source %q is declared more than once", def.Name)
+		}
+		config, fields, err := planConnector(constants.ConfigKindSource, def, stored[constants.ConfigKindSource+"/"+def.Name], lookup)
+		if err != nil {
+			return nil, nil, err
+		}
+		source, ok := currentSources[def.Name]
+		if !ok {
+			source = &models.Source{ProjectID: snapshot.projectID, CreatedBy: user, UpdatedBy: user}
+			changes.CreateSources = append(changes.CreateSources, source)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeCreate, Kind: constants.ConfigKindSource, Name: def.Name})
+		} else if len(fields) > 0 {
+			changes.UpdateSources = append(changes.UpdateSources, source)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindSource, Name: def.Name, Fields: fields})
+			if user != nil {
+				source.UpdatedBy = user
+			}
+		}
+		source.Name, source.Type, source.Version, source.MaxConcurrentSyncs, source.Config = def.Name, def.Type, def.Version, def.MaxConcurrentSyncs, config
+		sources[def.Name] = source
+	}
+	for _, source := range snapshot.sources {
+		if _, ok := sources[source.Name]; !ok {
+			changes.DeleteSources = append(changes.DeleteSources, source)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeDelete, Kind: constants.ConfigKindSource, Name: source.Name})
+		}
+	}
+
+	destinations := map[string]*models.Destination{}
+	for i := range doc.Destinations {
+		def := &doc.Destinations[i]
+		if def.Name == "" || def.Type == "" {
+			return nil, nil, fmt.Errorf("destinations need a name and type")
+		}
+		if _, ok := destinations[def.Name]; ok {
+			return nil, nil, fmt.Errorf("destination %q is declared more than once", def.Name)
+		}
+		config, fields, err := planConnector(constants.ConfigKindDestination, def, stored[constants.ConfigKindDestination+"/"+def.Name], lookup)
+		if err != nil {
+			return nil, nil, err
+		}
+		dest, ok := currentDestinations[def.Name]
+		if !ok {
+			dest = &models.Destination{ProjectID: snapshot.projectID, CreatedBy: user, UpdatedBy: user}
+			changes.CreateDestinations = append(changes.CreateDestinations, dest)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeCreate, Kind: constants.ConfigKindDestination, Name: def.Name})
+		} else if len(fields) > 0 {
+			changes.UpdateDestinations = append(changes.UpdateDestinations, dest)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindDestination, Name: def.Name, Fields: fields})
+			if user != nil {
+				dest.UpdatedBy = user
+			}
+		}
+		dest.Name, dest.DestType, dest.Version, dest.MaxConcurrentSyncs, dest.Config = def.Name, def.Type, def.Version, def.MaxConcurrentSyncs, config
+		destinations[def.Name] = dest
+	}
+	for _, dest := range snapshot.destinations {
+		if _, ok := destinations[dest.Name]; !ok {
+			changes.DeleteDestinations = append(changes.DeleteDestinations, dest)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeDelete, Kind: constants.ConfigKindDestination, Name: dest.Name})
+		}
+	}
+
+	projectWindows := snapshot.blackoutWindows
+	if doc.Settings != nil {
+		settings, fields, err := planSettings(doc.Settings, snapshot)
+		if err != nil {
+			return nil, nil, fmt.Errorf("settings: %s", err)
+		}
+		if len(fields) > 0 {
+			if user != nil {
+				settings.UpdatedBy = user
+			}
+			changes.Settings = settings
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindSettings, Name: snapshot.projectID, Fields: fields})
+		}
+		projectWindows = doc.Settings.BlackoutWindows
+	}
+
+	jobs := map[string]int{}
+	for i := range doc.Jobs {
+		def := &doc.Jobs[i]
+		if def.Name == "" {
+			return nil, nil, fmt.Errorf("jobs need a name")
+		}
+		if _, ok := jobs[def.Name]; ok {
+			return nil, nil, fmt.Errorf("job %q is declared more than once", def.Name)
+		}
+		jobs[def.Name] = i
+	}
+	if err := checkJobUpstreams(doc.Jobs, jobs); err != nil {
+		return nil, nil, err
+	}
+	currentUpstreams := jobUpstreams(snapshot.dependencies, jobNamesByID(snapshot.jobs))
+	planned := make([]*models.Job, len(doc.Jobs))
+	for i := range doc.Jobs {
+		def := &doc.Jobs[i]
+		job, fields, err := planJob(def, currentJobs[def.Name], sources, destinations, projectWindows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("job %q: %s", def.Name, err)
+		}
+		planned[i] = job
+		var current []models.JobUpstreamDefinition
+		if job.ID != 0 {
+			current = currentUpstreams[job.ID]
+		}
+		if !slices.Equal(current, def.Upstreams) {
+			if job.ID != 0 {
+				fields = append(fields, "upstreams")
+			}
+			changes.Upstreams = append(changes.Upstreams, &database.JobUpstreams{Job: job})
+		}
+		switch {
+		case job.ID == 0:
+			job.ProjectID, job.State, job.CreatedBy, job.UpdatedBy = snapshot.projectID, "{}", user, user
+			changes.CreateJobs = append(changes.CreateJobs, job)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeCreate, Kind: constants.ConfigKindJob, Name: def.Name})
+		case len(fields) > 0:
+			if user != nil {
+				job.UpdatedBy = user
+			}
+			changes.UpdateJobs = append(changes.UpdateJobs, job)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindJob, Name: def.Name, Fields: fields})
+		}
+	}
+	for _, upstreams := range changes.Upstreams {
+		def := &doc.Jobs[jobs[upstreams.Job.Name]]
+		upstreams.Dependencies = make([]*models.JobDependency, 0, len(def.Upstreams))
+		for _, upstream := range def.Upstreams {
+			upstreams.Dependencies = append(upstreams.Dependencies, &models.JobDependency{UpstreamJobID: planned[jobs[upstream.Job]], Mode: upstream.Mode})
+		}
+	}
+	for _, job := range snapshot.jobs {
+		if _, ok := jobs[job.Name]; !ok {
+			changes.DeleteJobs = append(changes.DeleteJobs, job)
+			plan = append(plan, models.ConfigChange{Action: constants.ConfigChangeDelete, Kind: constants.ConfigKindJob, Name: job.Name})
+		}
+	}
+	if plan == nil {
+		plan = []models.ConfigChange{}
+	}
+	changes.ScheduleActions = planScheduleActions(changes, plan, planned)
+	changes.DeleteAction = string(connectorDeleteAction(constants.DeleteModeCascade))
+	return changes, plan, nil
+}
+
+// planSettings builds the project settings a declaration asks for and returns the fields that change
+func planSettings(def *models.ProjectSettingsDefinition, snapshot *projectSnapshot) (*models.ProjectSettings, []string, error) {
+	if def.BlackoutWindows == nil {
+		def.BlackoutWindows = []models.BlackoutWindow{}
+	}
+	if err := temporal.ValidateBlackoutWindows(def.BlackoutWindows); err != nil {
+		return nil, nil, err
+	}
+	if def.MaxConcurrentSyncs < 0 {
+		return nil, nil, fmt.Errorf("max_concurrent_syncs must not be negative")
+	}
+	blackoutWindows, err := json.Marshal(def.BlackoutWindows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode blackout windows: %s", err)
+	}
+
+	var fields []string
+	if len(snapshot.blackoutWindows)+len(def.BlackoutWindows) > 0 && !reflect.DeepEqual(snapshot.blackoutWindows, def.BlackoutWindows) {
+		fields = append(fields, "blackout_windows")
+	}
+	if def.MaxConcurrentSyncs != snapshot.settings.MaxConcurrentSyncs {
+		fields = append(fields, "max_concurrent_syncs")
+	}
+	settings := *snapshot.settings
+	settings.ProjectID = snapshot.projectID
+	settings.BlackoutWindows = string(blackoutWindows)
+	settings.MaxConcurrentSyncs = def.MaxConcurrentSyncs
+	return &settings, fields, nil
+}
+
+// checkJobUpstreams validates the upstream declarations of the jobs of a config
+// document, jobs maps job names to their index in the document
+func checkJobUpstreams(defs []models.JobDefinition, jobs map[string]int) error {
+	graph := map[int][]int{}
+	for i := range defs {
+		def := &defs[i]
+		seen := map[string]bool{}
+		for j := range def.Upstreams {
+			upstream := &def.Upstreams[j]
+			if upstream.Mode == "" {
+				upstream.Mode = constants.DependencyModeTrigger
+			}
+			if upstream.Mode != constants.DependencyModeTrigger && upstream.Mode != constants.DependencyModeWait {
+				return fmt.Errorf("job %q: invalid dependency mode %q, must be %s or %s", def.Name, upstream.Mode, constants.DependencyModeTrigger, constants.DependencyModeWait)
+			}
+			if upstream.Job == def.Name {
+				return fmt.Errorf("job %q: a job cannot depend on itself", def.Name)
+			}
+			if seen[upstream.Job] {
+				return fmt.Errorf("job %q: upstream job %q is listed more than once", def.Name, upstream.Job)
+			}
+			seen[upstream.Job] = true
+			upstreamIdx, ok := jobs[upstream.Job]
+			if !ok {
+				return fmt.Errorf("job %q: upstream job %q is not declared", def.Name, upstream.Job)
+			}
+			graph[upstreamIdx] = append(graph[upstreamIdx], i)
+		}
+		sort.Slice(def.Upstreams, func(a, b int) bool { return def.Upstreams[a].Job < def.Upstreams[b].Job })
+	}
+	if cycle := utils.FindCycle(graph); cycle != nil {
+		path := make([]string, 0, len(cycle))
+		for _, idx := range cycle {
+			path = append(path, defs[idx].Name)
+		}
+		return fmt.Errorf("job dependencies would create a cycle: %s", strings.Join(path, " -> "))
+	}
+	return nil
+}
+
+func jobNamesByID(jobs []*models.Job) map[int]string {
+	names := make(map[int]string, len(jobs))
+	for _, job := range jobs {
+		names[job.ID] = job.Name
+	}
+	return names
+}
+
+// planConnector resolves the secrets of a declared connector and returns its
+// encoded config and, when it exists, the fields that change
+func planConnector(kind string, def, existing *models.ConnectorDefinition, lookup func(utils.SecretReference) (interface{}, error)) (string, []string, error) {
+	if def.Config == nil {
+		def.Config = map[string]interface{}{}
+	}
+	if err := utils.ResolveSecrets(def.Config, lookup); err != nil {
+		return "", nil, fmt.Errorf("%s %q: %s", kind, def.Name, err)
+	}
+	if existing != nil {
+		utils.KeepOmittedSecrets(existing.Config, def.Config)
+	}
+	config, err := json.Marshal(def.Config)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s %q: failed to encode config: %s", kind, def.Name, err)
+	}
+	if existing == nil {
+		return string(config), nil, nil
+	}
+
+	var fields []string
+	if def.Type != existing.Type {
+		fields = append(fields, "type")
+	}
+	if def.Version != existing.Version {
+		fields = append(fields, "version")
+	}
+	if def.MaxConcurrentSyncs != existing.MaxConcurrentSyncs {
+		fields = append(fields, "max_concurrent_syncs")
+	}
+	for _, key := range changedKeys(existing.Config, def.Config) {
+		fields = append(fields, "config."+key)
+	}
+	return string(config), fields, nil
+}
+
+// planJob builds the job a declaration asks for, updating existing in place,
+// and returns the fields that change
+func planJob(def *models.JobDefinition, existing *models.Job, sources map[string]*models.Source, destinations map[string]*models.Destination, projectWindows []models.BlackoutWindow) (*models.Job, []string, error) {
+	source, ok := sources[def.Source]
+	if !ok {
+		return nil, nil, fmt.Errorf("source %q is not declared", def.Source)
+	}
+	dest, ok := destinations[def.Destination]
+	if !ok {
+		return nil, nil, fmt.Errorf("destination %q is not declared", def.Destination)
+	}
+	scheduleConfig, err := validateJobSchedule(def.Frequency, def.ScheduleConfig, projectWindows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule: %s", err)
+	}
+	retryPolicy, err := validateJobRetryPolicy(def.RetryPolicy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid retry policy: %s", err)
+	}
+	if def.StreamsConfig == nil {
+		return nil, nil, fmt.Errorf("streams_config is required")
+	}
+	streamsConfig, err := json.Marshal(def.StreamsConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode streams config: %s", err)
+	}
+	active := def.Active == nil || *def.Active
+
+	var fields []string
+	job := existing
+	if job == nil {
+		job = &models.Job{}
+	} else {
+		if job.SourceID == nil || job.SourceID.Name != def.Source {
+			fields = append(fields, "source")
+		}
+		if job.DestID == nil || job.DestID.Name != def.Destination {
+			fields = append(fields, "destination")
+		}
+		if job.Active != active {
+			fields = append(fields, "active")
+		}
+		if job.Frequency != def.Frequency {
+			fields = append(fields, "frequency")
+		}
+		if !jsonEqual(job.ScheduleConfig, scheduleConfig) {
+			fields = append(fields, "schedule_config")
+		}
+		if !jsonEqual(job.RetryPolicy, retryPolicy) {
+			fields = append(fields, "retry_policy")
+		}
+		if !jsonEqual(job.StreamsConfig, string(streamsConfig)) {
+			fields = append(fields, "streams_config")
+		}
+	}
+	job.Name = def.Name
+	job.SourceID = source
+	job.DestID = dest
+	job.Active = active
+	job.Frequency = def.Frequency
+	job.ScheduleConfig = scheduleConfig
+	job.RetryPolicy = retryPolicy
+	job.StreamsConfig = string(streamsConfig)
+	return job, fields, nil
+}
+
+// changedKeys returns the sorted top level keys whose values differ between two decoded configs
+func changedKeys(current, updated map[string]interface{}) []string {
+	var keys []string
+	for key, value := range current {
+		if updatedValue, ok := updated[key]; !ok || !reflect.DeepEqual(value, updatedValue) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range updated {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeJSONObject decodes a stored JSON object, empty values decode to an empty object
+func decodeJSONObject(data string) (map[string]interface{}, error) {
+	decoded := map[string]interface{}{}
+	if data == "" {
+		return decoded, nil
+	}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		decoded = map[string]interface{}{}
+	}
+	return decoded, nil
+}
+
+// jsonEqual reports whether two stored JSON values are equal, empty values equal an empty object
+func jsonEqual(a, b string) bool {
+	decodedA, errA := decodeJSONObject(a)
+	decodedB, errB := decodeJSONObject(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(decodedA, decodedB)
+}
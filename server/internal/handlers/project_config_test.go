@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+func testProjectSnapshot() *projectSnapshot {
+	pg := &models.Source{ID: 1, Name: "pg", Type: "postgres", Version: "v1", Config: `{"host": "db", "password": "p", "dsn": "postgres://u:p@db/orders"}`}
+	lake := &models.Destination{ID: 2, Name: "lake", DestType: "iceberg", Version: "v1", Config: `{"writer": {"catalog_type": "rest", "aws_secret_key": "s"}}`}
+	orders := &models.Job{ID: 10, Name: "orders", SourceID: pg, DestID: lake, Active: true, Frequency: "1-days", ScheduleConfig: "{}", RetryPolicy: "{}", StreamsConfig: `{"selected_streams": {}}`}
+	users := &models.Job{ID: 11, Name: "users", SourceID: pg, DestID: lake, Active: false, Frequency: "1-days", ScheduleConfig: "{}", RetryPolicy: `{"maximum_attempts": 2}`, StreamsConfig: `{"selected_streams": {}}`}
+	windows := []models.BlackoutWindow{{StartTime: "01:00", EndTime: "02:00", TimeZone: "UTC"}}
+	return &projectSnapshot{
+		projectID:    "p1",
+		sources:      []*models.Source{pg},
+		destinations: []*models.Destination{lake},
+		jobs:         []*models.Job{users, orders},
+		dependencies: []*models.JobDependency{
+			{JobID: &models.Job{ID: 11}, UpstreamJobID: &models.Job{ID: 10}, Mode: constants.DependencyModeWait},
+		},
+		settings:        &models.ProjectSettings{ID: 5, ProjectID: "p1", BlackoutWindows: `[{"start_time": "01:00", "end_time": "02:00", "time_zone": "UTC"}]`, MaxConcurrentSyncs: 3},
+		blackoutWindows: windows,
+	}
+}
+
+// exportedProjectConfig exports the snapshot and reads it back the way an import does
+func exportedProjectConfig(t *testing.T, snapshot *projectSnapshot) *models.ProjectConfig {
+	t.Helper()
+	doc, err := exportProjectConfig(snapshot, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = utils.JSONToYAML(data); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = utils.YAMLToJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	var imported models.ProjectConfig
+	if err := json.Unmarshal(data, &imported); err != nil {
+		t.Fatal(err)
+	}
+	return &imported
+}
+
+func TestExportProjectConfig(t *testing.T) {
+	doc, err := exportProjectConfig(testProjectSnapshot(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Jobs) != 2 || doc.Jobs[0].Name != "orders" || doc.Jobs[1].Name != "users" {
+		t.Fatalf("jobs = %+v, want orders and users sorted by name", doc.Jobs)
+	}
+	if want := []models.JobUpstreamDefinition{{Job: "orders", Mode: constants.DependencyModeWait}}; !reflect.DeepEqual(doc.Jobs[1].Upstreams, want) {
+		t.Errorf("users upstreams = %+v, want %+v", doc.Jobs[1].Upstreams, want)
+	}
+	if doc.Jobs[0].Upstreams != nil {
+		t.Errorf("orders upstreams = %+v, want none", doc.Jobs[0].Upstreams)
+	}
+	if *doc.Jobs[1].Active || doc.Jobs[1].RetryPolicy == nil || doc.Jobs[1].RetryPolicy.MaximumAttempts != 2 {
+		t.Errorf("users = %+v", doc.Jobs[1])
+	}
+	if doc.Settings == nil || doc.Settings.MaxConcurrentSyncs != 3 || len(doc.Settings.BlackoutWindows) != 1 {
+		t.Errorf("settings = %+v", doc.Settings)
+	}
+
+	source := doc.Sources[0].Config
+	if source["host"] != "db" || source["password"] != "${secret:source/pg/password}" || source["dsn"] != "${secret:source/pg/dsn}" {
+		t.Errorf("source config = %v", source)
+	}
+	writer := doc.Destinations[0].Config["writer"].(map[string]interface{})
+	if writer["catalog_type"] != "rest" || writer["aws_secret_key"] != "${secret:destination/lake/writer.aws_secret_key}" {
+		t.Errorf("destination config = %v", writer)
+	}
+
+	doc, err = exportProjectConfig(testProjectSnapshot(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Sources[0].Config["dsn"]; ok {
+		t.Errorf("source config with omitted secrets = %v", doc.Sources[0].Config)
+	}
+}
+
+func TestPlanProjectConfigUnchanged(t *testing.T) {
+	snapshot := testProjectSnapshot()
+	changes, plan, err := planProjectConfig(exportedProjectConfig(t, snapshot), testProjectSnapshot(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 0 {
+		t.Errorf("plan of an unchanged export = %+v, want no changes", plan)
+	}
+	if len(changes.Upstreams) != 0 || changes.Settings != nil || len(changes.ScheduleActions) != 0 {
+		t.Errorf("changes of an unchanged export = %+v", changes)
+	}
+
+	// omitted secrets keep their stored value
+	doc, err := exportProjectConfig(snapshot, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, plan, err = planProjectConfig(doc, testProjectSnapshot(), nil); err != nil || len(plan) != 0 {
+		t.Errorf("plan of an export without secrets = %+v, %v, want no changes", plan, err)
+	}
+}
+
+func TestPlanProjectConfigChanges(t *testing.T) {
+	doc := exportedProjectConfig(t, testProjectSnapshot())
+	doc.Sources[0].Config["host"] = "db2"
+	doc.Destinations = append(doc.Destinations, models.ConnectorDefinition{Name: "lake2", Type: "iceberg", Version: "v1", Config: map[string]interface{}{"password": "${secret:destination/lake/writer.aws_secret_key}"}})
+	// orders moves to the new destination, users is deleted and events is created downstream of orders
+	doc.Jobs[0].Destination = "lake2"
+	doc.Jobs = append(doc.Jobs[:1], models.JobDefinition{
+		Name:          "events",
+		Source:        "pg",
+		Destination:   "lake",
+		Frequency:     "1-hours",
+		StreamsConfig: map[string]interface{}{},
+		Upstreams:     []models.JobUpstreamDefinition{{Job: "orders"}},
+	})
+	doc.Settings.MaxConcurrentSyncs = 4
+	doc.Settings.BlackoutWindows = nil
+
+	user := &models.User{ID: 7}
+	changes, plan, err := planProjectConfig(doc, testProjectSnapshot(), user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.ConfigChange{
+		{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindSource, Name: "pg", Fields: []string{"config.host"}},
+		{Action: constants.ConfigChangeCreate, Kind: constants.ConfigKindDestination, Name: "lake2"},
+		{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindSettings, Name: "p1", Fields: []string{"blackout_windows", "max_concurrent_syncs"}},
+		{Action: constants.ConfigChangeUpdate, Kind: constants.ConfigKindJob, Name: "orders", Fields: []string{"destination"}},
+		{Action: constants.ConfigChangeCreate, Kind: constants.ConfigKindJob, Name: "events"},
+		{Action: constants.ConfigChangeDelete, Kind: constants.ConfigKindJob, Name: "users"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("plan = %+v, want %+v", plan, want)
+	}
+
+	if len(changes.CreateDestinations) != 1 || changes.CreateDestinations[0].Config != `{"password":"s"}` {
+		t.Errorf("created destinations = %+v, want the referenced secret resolved", changes.CreateDestinations)
+	}
+	if len(changes.CreateJobs) != 1 || changes.CreateJobs[0].DestID.Name != "lake" || changes.CreateJobs[0].CreatedBy != user {
+		t.Errorf("created jobs = %+v", changes.CreateJobs)
+	}
+	if len(changes.UpdateJobs) != 1 || changes.UpdateJobs[0].ID != 10 || changes.UpdateJobs[0].DestID != changes.CreateDestinations[0] {
+		t.Errorf("updated jobs = %+v", changes.UpdateJobs)
+	}
+	if len(changes.DeleteJobs) != 1 || changes.DeleteJobs[0].ID != 11 {
+		t.Errorf("deleted jobs = %+v", changes.DeleteJobs)
+	}
+	if len(changes.Upstreams) != 1 || changes.Upstreams[0].Job != changes.CreateJobs[0] {
+		t.Fatalf("upstream changes = %+v, want the dependencies of events", changes.Upstreams)
+	}
+	if deps := changes.Upstreams[0].Dependencies; len(deps) != 1 || deps[0].UpstreamJobID.ID != 10 || deps[0].Mode != constants.DependencyModeTrigger {
+		t.Errorf("events dependencies = %+v, want a trigger on orders", deps)
+	}
+	if settings := changes.Settings; settings == nil || settings.ID != 5 || settings.BlackoutWindows != "[]" || settings.MaxConcurrentSyncs != 4 || settings.UpdatedBy != user {
+		t.Errorf("settings = %+v", settings)
+	}
+	// the blackout windows changed, so every schedule of the project is rebuilt
+	if got, want := scheduleActions(changes), []string{"orders update", "events create"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("schedule actions = %v, want %v", got, want)
+	}
+	if changes.DeleteAction != string(temporal.ActionDelete) {
+		t.Errorf("deleted jobs record %q, want their schedules deleted", changes.DeleteAction)
+	}
+}
+
+// scheduleActions returns the "<job> <action>" recorded in the schedule outbox for changes
+func scheduleActions(changes *database.ProjectConfigChanges) []string {
+	actions := []string{}
+	for _, scheduled := range changes.ScheduleActions {
+		actions = append(actions, scheduled.Job.Name+" "+scheduled.Action)
+	}
+	return actions
+}
+
+func TestPlanProjectConfigScheduleActions(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(doc *models.ProjectConfig)
+		want   []string
+	}{
+		{"frequency", func(doc *models.ProjectConfig) { doc.Jobs[0].Frequency = "2-hours" }, []string{"orders update"}},
+		{"activated", func(doc *models.ProjectConfig) { *doc.Jobs[1].Active = true }, []string{"users unpause"}},
+		{"deactivated with a new frequency", func(doc *models.ProjectConfig) {
+			*doc.Jobs[0].Active = false
+			doc.Jobs[0].Frequency = "2-hours"
+		}, []string{"orders update", "orders pause"}},
+		{"retry policy", func(doc *models.ProjectConfig) { doc.Jobs[0].RetryPolicy = &models.RetryPolicy{MaximumAttempts: 5} }, nil},
+		{"concurrency", func(doc *models.ProjectConfig) { doc.Settings.MaxConcurrentSyncs = 5 }, nil},
+		{"blackout windows", func(doc *models.ProjectConfig) { doc.Settings.BlackoutWindows = nil }, []string{"orders update", "users update"}},
+	}
+	for _, tc := range cases {
+		doc := exportedProjectConfig(t, testProjectSnapshot())
+		tc.change(doc)
+		changes, _, err := planProjectConfig(doc, testProjectSnapshot(), nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		want := tc.want
+		if want == nil {
+			want = []string{}
+		}
+		if got := scheduleActions(changes); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: schedule actions = %v, want %v", tc.name, got, want)
+		}
+	}
+}
+
+func TestPlanProjectConfigUpstreams(t *testing.T) {
+	doc := exportedProjectConfig(t, testProjectSnapshot())
+	doc.Jobs[1].Upstreams = nil
+	changes, plan, err := planProjectConfig(doc, testProjectSnapshot(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].Name != "users" || !reflect.DeepEqual(plan[0].Fields, []string{"upstreams"}) {
+		t.Errorf("plan = %+v, want the upstreams of users", plan)
+	}
+	if len(changes.Upstreams) != 1 || changes.Upstreams[0].Job.ID != 11 || len(changes.Upstreams[0].Dependencies) != 0 {
+		t.Errorf("upstream changes = %+v, want users without dependencies", changes.Upstreams)
+	}
+
+	// settings left out of the document are kept
+	doc = exportedProjectConfig(t, testProjectSnapshot())
+	doc.Settings = nil
+	if changes, plan, err := planProjectConfig(doc, testProjectSnapshot(), nil); err != nil || len(plan) != 0 || changes.Settings != nil {
+		t.Errorf("plan without settings = %+v, %v", plan, err)
+	}
+}
+
+func TestPlanProjectConfigInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(doc *models.ProjectConfig)
+		want   string
+	}{
+		{"version", func(doc *models.ProjectConfig) { doc.Version = 2 }, "unsupported version"},
+		{"undeclared source", func(doc *models.ProjectConfig) { doc.Jobs[0].Source = "mysql" }, `source "mysql" is not declared`},
+		{"duplicate job", func(doc *models.ProjectConfig) { doc.Jobs = append(doc.Jobs, doc.Jobs[0]) }, "declared more than once"},
+		{"unknown secret", func(doc *models.ProjectConfig) {
+			doc.Sources[0].Config["password"] = "${secret:source/pg/missing}"
+		}, "has no missing"},
+		{"undeclared upstream", func(doc *models.ProjectConfig) {
+			doc.Jobs[0].Upstreams = []models.JobUpstreamDefinition{{Job: "events"}}
+		}, `upstream job "events" is not declared`},
+		{"self dependency", func(doc *models.ProjectConfig) {
+			doc.Jobs[0].Upstreams = []models.JobUpstreamDefinition{{Job: "orders"}}
+		}, "cannot depend on itself"},
+		{"dependency mode", func(doc *models.ProjectConfig) {
+			doc.Jobs[0].Upstreams = []models.JobUpstreamDefinition{{Job: "users", Mode: "after"}}
+		}, "invalid dependency mode"},
+		{"cycle", func(doc *models.ProjectConfig) {
+			doc.Jobs[0].Upstreams = []models.JobUpstreamDefinition{{Job: "users"}}
+		}, "cycle"},
+		{"blackout window", func(doc *models.ProjectConfig) {
+			doc.Settings.BlackoutWindows = []models.BlackoutWindow{{StartTime: "25:00", EndTime: "02:00"}}
+		}, "settings"},
+		{"blacked out job", func(doc *models.ProjectConfig) {
+			doc.Settings.BlackoutWindows = []models.BlackoutWindow{{StartTime: "23:00", EndTime: "01:00", TimeZone: constants.DefaultTimeZone}}
+		}, "invalid schedule"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := exportedProjectConfig(t, testProjectSnapshot())
+			tc.modify(doc)
+			if _, _, err := planProjectConfig(doc, testProjectSnapshot(), nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("planProjectConfig() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+type ScheduleHandler struct {
+	web.Controller
+}
+
+// @router /schedules/reconcile [post]
+func (c *ScheduleHandler) ReconcileSchedules() {
+	// the check reads and repairs the schedules of every project
+	if !requireAdmin(&c.Controller) {
+		return
+	}
+	apply, err := c.GetBool("apply", false)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid apply value")
+		return
+	}
+	tempClient, err := temporal.NewClient()
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusServiceUnavailable, "Cannot reconcile schedules, Temporal is unavailable")
+		return
+	}
+	defer tempClient.Close()
+
+	drifts, err := tempClient.CheckScheduleDrift(c.Ctx.Request.Context(), apply)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to reconcile schedules: %s", err))
+		return
+	}
+	if drifts == nil {
+		drifts = []*models.ScheduleDrift{}
+	}
+	utils.SuccessResponse(&c.Controller, drifts)
+}
+
+// connectorDeleteAction is the action on the schedules of the jobs of a source
+// or destination deleted with mode, the schedules of cascaded jobs are removed
+// and the ones of detached jobs paused
+func connectorDeleteAction(mode string) temporal.SyncAction {
+	if mode == constants.DeleteModeCascade {
+		return temporal.ActionDelete
+	}
+	return temporal.ActionPause
+}
+
+// applyScheduleOperations applies operations of the schedule outbox right
+// away, the schedule outbox workflow retries them when this fails. The error is
+// only for responses that report it, the operations are applied either way.
+func applyScheduleOperations(ctx context.Context, tempClient *temporal.Client, ops ...*models.ScheduleOperation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	if tempClient == nil {
+		logs.Warn("Temporal is unavailable, %d schedule operations are left to the schedule outbox", len(ops))
+		return fmt.Errorf("temporal is unavailable")
+	}
+	if err := tempClient.ApplyScheduleOperations(ctx, ops); err != nil {
+		logs.Warn("Failed to apply %d schedule operations, the schedule outbox retries them: %s", len(ops), err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+)
+
+func TestConnectorDeleteAction(t *testing.T) {
+	cases := []struct {
+		mode string
+		want temporal.SyncAction
+	}{
+		{constants.DeleteModeCascade, temporal.ActionDelete},
+		{constants.DeleteModeDetach, temporal.ActionPause},
+		// a rejected delete records nothing, the action is never applied
+		{constants.DeleteModeReject, temporal.ActionPause},
+	}
+	for _, tc := range cases {
+		if got := connectorDeleteAction(tc.mode); got != tc.want {
+			t.Errorf("connectorDeleteAction(%q) = %s, want %s", tc.mode, got, tc.want)
+		}
+	}
+}
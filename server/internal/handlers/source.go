@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -51,13 +50,14 @@ func (c *SourceHandler) GetAllSources() {
 
 	for _, source := range sources {
 		item := models.SourceDataItem{
-			ID:        source.ID,
-			Name:      source.Name,
-			Type:      source.Type,
-			Version:   source.Version,
-			Config:    source.Config,
-			CreatedAt: source.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: source.UpdatedAt.Format(time.RFC3339),
+			ID:                 source.ID,
+			Name:               source.Name,
+			Type:               source.Type,
+			Version:            source.Version,
+			Config:             source.Config,
+			CreatedAt:          source.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:          source.UpdatedAt.Format(time.RFC3339),
+			MaxConcurrentSyncs: source.MaxConcurrentSyncs,
 		}
 
 		setUsernames(&item.CreatedBy, &item.UpdatedBy, source.CreatedBy, source.UpdatedBy)
@@ -82,13 +82,18 @@ func (c *SourceHandler) CreateSource() {
 		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
 		return
 	}
+	if req.MaxConcurrentSyncs < 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "max_concurrent_syncs must not be negative")
+		return
+	}
 
 	// Convert request to Source model
 	source := &models.Source{
-		Name:    req.Name,
-		Type:    req.Type,
-		Version: req.Version,
-		Config:  req.Config,
+		Name:               req.Name,
+		Type:               req.Type,
+		Version:            req.Version,
+		Config:             req.Config,
+		MaxConcurrentSyncs: req.MaxConcurrentSyncs,
 	}
 
 	// Get project ID if needed
@@ -121,6 +126,10 @@ func (c *SourceHandler) UpdateSource() {
 		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
 		return
 	}
+	if req.MaxConcurrentSyncs < 0 {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "max_concurrent_syncs must not be negative")
+		return
+	}
 	// Get existing source
 	existingSource, err := c.sourceORM.GetByID(id)
 	if err != nil {
@@ -133,6 +142,7 @@ func (c *SourceHandler) UpdateSource() {
 	existingSource.Config = req.Config
 	existingSource.Type = req.Type
 	existingSource.Version = req.Version
+	existingSource.MaxConcurrentSyncs = req.MaxConcurrentSyncs
 	existingSource.UpdatedAt = time.Now()
 
 	userID := c.GetSession(constants.SessionUserID)
@@ -152,37 +162,56 @@ func (c *SourceHandler) UpdateSource() {
 // @router /project/:projectid/sources/:id [delete]
 func (c *SourceHandler) DeleteSource() {
 	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	mode, ok := deleteMode(&c.Controller)
+	if !ok {
+		return
+	}
 	source, err := c.sourceORM.GetByID(id)
 	if err != nil {
 		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Source not found")
 		return
 	}
 
-	// Get all jobs using this source
-	jobs, err := c.jobORM.GetBySourceID(id)
+	// the schedule changes commit with the delete in the schedule outbox, which
+	// keeps retrying them so that no schedule keeps firing for a deleted source
+	ctx := c.Ctx.Request.Context()
+	jobs, ops, err := c.sourceORM.Delete(id, mode, string(connectorDeleteAction(mode)))
 	if err != nil {
-		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to get jobs for source")
+		deleteErrorResponse(&c.Controller, "Source", jobs, err)
 		return
 	}
+	applyScheduleOperations(ctx, c.tempClient, ops...)
+	emitDeleteModeEvents(ctx, c.tempClient, mode, jobs)
 
-	// Deactivate all jobs using this source
-	for _, job := range jobs {
-		job.Active = false
-		if err := c.jobORM.Update(job); err != nil {
-			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to deactivate jobs using this source")
-			return
-		}
+	resp := &models.DeleteSourceResponse{Name: source.Name}
+	if len(jobs) > 0 {
+		resp.Mode, resp.Jobs = mode, jobNames(jobs)
 	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
 
-	// Delete the source
-	if err := c.sourceORM.Delete(id); err != nil {
-		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to delete source")
+// @router /project/:projectid/sources/:id/restore [post]
+func (c *SourceHandler) RestoreSource() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
 		return
 	}
-
-	utils.SuccessResponse(&c.Controller, &models.DeleteSourceResponse{
-		Name: source.Name,
-	})
+	// jobs deleted with the source come back inactive with paused schedules
+	jobs, ops, err := c.sourceORM.Restore(c.Ctx.Input.Param(":projectid"), id, string(temporal.ActionCreate))
+	if err != nil {
+		restoreErrorResponse(&c.Controller, "Source", err)
+		return
+	}
+	applyScheduleOperations(c.Ctx.Request.Context(), c.tempClient, ops...)
+	source, err := c.sourceORM.GetByID(id)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get restored source: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(source.Name, jobs))
 }
 
 // @router /project/:projectid/sources/test [post]
@@ -197,7 +226,7 @@ func (c *SourceHandler) TestConnection() {
 		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to encrypt config")
 		return
 	}
-	result, err := c.tempClient.TestConnection(context.Background(), "config", req.Type, req.Version, encryptedConfig)
+	result, err := c.tempClient.TestConnection(c.Ctx.Request.Context(), "config", req.Type, req.Version, encryptedConfig)
 	if result == nil {
 		result = map[string]interface{}{
 			"message": err.Error(),
@@ -247,7 +276,7 @@ func (c *SourceHandler) GetSourceCatalog() {
 	utils.SuccessResponse(&c.Controller, newStreams)
 }
 
-// @router /sources/:id/jobs [get]
+// @router /project/:projectid/sources/:id/jobs [get]
 func (c *SourceHandler) GetSourceJobs() {
 	id := GetIDFromPath(&c.Controller)
 	// Check if source exists
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// @router /project/:projectid/trash [get]
+func (c *ProjectHandler) GetTrash() {
+	items, err := c.trashORM.GetAllByProjectID(c.Ctx.Input.Param(":projectid"))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get trash: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, items)
+}
+
+// restoreErrorResponse responds to a failed restore of a kind of row, e.g. "Source"
+func restoreErrorResponse(c *web.Controller, kind string, err error) {
+	switch {
+	case errors.Is(err, database.ErrNotInTrash):
+		utils.ErrorResponse(c, http.StatusNotFound, fmt.Sprintf("%s not found in trash", kind))
+	case errors.Is(err, database.ErrConnectorInTrash):
+		utils.ErrorResponse(c, http.StatusConflict, "Restore the source and destination of the job first")
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("Failed to restore %s: %s", strings.ToLower(kind), err))
+	}
+}
+
+// deleteMode reads the mode of deleting a source or destination from the
+// request, detach by default, responding when it is not one of constants.DeleteModes
+func deleteMode(c *web.Controller) (string, bool) {
+	mode := c.GetString("mode", constants.DeleteModeDetach)
+	if !slices.Contains(constants.DeleteModes, mode) {
+		utils.ErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("Invalid mode %q, use one of %s", mode, strings.Join(constants.DeleteModes, ", ")))
+		return "", false
+	}
+	return mode, true
+}
+
+// deleteErrorResponse responds to a failed delete of a kind of connector, e.g. "Source"
+func deleteErrorResponse(c *web.Controller, kind string, jobs []*models.Job, err error) {
+	if errors.Is(err, database.ErrConnectorInUse) {
+		utils.ErrorResponse(c, http.StatusConflict, fmt.Sprintf("%s is used by jobs %s, delete them first or use mode %s or %s",
+			kind, strings.Join(jobNames(jobs), ", "), constants.DeleteModeCascade, constants.DeleteModeDetach))
+		return
+	}
+	utils.ErrorResponse(c, http.StatusInternalServerError, fmt.Sprintf("Failed to delete %s, nothing was changed: %s", strings.ToLower(kind), err))
+}
+
+// emitDeleteModeEvents reports the jobs of a deleted connector as deleted with
+// cascade and as updated, now inactive, with detach
+func emitDeleteModeEvents(ctx context.Context, tempClient *temporal.Client, mode string, jobs []*models.Job) {
+	event := constants.WebhookEventJobUpdated
+	if mode == constants.DeleteModeCascade {
+		event = constants.WebhookEventJobDeleted
+	}
+	for _, job := range jobs {
+		job.Active = false
+		emitJobEvent(ctx, tempClient, event, job)
+	}
+}
+
+func jobNames(jobs []*models.Job) []string {
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+	return names
+}
+
+func restoreResponse(name string, jobs []*models.Job) models.RestoreResponse {
+	resp := models.RestoreResponse{Name: name}
+	if len(jobs) > 0 {
+		resp.Jobs = jobNames(jobs)
+	}
+	return resp
+}
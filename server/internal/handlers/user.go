@@ -101,3 +101,21 @@ func (c *UserHandler) DeleteUser() {
 
 	c.Ctx.ResponseWriter.WriteHeader(http.StatusNoContent)
 }
+
+// @router /users/:id/restore [post]
+func (c *UserHandler) RestoreUser() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	if err := c.userORM.Restore(id); err != nil {
+		restoreErrorResponse(&c.Controller, "User", err)
+		return
+	}
+	user, err := c.userORM.GetByID(id)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to get restored user: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(user.Username, nil))
+}
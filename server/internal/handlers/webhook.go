@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/temporal"
+	"github.com/datazip/olake-frontend/server/internal/webhooks"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// maxWebhookDeliveries caps the limit of a delivery log request
+const maxWebhookDeliveries = 500
+
+// @router /project/:projectid/webhooks [get]
+func (c *ProjectHandler) GetWebhooks() {
+	hooks, err := c.webhookORM.GetAllByProjectID(c.Ctx.Input.Param(":projectid"))
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to retrieve webhooks")
+		return
+	}
+	resp := make([]models.WebhookResponse, 0, len(hooks))
+	for _, webhook := range hooks {
+		resp = append(resp, buildWebhookResponse(webhook, false))
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/webhooks [post]
+func (c *ProjectHandler) CreateWebhook() {
+	var req models.WebhookRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	webhook := &models.Webhook{ProjectID: c.Ctx.Input.Param(":projectid"), Active: true}
+	if req.Secret == "" {
+		secret, err := webhooks.GenerateSecret()
+		if err != nil {
+			utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+			return
+		}
+		req.Secret = secret
+	}
+	if err := setWebhook(webhook, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		user := &models.User{ID: userID.(int)}
+		webhook.CreatedBy = user
+		webhook.UpdatedBy = user
+	}
+
+	if err := c.webhookORM.Create(webhook); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to create webhook: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildWebhookResponse(webhook, true))
+}
+
+// @router /project/:projectid/webhooks/:id [put]
+func (c *ProjectHandler) UpdateWebhook() {
+	var req models.WebhookRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	webhook, ok := c.getProjectWebhook(GetIDFromPath(&c.Controller))
+	if !ok {
+		return
+	}
+	rotated := req.Secret != ""
+	if !rotated {
+		req.Secret = webhook.Secret
+	}
+	if err := setWebhook(webhook, &req); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, err.Error())
+		return
+	}
+	if userID := c.GetSession(constants.SessionUserID); userID != nil {
+		webhook.UpdatedBy = &models.User{ID: userID.(int)}
+	}
+
+	if err := c.webhookORM.Update(webhook); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, fmt.Sprintf("Failed to update webhook: %s", err))
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildWebhookResponse(webhook, rotated))
+}
+
+// @router /project/:projectid/webhooks/:id [delete]
+func (c *ProjectHandler) DeleteWebhook() {
+	webhook, ok := c.getProjectWebhook(GetIDFromPath(&c.Controller))
+	if !ok {
+		return
+	}
+	// the delivery log of the webhook is kept until the webhook is purged from the trash
+	if err := c.webhookORM.Delete(webhook.ID); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, "Failed to delete webhook")
+		return
+	}
+	utils.SuccessResponse(&c.Controller, map[string]interface{}{
+		"name": webhook.Name,
+	})
+}
+
+// @router /project/:projectid/webhooks/:id/restore [post]
+func (c *ProjectHandler) RestoreWebhook() {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return
+	}
+	if err := c.webhookORM.Restore(c.Ctx.Input.Param(":projectid"), id); err != nil {
+		restoreErrorResponse(&c.Controller, "Webhook", err)
+		return
+	}
+	webhook, ok := c.getProjectWebhook(id)
+	if !ok {
+		return
+	}
+	utils.SuccessResponse(&c.Controller, restoreResponse(webhook.Name, nil))
+}
+
+// @router /project/:projectid/webhook-deliveries [get]
+func (c *ProjectHandler) GetWebhookDeliveries() {
+	filter := database.WebhookDeliveryFilter{
+		Status: c.GetString("status"),
+		Event:  c.GetString("event"),
+	}
+	var err error
+	if filter.Limit, err = c.GetInt("limit", 50); err != nil || filter.Limit < 1 || filter.Limit > maxWebhookDeliveries {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, fmt.Sprintf("Invalid limit value, must be between 1 and %d", maxWebhookDeliveries))
+		return
+	}
+	if filter.WebhookID, err = c.GetInt("webhook_id", 0); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusBadRequest, "Invalid webhook_id value")
+		return
+	}
+	deliveries, err := c.deliveryORM.GetAllByProjectID(c.Ctx.Input.Param(":projectid"), filter)
+	if err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := make([]models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, buildWebhookDeliveryResponse(delivery, false))
+	}
+	utils.SuccessResponse(&c.Controller, resp)
+}
+
+// @router /project/:projectid/webhook-deliveries/:id [get]
+func (c *ProjectHandler) GetWebhookDelivery() {
+	delivery, ok := c.getProjectWebhookDelivery()
+	if !ok {
+		return
+	}
+	utils.SuccessResponse(&c.Controller, buildWebhookDeliveryResponse(delivery, true))
+}
+
+// @router /project/:projectid/webhook-deliveries/:id/replay [post]
+func (c *ProjectHandler) ReplayWebhookDelivery() {
+	delivery, ok := c.getProjectWebhookDelivery()
+	if !ok {
+		return
+	}
+	if delivery.Status == constants.WebhookDeliveryPending {
+		utils.ErrorResponse(&c.Controller, http.StatusConflict, "The delivery is still being attempted")
+		return
+	}
+	if _, ok := c.getProjectWebhook(delivery.WebhookID.ID); !ok {
+		return
+	}
+	if c.tempClient == nil {
+		utils.ErrorResponse(&c.Controller, http.StatusServiceUnavailable, "Cannot replay the delivery, Temporal is unavailable")
+		return
+	}
+
+	if err := c.deliveryORM.UpdateStatus(delivery.ID, constants.WebhookDeliveryPending); err != nil {
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := c.tempClient.ReplayWebhookDelivery(c.Ctx.Request.Context(), delivery.ID); err != nil {
+		if err := c.deliveryORM.UpdateStatus(delivery.ID, delivery.Status); err != nil {
+			logs.Error("Failed to restore status of webhook delivery %d: %s", delivery.ID, err)
+		}
+		utils.ErrorResponse(&c.Controller, http.StatusInternalServerError, err.Error())
+		return
+	}
+	delivery.Status = constants.WebhookDeliveryPending
+	utils.SuccessResponse(&c.Controller, buildWebhookDeliveryResponse(delivery, false))
+}
+
+// getProjectWebhook loads a webhook of the project, responding with 404 if it is not in the project
+func (c *ProjectHandler) getProjectWebhook(id int) (*models.Webhook, bool) {
+	if id == 0 {
+		return nil, false
+	}
+	webhook, err := c.webhookORM.GetByID(id)
+	if err != nil || webhook.ProjectID != c.Ctx.Input.Param(":projectid") {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Webhook not found")
+		return nil, false
+	}
+	return webhook, true
+}
+
+// getProjectWebhookDelivery loads the delivery of the request path, responding with 404 if it is not in the project
+func (c *ProjectHandler) getProjectWebhookDelivery() (*models.WebhookDelivery, bool) {
+	id := GetIDFromPath(&c.Controller)
+	if id == 0 {
+		return nil, false
+	}
+	delivery, err := c.deliveryORM.GetByID(id)
+	if err != nil || delivery.ProjectID != c.Ctx.Input.Param(":projectid") {
+		utils.ErrorResponse(&c.Controller, http.StatusNotFound, "Webhook delivery not found")
+		return nil, false
+	}
+	return delivery, true
+}
+
+// setWebhook validates a webhook request and stores it on the webhook
+func setWebhook(webhook *models.Webhook, req *models.WebhookRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := webhooks.ValidateURL(req.URL); err != nil {
+		return err
+	}
+	if len(req.Events) == 0 {
+		return fmt.Errorf("events must list at least one event")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(constants.WebhookEvents, event) {
+			return fmt.Errorf("unknown event %q, must be one of %v", event, constants.WebhookEvents)
+		}
+	}
+	events, err := json.Marshal(req.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %s", err)
+	}
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.Events = string(events)
+	webhook.Secret = req.Secret
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	return nil
+}
+
+func buildWebhookResponse(webhook *models.Webhook, withSecret bool) models.WebhookResponse {
+	resp := models.WebhookResponse{
+		ID:        webhook.ID,
+		Name:      webhook.Name,
+		URL:       webhook.URL,
+		Events:    []string{},
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: webhook.UpdatedAt.Format(time.RFC3339),
+	}
+	if withSecret {
+		resp.Secret = webhook.Secret
+	}
+	if err := json.Unmarshal([]byte(webhook.Events), &resp.Events); err != nil {
+		logs.Error("Failed to decode events of webhook[%d]: %s", webhook.ID, err)
+	}
+	setUsernames(&resp.CreatedBy, &resp.UpdatedBy, webhook.CreatedBy, webhook.UpdatedBy)
+	return resp
+}
+
+func buildWebhookDeliveryResponse(delivery *models.WebhookDelivery, withPayload bool) models.WebhookDeliveryResponse {
+	resp := models.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID.ID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if delivery.LastAttemptAt != nil {
+		resp.LastAttemptAt = delivery.LastAttemptAt.Format(time.RFC3339)
+	}
+	if delivery.DeliveredAt != nil {
+		resp.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+	if withPayload {
+		if err := json.Unmarshal([]byte(delivery.Payload), &resp.Payload); err != nil {
+			logs.Error("Failed to decode payload of webhook delivery[%d]: %s", delivery.ID, err)
+		}
+	}
+	return resp
+}
+
+// emitJobEvent sends an event of a job to the webhooks of its project, the
+// request does not fail when the event cannot be sent
+func emitJobEvent(ctx context.Context, tempClient *temporal.Client, eventType string, job *models.Job, streams ...string) {
+	if tempClient == nil {
+		logs.Error("Cannot emit webhook event %s of job %d, Temporal is unavailable", eventType, job.ID)
+		return
+	}
+	event := webhooks.NewEvent(utils.ULID(), eventType, job, time.Now())
+	event.Data.Streams = streams
+	if err := tempClient.EmitWebhookEvent(ctx, event); err != nil {
+		logs.Error("Failed to emit webhook event %s of job %d: %s", eventType, job.ID, err)
+	}
+}
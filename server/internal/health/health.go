@@ -0,0 +1,139 @@
+// Package health runs the readiness checks of the server and the worker. Both
+// serve a /healthz liveness probe, which only tells that the process answers,
+// and a /readyz readiness probe running the checks.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// CheckTimeout bounds a single check, so a hanging dependency fails the probe instead of timing it out
+const CheckTimeout = 5 * time.Second
+
+// Check is a named readiness check
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Run runs checks in parallel and reports ready when all of them pass
+func Run(ctx context.Context, checks ...Check) models.ReadinessResponse {
+	report := models.ReadinessResponse{Ready: true, Checks: make([]models.HealthCheck, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+			defer cancel()
+			start := time.Now()
+			err := check.Run(checkCtx)
+			result := models.HealthCheck{Name: check.Name, OK: err == nil, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			report.Checks[i] = result
+		}()
+	}
+	wg.Wait()
+	for _, result := range report.Checks {
+		report.Ready = report.Ready && result.OK
+	}
+	return report
+}
+
+// Database checks that Postgres answers a query on the connection pool of the ORM
+func Database() Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		db, err := orm.GetDB("default")
+		if err != nil {
+			return fmt.Errorf("database is not initialized: %s", err)
+		}
+		if _, err := db.ExecContext(ctx, "SELECT 1"); err != nil {
+			return fmt.Errorf("database is unreachable: %s", err)
+		}
+		return nil
+	}}
+}
+
+// ConfigDir checks that files can be created in the directory the connector configs are written to
+func ConfigDir(dir string) Check {
+	return Check{Name: "config_dir", Run: func(_ context.Context) error {
+		file, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return fmt.Errorf("config dir %s is not writable: %s", dir, err)
+		}
+		defer os.Remove(file.Name())
+		if _, err := file.WriteString("ok"); err != nil {
+			file.Close()
+			return fmt.Errorf("config dir %s is not writable: %s", dir, err)
+		}
+		return file.Close()
+	}}
+}
+
+// EncryptionKey checks that secrets encrypted with the configured key decrypt
+// again, which fails with a malformed key or a KMS key the process may not use
+func EncryptionKey() Check {
+	return Check{Name: "encryption_key", Run: func(_ context.Context) error {
+		const probe = "olake-readiness-probe"
+		encrypted, err := utils.Encrypt(probe)
+		if err != nil {
+			return err
+		}
+		decrypted, err := utils.Decrypt(encrypted)
+		if err != nil {
+			return err
+		}
+		if decrypted != probe {
+			return fmt.Errorf("encryption key does not decrypt what it encrypts")
+		}
+		return nil
+	}}
+}
+
+// Docker checks that the Docker daemon running the connectors is reachable
+func Docker() Check {
+	return Check{Name: "docker", Run: func(ctx context.Context) error {
+		_, err := docker.ServerVersion(ctx)
+		return err
+	}}
+}
+
+// LivenessHandler answers every request with success, for processes serving
+// plain net/http like the worker
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, models.JSONResponse{Success: true, Message: "success", Data: map[string]string{"status": "ok"}})
+	})
+}
+
+// ReadinessHandler runs checks on every request and answers 503 when one of them fails
+func ReadinessHandler(checks ...Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := Run(r.Context(), checks...)
+		if !report.Ready {
+			writeJSON(w, http.StatusServiceUnavailable, models.JSONResponse{Success: false, Message: "Not ready", Data: report})
+			return
+		}
+		writeJSON(w, http.StatusOK, models.JSONResponse{Success: true, Message: "success", Data: report})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body models.JSONResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	pass := Check{Name: "pass", Run: func(context.Context) error { return nil }}
+	fail := Check{Name: "fail", Run: func(context.Context) error { return errors.New("unreachable") }}
+	hang := Check{Name: "hang", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	cases := []struct {
+		name   string
+		checks []Check
+		status int
+		failed []string
+	}{
+		{"all pass", []Check{pass, ConfigDir(t.TempDir())}, http.StatusOK, nil},
+		{"one fails", []Check{pass, fail}, http.StatusServiceUnavailable, []string{"fail"}},
+		{"missing config dir", []Check{ConfigDir(filepath.Join(t.TempDir(), "missing"))}, http.StatusServiceUnavailable, []string{"config_dir"}},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		ReadinessHandler(tc.checks...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, rec.Code, tc.status)
+		}
+		var body struct {
+			Data models.ReadinessResponse `json:"data"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		var failed []string
+		for _, check := range body.Data.Checks {
+			if !check.OK {
+				failed = append(failed, check.Name)
+			}
+		}
+		if len(failed) != len(tc.failed) || (len(failed) > 0 && failed[0] != tc.failed[0]) {
+			t.Errorf("%s: failed checks %v, want %v", tc.name, failed, tc.failed)
+		}
+	}
+
+	// a hanging check ends with the request of the probe
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if report := Run(ctx, hang); report.Ready || report.Checks[0].Error == "" {
+		t.Errorf("hanging check reported %+v", report)
+	}
+}
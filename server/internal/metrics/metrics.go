@@ -0,0 +1,119 @@
+// Package metrics holds the Prometheus metrics of the server and the worker,
+// both serve them on /metrics behind the metricstoken bearer token.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "olake"
+
+// Sync run statuses
+const (
+	SyncCompleted = "completed"
+	SyncFailed    = "failed"
+	SyncCanceled  = "canceled"
+)
+
+// syncBuckets cover syncs from seconds to a day
+var syncBuckets = prometheus.ExponentialBuckets(5, 3, 10)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	TemporalClientErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "temporal_client_errors_total",
+		Help:      "Failed Temporal client calls by method and gRPC code.",
+	}, []string{"method", "code"})
+
+	SyncRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_runs_total",
+		Help:      "Finished sync runs by job, kind and status.",
+	}, []string{"job_id", "kind", "status"})
+
+	SyncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of sync runs by job, kind and status.",
+		Buckets:   syncBuckets,
+	}, []string{"job_id", "kind", "status"})
+
+	SyncRecords = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_records_total",
+		Help:      "Records synced by job, as reported by the connector.",
+	}, []string{"job_id"})
+
+	ActiveSyncs = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_syncs",
+		Help:      "Sync runs in progress on this worker.",
+	})
+
+	DockerCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "docker_command_duration_seconds",
+		Help:      "Duration of connector Docker commands by command.",
+		Buckets:   syncBuckets,
+	}, []string{"command"})
+
+	DockerCommandExits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "docker_command_exits_total",
+		Help:      "Connector Docker commands by command and exit code, -1 when the command did not exit on its own.",
+	}, []string{"command", "exit_code"})
+
+	EncryptionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "encryption_duration_seconds",
+		Help:      "Latency of encrypting and decrypting secrets by operation and backend (aes or kms).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "backend"})
+)
+
+// Since returns the seconds elapsed since start, for observing durations
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// JobLabel returns the job_id label value of a job
+func JobLabel(jobID int) string {
+	return strconv.Itoa(jobID)
+}
+
+// Handler serves the metrics, only to requests carrying token as a bearer token when it is set
+func Handler(token string) http.Handler {
+	handler := promhttp.Handler()
+	if token == "" {
+		return handler
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
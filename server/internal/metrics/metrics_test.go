@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	cases := []struct {
+		token  string
+		header string
+		want   int
+	}{
+		{"", "", http.StatusOK},
+		{"s3cret", "Bearer s3cret", http.StatusOK},
+		{"s3cret", "", http.StatusUnauthorized},
+		{"s3cret", "Bearer other", http.StatusUnauthorized},
+		{"s3cret", "s3cret", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		Handler(tc.token).ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("token %q with Authorization %q = %d, want %d", tc.token, tc.header, rec.Code, tc.want)
+		}
+	}
+}
@@ -37,6 +37,8 @@ type Source struct {
 	CreatedBy *User  `json:"created_by" orm:"rel(fk)"`
 	UpdatedBy *User  `json:"updated_by" orm:"rel(fk)"`
 	Type      string `json:"type"`
+	// MaxConcurrentSyncs caps the syncs reading from the source at once, 0 means unlimited
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs" orm:"default(0)"`
 }
 
 func (s *Source) TableName() string {
@@ -54,6 +56,8 @@ type Destination struct {
 	Config    string `json:"config" orm:"type(jsonb)"`
 	CreatedBy *User  `json:"created_by" orm:"rel(fk)"`
 	UpdatedBy *User  `json:"updated_by" orm:"rel(fk)"`
+	// MaxConcurrentSyncs caps the syncs writing to the destination at once, 0 means unlimited
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs" orm:"default(0)"`
 }
 
 func (d *Destination) TableName() string {
@@ -62,24 +66,226 @@ func (d *Destination) TableName() string {
 
 // Job represents a synchronization job
 type Job struct {
-	BaseModel     `orm:"embedded"`
-	ID            int          `json:"id" orm:"column(id);pk;auto"`
-	Name          string       `json:"name" orm:"size(100)"`
-	SourceID      *Source      `json:"source_id" orm:"column(source_id);rel(fk)"`
-	DestID        *Destination `json:"dest_id" orm:"column(dest_id);rel(fk)"`
-	Active        bool         `json:"active"`
-	Frequency     string       `json:"frequency"`
-	StreamsConfig string       `json:"streams_config" orm:"type(jsonb)"`
-	State         string       `json:"state" orm:"type(jsonb)"`
-	CreatedBy     *User        `json:"created_by" orm:"rel(fk)"`
-	UpdatedBy     *User        `json:"updated_by" orm:"rel(fk)"`
-	ProjectID     string       `json:"project_id" orm:"column(project_id)"`
+	BaseModel      `orm:"embedded"`
+	ID             int          `json:"id" orm:"column(id);pk;auto"`
+	Name           string       `json:"name" orm:"size(100)"`
+	SourceID       *Source      `json:"source_id" orm:"column(source_id);rel(fk)"`
+	DestID         *Destination `json:"dest_id" orm:"column(dest_id);rel(fk)"`
+	Active         bool         `json:"active"`
+	Frequency      string       `json:"frequency"`
+	StreamsConfig  string       `json:"streams_config" orm:"type(jsonb)"`
+	ScheduleConfig string       `json:"schedule_config" orm:"type(jsonb);null"`
+	RetryPolicy    string       `json:"retry_policy" orm:"type(jsonb);null"`
+	State          string       `json:"state" orm:"type(jsonb)"`
+	CreatedBy      *User        `json:"created_by" orm:"rel(fk)"`
+	UpdatedBy      *User        `json:"updated_by" orm:"rel(fk)"`
+	ProjectID      string       `json:"project_id" orm:"column(project_id)"`
 }
 
 func (j *Job) TableName() string {
 	return constants.TableNameMap[constants.JobTable]
 }
 
+// ProjectSettings holds project wide settings, projects themselves are not stored
+type ProjectSettings struct {
+	BaseModel       `orm:"embedded"`
+	ID              int    `json:"id" orm:"column(id);pk;auto"`
+	ProjectID       string `json:"project_id" orm:"column(project_id);unique"`
+	BlackoutWindows string `json:"blackout_windows" orm:"type(jsonb);null"`
+	// MaxConcurrentSyncs caps the syncs of the project running at once, 0 means unlimited
+	MaxConcurrentSyncs int   `json:"max_concurrent_syncs" orm:"default(0)"`
+	UpdatedBy          *User `json:"updated_by" orm:"rel(fk);null"`
+}
+
+func (p *ProjectSettings) TableName() string {
+	return constants.TableNameMap[constants.ProjectSettingsTable]
+}
+
+// JobDependency makes a job depend on an upstream job of the same project
+type JobDependency struct {
+	BaseModel     `orm:"embedded"`
+	ID            int    `json:"id" orm:"column(id);pk;auto"`
+	ProjectID     string `json:"project_id" orm:"column(project_id)"`
+	JobID         *Job   `json:"job_id" orm:"column(job_id);rel(fk)"`
+	UpstreamJobID *Job   `json:"upstream_job_id" orm:"column(upstream_job_id);rel(fk)"`
+	// Mode is either constants.DependencyModeTrigger or constants.DependencyModeWait
+	Mode string `json:"mode" orm:"size(20)"`
+}
+
+func (d *JobDependency) TableName() string {
+	return constants.TableNameMap[constants.JobDependencyTable]
+}
+
+func (d *JobDependency) TableUnique() [][]string {
+	return [][]string{{"JobID", "UpstreamJobID"}}
+}
+
+// JobStateHistory is a revision of the state of a job
+type JobStateHistory struct {
+	BaseModel `orm:"embedded"`
+	ID        int    `json:"id" orm:"column(id);pk;auto"`
+	JobID     *Job   `json:"job_id" orm:"column(job_id);rel(fk)"`
+	Revision  int    `json:"revision"`
+	State     string `json:"state" orm:"type(jsonb)"`
+	// Reason is one of the constants.StateChange values
+	Reason string `json:"reason" orm:"size(20)"`
+	// Streams lists the streams cleared by a stream reset
+	Streams string `json:"streams" orm:"type(jsonb);null"`
+	// WorkflowID is the sync run that produced the state
+	WorkflowID string `json:"workflow_id" orm:"column(workflow_id);size(255);null"`
+	// RestoredFrom is the revision a restored state was copied from
+	RestoredFrom int   `json:"restored_from" orm:"default(0)"`
+	CreatedBy    *User `json:"created_by" orm:"rel(fk);null"`
+	// BaseRevision is the latest revision when the state was read, it is not stored
+	BaseRevision int `json:"-" orm:"-"`
+}
+
+func (h *JobStateHistory) TableName() string {
+	return constants.TableNameMap[constants.JobStateHistoryTable]
+}
+
+func (h *JobStateHistory) TableUnique() [][]string {
+	return [][]string{{"JobID", "Revision"}}
+}
+
+// JobTemplate is a job definition with {{variable}} placeholders that jobs are created from
+type JobTemplate struct {
+	BaseModel `orm:"embedded"`
+	ID        int    `json:"id" orm:"column(id);pk;auto"`
+	Name      string `json:"name" orm:"size(100)"`
+	ProjectID string `json:"project_id" orm:"column(project_id)"`
+	// Spec is a create job request, encrypted as it holds connector configs
+	Spec string `json:"spec" orm:"type(jsonb)"`
+	// Variables lists the placeholders used in Spec
+	Variables string `json:"variables" orm:"type(jsonb)"`
+	CreatedBy *User  `json:"created_by" orm:"rel(fk);null"`
+	UpdatedBy *User  `json:"updated_by" orm:"rel(fk);null"`
+}
+
+func (t *JobTemplate) TableName() string {
+	return constants.TableNameMap[constants.JobTemplateTable]
+}
+
+// NotificationChannel is a webhook, Slack webhook or email destination of the notifications of a project
+type NotificationChannel struct {
+	BaseModel `orm:"embedded"`
+	ID        int    `json:"id" orm:"column(id);pk;auto"`
+	Name      string `json:"name" orm:"size(100)"`
+	ProjectID string `json:"project_id" orm:"column(project_id)"`
+	// Type is one of the constants.NotificationChannel values
+	Type string `json:"type" orm:"size(20)"`
+	// Config is an encrypted NotificationChannelConfig as it may hold credentials
+	Config    string `json:"config" orm:"type(jsonb)"`
+	CreatedBy *User  `json:"created_by" orm:"rel(fk);null"`
+	UpdatedBy *User  `json:"updated_by" orm:"rel(fk);null"`
+}
+
+func (n *NotificationChannel) TableName() string {
+	return constants.TableNameMap[constants.NotificationChannelTable]
+}
+
+// JobNotification subscribes a notification channel to events of a job, it
+// also tracks what was notified so that a condition is only reported once
+type JobNotification struct {
+	BaseModel `orm:"embedded"`
+	ID        int                  `json:"id" orm:"column(id);pk;auto"`
+	ProjectID string               `json:"project_id" orm:"column(project_id)"`
+	JobID     *Job                 `json:"job_id" orm:"column(job_id);rel(fk)"`
+	ChannelID *NotificationChannel `json:"channel_id" orm:"column(channel_id);rel(fk)"`
+	// Events lists constants.NotificationEvent values
+	Events string `json:"events" orm:"type(jsonb)"`
+	// RunLongerThan is the duration after which a running sync is reported as long_run
+	RunLongerThan string `json:"run_longer_than" orm:"size(20);null"`
+	// NoSuccessWithin is the duration without a successful sync reported as no_success
+	NoSuccessWithin string `json:"no_success_within" orm:"size(20);null"`
+	// LastStatus is the status of the last finished sync, for reporting a recovery
+	LastStatus    string     `json:"last_status" orm:"size(20);null"`
+	LastSuccessAt *time.Time `json:"last_success_at" orm:"type(datetime);null"`
+	// LongRunWorkflowID is the last sync reported as long_run
+	LongRunWorkflowID string `json:"long_run_workflow_id" orm:"column(long_run_workflow_id);size(255);null"`
+	// NoSuccessNotifiedAt is set while a missing successful sync is reported
+	NoSuccessNotifiedAt *time.Time `json:"no_success_notified_at" orm:"type(datetime);null"`
+	// SchemaDrift is the fingerprint of the last reported schema drift
+	SchemaDrift string `json:"schema_drift" orm:"size(64);null"`
+}
+
+func (n *JobNotification) TableName() string {
+	return constants.TableNameMap[constants.JobNotificationTable]
+}
+
+func (n *JobNotification) TableUnique() [][]string {
+	return [][]string{{"JobID", "ChannelID"}}
+}
+
+// Webhook posts signed job and sync events of a project to a URL
+type Webhook struct {
+	BaseModel `orm:"embedded"`
+	ID        int    `json:"id" orm:"column(id);pk;auto"`
+	Name      string `json:"name" orm:"size(100)"`
+	ProjectID string `json:"project_id" orm:"column(project_id)"`
+	URL       string `json:"url" orm:"column(url);type(text)"`
+	// Secret signs the payloads, it is stored encrypted
+	Secret string `json:"secret" orm:"type(text)"`
+	// Events lists constants.WebhookEvent values
+	Events    string `json:"events" orm:"type(jsonb)"`
+	Active    bool   `json:"active"`
+	CreatedBy *User  `json:"created_by" orm:"rel(fk);null"`
+	UpdatedBy *User  `json:"updated_by" orm:"rel(fk);null"`
+}
+
+func (w *Webhook) TableName() string {
+	return constants.TableNameMap[constants.WebhookTable]
+}
+
+// WebhookDelivery is the delivery of an event to a webhook, it is kept as the
+// delivery log and replays send the same payload again
+type WebhookDelivery struct {
+	BaseModel `orm:"embedded"`
+	ID        int      `json:"id" orm:"column(id);pk;auto"`
+	ProjectID string   `json:"project_id" orm:"column(project_id)"`
+	WebhookID *Webhook `json:"webhook_id" orm:"column(webhook_id);rel(fk)"`
+	EventID   string   `json:"event_id" orm:"column(event_id);size(255)"`
+	Event     string   `json:"event" orm:"size(50)"`
+	Payload   string   `json:"payload" orm:"type(jsonb)"`
+	// Status is one of the constants.WebhookDelivery values
+	Status   string `json:"status" orm:"size(20)"`
+	Attempts int    `json:"attempts" orm:"default(0)"`
+	// ResponseStatus is the HTTP status of the last attempt, 0 if there was no response
+	ResponseStatus int        `json:"response_status" orm:"default(0)"`
+	LastError      string     `json:"last_error" orm:"type(text);null"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at" orm:"type(datetime);null"`
+	DeliveredAt    *time.Time `json:"delivered_at" orm:"type(datetime);null"`
+}
+
+func (d *WebhookDelivery) TableName() string {
+	return constants.TableNameMap[constants.WebhookDeliveryTable]
+}
+
+// TableUnique makes recording the deliveries of an event idempotent
+func (d *WebhookDelivery) TableUnique() [][]string {
+	return [][]string{{"WebhookID", "EventID"}}
+}
+
+// ScheduleOperation is a change of the Temporal schedule of a job, recorded in
+// the schedule outbox in the transaction that changes the job
+type ScheduleOperation struct {
+	BaseModel `orm:"embedded"`
+	ID        int    `json:"id" orm:"column(id);pk;auto"`
+	ProjectID string `json:"project_id" orm:"column(project_id)"`
+	// JobID is no foreign key, the operation deleting the schedule of a job outlives it
+	JobID  int    `json:"job_id" orm:"column(job_id)"`
+	Action string `json:"action" orm:"size(20)"`
+	// Status is one of the constants.ScheduleOperation values
+	Status      string     `json:"status" orm:"size(20)"`
+	Attempts    int        `json:"attempts" orm:"default(0)"`
+	LastError   string     `json:"last_error" orm:"type(text);null"`
+	ProcessedAt *time.Time `json:"processed_at" orm:"type(datetime);null"`
+}
+
+func (o *ScheduleOperation) TableName() string {
+	return constants.TableNameMap[constants.ScheduleOperationTable]
+}
+
 type Catalog struct {
 	BaseModel `orm:"embedded"`
 	ID        int    `json:"id" orm:"column(id);pk;auto"`
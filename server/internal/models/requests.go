@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Common fields for source/destination config
 type ConnectorConfig struct {
 	Name    string `json:"name"`
@@ -38,18 +40,22 @@ type DestinationTestConnectionRequest struct {
 // Create/Update source and destination requests
 type CreateSourceRequest struct {
 	ConnectorConfig
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs,omitempty"`
 }
 
 type UpdateSourceRequest struct {
 	ConnectorConfig
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs,omitempty"`
 }
 
 type CreateDestinationRequest struct {
 	ConnectorConfig
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs,omitempty"`
 }
 
 type UpdateDestinationRequest struct {
 	ConnectorConfig
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs,omitempty"`
 }
 
 // Job source and destination configurations
@@ -58,19 +64,248 @@ type JobDestinationConfig = ConnectorConfig
 
 // Create and update job requests
 type CreateJobRequest struct {
-	Name          string               `json:"name"`
-	Source        JobSourceConfig      `json:"source"`
-	Destination   JobDestinationConfig `json:"destination"`
-	Frequency     string               `json:"frequency"`
-	StreamsConfig string               `json:"streams_config" orm:"type(jsonb)"`
-	Activate      bool                 `json:"activate,omitempty"`
+	Name           string               `json:"name"`
+	Source         JobSourceConfig      `json:"source"`
+	Destination    JobDestinationConfig `json:"destination"`
+	Frequency      string               `json:"frequency"`
+	ScheduleConfig *ScheduleConfig      `json:"schedule_config,omitempty"`
+	RetryPolicy    *RetryPolicy         `json:"retry_policy,omitempty"`
+	StreamsConfig  string               `json:"streams_config" orm:"type(jsonb)"`
+	Activate       bool                 `json:"activate,omitempty"`
 }
 
 type UpdateJobRequest struct {
-	Name          string               `json:"name"`
-	Source        JobSourceConfig      `json:"source"`
-	Destination   JobDestinationConfig `json:"destination"`
-	Frequency     string               `json:"frequency"`
-	StreamsConfig string               `json:"streams_config" orm:"type(jsonb)"`
-	Activate      bool                 `json:"activate,omitempty"`
+	Name           string               `json:"name"`
+	Source         JobSourceConfig      `json:"source"`
+	Destination    JobDestinationConfig `json:"destination"`
+	Frequency      string               `json:"frequency"`
+	ScheduleConfig *ScheduleConfig      `json:"schedule_config,omitempty"`
+	RetryPolicy    *RetryPolicy         `json:"retry_policy,omitempty"`
+	StreamsConfig  string               `json:"streams_config" orm:"type(jsonb)"`
+	Activate       bool                 `json:"activate,omitempty"`
+}
+
+// ScheduleConfig holds the advanced scheduling options of a job. Calendars and
+// intervals are added to the schedule derived from the job frequency.
+type ScheduleConfig struct {
+	TimeZone  string         `json:"time_zone,omitempty"`
+	Jitter    string         `json:"jitter,omitempty"`
+	StartAt   *time.Time     `json:"start_at,omitempty"`
+	EndAt     *time.Time     `json:"end_at,omitempty"`
+	Calendars []CalendarSpec `json:"calendars,omitempty"`
+	Intervals []IntervalSpec `json:"intervals,omitempty"`
+	// BlackoutWindows are skipped by the schedule in addition to the project windows
+	BlackoutWindows []BlackoutWindow `json:"blackout_windows,omitempty"`
+}
+
+// RetryPolicy controls how failed syncs of a job are retried, e.g.
+// {"maximum_attempts": 3, "initial_interval": "1m", "backoff_coefficient": 2, "non_retryable_errors": ["rate_limit"]}.
+// Authentication, configuration and image errors are never retried.
+type RetryPolicy struct {
+	MaximumAttempts    int      `json:"maximum_attempts,omitempty"`
+	InitialInterval    string   `json:"initial_interval,omitempty"`
+	BackoffCoefficient float64  `json:"backoff_coefficient,omitempty"`
+	MaximumInterval    string   `json:"maximum_interval,omitempty"`
+	NonRetryableErrors []string `json:"non_retryable_errors,omitempty"`
+}
+
+// CalendarSpec fields use cron syntax, e.g. {"minute": "30", "hour": "2", "day_of_week": "MON-FRI"}
+type CalendarSpec struct {
+	Minute     string `json:"minute,omitempty"`
+	Hour       string `json:"hour,omitempty"`
+	DayOfMonth string `json:"day_of_month,omitempty"`
+	Month      string `json:"month,omitempty"`
+	DayOfWeek  string `json:"day_of_week,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// IntervalSpec fires every fixed duration, e.g. {"every": "36h", "offset": "1h"}
+type IntervalSpec struct {
+	Every  string `json:"every"`
+	Offset string `json:"offset,omitempty"`
+}
+
+// BlackoutWindow is a weekly time range during which no sync may start,
+// e.g. {"days_of_week": "MON-FRI", "start_time": "09:00", "end_time": "18:00", "time_zone": "Europe/Berlin"}.
+// An end time before the start time wraps past midnight.
+type BlackoutWindow struct {
+	DaysOfWeek string `json:"days_of_week,omitempty"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	TimeZone   string `json:"time_zone,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// SchedulePreviewRequest validates a schedule and asks for its next fire times
+type SchedulePreviewRequest struct {
+	Frequency      string          `json:"frequency"`
+	ScheduleConfig *ScheduleConfig `json:"schedule_config,omitempty"`
+	Count          int             `json:"count,omitempty"`
+}
+
+// SyncPreviewRequest runs the streams of a job that is not created yet into a throwaway local sink
+type SyncPreviewRequest struct {
+	Source        JobSourceConfig `json:"source"`
+	StreamsConfig string          `json:"streams_config"`
+	// RowLimit stops reading a stream once this many rows were read
+	RowLimit   int `json:"row_limit,omitempty"`
+	SampleSize int `json:"sample_size,omitempty"`
+}
+
+type ProjectSettingsRequest struct {
+	BlackoutWindows    []BlackoutWindow `json:"blackout_windows"`
+	MaxConcurrentSyncs int              `json:"max_concurrent_syncs,omitempty"`
+}
+
+// JobDependencyConfig references another job of the project, e.g. {"job_id": 3, "mode": "trigger"}
+type JobDependencyConfig struct {
+	JobID int    `json:"job_id"`
+	Mode  string `json:"mode"`
+}
+
+// JobDependenciesRequest replaces the upstream dependencies of a job
+type JobDependenciesRequest struct {
+	Upstreams []JobDependencyConfig `json:"upstreams"`
+}
+
+// JobStateRequest replaces the state of a job
+type JobStateRequest struct {
+	State string `json:"state"`
+}
+
+// JobStateResetRequest clears the given streams of a job state, or all of it when empty
+type JobStateResetRequest struct {
+	Streams []string `json:"streams,omitempty"`
+}
+
+// BackfillRequest starts a one-off run of some streams of a job
+type BackfillRequest struct {
+	Streams   []string           `json:"streams"`
+	TimeRange *BackfillTimeRange `json:"time_range,omitempty"`
+}
+
+// BackfillTimeRange limits a backfill to the rows whose column is within [from, to), either bound may be omitted
+type BackfillTimeRange struct {
+	Column string `json:"column"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// JobCloneRequest copies a job, the fields that are set override those of the job
+type JobCloneRequest struct {
+	Name string `json:"name,omitempty"`
+	// Source and Destination override the set fields of the connectors of the job
+	Source         *JobSourceConfig      `json:"source,omitempty"`
+	Destination    *JobDestinationConfig `json:"destination,omitempty"`
+	Frequency      string                `json:"frequency,omitempty"`
+	ScheduleConfig *ScheduleConfig       `json:"schedule_config,omitempty"`
+	RetryPolicy    *RetryPolicy          `json:"retry_policy,omitempty"`
+	StreamsConfig  string                `json:"streams_config,omitempty"`
+}
+
+// JobTemplateRequest creates or replaces a job template, the string values of
+// Job, including the connector and streams configs, may hold {{variable}} placeholders
+type JobTemplateRequest struct {
+	Name string           `json:"name"`
+	Job  CreateJobRequest `json:"job"`
+}
+
+// JobTemplateInstantiateRequest creates a job from a template for each set of variable values
+type JobTemplateInstantiateRequest struct {
+	VariableSets []map[string]string `json:"variable_sets"`
+}
+
+// ProjectConfig is a declarative document of the sources, destinations and jobs
+// of a project, resources are identified by name
+type ProjectConfig struct {
+	Version      int                   `json:"version"`
+	Sources      []ConnectorDefinition `json:"sources"`
+	Destinations []ConnectorDefinition `json:"destinations"`
+	Jobs         []JobDefinition       `json:"jobs"`
+	// Settings are left as they are when not declared
+	Settings *ProjectSettingsDefinition `json:"settings,omitempty"`
+}
+
+// ProjectSettingsDefinition declares the project settings of a project config
+type ProjectSettingsDefinition struct {
+	BlackoutWindows    []BlackoutWindow `json:"blackout_windows"`
+	MaxConcurrentSyncs int              `json:"max_concurrent_syncs,omitempty"`
+}
+
+// ConnectorDefinition declares a source or destination of a project config
+type ConnectorDefinition struct {
+	Name               string                 `json:"name"`
+	Type               string                 `json:"type"`
+	Version            string                 `json:"version"`
+	MaxConcurrentSyncs int                    `json:"max_concurrent_syncs,omitempty"`
+	Config             map[string]interface{} `json:"config"`
+}
+
+// JobDefinition declares a job of a project config, its source and destination are given by name
+type JobDefinition struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// Active defaults to true
+	Active         *bool                  `json:"active,omitempty"`
+	Frequency      string                 `json:"frequency"`
+	ScheduleConfig *ScheduleConfig        `json:"schedule_config,omitempty"`
+	RetryPolicy    *RetryPolicy           `json:"retry_policy,omitempty"`
+	StreamsConfig  map[string]interface{} `json:"streams_config"`
+	// Upstreams replace the upstream dependencies of the job
+	Upstreams []JobUpstreamDefinition `json:"upstreams,omitempty"`
+}
+
+// JobUpstreamDefinition declares a dependency of a job on another job of the project config by name
+type JobUpstreamDefinition struct {
+	Job  string `json:"job"`
+	Mode string `json:"mode"`
+}
+
+// NotificationChannelRequest creates or replaces a notification channel of a project
+type NotificationChannelRequest struct {
+	Name string `json:"name"`
+	// Type is webhook, slack or email
+	Type   string                    `json:"type"`
+	Config NotificationChannelConfig `json:"config"`
+}
+
+// NotificationChannelConfig holds the settings of a channel by its type:
+// webhook {"url", "headers"}, slack {"url"} and email {"host", "port", "username", "password", "from", "to"}.
+// Email is sent with STARTTLS when the server offers it, or over TLS on port 465.
+type NotificationChannelConfig struct {
+	URL      string            `json:"url,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Host     string            `json:"host,omitempty"`
+	Port     int               `json:"port,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	From     string            `json:"from,omitempty"`
+	To       []string          `json:"to,omitempty"`
+}
+
+// JobNotificationConfig subscribes a channel to events of a job, e.g.
+// {"channel_id": 1, "events": ["failure", "recovery", "long_run"], "run_longer_than": "2h"}.
+// long_run needs run_longer_than and no_success needs no_success_within.
+type JobNotificationConfig struct {
+	ChannelID       int      `json:"channel_id"`
+	Events          []string `json:"events"`
+	RunLongerThan   string   `json:"run_longer_than,omitempty"`
+	NoSuccessWithin string   `json:"no_success_within,omitempty"`
+}
+
+// JobNotificationsRequest replaces the notification subscriptions of a job
+type JobNotificationsRequest struct {
+	Subscriptions []JobNotificationConfig `json:"subscriptions"`
+}
+
+// WebhookRequest creates or replaces a webhook of a project, a secret is
+// generated when it is created without one and kept when it is updated without one
+type WebhookRequest struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Active defaults to true
+	Active *bool  `json:"active,omitempty"`
+	Secret string `json:"secret,omitempty"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type LoginResponse struct {
 	Message string `json:"message"`
 	Success bool   `json:"success"`
@@ -26,10 +28,16 @@ type APIResponse[T any] struct {
 
 type DeleteSourceResponse struct {
 	Name string `json:"name"`
+	// Mode and Jobs tell how the jobs using it were handled
+	Mode string   `json:"mode,omitempty"`
+	Jobs []string `json:"jobs,omitempty"`
 }
 
 type DeleteDestinationResponse struct {
 	Name string `json:"name"`
+	// Mode and Jobs tell how the jobs using it were handled
+	Mode string   `json:"mode,omitempty"`
+	Jobs []string `json:"jobs,omitempty"`
 }
 
 type JobStatus struct {
@@ -38,19 +46,21 @@ type JobStatus struct {
 
 // Job response
 type JobResponse struct {
-	ID            int                  `json:"id"`
-	Name          string               `json:"name"`
-	Source        JobSourceConfig      `json:"source"`
-	Destination   JobDestinationConfig `json:"destination"`
-	StreamsConfig string               `json:"streams_config"`
-	Frequency     string               `json:"frequency"`
-	LastRunTime   string               `json:"last_run_time,omitempty"`
-	LastRunState  string               `json:"last_run_state,omitempty"`
-	CreatedAt     string               `json:"created_at"`
-	UpdatedAt     string               `json:"updated_at"`
-	Activate      bool                 `json:"activate"`
-	CreatedBy     string               `json:"created_by,omitempty"`
-	UpdatedBy     string               `json:"updated_by,omitempty"`
+	ID             int                  `json:"id"`
+	Name           string               `json:"name"`
+	Source         JobSourceConfig      `json:"source"`
+	Destination    JobDestinationConfig `json:"destination"`
+	StreamsConfig  string               `json:"streams_config"`
+	Frequency      string               `json:"frequency"`
+	ScheduleConfig *ScheduleConfig      `json:"schedule_config,omitempty"`
+	RetryPolicy    *RetryPolicy         `json:"retry_policy,omitempty"`
+	LastRunTime    string               `json:"last_run_time,omitempty"`
+	LastRunState   string               `json:"last_run_state,omitempty"`
+	CreatedAt      string               `json:"created_at"`
+	UpdatedAt      string               `json:"updated_at"`
+	Activate       bool                 `json:"activate"`
+	CreatedBy      string               `json:"created_by,omitempty"`
+	UpdatedBy      string               `json:"updated_by,omitempty"`
 }
 
 type JobTask struct {
@@ -58,6 +68,10 @@ type JobTask struct {
 	StartTime string `json:"start_time"`
 	Status    string `json:"status"`
 	FilePath  string `json:"file_path"`
+	// Attempt is the attempt number of the run, starting at 1
+	Attempt int `json:"attempt"`
+	// Type is constants.TaskTypeSync or constants.TaskTypeBackfill
+	Type string `json:"type"`
 }
 
 type SourceDataItem struct {
@@ -71,6 +85,8 @@ type SourceDataItem struct {
 	CreatedBy string        `json:"created_by"`
 	UpdatedBy string        `json:"updated_by"`
 	Jobs      []JobDataItem `json:"jobs"`
+	// MaxConcurrentSyncs is 0 when unlimited
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs"`
 }
 
 type DestinationDataItem struct {
@@ -84,6 +100,8 @@ type DestinationDataItem struct {
 	CreatedBy string        `json:"created_by"`
 	UpdatedBy string        `json:"updated_by"`
 	Jobs      []JobDataItem `json:"jobs"`
+	// MaxConcurrentSyncs is 0 when unlimited
+	MaxConcurrentSyncs int `json:"max_concurrent_syncs"`
 }
 
 type JobDataItem struct {
@@ -97,3 +115,264 @@ type JobDataItem struct {
 	LastRunTime     string `json:"last_run_time,omitempty"`
 	LastRunState    string `json:"last_run_state,omitempty"`
 }
+
+type SchedulePreviewResponse struct {
+	TimeZone string   `json:"time_zone"`
+	NextRuns []string `json:"next_runs"`
+}
+
+type ProjectSettingsResponse struct {
+	ProjectID          string           `json:"project_id"`
+	BlackoutWindows    []BlackoutWindow `json:"blackout_windows"`
+	MaxConcurrentSyncs int              `json:"max_concurrent_syncs"`
+	UpdatedAt          string           `json:"updated_at,omitempty"`
+	UpdatedBy          string           `json:"updated_by,omitempty"`
+}
+
+type JobDependenciesResponse struct {
+	JobID       int                   `json:"job_id"`
+	Upstreams   []JobDependencyConfig `json:"upstreams"`
+	Downstreams []JobDependencyConfig `json:"downstreams"`
+}
+
+type JobDAGResponse struct {
+	Nodes []JobDAGNode `json:"nodes"`
+	Edges []JobDAGEdge `json:"edges"`
+}
+
+type JobDAGNode struct {
+	JobID    int    `json:"job_id"`
+	Name     string `json:"name"`
+	Activate bool   `json:"activate"`
+	// Level is the length of the longest upstream path, roots are at level 0
+	Level        int    `json:"level"`
+	LastRunTime  string `json:"last_run_time,omitempty"`
+	LastRunState string `json:"last_run_state,omitempty"`
+}
+
+type JobDAGEdge struct {
+	UpstreamJobID int    `json:"upstream_job_id"`
+	JobID         int    `json:"job_id"`
+	Mode          string `json:"mode"`
+}
+
+type ConcurrencyResponse struct {
+	Running []ConcurrencySlot `json:"running"`
+	Queued  []ConcurrencySlot `json:"queued"`
+}
+
+type ConcurrencySlot struct {
+	WorkflowID  string   `json:"workflow_id"`
+	JobID       int      `json:"job_id"`
+	Scopes      []string `json:"scopes"`
+	RequestedAt string   `json:"requested_at"`
+	GrantedAt   string   `json:"granted_at,omitempty"`
+}
+
+type JobStateResponse struct {
+	JobID int    `json:"job_id"`
+	State string `json:"state"`
+	// Revision is the latest recorded state revision, 0 if none is recorded
+	Revision  int    `json:"revision"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+type JobStateRevisionResponse struct {
+	Revision int    `json:"revision"`
+	State    string `json:"state"`
+	Reason   string `json:"reason"`
+	// Streams are the streams cleared by a stream reset
+	Streams []string `json:"streams,omitempty"`
+	// WorkflowID is the sync run that produced the state
+	WorkflowID   string `json:"workflow_id,omitempty"`
+	RestoredFrom int    `json:"restored_from,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	CreatedBy    string `json:"created_by,omitempty"`
+}
+
+type BackfillResponse struct {
+	WorkflowID string `json:"workflow_id"`
+}
+
+type SyncPreviewResponse struct {
+	WorkflowID string          `json:"workflow_id"`
+	Streams    []StreamPreview `json:"streams"`
+}
+
+type StreamPreview struct {
+	Stream string `json:"stream"`
+	// Columns are the columns and types the source catalog infers for the stream
+	Columns []PreviewColumn `json:"columns"`
+	// RowCountEstimate is the number of rows read, the exact row count of the stream when
+	// Complete and otherwise a lower bound as the preview stopped the connector early
+	RowCountEstimate int64                    `json:"row_count_estimate"`
+	Complete         bool                     `json:"complete"`
+	SampleRows       []map[string]interface{} `json:"sample_rows"`
+}
+
+type PreviewColumn struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+}
+
+type CreatedJobResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type JobTemplateResponse struct {
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Job       CreateJobRequest `json:"job"`
+	Variables []string         `json:"variables"`
+	CreatedAt string           `json:"created_at"`
+	UpdatedAt string           `json:"updated_at"`
+	CreatedBy string           `json:"created_by,omitempty"`
+	UpdatedBy string           `json:"updated_by,omitempty"`
+}
+
+// JobTemplateInstanceResponse is the outcome of creating a job for one set of template variables
+type JobTemplateInstanceResponse struct {
+	Variables map[string]string   `json:"variables"`
+	Job       *CreatedJobResponse `json:"job,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// ConfigChange is a create, update or delete of a resource when importing a project config
+type ConfigChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// Fields lists the fields an update changes, config keys as "config.<key>"
+	Fields []string `json:"fields,omitempty"`
+}
+
+type ProjectConfigPlanResponse struct {
+	Changes []ConfigChange `json:"changes"`
+}
+
+type ProjectConfigApplyResponse struct {
+	Changes []ConfigChange `json:"changes"`
+	// ScheduleErrors lists the Temporal schedules that could not be reconciled after the changes were saved
+	ScheduleErrors []string `json:"schedule_errors,omitempty"`
+}
+
+type NotificationChannelResponse struct {
+	ID        int                       `json:"id"`
+	Name      string                    `json:"name"`
+	Type      string                    `json:"type"`
+	Config    NotificationChannelConfig `json:"config"`
+	CreatedAt string                    `json:"created_at"`
+	UpdatedAt string                    `json:"updated_at"`
+	CreatedBy string                    `json:"created_by,omitempty"`
+	UpdatedBy string                    `json:"updated_by,omitempty"`
+}
+
+type JobNotificationsResponse struct {
+	JobID         int                     `json:"job_id"`
+	Subscriptions []JobNotificationConfig `json:"subscriptions"`
+}
+
+type WebhookResponse struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// Secret is only returned when it is set
+	Secret    string `json:"secret,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	CreatedBy string `json:"created_by,omitempty"`
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int    `json:"id"`
+	WebhookID      int    `json:"webhook_id"`
+	EventID        string `json:"event_id"`
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+	// Payload is only returned for a single delivery
+	Payload       map[string]interface{} `json:"payload,omitempty"`
+	CreatedAt     string                 `json:"created_at"`
+	LastAttemptAt string                 `json:"last_attempt_at,omitempty"`
+	DeliveredAt   string                 `json:"delivered_at,omitempty"`
+}
+
+// HealthCheck is the outcome of one readiness check
+type HealthCheck struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type ReadinessResponse struct {
+	Ready  bool          `json:"ready"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+type TaskQueuePoller struct {
+	// Type is workflow or activity
+	Type           string  `json:"type"`
+	Identity       string  `json:"identity"`
+	LastAccessTime string  `json:"last_access_time,omitempty"`
+	RatePerSecond  float64 `json:"rate_per_second"`
+}
+
+type TaskQueueResponse struct {
+	Name    string            `json:"name"`
+	Pollers []TaskQueuePoller `json:"pollers"`
+	// Error is set when the pollers could not be listed
+	Error string `json:"error,omitempty"`
+}
+
+type DiagnosticsResponse struct {
+	Build          string `json:"build"`
+	CommitSHA      string `json:"commit_sha"`
+	ReleaseChannel string `json:"release_channel"`
+	GoVersion      string `json:"go_version"`
+	// Config summarizes the configuration of the server, credentials are redacted
+	Config    map[string]string `json:"config"`
+	Readiness ReadinessResponse `json:"readiness"`
+	TaskQueue TaskQueueResponse `json:"task_queue"`
+	// PendingScheduleOperations is the number of schedule changes the schedule outbox has not applied yet
+	PendingScheduleOperations int64 `json:"pending_schedule_operations"`
+}
+
+// TrashItem is a deleted row that can still be restored
+type TrashItem struct {
+	// Kind is one of the constants.TrashKind values
+	Kind      string    `json:"kind"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the row is deleted for good, unset when the trash is kept forever
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
+
+type RestoreResponse struct {
+	Name string `json:"name"`
+	// Jobs lists the jobs restored together with a source or destination
+	Jobs []string `json:"jobs,omitempty"`
+}
+
+// ScheduleDrift is a difference between a job and its Temporal schedule
+type ScheduleDrift struct {
+	ScheduleID string `json:"schedule_id"`
+	ProjectID  string `json:"project_id"`
+	JobID      int    `json:"job_id"`
+	// JobName is unset for orphaned schedules
+	JobName string `json:"job_name,omitempty"`
+	// Kind is one of the constants.ScheduleDrift values
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Repaired bool   `json:"repaired"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,232 @@
+// Package notify delivers job notifications to the webhook, Slack and email
+// channels of a project.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// SendTimeout bounds a single delivery attempt
+const SendTimeout = 30 * time.Second
+
+// smtpsPort is the port email is sent to over implicit TLS instead of STARTTLS
+const smtpsPort = 465
+
+// Notification is what a channel is told about an event of a job, webhooks
+// receive it as JSON
+type Notification struct {
+	Event      string `json:"event"`
+	ProjectID  string `json:"project_id"`
+	JobID      int    `json:"job_id"`
+	JobName    string `json:"job_name"`
+	WorkflowID string `json:"workflow_id,omitempty"`
+	Message    string `json:"message"`
+	// Details lists specifics of the event, e.g. the columns of a schema drift
+	Details []string  `json:"details,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Subject is the one line summary of a notification
+func (n *Notification) Subject() string {
+	subject := fmt.Sprintf("[OLake] %s: %s", n.JobName, strings.ReplaceAll(n.Event, "_", " "))
+	// the subject becomes an email header
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+}
+
+// text renders the notification for people
+func (n *Notification) text() string {
+	var b strings.Builder
+	b.WriteString(n.Message)
+	b.WriteString("\n")
+	for _, detail := range n.Details {
+		fmt.Fprintf(&b, "- %s\n", detail)
+	}
+	if n.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", n.Error)
+	}
+	if n.WorkflowID != "" {
+		fmt.Fprintf(&b, "Run: %s\n", n.WorkflowID)
+	}
+	return b.String()
+}
+
+// ParseConfig decodes the config stored on a channel
+func ParseConfig(raw string) (*models.NotificationChannelConfig, error) {
+	config := &models.NotificationChannelConfig{}
+	if err := json.Unmarshal([]byte(raw), config); err != nil {
+		return nil, fmt.Errorf("invalid notification channel config: %s", err)
+	}
+	return config, nil
+}
+
+// ValidateConfig checks that a config has what its channel type needs
+func ValidateConfig(channelType string, config *models.NotificationChannelConfig) error {
+	switch channelType {
+	case constants.NotificationChannelWebhook, constants.NotificationChannelSlack:
+		u, err := url.Parse(config.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("config.url must be an http or https URL")
+		}
+	case constants.NotificationChannelEmail:
+		if config.Host == "" {
+			return fmt.Errorf("config.host is required")
+		}
+		if config.Port < 0 || config.Port > 65535 {
+			return fmt.Errorf("config.port must be a TCP port")
+		}
+		if _, err := mail.ParseAddress(config.From); err != nil {
+			return fmt.Errorf("config.from must be an email address")
+		}
+		if len(config.To) == 0 {
+			return fmt.Errorf("config.to must list at least one recipient")
+		}
+		for _, to := range config.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("config.to has an invalid email address %q", to)
+			}
+		}
+	default:
+		return fmt.Errorf("type must be %s, %s or %s", constants.NotificationChannelWebhook, constants.NotificationChannelSlack, constants.NotificationChannelEmail)
+	}
+	return nil
+}
+
+// Send delivers a notification to a channel
+func Send(ctx context.Context, channel *models.NotificationChannel, n *Notification) error {
+	config, err := ParseConfig(channel.Config)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, SendTimeout)
+	defer cancel()
+
+	switch channel.Type {
+	case constants.NotificationChannelWebhook:
+		payload, err := json.Marshal(n)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification: %s", err)
+		}
+		return post(ctx, config.URL, config.Headers, payload)
+	case constants.NotificationChannelSlack:
+		payload, err := json.Marshal(map[string]string{"text": fmt.Sprintf("*%s*\n%s", n.Subject(), n.text())})
+		if err != nil {
+			return fmt.Errorf("failed to encode notification: %s", err)
+		}
+		return post(ctx, config.URL, nil, payload)
+	case constants.NotificationChannelEmail:
+		return sendMail(ctx, config, n)
+	default:
+		return fmt.Errorf("unsupported notification channel type: %s", channel.Type)
+	}
+}
+
+// post sends a JSON payload, any status other than 2xx fails the delivery
+func post(ctx context.Context, target string, headers map[string]string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "olake-notifications")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %s", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sendMail mails a notification, upgrading to TLS when the server offers STARTTLS
+func sendMail(ctx context.Context, config *models.NotificationChannelConfig, n *Notification) error {
+	port := config.Port
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: config.Host}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %s", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if port == smtpsPort {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session with %s: %s", addr, err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != smtpsPort {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS with %s: %s", addr, err)
+		}
+	}
+	if config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", config.Username, config.Password, config.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate to %s: %s", addr, err)
+		}
+	}
+	if err := client.Mail(config.From); err != nil {
+		return fmt.Errorf("SMTP server rejected sender %s: %s", config.From, err)
+	}
+	for _, to := range config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %s", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %s", err)
+	}
+	if _, err := w.Write(message(config, n)); err != nil {
+		return fmt.Errorf("failed to send email: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %s", err)
+	}
+	return client.Quit()
+}
+
+// message builds the plain text email of a notification
+func message(config *models.NotificationChannelConfig, n *Notification) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", n.Subject())
+	fmt.Fprintf(&b, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(n.text(), "\n", "\r\n"))
+	return b.Bytes()
+}
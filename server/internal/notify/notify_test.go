@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+var failure = &Notification{
+	Event:      constants.NotificationEventFailure,
+	ProjectID:  "123",
+	JobID:      3,
+	JobName:    "orders",
+	WorkflowID: "sync-123-3-2026-10-19T02:00:00Z",
+	Message:    "Sync of job orders failed after 1m30s.",
+	Error:      "connection refused",
+	Time:       time.Date(2026, 10, 19, 2, 1, 30, 0, time.UTC),
+}
+
+func channel(t *testing.T, channelType string, config models.NotificationChannelConfig) *models.NotificationChannel {
+	t.Helper()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.NotificationChannel{Name: "ops", Type: channelType, Config: string(data)}
+}
+
+// webhookServer is an HTTP stand-in answering with status and recording the requests it gets
+func webhookServer(t *testing.T, status int) (*httptest.Server, chan *http.Request, chan []byte) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("nope"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, bodies
+}
+
+func TestWebhook(t *testing.T) {
+	server, requests, bodies := webhookServer(t, http.StatusNoContent)
+	config := models.NotificationChannelConfig{URL: server.URL + "/hooks/olake", Headers: map[string]string{"Authorization": "Bearer token"}}
+	if err := Send(context.Background(), channel(t, constants.NotificationChannelWebhook, config), failure); err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+
+	req := <-requests
+	if req.Method != http.MethodPost || req.URL.Path != "/hooks/olake" {
+		t.Errorf("got %s %s, want POST /hooks/olake", req.Method, req.URL.Path)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization header = %q, want the configured header", got)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var got Notification
+	if err := json.Unmarshal(<-bodies, &got); err != nil {
+		t.Fatalf("payload is not a notification: %s", err)
+	}
+	if got.Event != failure.Event || got.JobID != failure.JobID || got.Error != failure.Error || !got.Time.Equal(failure.Time) {
+		t.Errorf("payload = %+v, want %+v", got, failure)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	server, _, bodies := webhookServer(t, http.StatusOK)
+	config := models.NotificationChannelConfig{URL: server.URL}
+	if err := Send(context.Background(), channel(t, constants.NotificationChannelSlack, config), failure); err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatalf("payload is not a Slack message: %s", err)
+	}
+	for _, want := range []string{"*[OLake] orders: failure*", failure.Message, "Error: connection refused"} {
+		if !strings.Contains(payload["text"], want) {
+			t.Errorf("text %q does not contain %q", payload["text"], want)
+		}
+	}
+}
+
+func TestWebhookError(t *testing.T) {
+	server, _, _ := webhookServer(t, http.StatusInternalServerError)
+	config := models.NotificationChannelConfig{URL: server.URL}
+	err := Send(context.Background(), channel(t, constants.NotificationChannelWebhook, config), failure)
+	if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Send = %v, want an error with the status and body", err)
+	}
+}
+
+// smtpSession is what the SMTP stand-in received
+type smtpSession struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is an SMTP stand-in accepting a single session with AUTH PLAIN
+func smtpServer(t *testing.T) (string, int, chan smtpSession) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	sessions := make(chan smtpSession, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		var session smtpSession
+		reply("220 localhost ESMTP stand-in")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case command == "EHLO" || command == "HELO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(strings.ToUpper(line), "AUTH PLAIN"):
+				decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN"):]))
+				session.auth = string(decoded)
+				reply("235 2.7.0 Authentication successful")
+			case command == "MAIL":
+				session.from = line
+				reply("250 OK")
+			case command == "RCPT":
+				session.to = append(session.to, line)
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				session.data = data.String()
+				reply("250 OK queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				sessions <- session
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber, sessions
+}
+
+func TestEmail(t *testing.T) {
+	host, port, sessions := smtpServer(t)
+	config := models.NotificationChannelConfig{
+		Host:     host,
+		Port:     port,
+		Username: "olake",
+		Password: "secret",
+		From:     "olake@example.com",
+		To:       []string{"oncall@example.com", "data@example.com"},
+	}
+	if err := Send(context.Background(), channel(t, constants.NotificationChannelEmail, config), failure); err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+
+	session := <-sessions
+	if session.auth != "\x00olake\x00secret" {
+		t.Errorf("auth = %q, want the configured credentials", session.auth)
+	}
+	if session.from != "MAIL FROM:<olake@example.com>" {
+		t.Errorf("sender = %q", session.from)
+	}
+	if len(session.to) != 2 || session.to[0] != "RCPT TO:<oncall@example.com>" || session.to[1] != "RCPT TO:<data@example.com>" {
+		t.Errorf("recipients = %q", session.to)
+	}
+	for _, want := range []string{
+		"Subject: [OLake] orders: failure\r\n",
+		"To: oncall@example.com, data@example.com\r\n",
+		failure.Message + "\r\n",
+		"Error: connection refused\r\n",
+		"Run: " + failure.WorkflowID + "\r\n",
+	} {
+		if !strings.Contains(session.data, want) {
+			t.Errorf("email %q does not contain %q", session.data, want)
+		}
+	}
+}
+
+func TestEmailUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+
+	config := models.NotificationChannelConfig{Host: "127.0.0.1", Port: addr.Port, From: "olake@example.com", To: []string{"oncall@example.com"}}
+	if err := Send(context.Background(), channel(t, constants.NotificationChannelEmail, config), failure); err == nil {
+		t.Error("Send to a closed port succeeded")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	email := models.NotificationChannelConfig{Host: "smtp.example.com", Port: 587, From: "olake@example.com", To: []string{"oncall@example.com"}}
+	cases := []struct {
+		name        string
+		channelType string
+		config      models.NotificationChannelConfig
+		valid       bool
+	}{
+		{"webhook", constants.NotificationChannelWebhook, models.NotificationChannelConfig{URL: "https://example.com/hook"}, true},
+		{"slack", constants.NotificationChannelSlack, models.NotificationChannelConfig{URL: "https://hooks.slack.com/services/T/B/X"}, true},
+		{"webhook without url", constants.NotificationChannelWebhook, models.NotificationChannelConfig{}, false},
+		{"webhook with other scheme", constants.NotificationChannelWebhook, models.NotificationChannelConfig{URL: "ftp://example.com"}, false},
+		{"email", constants.NotificationChannelEmail, email, true},
+		{"email without host", constants.NotificationChannelEmail, models.NotificationChannelConfig{From: email.From, To: email.To}, false},
+		{"email without recipients", constants.NotificationChannelEmail, models.NotificationChannelConfig{Host: email.Host, From: email.From}, false},
+		{"email with invalid recipient", constants.NotificationChannelEmail, models.NotificationChannelConfig{Host: email.Host, From: email.From, To: []string{"oncall"}}, false},
+		{"unknown type", "pager", models.NotificationChannelConfig{URL: "https://example.com"}, false},
+	}
+	for _, tc := range cases {
+		err := ValidateConfig(tc.channelType, &tc.config)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: ValidateConfig = %v, want valid %v", tc.name, err, tc.valid)
+		}
+	}
+}
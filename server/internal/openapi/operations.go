@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// operation documents a route. Request and Response are values of the types
+// the handler decodes and responds with, Response being the data of the
+// utils.SuccessResponse envelope.
+type operation struct {
+	Method  string
+	Path    string
+	Handler string
+	Tag     string
+	Summary string
+	Query   []query
+
+	Request         interface{}
+	OptionalRequest bool
+	// Document is set when the request is a YAML or JSON document instead of a JSON body
+	Document bool
+
+	Response interface{}
+	// Raw lists the content types of a response that is not wrapped in the envelope
+	Raw       []string
+	NoContent bool
+}
+
+type query struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+func (op *operation) key() string {
+	return op.Method + " " + op.Path
+}
+
+// response data built from maps or anonymous structs in the handlers
+type (
+	usernameData struct {
+		Username string `json:"username"`
+	}
+	signupData struct {
+		Email    string `json:"email"`
+		Username string `json:"username"`
+	}
+	versionsData struct {
+		Version []string `json:"version"`
+	}
+	connectorJobsData struct {
+		Jobs []models.Job `json:"jobs"`
+	}
+	connectorSpecData struct {
+		Version  string      `json:"version"`
+		Type     string      `json:"type"`
+		Spec     interface{} `json:"spec"`
+		UISchema interface{} `json:"uiSchema"`
+	}
+	statusData struct {
+		Status string `json:"status"`
+	}
+	nameData struct {
+		Name string `json:"name"`
+	}
+	taskLogsRequest struct {
+		FilePath string `json:"file_path"`
+	}
+	taskLogEntry struct {
+		Level   string `json:"level"`
+		Time    string `json:"time"`
+		Message string `json:"message"`
+	}
+)
+
+const projectPath = "/api/v1/project/:projectid"
+
+// operations documents every route registered in routes.Init, the contract
+// test fails when the two disagree
+var operations = []*operation{
+	// authentication
+	{Method: http.MethodPost, Path: "/login", Handler: "Login", Tag: "auth", Summary: "Log in and start a session",
+		Request: models.LoginRequest{}, Response: usernameData{}},
+	{Method: http.MethodPost, Path: "/logout", Handler: "Logout", Tag: "auth", Summary: "End the session",
+		Response: models.LoginResponse{}},
+	{Method: http.MethodPost, Path: "/signup", Handler: "Signup", Tag: "auth", Summary: "Register a user",
+		Request: models.User{}, Response: signupData{}},
+	{Method: http.MethodGet, Path: "/auth/check", Handler: "CheckAuth", Tag: "auth", Summary: "Check the session",
+		Response: models.LoginResponse{}},
+
+	// health
+	{Method: http.MethodGet, Path: "/healthz", Handler: "Liveness", Tag: "health", Summary: "Liveness probe, answers while the process runs",
+		Response: statusData{}},
+	{Method: http.MethodGet, Path: "/readyz", Handler: "Readiness", Tag: "health", Summary: "Readiness probe, 503 when a dependency check fails",
+		Response: models.ReadinessResponse{}},
+	{Method: http.MethodGet, Path: "/api/v1/diagnostics", Handler: "GetDiagnostics", Tag: "health", Summary: "Versions, config summary, readiness and task queue pollers, admins only",
+		Response: models.DiagnosticsResponse{}},
+
+	// schedules
+	{Method: http.MethodPost, Path: "/api/v1/schedules/reconcile", Handler: "ReconcileSchedules", Tag: "schedules", Summary: "Compare the jobs of all projects with their Temporal schedules, admins only",
+		Query:    []query{{Name: "apply", Type: "boolean", Description: "also repair the differences, false by default"}},
+		Response: []models.ScheduleDrift{}},
+
+	// documentation
+	{Method: http.MethodGet, Path: "/api/v1/openapi.json", Handler: "GetOpenAPISpec", Tag: "docs", Summary: "This OpenAPI document",
+		Raw: []string{"application/json"}},
+	{Method: http.MethodGet, Path: "/api/v1/docs", Handler: "GetSwaggerUI", Tag: "docs", Summary: "Swagger UI for this document",
+		Raw: []string{"text/html"}},
+
+	// users
+	{Method: http.MethodPost, Path: "/api/v1/users", Handler: "CreateUser", Tag: "users", Summary: "Create a user",
+		Request: models.User{}, Response: models.User{}},
+	{Method: http.MethodGet, Path: "/api/v1/users", Handler: "GetAllUsers", Tag: "users", Summary: "List users",
+		Response: []models.User{}},
+	{Method: http.MethodPut, Path: "/api/v1/users/:id", Handler: "UpdateUser", Tag: "users", Summary: "Update a user",
+		Request: models.User{}, Response: models.User{}},
+	{Method: http.MethodDelete, Path: "/api/v1/users/:id", Handler: "DeleteUser", Tag: "users", Summary: "Delete a user",
+		NoContent: true},
+	{Method: http.MethodPost, Path: "/api/v1/users/:id/restore", Handler: "RestoreUser", Tag: "users", Summary: "Restore a deleted user",
+		Response: models.RestoreResponse{}},
+
+	// projects
+	{Method: http.MethodGet, Path: projectPath + "/settings", Handler: "GetProjectSettings", Tag: "projects", Summary: "Get the project settings",
+		Response: models.ProjectSettingsResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/settings", Handler: "UpdateProjectSettings", Tag: "projects", Summary: "Update the project settings",
+		Request: models.ProjectSettingsRequest{}, Response: models.ProjectSettingsResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/concurrency", Handler: "GetSyncConcurrency", Tag: "projects", Summary: "Get the sync slots in use",
+		Response: models.ConcurrencyResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/config/export", Handler: "ExportProjectConfig", Tag: "projects", Summary: "Export the project config",
+		Query: []query{
+			{Name: "format", Type: "string", Description: "yaml (default) or json"},
+			{Name: "secrets", Type: "string", Description: "reference (default) or omit"},
+		},
+		Response: models.ProjectConfig{}, Raw: []string{"application/yaml", "application/json"}},
+	{Method: http.MethodPost, Path: projectPath + "/config/plan", Handler: "PlanProjectConfig", Tag: "projects", Summary: "Show the changes importing a project config makes",
+		Request: models.ProjectConfig{}, Document: true, Response: models.ProjectConfigPlanResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/config/apply", Handler: "ApplyProjectConfig", Tag: "projects", Summary: "Import a project config",
+		Request: models.ProjectConfig{}, Document: true, Response: models.ProjectConfigApplyResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/trash", Handler: "GetTrash", Tag: "projects", Summary: "List deleted rows that can be restored, newest first",
+		Response: []models.TrashItem{}},
+
+	// notifications
+	{Method: http.MethodGet, Path: projectPath + "/notification-channels", Handler: "GetNotificationChannels", Tag: "notifications", Summary: "List notification channels",
+		Response: []models.NotificationChannelResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/notification-channels", Handler: "CreateNotificationChannel", Tag: "notifications", Summary: "Create a notification channel",
+		Request: models.NotificationChannelRequest{}, Response: models.NotificationChannelResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/notification-channels/:id", Handler: "UpdateNotificationChannel", Tag: "notifications", Summary: "Replace a notification channel",
+		Request: models.NotificationChannelRequest{}, Response: models.NotificationChannelResponse{}},
+	{Method: http.MethodDelete, Path: projectPath + "/notification-channels/:id", Handler: "DeleteNotificationChannel", Tag: "notifications", Summary: "Move a notification channel to the trash",
+		Response: nameData{}},
+	{Method: http.MethodPost, Path: projectPath + "/notification-channels/:id/restore", Handler: "RestoreNotificationChannel", Tag: "notifications", Summary: "Restore a notification channel and its subscriptions",
+		Response: models.RestoreResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/notification-channels/:id/test", Handler: "TestNotificationChannel", Tag: "notifications", Summary: "Send a test notification",
+		Response: nameData{}},
+
+	// webhooks
+	{Method: http.MethodGet, Path: projectPath + "/webhooks", Handler: "GetWebhooks", Tag: "webhooks", Summary: "List webhooks",
+		Response: []models.WebhookResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/webhooks", Handler: "CreateWebhook", Tag: "webhooks", Summary: "Create a webhook, the response holds its signing secret",
+		Request: models.WebhookRequest{}, Response: models.WebhookResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/webhooks/:id", Handler: "UpdateWebhook", Tag: "webhooks", Summary: "Replace a webhook",
+		Request: models.WebhookRequest{}, Response: models.WebhookResponse{}},
+	{Method: http.MethodDelete, Path: projectPath + "/webhooks/:id", Handler: "DeleteWebhook", Tag: "webhooks", Summary: "Move a webhook to the trash",
+		Response: nameData{}},
+	{Method: http.MethodPost, Path: projectPath + "/webhooks/:id/restore", Handler: "RestoreWebhook", Tag: "webhooks", Summary: "Restore a webhook",
+		Response: models.RestoreResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/webhook-deliveries", Handler: "GetWebhookDeliveries", Tag: "webhooks", Summary: "List webhook deliveries, newest first",
+		Query: []query{
+			{Name: "webhook_id", Type: "integer", Description: "only deliveries to this webhook"},
+			{Name: "status", Type: "string", Description: "pending, succeeded or dead, dead lists the dead letters"},
+			{Name: "event", Type: "string", Description: "only deliveries of this event type"},
+			{Name: "limit", Type: "integer", Description: "number of deliveries, 50 by default and at most 500"},
+		}, Response: []models.WebhookDeliveryResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/webhook-deliveries/:id", Handler: "GetWebhookDelivery", Tag: "webhooks", Summary: "Get a webhook delivery with its payload",
+		Response: models.WebhookDeliveryResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/webhook-deliveries/:id/replay", Handler: "ReplayWebhookDelivery", Tag: "webhooks", Summary: "Send a finished webhook delivery again",
+		Response: models.WebhookDeliveryResponse{}},
+
+	// sources
+	{Method: http.MethodGet, Path: projectPath + "/sources", Handler: "GetAllSources", Tag: "sources", Summary: "List sources",
+		Response: []models.SourceDataItem{}},
+	{Method: http.MethodPost, Path: projectPath + "/sources", Handler: "CreateSource", Tag: "sources", Summary: "Create a source",
+		Request: models.CreateSourceRequest{}, Response: models.CreateSourceRequest{}},
+	{Method: http.MethodPut, Path: projectPath + "/sources/:id", Handler: "UpdateSource", Tag: "sources", Summary: "Update a source",
+		Request: models.UpdateSourceRequest{}, Response: models.UpdateSourceRequest{}},
+	{Method: http.MethodDelete, Path: projectPath + "/sources/:id", Handler: "DeleteSource", Tag: "sources", Summary: "Move a source to the trash",
+		Query:    []query{{Name: "mode", Type: "string", Description: "what happens to the jobs using it: reject, cascade or detach (default)"}},
+		Response: models.DeleteSourceResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/sources/:id/restore", Handler: "RestoreSource", Tag: "sources", Summary: "Restore a source and the jobs deleted with it",
+		Response: models.RestoreResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/sources/:id/jobs", Handler: "GetSourceJobs", Tag: "sources", Summary: "List the jobs of a source",
+		Response: connectorJobsData{}},
+	{Method: http.MethodPost, Path: projectPath + "/sources/test", Handler: "TestConnection", Tag: "sources", Summary: "Test a source connection",
+		Request: models.SourceTestConnectionRequest{}, Response: map[string]interface{}{}},
+	{Method: http.MethodPost, Path: projectPath + "/sources/streams", Handler: "GetSourceCatalog", Tag: "sources", Summary: "Discover the streams of a source",
+		Request: models.StreamsRequest{}, Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: projectPath + "/sources/versions", Handler: "GetSourceVersions", Tag: "sources", Summary: "List the versions of a source type",
+		Query: []query{{Name: "type", Type: "string", Description: "source type"}}, Response: versionsData{}},
+	{Method: http.MethodPost, Path: projectPath + "/sources/spec", Handler: "GetProjectSourceSpec", Tag: "sources", Summary: "Get the config spec of a source type",
+		Request: models.SpecRequest{}, Response: models.SpecResponse{}},
+
+	// destinations
+	{Method: http.MethodGet, Path: projectPath + "/destinations", Handler: "GetAllDestinations", Tag: "destinations", Summary: "List destinations",
+		Response: []models.DestinationDataItem{}},
+	{Method: http.MethodPost, Path: projectPath + "/destinations", Handler: "CreateDestination", Tag: "destinations", Summary: "Create a destination",
+		Request: models.CreateDestinationRequest{}, Response: models.CreateDestinationRequest{}},
+	{Method: http.MethodPut, Path: projectPath + "/destinations/:id", Handler: "UpdateDestination", Tag: "destinations", Summary: "Update a destination",
+		Request: models.UpdateDestinationRequest{}, Response: models.UpdateDestinationRequest{}},
+	{Method: http.MethodDelete, Path: projectPath + "/destinations/:id", Handler: "DeleteDestination", Tag: "destinations", Summary: "Move a destination to the trash",
+		Query:    []query{{Name: "mode", Type: "string", Description: "what happens to the jobs using it: reject, cascade or detach (default)"}},
+		Response: models.DeleteDestinationResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/destinations/:id/restore", Handler: "RestoreDestination", Tag: "destinations", Summary: "Restore a destination and the jobs deleted with it",
+		Response: models.RestoreResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/destinations/:id/jobs", Handler: "GetDestinationJobs", Tag: "destinations", Summary: "List the jobs of a destination",
+		Response: connectorJobsData{}},
+	{Method: http.MethodPost, Path: projectPath + "/destinations/test", Handler: "TestConnection", Tag: "destinations", Summary: "Test a destination connection",
+		Request: models.DestinationTestConnectionRequest{}, Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: projectPath + "/destinations/versions", Handler: "GetDestinationVersions", Tag: "destinations", Summary: "List the versions of a destination type",
+		Query: []query{{Name: "type", Type: "string", Description: "destination type"}}, Response: versionsData{}},
+	{Method: http.MethodPost, Path: projectPath + "/destinations/spec", Handler: "GetDestinationSpec", Tag: "destinations", Summary: "Get the config spec of a destination type",
+		Request: models.SpecRequest{}, Response: connectorSpecData{}},
+
+	// jobs
+	{Method: http.MethodGet, Path: projectPath + "/jobs", Handler: "GetAllJobs", Tag: "jobs", Summary: "List jobs",
+		Response: []models.JobResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs", Handler: "CreateJob", Tag: "jobs", Summary: "Create a job",
+		Request: models.CreateJobRequest{}, Response: models.CreateJobRequest{}},
+	{Method: http.MethodPut, Path: projectPath + "/jobs/:id", Handler: "UpdateJob", Tag: "jobs", Summary: "Update a job",
+		Request: models.UpdateJobRequest{}, Response: models.UpdateJobRequest{}},
+	{Method: http.MethodDelete, Path: projectPath + "/jobs/:id", Handler: "DeleteJob", Tag: "jobs", Summary: "Move a job to the trash and delete its schedule",
+		Response: models.DeleteDestinationResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/restore", Handler: "RestoreJob", Tag: "jobs", Summary: "Restore a job and recreate its schedule",
+		Response: models.RestoreResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/schedule/preview", Handler: "PreviewSchedule", Tag: "jobs", Summary: "Preview the next runs of a schedule",
+		Request: models.SchedulePreviewRequest{}, Response: models.SchedulePreviewResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/preview", Handler: "PreviewSync", Tag: "jobs", Summary: "Sample streams into a local sink",
+		Request: models.SyncPreviewRequest{}, Response: models.SyncPreviewResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/jobs/dag", Handler: "GetJobDAG", Tag: "jobs", Summary: "Get the job dependency graph",
+		Response: models.JobDAGResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/jobs/:id/dependencies", Handler: "GetJobDependencies", Tag: "jobs", Summary: "Get the upstream jobs of a job",
+		Response: models.JobDependenciesResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/jobs/:id/dependencies", Handler: "UpdateJobDependencies", Tag: "jobs", Summary: "Replace the upstream jobs of a job",
+		Request: models.JobDependenciesRequest{}, Response: models.JobDependenciesResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/jobs/:id/notifications", Handler: "GetJobNotifications", Tag: "jobs", Summary: "Get the notification subscriptions of a job",
+		Response: models.JobNotificationsResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/jobs/:id/notifications", Handler: "UpdateJobNotifications", Tag: "jobs", Summary: "Replace the notification subscriptions of a job",
+		Request: models.JobNotificationsRequest{}, Response: models.JobNotificationsResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/jobs/:id/state", Handler: "GetJobState", Tag: "jobs", Summary: "Get the sync state of a job",
+		Response: models.JobStateResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/jobs/:id/state", Handler: "UpdateJobState", Tag: "jobs", Summary: "Replace the sync state of a job",
+		Request: models.JobStateRequest{}, Response: models.JobStateResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/state/reset", Handler: "ResetJobState", Tag: "jobs", Summary: "Reset the sync state of a job or of some streams",
+		Request: models.JobStateResetRequest{}, OptionalRequest: true, Response: models.JobStateResponse{}},
+	{Method: http.MethodGet, Path: projectPath + "/jobs/:id/states", Handler: "GetJobStateHistory", Tag: "jobs", Summary: "List the state revisions of a job",
+		Query: []query{{Name: "limit", Type: "integer", Description: "revisions to return, 50 by default"}}, Response: []models.JobStateRevisionResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/states/:rev/restore", Handler: "RestoreJobState", Tag: "jobs", Summary: "Restore a state revision",
+		Response: models.JobStateResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/sync", Handler: "SyncJob", Tag: "jobs", Summary: "Trigger a sync",
+		Query: []query{{Name: "override_blackout", Type: "boolean", Description: "run even inside a blackout window"}}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/backfill", Handler: "BackfillJob", Tag: "jobs", Summary: "Backfill some streams",
+		Query:   []query{{Name: "override_blackout", Type: "boolean", Description: "run even inside a blackout window"}},
+		Request: models.BackfillRequest{}, Response: models.BackfillResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/clone", Handler: "CloneJob", Tag: "jobs", Summary: "Clone a job",
+		Request: models.JobCloneRequest{}, Response: models.CreatedJobResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/activate", Handler: "ActivateJob", Tag: "jobs", Summary: "Activate or pause a job",
+		Request: models.JobStatus{}, Response: models.JobStatus{}},
+	{Method: http.MethodGet, Path: projectPath + "/jobs/:id/tasks", Handler: "GetJobTasks", Tag: "jobs", Summary: "List the runs of a job",
+		Response: []models.JobTask{}},
+	{Method: http.MethodPost, Path: projectPath + "/jobs/:id/tasks/:taskid/logs", Handler: "GetTaskLogs", Tag: "jobs", Summary: "Get the logs of a run",
+		Request: taskLogsRequest{}, Response: []taskLogEntry{}},
+
+	// job templates
+	{Method: http.MethodGet, Path: projectPath + "/job-templates", Handler: "GetJobTemplates", Tag: "job templates", Summary: "List job templates",
+		Response: []models.JobTemplateResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/job-templates", Handler: "CreateJobTemplate", Tag: "job templates", Summary: "Create a job template",
+		Request: models.JobTemplateRequest{}, Response: models.JobTemplateResponse{}},
+	{Method: http.MethodPut, Path: projectPath + "/job-templates/:id", Handler: "UpdateJobTemplate", Tag: "job templates", Summary: "Replace a job template",
+		Request: models.JobTemplateRequest{}, Response: models.JobTemplateResponse{}},
+	{Method: http.MethodDelete, Path: projectPath + "/job-templates/:id", Handler: "DeleteJobTemplate", Tag: "job templates", Summary: "Move a job template to the trash",
+		Response: nameData{}},
+	{Method: http.MethodPost, Path: projectPath + "/job-templates/:id/restore", Handler: "RestoreJobTemplate", Tag: "job templates", Summary: "Restore a job template",
+		Response: models.RestoreResponse{}},
+	{Method: http.MethodPost, Path: projectPath + "/job-templates/:id/instantiate", Handler: "InstantiateJobTemplate", Tag: "job templates", Summary: "Create jobs from a job template",
+		Request: models.JobTemplateInstantiateRequest{}, Response: []models.JobTemplateInstanceResponse{}},
+}
+
+var operationsByKey = func() map[string]*operation {
+	byKey := make(map[string]*operation, len(operations))
+	for _, op := range operations {
+		byKey[op.key()] = op
+	}
+	return byKey
+}()
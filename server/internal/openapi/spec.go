@@ -0,0 +1,281 @@
+// Package openapi builds the OpenAPI 3 document of the server from the routes
+// registered with beego and the request and response models of each route.
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/beego/beego/v2/server/web"
+	"github.com/spf13/viper"
+)
+
+// Version is the OpenAPI version of the generated document
+const Version = "3.0.3"
+
+// sessionCookie is the beego session cookie authenticating /api/v1 requests
+const sessionCookie = "beegosessionID"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Responses       map[string]*Response       `json:"responses"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// Route is a route registered with the router
+type Route struct {
+	Method string
+	// Path is the beego pattern, e.g. /api/v1/users/:id
+	Path string
+	// Handler is the name of the controller method serving the route
+	Handler string
+}
+
+func (r Route) key() string {
+	return r.Method + " " + r.Path
+}
+
+// RegisteredRoutes returns the routes registered with the beego app, sorted by path and method
+func RegisteredRoutes() []Route {
+	var routes []Route
+	for _, info := range web.BeeApp.Handlers.GetAllControllerInfo() {
+		for method, handler := range info.GetMethod() {
+			routes = append(routes, Route{Method: method, Path: info.GetPattern(), Handler: handler})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Check compares routes with the documented operations and returns where they disagree
+func Check(routes []Route) []string {
+	var problems []string
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[route.key()] = true
+		op, ok := operationsByKey[route.key()]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is routed to %s but not documented", route.key(), route.Handler))
+		case op.Handler != route.Handler:
+			problems = append(problems, fmt.Sprintf("%s is routed to %s but documented as %s", route.key(), route.Handler, op.Handler))
+		}
+	}
+	for _, op := range operations {
+		if !registered[op.key()] {
+			problems = append(problems, fmt.Sprintf("%s is documented but not routed", op.key()))
+		}
+	}
+	return problems
+}
+
+// Build returns the OpenAPI document of routes. Routes that are not
+// documented are still listed, without their models.
+func Build(routes []Route) *Document {
+	registry := newSchemaRegistry()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: "OLake Server API", Version: viper.GetString("BUILD")},
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: registry.components,
+			Responses: map[string]*Response{
+				"Error": {
+					Description: "Error",
+					Content:     jsonContent(envelope(nil)),
+				},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"session": {Type: "apiKey", In: "cookie", Name: sessionCookie},
+			},
+		},
+	}
+
+	// handlers shared by several routes get the path in their operation ID
+	handlers := map[string]int{}
+	for _, route := range routes {
+		handlers[route.Handler]++
+	}
+
+	for _, route := range routes {
+		op, ok := operationsByKey[route.key()]
+		if !ok {
+			op = &operation{Method: route.Method, Path: route.Path, Handler: route.Handler}
+		}
+		operationID := route.Handler
+		if handlers[route.Handler] > 1 {
+			operationID = route.Handler + pathIdentifier(route.Path)
+		}
+		path, params := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op.build(registry, operationID, params)
+	}
+	return doc
+}
+
+// pathIdentifier turns a path into a camel case identifier, e.g. /api/v1/users into ApiV1Users
+func pathIdentifier(path string) string {
+	words := strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	var identifier strings.Builder
+	for _, word := range words {
+		identifier.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return identifier.String()
+}
+
+// openAPIPath converts a beego pattern to an OpenAPI path and its path parameters
+func openAPIPath(pattern string) (string, []*Parameter) {
+	segments := strings.Split(pattern, "/")
+	var params []*Parameter
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		schema := &Schema{Type: "string"}
+		if name == "id" || name == "rev" {
+			schema = &Schema{Type: "integer"}
+		}
+		params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		segments[i] = "{" + name + "}"
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func (op *operation) build(registry *schemaRegistry, operationID string, params []*Parameter) *Operation {
+	out := &Operation{
+		OperationID: operationID,
+		Summary:     op.Summary,
+		Parameters:  params,
+		Responses: map[string]*Response{
+			"default": {Ref: "#/components/responses/Error"},
+		},
+	}
+	if op.Tag != "" {
+		out.Tags = []string{op.Tag}
+	}
+	if strings.HasPrefix(op.Path, "/api/v1/") {
+		out.Security = []map[string][]string{{"session": {}}}
+	}
+	for _, query := range op.Query {
+		out.Parameters = append(out.Parameters, &Parameter{
+			Name:        query.Name,
+			In:          "query",
+			Description: query.Description,
+			Schema:      &Schema{Type: query.Type},
+		})
+	}
+
+	switch {
+	case op.Document:
+		// config documents may be sent as YAML or JSON
+		out.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/yaml": {Schema: registry.schemaOf(op.Request)},
+			"application/json": {Schema: registry.schemaOf(op.Request)},
+		}}
+	case op.Request != nil:
+		out.RequestBody = &RequestBody{Required: !op.OptionalRequest, Content: jsonContent(registry.schemaOf(op.Request))}
+	}
+
+	switch {
+	case op.NoContent:
+		out.Responses["204"] = &Response{Description: "No Content"}
+	case len(op.Raw) > 0:
+		// unwrapped responses are returned as is in each of their content types
+		content := map[string]*MediaType{}
+		for _, contentType := range op.Raw {
+			schema := &Schema{}
+			switch {
+			case op.Response != nil:
+				schema = registry.schemaOf(op.Response)
+			case strings.HasPrefix(contentType, "text/"):
+				schema = &Schema{Type: "string"}
+			}
+			content[contentType] = &MediaType{Schema: schema}
+		}
+		out.Responses["200"] = &Response{Description: "OK", Content: content}
+	case op.Response != nil:
+		out.Responses["200"] = &Response{Description: "OK", Content: jsonContent(envelope(registry.schemaOf(op.Response)))}
+	default:
+		out.Responses["200"] = &Response{Description: "OK", Content: jsonContent(envelope(nil))}
+	}
+	return out
+}
+
+// envelope returns the schema of a response wrapping data like utils.SuccessResponse
+func envelope(data *Schema) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{
+		"success": {Type: "boolean"},
+		"message": {Type: "string"},
+	}}
+	if data != nil {
+		schema.Properties["data"] = data
+	}
+	return schema
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
@@ -2,14 +2,22 @@ package temporal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
 	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/tracing"
+	"go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/activity"
 )
 
 // DiscoverCatalogActivity runs the discover command to get catalog data
 func DiscoverCatalogActivity(ctx context.Context, params *ActivityParams) (map[string]interface{}, error) {
+	ctx = tracing.Annotate(ctx, tracing.WorkflowID(params.WorkflowID))
 	logger := activity.GetLogger(ctx)
 	logger.Info("Starting sync activity",
 		"sourceType", params.SourceType,
@@ -46,6 +54,7 @@ func DiscoverCatalogActivity(ctx context.Context, params *ActivityParams) (map[s
 
 // TestConnectionActivity runs the check command to test connection
 func TestConnectionActivity(ctx context.Context, params *ActivityParams) (map[string]interface{}, error) {
+	ctx = tracing.Annotate(ctx, tracing.WorkflowID(params.WorkflowID))
 	// Create a Docker runner with the default config directory
 	runner := docker.NewRunner(docker.GetDefaultConfigDir())
 	resp, err := runner.TestConnection(ctx, params.Flag, params.SourceType, params.Version, params.Config, params.WorkflowID)
@@ -54,6 +63,7 @@ func TestConnectionActivity(ctx context.Context, params *ActivityParams) (map[st
 
 // SyncActivity runs the sync command to transfer data between source and destination
 func SyncActivity(ctx context.Context, params *SyncParams) (map[string]interface{}, error) {
+	ctx = tracing.Annotate(ctx, tracing.JobID(params.JobID), tracing.WorkflowID(params.WorkflowID))
 	// Get activity logger
 	logger := activity.GetLogger(ctx)
 	logger.Info("Starting sync activity",
@@ -63,16 +73,146 @@ func SyncActivity(ctx context.Context, params *SyncParams) (map[string]interface
 	runner := docker.NewRunner(docker.GetDefaultConfigDir())
 	// Record heartbeat
 	activity.RecordHeartbeat(ctx, "Running sync command")
-	// Execute the sync operation
+	// Execute the sync operation, heartbeating with the latest checkpoint
+	done := trackSync(ctx, params.JobID, "sync")
 	result, err := runner.RunSync(
 		ctx,
 		params.JobID,
 		params.WorkflowID,
+		func(checkpoint *docker.Checkpoint) {
+			if checkpoint == nil {
+				activity.RecordHeartbeat(ctx, "Running sync command")
+				return
+			}
+			activity.RecordHeartbeat(ctx, checkpoint)
+		},
 	)
+	done(err)
 	if err != nil {
 		logger.Error("Sync command failed", "error", err)
-		return result, fmt.Errorf("sync command failed: %v", err)
+		return result, toSyncError(err)
 	}
 
 	return result, nil
 }
+
+// BackfillActivity runs the sync command for some streams of a job from a copy of its state
+func BackfillActivity(ctx context.Context, params BackfillParams) (map[string]interface{}, error) {
+	ctx = tracing.Annotate(ctx, tracing.JobID(params.JobID), tracing.WorkflowID(params.WorkflowID))
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting backfill activity",
+		"jobId", params.JobID,
+		"workflowID", params.WorkflowID,
+		"streams", params.Streams)
+	runner := docker.NewRunner(docker.GetDefaultConfigDir())
+	activity.RecordHeartbeat(ctx, "Running backfill command")
+	done := trackSync(ctx, params.JobID, "backfill")
+	result, err := runner.RunBackfill(ctx, params.JobID, params.WorkflowID, params.Streams, params.Filter)
+	done(err)
+	if err != nil {
+		logger.Error("Backfill command failed", "error", err)
+		return result, toSyncError(err)
+	}
+	return result, nil
+}
+
+// PreviewActivity runs the sync command for the selected streams into a local sink and samples the rows
+func PreviewActivity(ctx context.Context, params PreviewParams) (*models.SyncPreviewResponse, error) {
+	ctx = tracing.Annotate(ctx, tracing.WorkflowID(params.WorkflowID))
+	logger := activity.GetLogger(ctx)
+	logger.Info("Starting preview activity",
+		"sourceType", params.SourceType,
+		"workflowID", params.WorkflowID)
+	runner := docker.NewRunner(docker.GetDefaultConfigDir())
+	activity.RecordHeartbeat(ctx, "Running preview command")
+	result, err := runner.RunPreview(ctx, params.SourceType, params.Version, params.Config, params.StreamsConfig,
+		params.WorkflowID, params.RowLimit, params.SampleSize)
+	if err != nil {
+		logger.Error("Preview command failed", "error", err)
+		return nil, fmt.Errorf("preview command failed: %v", err)
+	}
+	return result, nil
+}
+
+// CheckDependenciesActivity decides whether a job run can start based on the
+// latest sync runs of the job and its upstream jobs
+func CheckDependenciesActivity(ctx context.Context, params JobWorkflowParams) (*DependencyCheck, error) {
+	ctx = tracing.Annotate(ctx, tracing.JobID(params.JobID))
+	job, err := database.NewJobORM().GetByID(params.JobID, false)
+	if err != nil {
+		return nil, err
+	}
+	if params.TriggeredBy != 0 && !job.Active {
+		return &DependencyCheck{SkipReason: "job is paused"}, nil
+	}
+
+	temporalClient := activity.GetClient(ctx)
+	latest, err := latestSyncExecution(ctx, temporalClient, job.ProjectID, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	// the sync of this run has not started yet, so a running sync belongs to another run
+	if latest != nil && latest.Status == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return &DependencyCheck{SkipReason: "a sync of the job is already running"}, nil
+	}
+
+	upstreams, err := database.NewJobDependencyORM().GetUpstreams(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	check := &DependencyCheck{}
+	for _, dependency := range upstreams {
+		upstream := dependency.UpstreamJobID
+		// trigger dependencies only matter to runs triggered by another upstream job
+		if dependency.Mode == constants.DependencyModeTrigger && (params.TriggeredBy == 0 || upstream.ID == params.TriggeredBy) {
+			continue
+		}
+		latest, err := latestSyncExecution(ctx, temporalClient, upstream.ProjectID, upstream.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case latest != nil && latest.Status == enums.WORKFLOW_EXECUTION_STATUS_RUNNING:
+			if dependency.Mode == constants.DependencyModeWait {
+				check.WaitingFor = append(check.WaitingFor, upstream.ID)
+				continue
+			}
+			// the running upstream job triggers this job again when it completes
+			return &DependencyCheck{SkipReason: fmt.Sprintf("upstream job %s is running", upstream.Name)}, nil
+		case latest == nil || latest.Status != enums.WORKFLOW_EXECUTION_STATUS_COMPLETED:
+			return &DependencyCheck{SkipReason: fmt.Sprintf("upstream job %s has not completed successfully", upstream.Name)}, nil
+		}
+	}
+	return check, nil
+}
+
+// CheckBlackoutActivity returns the blackout window of the job or its project
+// that is active now, "" outside of them. The skip specs of the schedule only
+// cover the scheduled fire times, not runs started by an upstream job or
+// runs that waited for upstream jobs or a sync slot.
+func CheckBlackoutActivity(_ context.Context, jobID int) (string, error) {
+	job, err := database.NewJobORM().GetByID(jobID, false)
+	if err != nil {
+		return "", err
+	}
+	err = checkBlackoutWindows(job, time.Now())
+	if errors.Is(err, ErrBlackoutWindow) {
+		return err.Error(), nil
+	}
+	return "", err
+}
+
+// GetTriggeredJobsActivity returns the jobs to start after a successful sync of a job
+func GetTriggeredJobsActivity(_ context.Context, jobID int) ([]int, error) {
+	downstreams, err := database.NewJobDependencyORM().GetDownstreams(jobID)
+	if err != nil {
+		return nil, err
+	}
+	var jobIDs []int
+	for _, dependency := range downstreams {
+		if dependency.Mode == constants.DependencyModeTrigger {
+			jobIDs = append(jobIDs, dependency.JobID.ID)
+		}
+	}
+	return jobIDs, nil
+}
@@ -0,0 +1,254 @@
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// ErrBlackoutWindow is returned when a sync is triggered inside a blackout window
+var ErrBlackoutWindow = errors.New("sync is not allowed during a blackout window")
+
+// minuteRange is a range of minutes of the week starting sunday 00:00, end exclusive
+type minuteRange struct {
+	start int
+	end   int
+}
+
+// blackoutWindow is a validated blackout window
+type blackoutWindow struct {
+	window    models.BlackoutWindow
+	location  *time.Location
+	intervals []minuteRange
+}
+
+// ParseBlackoutWindows decodes blackout windows stored as json
+func ParseBlackoutWindows(raw string) ([]models.BlackoutWindow, error) {
+	var windows []models.BlackoutWindow
+	if strings.TrimSpace(raw) == "" {
+		return windows, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &windows); err != nil {
+		return nil, fmt.Errorf("invalid blackout windows: %s", err)
+	}
+	return windows, nil
+}
+
+// ValidateBlackoutWindows checks project level blackout windows, which default to each job's time zone
+func ValidateBlackoutWindows(windows []models.BlackoutWindow) error {
+	_, err := compileBlackoutWindows(windows, time.UTC)
+	return err
+}
+
+// projectBlackoutWindows reads the project blackout windows the schedules of
+// jobs are built with, tests replace it to build schedules without a database
+var projectBlackoutWindows = LoadProjectBlackoutWindows
+
+// LoadProjectBlackoutWindows reads the blackout windows shared by all jobs of a project
+func LoadProjectBlackoutWindows(projectID string) ([]models.BlackoutWindow, error) {
+	settings, err := database.NewProjectSettingsORM().GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBlackoutWindows(settings.BlackoutWindows)
+}
+
+func compileBlackoutWindows(windows []models.BlackoutWindow, defaultLocation *time.Location) ([]*blackoutWindow, error) {
+	compiled := make([]*blackoutWindow, 0, len(windows))
+	for idx, window := range windows {
+		blackout, err := compileBlackoutWindow(window, defaultLocation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blackout window at index %d: %s", idx, err)
+		}
+		compiled = append(compiled, blackout)
+	}
+	return compiled, nil
+}
+
+func compileBlackoutWindow(window models.BlackoutWindow, defaultLocation *time.Location) (*blackoutWindow, error) {
+	location := defaultLocation
+	if window.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %s", window.TimeZone, err)
+		}
+	}
+
+	start, err := parseClockTime(window.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_time: %s", err)
+	}
+	end, err := parseClockTime(window.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_time: %s", err)
+	}
+	if start%minutesPerDay == end%minutesPerDay {
+		return nil, fmt.Errorf("start_time and end_time must differ")
+	}
+
+	// only the day of week field matters, the others take their defaults
+	days, err := utils.ParseCalendarFields("", "", "", "", window.DaysOfWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	length := end - start
+	if length < 0 {
+		length += minutesPerDay
+	}
+	blackout := &blackoutWindow{window: window, location: location}
+	for day := 0; day < 7; day++ {
+		if !days.DayOfWeek.Matches(day) {
+			continue
+		}
+		rangeStart := day*minutesPerDay + start
+		rangeEnd := rangeStart + length
+		if rangeEnd <= minutesPerWeek {
+			blackout.intervals = append(blackout.intervals, minuteRange{rangeStart, rangeEnd})
+			continue
+		}
+		// saturday night windows continue on sunday morning
+		blackout.intervals = append(blackout.intervals,
+			minuteRange{rangeStart, minutesPerWeek},
+			minuteRange{0, rangeEnd - minutesPerWeek},
+		)
+	}
+	return blackout, nil
+}
+
+// parseClockTime parses "HH:MM" into minutes after midnight, "24:00" is accepted as an end of day
+func parseClockTime(value string) (int, error) {
+	if value == "24:00" {
+		return minutesPerDay, nil
+	}
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q must be in HH:MM format", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// contains reports whether t falls inside the window
+func (w *blackoutWindow) contains(t time.Time) bool {
+	local := t.In(w.location)
+	minute := int(local.Weekday())*minutesPerDay + local.Hour()*60 + local.Minute()
+	for _, r := range w.intervals {
+		if minute >= r.start && minute < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// shift returns the minutes to add to a time of the window to get the time in
+// the schedule time zone at the given time
+func (w *blackoutWindow) shift(scheduleLocation *time.Location, at time.Time) int {
+	_, windowOffset := at.In(w.location).Zone()
+	_, scheduleOffset := at.In(scheduleLocation).Zone()
+	return (scheduleOffset - windowOffset) / 60
+}
+
+// blackoutShiftChanged reports whether a blackout window in another time zone
+// than the schedule moved against it between from and to, which happens when
+// only one of both zones changes to or from DST
+func (s *compiledSchedule) blackoutShiftChanged(from, to time.Time) bool {
+	for _, blackout := range s.blackouts {
+		if blackout.shift(s.location, from) != blackout.shift(s.location, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipSpecs converts the window into Temporal skip specs, which are evaluated in
+// the schedule time zone and can not carry a time zone of their own. A window in
+// another time zone is shifted by the offset between both zones at the given
+// time. When a DST change alters that offset, BlackoutShiftWorkflow rebuilds the
+// schedule. The trigger time check and the schedule preview use the exact window.
+func (w *blackoutWindow) skipSpecs(scheduleLocation *time.Location, at time.Time) []client.ScheduleCalendarSpec {
+	shift := w.shift(scheduleLocation, at)
+
+	// split the ranges per day, days sharing the same hours produce the same specs
+	segments := map[minuteRange][]int{}
+	for _, r := range w.intervals {
+		start := ((r.start+shift)%minutesPerWeek + minutesPerWeek) % minutesPerWeek
+		for length := r.end - r.start; length > 0; {
+			day, dayStart := start/minutesPerDay, start%minutesPerDay
+			segmentLength := min(length, minutesPerDay-dayStart)
+			segment := minuteRange{dayStart, dayStart + segmentLength}
+			segments[segment] = append(segments[segment], day)
+			length -= segmentLength
+			start = (start + segmentLength) % minutesPerWeek
+		}
+	}
+
+	keys := make([]minuteRange, 0, len(segments))
+	for segment := range segments {
+		keys = append(keys, segment)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].start < keys[j].start || (keys[i].start == keys[j].start && keys[i].end < keys[j].end)
+	})
+
+	var specs []client.ScheduleCalendarSpec
+	for _, segment := range keys {
+		days := make([]client.ScheduleRange, 0, len(segments[segment]))
+		for _, day := range segments[segment] {
+			days = append(days, client.ScheduleRange{Start: day})
+		}
+		specs = append(specs, segmentSkipSpecs(segment, days, w.window.Comment)...)
+	}
+	return specs
+}
+
+// segmentSkipSpecs covers a range of minutes within a day, which takes up to
+// three specs: the partial first hour, the full hours and the partial last hour
+func segmentSkipSpecs(segment minuteRange, days []client.ScheduleRange, comment string) []client.ScheduleCalendarSpec {
+	spec := func(startHour, endHour, startMinute, endMinute int) client.ScheduleCalendarSpec {
+		return client.ScheduleCalendarSpec{
+			// skip specs must match every field, including seconds
+			Second:     []client.ScheduleRange{{Start: 0, End: 59}},
+			Minute:     []client.ScheduleRange{{Start: startMinute, End: endMinute}},
+			Hour:       []client.ScheduleRange{{Start: startHour, End: endHour}},
+			DayOfMonth: []client.ScheduleRange{{Start: 1, End: 31}},
+			Month:      []client.ScheduleRange{{Start: 1, End: 12}},
+			DayOfWeek:  days,
+			Comment:    comment,
+		}
+	}
+
+	startHour, startMinute := segment.start/60, segment.start%60
+	endHour, endMinute := segment.end/60, segment.end%60
+	if startHour == endHour {
+		return []client.ScheduleCalendarSpec{spec(startHour, startHour, startMinute, endMinute-1)}
+	}
+
+	var specs []client.ScheduleCalendarSpec
+	firstFullHour := startHour
+	if startMinute > 0 {
+		specs = append(specs, spec(startHour, startHour, startMinute, 59))
+		firstFullHour++
+	}
+	if firstFullHour <= endHour-1 {
+		specs = append(specs, spec(firstFullHour, endHour-1, 0, 59))
+	}
+	if endMinute > 0 {
+		specs = append(specs, spec(endHour, endHour, 0, endMinute-1))
+	}
+	return specs
+}
@@ -0,0 +1,107 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+const (
+	// BlackoutShiftWorkflowID is the ID of the workflow rebuilding schedules whose skip specs moved
+	BlackoutShiftWorkflowID = "olake-blackout-shift"
+	// BlackoutShiftSchedule is the cron schedule of BlackoutShiftWorkflow
+	BlackoutShiftSchedule = "5 * * * *"
+	// blackoutShiftLookback covers the time since the previous run, with a margin for a late run
+	blackoutShiftLookback = 70 * time.Minute
+)
+
+// BlackoutShiftWorkflow rebuilds the schedules whose blackout skip specs moved
+// with a DST change and the schedules of every N weeks entering a new week, the
+// worker runs it on BlackoutShiftSchedule
+func BlackoutShiftWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 10,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	return workflow.ExecuteActivity(ctx, RefreshBlackoutShiftsActivity).Get(ctx, nil)
+}
+
+// RefreshBlackoutShiftsActivity records an update in the schedule outbox for
+// every job with a blackout window in a time zone whose offset to the schedule
+// changed since the previous run, or whose skipped weeks moved along, and applies them
+func RefreshBlackoutShiftsActivity(ctx context.Context) error {
+	logger := activity.GetLogger(ctx)
+	jobs, err := database.NewJobORM().GetAllForSchedules()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	projectWindows := make(map[string][]models.BlackoutWindow)
+	var shifted []*models.Job
+	for _, job := range jobs {
+		windows, ok := projectWindows[job.ProjectID]
+		if !ok {
+			if windows, err = LoadProjectBlackoutWindows(job.ProjectID); err != nil {
+				return err
+			}
+			projectWindows[job.ProjectID] = windows
+		}
+		changed, err := blackoutShiftChanged(job, windows, now.Add(-blackoutShiftLookback), now)
+		if err != nil {
+			logger.Warn("Skipping job with an invalid schedule", "jobId", job.ID, "error", err)
+			continue
+		}
+		if changed {
+			shifted = append(shifted, job)
+		}
+	}
+	if len(shifted) == 0 {
+		return nil
+	}
+
+	ops, err := database.NewScheduleOutboxORM().Enqueue(shifted, string(ActionUpdate))
+	if err != nil {
+		return err
+	}
+	logger.Info("Rebuilding schedules whose skip specs moved", "count", len(ops))
+	c := &Client{temporalClient: activity.GetClient(ctx)}
+	// failed operations stay pending for ScheduleOutboxWorkflow
+	if err := c.ApplyScheduleOperations(ctx, ops); err != nil {
+		logger.Warn("Failed to apply schedule operations", "error", err)
+	}
+	return nil
+}
+
+// blackoutShiftChanged reports whether the skip specs of the job schedule differ between from and to
+func blackoutShiftChanged(job *models.Job, projectWindows []models.BlackoutWindow, from, to time.Time) (bool, error) {
+	config, err := ParseScheduleConfig(job.ScheduleConfig)
+	if err != nil {
+		return false, err
+	}
+	schedule, err := compileSchedule(job.Frequency, config, projectWindows)
+	if err != nil {
+		return false, err
+	}
+	return schedule.blackoutShiftChanged(from, to) || schedule.weekSkipsChanged(from, to), nil
+}
+
+// startBlackoutShiftCheck starts BlackoutShiftWorkflow unless it is already running
+func startBlackoutShiftCheck(ctx context.Context, temporalClient client.Client) error {
+	_, err := temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:           BlackoutShiftWorkflowID,
+		TaskQueue:    TaskQueue,
+		CronSchedule: BlackoutShiftSchedule,
+	}, BlackoutShiftWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to start blackout shift check: %s", err)
+	}
+	return nil
+}
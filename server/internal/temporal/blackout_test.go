@@ -0,0 +1,235 @@
+package temporal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestCompileBlackoutWindow(t *testing.T) {
+	cases := []struct {
+		name   string
+		window models.BlackoutWindow
+		want   []minuteRange
+	}{
+		{"same day", models.BlackoutWindow{DaysOfWeek: "MON", StartTime: "09:00", EndTime: "18:00"},
+			[]minuteRange{{minutesPerDay + 540, minutesPerDay + 1080}}},
+		{"until midnight", models.BlackoutWindow{DaysOfWeek: "1", StartTime: "22:00", EndTime: "24:00"},
+			[]minuteRange{{minutesPerDay + 1320, 2 * minutesPerDay}}},
+		{"overnight", models.BlackoutWindow{DaysOfWeek: "FRI", StartTime: "22:00", EndTime: "02:00"},
+			[]minuteRange{{5*minutesPerDay + 1320, 6*minutesPerDay + 120}}},
+		{"saturday night wraps to sunday", models.BlackoutWindow{DaysOfWeek: "SAT", StartTime: "23:00", EndTime: "01:00"},
+			[]minuteRange{{6*minutesPerDay + 1380, minutesPerWeek}, {0, 60}}},
+	}
+	for _, tc := range cases {
+		blackout, err := compileBlackoutWindow(tc.window, time.UTC)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(blackout.intervals, tc.want) {
+			t.Errorf("%s: intervals = %v, want %v", tc.name, blackout.intervals, tc.want)
+		}
+	}
+}
+
+func TestCompileBlackoutWindowInvalid(t *testing.T) {
+	for name, window := range map[string]models.BlackoutWindow{
+		"invalid time zone":   {DaysOfWeek: "*", StartTime: "09:00", EndTime: "10:00", TimeZone: "Mars/Olympus"},
+		"invalid start":       {DaysOfWeek: "*", StartTime: "9am", EndTime: "10:00"},
+		"invalid end":         {DaysOfWeek: "*", StartTime: "09:00", EndTime: "24:01"},
+		"empty window":        {DaysOfWeek: "*", StartTime: "09:00", EndTime: "09:00"},
+		"whole day":           {DaysOfWeek: "*", StartTime: "00:00", EndTime: "24:00"},
+		"invalid day of week": {DaysOfWeek: "MONDAY", StartTime: "09:00", EndTime: "10:00"},
+	} {
+		if _, err := compileBlackoutWindow(window, time.UTC); err == nil {
+			t.Errorf("%s: compileBlackoutWindow succeeded, want an error", name)
+		}
+	}
+}
+
+func TestBlackoutContains(t *testing.T) {
+	blackout, err := compileBlackoutWindow(models.BlackoutWindow{
+		DaysOfWeek: "MON", StartTime: "09:00", EndTime: "18:00", TimeZone: "America/New_York",
+	}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		// 09:00 in New York is 14:00 UTC in winter and 13:00 UTC in summer
+		{time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 5, 13, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 1, 5, 22, 59, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 5, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 7, 6, 13, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 7, 6, 22, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 1, 6, 15, 0, 0, 0, time.UTC), false},
+	}
+	for _, tc := range cases {
+		if got := blackout.contains(tc.at); got != tc.want {
+			t.Errorf("contains(%s) = %v, want %v", tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestSkipSpecs(t *testing.T) {
+	hours := func(start, end int) []client.ScheduleRange { return []client.ScheduleRange{{Start: start, End: end}} }
+	minutes := hours
+	cases := []struct {
+		name     string
+		window   models.BlackoutWindow
+		at       time.Time
+		hours    [][]client.ScheduleRange
+		minutes  [][]client.ScheduleRange
+		weekdays []client.ScheduleRange
+	}{
+		{"whole hours", models.BlackoutWindow{DaysOfWeek: "MON,WED", StartTime: "09:00", EndTime: "18:00"}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			[][]client.ScheduleRange{hours(9, 17)}, [][]client.ScheduleRange{minutes(0, 59)}, []client.ScheduleRange{{Start: 1}, {Start: 3}}},
+		{"partial hours", models.BlackoutWindow{DaysOfWeek: "MON", StartTime: "09:30", EndTime: "18:15"}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			[][]client.ScheduleRange{hours(9, 9), hours(10, 17), hours(18, 18)}, [][]client.ScheduleRange{minutes(30, 59), minutes(0, 59), minutes(0, 14)}, []client.ScheduleRange{{Start: 1}}},
+		{"within an hour", models.BlackoutWindow{DaysOfWeek: "MON", StartTime: "09:10", EndTime: "09:20"}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			[][]client.ScheduleRange{hours(9, 9)}, [][]client.ScheduleRange{minutes(10, 19)}, []client.ScheduleRange{{Start: 1}}},
+		{"standard time in another zone", models.BlackoutWindow{DaysOfWeek: "MON", StartTime: "09:00", EndTime: "18:00", TimeZone: "America/New_York"}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			[][]client.ScheduleRange{hours(14, 22)}, [][]client.ScheduleRange{minutes(0, 59)}, []client.ScheduleRange{{Start: 1}}},
+		{"daylight saving time in another zone", models.BlackoutWindow{DaysOfWeek: "MON", StartTime: "09:00", EndTime: "18:00", TimeZone: "America/New_York"}, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+			[][]client.ScheduleRange{hours(13, 21)}, [][]client.ScheduleRange{minutes(0, 59)}, []client.ScheduleRange{{Start: 1}}},
+	}
+	for _, tc := range cases {
+		blackout, err := compileBlackoutWindow(tc.window, time.UTC)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		specs := blackout.skipSpecs(time.UTC, tc.at)
+		if len(specs) != len(tc.hours) {
+			t.Errorf("%s: %d skip specs, want %d", tc.name, len(specs), len(tc.hours))
+			continue
+		}
+		for i, spec := range specs {
+			if !reflect.DeepEqual(spec.Hour, tc.hours[i]) || !reflect.DeepEqual(spec.Minute, tc.minutes[i]) || !reflect.DeepEqual(spec.DayOfWeek, tc.weekdays) {
+				t.Errorf("%s: skip spec %d = hours %v minutes %v days %v, want %v %v %v", tc.name, i, spec.Hour, spec.Minute, spec.DayOfWeek, tc.hours[i], tc.minutes[i], tc.weekdays)
+			}
+		}
+	}
+
+	// the window ends past midnight in the schedule time zone
+	blackout, err := compileBlackoutWindow(models.BlackoutWindow{DaysOfWeek: "SAT", StartTime: "20:00", EndTime: "23:00", TimeZone: "Asia/Kolkata"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := blackout.skipSpecs(tokyo, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(specs) != 3 || !reflect.DeepEqual(specs[0].DayOfWeek, []client.ScheduleRange{{Start: 0}}) || !reflect.DeepEqual(specs[0].Hour, hours(0, 1)) ||
+		!reflect.DeepEqual(specs[1].Minute, minutes(0, 29)) || !reflect.DeepEqual(specs[2].DayOfWeek, []client.ScheduleRange{{Start: 6}}) ||
+		!reflect.DeepEqual(specs[2].Hour, hours(23, 23)) || !reflect.DeepEqual(specs[2].Minute, minutes(30, 59)) {
+		t.Errorf("saturday 20:00-23:00 in Kolkata skips %+v, want saturday 23:30 to sunday 02:30 in Tokyo", specs)
+	}
+}
+
+func TestBlackoutShiftChanged(t *testing.T) {
+	job := &models.Job{
+		Frequency:      "1-hours",
+		ScheduleConfig: `{"time_zone":"UTC","blackout_windows":[{"days_of_week":"*","start_time":"01:00","end_time":"02:00","time_zone":"America/New_York"}]}`,
+	}
+	// New York moves to daylight saving time on 2026-03-08 at 07:00 UTC
+	dstStart := time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		job      *models.Job
+		projects []models.BlackoutWindow
+		from, to time.Time
+		want     bool
+	}{
+		{"across the dst change", job, nil, dstStart.Add(-time.Minute), dstStart.Add(time.Hour), true},
+		{"before the dst change", job, nil, dstStart.Add(-2 * time.Hour), dstStart.Add(-time.Minute), false},
+		{"window in the schedule time zone", &models.Job{Frequency: "1-hours", ScheduleConfig: `{"time_zone":"America/New_York"}`},
+			[]models.BlackoutWindow{{DaysOfWeek: "*", StartTime: "01:00", EndTime: "02:00"}}, dstStart.Add(-time.Minute), dstStart.Add(time.Hour), false},
+		{"project window in another time zone", &models.Job{Frequency: "1-hours", ScheduleConfig: `{"time_zone":"Asia/Kolkata"}`},
+			[]models.BlackoutWindow{{DaysOfWeek: "*", StartTime: "01:00", EndTime: "02:00", TimeZone: "America/New_York"}}, dstStart.Add(-time.Minute), dstStart.Add(time.Hour), true},
+		// the week of New York starts on 2026-03-08 at 05:00 UTC
+		{"every 2 weeks entering a new week", &models.Job{Frequency: "2-weeks", ScheduleConfig: `{"time_zone":"America/New_York"}`},
+			nil, dstStart.Add(-150 * time.Minute), dstStart.Add(-90 * time.Minute), true},
+		{"every 2 weeks within a week", &models.Job{Frequency: "2-weeks", ScheduleConfig: `{"time_zone":"America/New_York"}`},
+			nil, dstStart.Add(-time.Minute), dstStart.Add(time.Hour), false},
+		{"weekly entering a new week", &models.Job{Frequency: "1-weeks", ScheduleConfig: `{"time_zone":"America/New_York"}`},
+			nil, dstStart.Add(-150 * time.Minute), dstStart.Add(-90 * time.Minute), false},
+	}
+	for _, tc := range cases {
+		got, err := blackoutShiftChanged(tc.job, tc.projects, tc.from, tc.to)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: blackoutShiftChanged = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestBlackoutAt(t *testing.T) {
+	config := &models.ScheduleConfig{
+		TimeZone:        "UTC",
+		BlackoutWindows: []models.BlackoutWindow{{DaysOfWeek: "MON", StartTime: "09:00", EndTime: "18:00"}},
+	}
+	projectWindows := []models.BlackoutWindow{{DaysOfWeek: "SUN", StartTime: "22:00", EndTime: "02:00", TimeZone: "Asia/Kolkata"}}
+	cases := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC), false},
+		// sunday 22:00 to monday 02:00 in Kolkata is sunday 16:30 to 20:30 UTC
+		{time.Date(2026, 1, 4, 16, 30, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 4, 20, 29, 0, 0, time.UTC), true},
+		{time.Date(2026, 1, 4, 20, 30, 0, 0, time.UTC), false},
+	}
+	for _, tc := range cases {
+		err := blackoutAt("1-hours", config, projectWindows, tc.at)
+		if got := errors.Is(err, ErrBlackoutWindow); got != tc.want {
+			t.Errorf("blackoutAt(%s) = %v, want blacked out %v", tc.at, err, tc.want)
+		}
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-24 * time.Hour)
+	mondayBlackout := []models.BlackoutWindow{{DaysOfWeek: "MON", StartTime: "09:00", EndTime: "18:00"}}
+	mondayAtTen := &models.ScheduleConfig{TimeZone: "UTC", Calendars: []models.CalendarSpec{{Minute: "0", Hour: "10", DayOfWeek: "MON"}}}
+
+	start := time.Now()
+	err := ValidateSchedule("", mondayAtTen, mondayBlackout, now)
+	if !errors.Is(err, ErrScheduleBlackedOut) {
+		t.Errorf("monday 10:00 in a monday 09:00-18:00 blackout = %v, want ErrScheduleBlackedOut", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("rejecting a blacked out schedule took %s", elapsed)
+	}
+	if _, _, err := PreviewSchedule("", mondayAtTen, mondayBlackout, now, 5); !errors.Is(err, ErrScheduleBlackedOut) {
+		t.Errorf("preview of a blacked out schedule = %v, want ErrScheduleBlackedOut", err)
+	}
+
+	if err := ValidateSchedule("", mondayAtTen, []models.BlackoutWindow{{DaysOfWeek: "MON", StartTime: "11:00", EndTime: "18:00"}}, now); err != nil {
+		t.Errorf("monday 10:00 outside of the blackout: %s", err)
+	}
+	if err := ValidateSchedule("0 0 31 2 *", &models.ScheduleConfig{TimeZone: "UTC"}, nil, now); err == nil {
+		t.Errorf("february 31st succeeded, want a schedule that never fires")
+	}
+	if err := ValidateSchedule("1-days", &models.ScheduleConfig{EndAt: &past}, nil, now); err != nil {
+		t.Errorf("schedule that ended: %s", err)
+	}
+	if err := ValidateSchedule("* * * *", nil, nil, now); err == nil {
+		t.Errorf("invalid cron succeeded, want an error")
+	}
+}
@@ -2,24 +2,45 @@ package temporal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/beego/beego/v2/server/web"
 	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
 	"github.com/datazip/olake-frontend/server/utils"
 	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflow/v1"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"google.golang.org/grpc"
 )
 
 // TaskQueue is the default task queue for Olake Docker workflows
 const TaskQueue = "OLAKE_DOCKER_TASK_QUEUE"
 
+// JobWorkflowIDPrefix prefixes the sync workflow ID of a job to get the ID of its job workflow
+const JobWorkflowIDPrefix = "dag-"
+
+// ScheduleIDPrefix prefixes the IDs of job schedules, which are schedule- and the sync workflow ID of the job
+const ScheduleIDPrefix = "schedule-sync-"
+
 var (
 	TemporalAddress string
 )
 
+var (
+	// ErrScheduleNotFound is returned by ManageSync when the schedule of a job does not exist
+	ErrScheduleNotFound = errors.New("schedule does not exist")
+	// ErrScheduleExists is returned by ManageSync when creating a schedule that exists already
+	ErrScheduleExists = errors.New("schedule already exists")
+	// ErrBackfillStarted is returned by StartBackfill when a backfill run with the same ID exists already
+	ErrBackfillStarted = errors.New("backfill already started")
+)
+
 // SyncAction represents the type of action to perform
 type SyncAction string
 
@@ -28,8 +49,10 @@ const (
 	ActionUpdate  SyncAction = "update"
 	ActionDelete  SyncAction = "delete"
 	ActionTrigger SyncAction = "trigger"
-	ActionPause   SyncAction = "pause"
-	ActionUnpause SyncAction = "unpause"
+	// ActionForceTrigger triggers a sync even inside a blackout window
+	ActionForceTrigger SyncAction = "force-trigger"
+	ActionPause        SyncAction = "pause"
+	ActionUnpause      SyncAction = "unpause"
 )
 
 func init() {
@@ -41,11 +64,21 @@ type Client struct {
 	temporalClient client.Client
 }
 
+// clientOptions are the options of the Temporal clients of the server and the
+// worker, workers created from a client share its interceptors
+func clientOptions() client.Options {
+	return client.Options{
+		HostPort:     TemporalAddress,
+		Interceptors: []interceptor.ClientInterceptor{newTracingInterceptor()},
+		ConnectionOptions: client.ConnectionOptions{
+			DialOptions: []grpc.DialOption{grpc.WithChainUnaryInterceptor(traceCalls, countErrors)},
+		},
+	}
+}
+
 // NewClient creates a new Temporal client
 func NewClient() (*Client, error) {
-	c, err := client.Dial(client.Options{
-		HostPort: TemporalAddress,
-	})
+	c, err := client.Dial(clientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Temporal client: %v", err)
 	}
@@ -91,6 +124,36 @@ func (c *Client) GetCatalog(ctx context.Context, sourceType, version, config, st
 	return result, nil
 }
 
+// PreviewSync runs a workflow reading the selected streams of a streams config
+// into a throwaway local sink and waits for the sampled rows
+func (c *Client) PreviewSync(ctx context.Context, sourceType, version, config, streamsConfig string, rowLimit, sampleSize int) (*models.SyncPreviewResponse, error) {
+	params := PreviewParams{
+		SourceType:    sourceType,
+		Version:       version,
+		Config:        config,
+		StreamsConfig: streamsConfig,
+		RowLimit:      rowLimit,
+		SampleSize:    sampleSize,
+		WorkflowID:    fmt.Sprintf("preview-sync-%s-%d", sourceType, time.Now().UnixNano()),
+	}
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        params.WorkflowID,
+		TaskQueue: TaskQueue,
+	}
+
+	run, err := c.temporalClient.ExecuteWorkflow(ctx, workflowOptions, PreviewWorkflow, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute preview workflow: %v", err)
+	}
+
+	var result models.SyncPreviewResponse
+	if err := run.Get(ctx, &result); err != nil {
+		return nil, fmt.Errorf("workflow execution failed: %v", err)
+	}
+	return &result, nil
+}
+
 // TestConnection runs a workflow to test connection
 func (c *Client) TestConnection(ctx context.Context, flag, sourceType, version, config string) (map[string]interface{}, error) {
 	params := &ActivityParams{
@@ -121,31 +184,35 @@ func (c *Client) TestConnection(ctx context.Context, flag, sourceType, version,
 }
 
 // ManageSync handles all sync operations (create, update, delete, trigger)
-func (c *Client) ManageSync(ctx context.Context, projectID string, jobID int, frequency string, action SyncAction) (map[string]interface{}, error) {
-	workflowID := fmt.Sprintf("sync-%s-%d", projectID, jobID)
-	scheduleID := fmt.Sprintf("schedule-%s", workflowID)
+func (c *Client) ManageSync(ctx context.Context, job *models.Job, action SyncAction) (map[string]interface{}, error) {
+	scheduleID := ScheduleID(job.ProjectID, job.ID)
 
 	handle := c.temporalClient.ScheduleClient().GetHandle(ctx, scheduleID)
-	currentSchedule, err := handle.Describe(ctx)
+	_, err := handle.Describe(ctx)
+	if _, notFound := err.(*serviceerror.NotFound); err != nil && !notFound {
+		return nil, fmt.Errorf("failed to describe schedule: %s", err)
+	}
 	scheduleExists := err == nil
 	if action != ActionCreate && !scheduleExists {
-		return nil, fmt.Errorf("schedule does not exist")
+		return nil, ErrScheduleNotFound
 	}
 	switch action {
 	case ActionCreate:
-		if frequency == "" {
-			return nil, fmt.Errorf("frequency is required for creating schedule")
-		}
 		if scheduleExists {
-			return nil, fmt.Errorf("schedule already exists")
+			return nil, ErrScheduleExists
 		}
-		return c.createSchedule(ctx, handle, scheduleID, workflowID, frequency, jobID)
+		spec, err := buildJobScheduleSpec(job)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule for creating schedule: %s", err)
+		}
+		return c.createSchedule(ctx, handle, scheduleID, spec, job)
 
 	case ActionUpdate:
-		if frequency == "" {
-			return nil, fmt.Errorf("frequency is required for updating schedule")
+		spec, err := buildJobScheduleSpec(job)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule for updating schedule: %s", err)
 		}
-		return c.updateSchedule(ctx, handle, currentSchedule, scheduleID, frequency)
+		return c.updateSchedule(ctx, handle, spec, job)
 
 	case ActionDelete:
 		if err := handle.Delete(ctx); err != nil {
@@ -153,7 +220,21 @@ func (c *Client) ManageSync(ctx context.Context, projectID string, jobID int, fr
 		}
 		return map[string]interface{}{"message": "Schedule deleted successfully"}, nil
 
+	case ActionForceTrigger:
+		// a schedule trigger can not tell the job workflow to ignore the blackout windows
+		_, err := c.temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+			ID:        fmt.Sprintf("%s-%s", JobWorkflowID(job.ProjectID, job.ID), time.Now().UTC().Format(time.RFC3339)),
+			TaskQueue: TaskQueue,
+		}, RunJobWorkflow, JobWorkflowParams{JobID: job.ID, ProjectID: job.ProjectID, OverrideBlackout: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start forced run: %s", err)
+		}
+		return map[string]interface{}{"message": "Schedule triggered successfully"}, nil
+
 	case ActionTrigger:
+		if err := checkBlackoutWindows(job, time.Now()); err != nil {
+			return nil, err
+		}
 		if err := handle.Trigger(ctx, client.ScheduleTriggerOptions{
 			Overlap: enums.SCHEDULE_OVERLAP_POLICY_SKIP,
 		}); err != nil {
@@ -181,22 +262,88 @@ func (c *Client) ManageSync(ctx context.Context, projectID string, jobID int, fr
 	}
 }
 
-// createSchedule creates a new schedule
-func (c *Client) createSchedule(ctx context.Context, _ client.ScheduleHandle, scheduleID, workflowID, frequency string, jobID int) (map[string]interface{}, error) {
-	cronSpec := utils.ToCron(frequency)
+// buildJobScheduleSpec builds the Temporal schedule spec from the job frequency and schedule config
+func buildJobScheduleSpec(job *models.Job) (*client.ScheduleSpec, error) {
+	config, err := ParseScheduleConfig(job.ScheduleConfig)
+	if err != nil {
+		return nil, err
+	}
+	projectWindows, err := projectBlackoutWindows(job.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return BuildScheduleSpec(job.Frequency, config, projectWindows)
+}
 
-	_, err := c.temporalClient.ScheduleClient().Create(ctx, client.ScheduleOptions{
-		ID: scheduleID,
-		Spec: client.ScheduleSpec{
-			CronExpressions: []string{cronSpec},
-		},
-		Action: &client.ScheduleWorkflowAction{
-			ID:        workflowID,
-			Workflow:  RunSyncWorkflow,
-			Args:      []any{jobID},
-			TaskQueue: TaskQueue,
+// checkBlackoutWindows returns ErrBlackoutWindow if t falls inside a blackout window of the job or its project
+func checkBlackoutWindows(job *models.Job, t time.Time) error {
+	config, err := ParseScheduleConfig(job.ScheduleConfig)
+	if err != nil {
+		return err
+	}
+	projectWindows, err := LoadProjectBlackoutWindows(job.ProjectID)
+	if err != nil {
+		return err
+	}
+	return blackoutAt(job.Frequency, config, projectWindows, t)
+}
+
+// blackoutAt returns ErrBlackoutWindow if t falls inside a blackout window of a schedule or its project
+func blackoutAt(frequency string, config *models.ScheduleConfig, projectWindows []models.BlackoutWindow, t time.Time) error {
+	schedule, err := compileSchedule(frequency, config, projectWindows)
+	if err != nil {
+		return err
+	}
+	if blackout := schedule.activeBlackout(t); blackout != nil {
+		return fmt.Errorf("%w: %s %s-%s %s", ErrBlackoutWindow, blackout.window.DaysOfWeek, blackout.window.StartTime, blackout.window.EndTime, blackout.location)
+	}
+	return nil
+}
+
+// SyncWorkflowID returns the workflow ID prefix of the sync runs of a job
+func SyncWorkflowID(projectID string, jobID int) string {
+	return fmt.Sprintf("sync-%s-%d", projectID, jobID)
+}
+
+// BackfillWorkflowID returns the workflow ID prefix of the backfill runs of a job
+func BackfillWorkflowID(projectID string, jobID int) string {
+	return fmt.Sprintf("backfill-%s-%d", projectID, jobID)
+}
+
+// JobWorkflowID returns the workflow ID prefix of the job workflow runs of a job
+func JobWorkflowID(projectID string, jobID int) string {
+	return JobWorkflowIDPrefix + SyncWorkflowID(projectID, jobID)
+}
+
+// ScheduleID returns the ID of the schedule of a job
+func ScheduleID(projectID string, jobID int) string {
+	return "schedule-" + SyncWorkflowID(projectID, jobID)
+}
+
+// jobScheduleAction starts the job workflow, which runs the sync as a child
+// workflow. Its memo records what the spec was built from for the drift check,
+// the memo of the schedule itself can not be updated.
+func jobScheduleAction(job *models.Job, spec *client.ScheduleSpec) *client.ScheduleWorkflowAction {
+	return &client.ScheduleWorkflowAction{
+		ID:        JobWorkflowID(job.ProjectID, job.ID),
+		Workflow:  RunJobWorkflow,
+		Args:      []any{JobWorkflowParams{JobID: job.ID, ProjectID: job.ProjectID}},
+		TaskQueue: TaskQueue,
+		Memo: map[string]interface{}{
+			scheduleMemoFrequency:   job.Frequency,
+			scheduleMemoFingerprint: scheduleFingerprint(spec),
 		},
+	}
+}
+
+// createSchedule creates a new schedule
+func (c *Client) createSchedule(ctx context.Context, _ client.ScheduleHandle, scheduleID string, spec *client.ScheduleSpec, job *models.Job) (map[string]interface{}, error) {
+	_, err := c.temporalClient.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID:      scheduleID,
+		Spec:    *spec,
+		Action:  jobScheduleAction(job, spec),
 		Overlap: enums.SCHEDULE_OVERLAP_POLICY_SKIP,
+		Paused:  !job.Active,
 	})
 
 	if err != nil {
@@ -205,25 +352,17 @@ func (c *Client) createSchedule(ctx context.Context, _ client.ScheduleHandle, sc
 
 	return map[string]interface{}{
 		"message": "Schedule created successfully",
-		"cron":    cronSpec,
+		"cron":    spec.CronExpressions,
 	}, nil
 }
 
-// updateSchedule updates an existing schedule
-func (c *Client) updateSchedule(ctx context.Context, handle client.ScheduleHandle, currentSchedule *client.ScheduleDescription, _, frequency string) (map[string]interface{}, error) {
-	cronSpec := utils.ToCron(frequency)
-
-	// Check if update is needed
-	if len(currentSchedule.Schedule.Spec.CronExpressions) > 0 &&
-		currentSchedule.Schedule.Spec.CronExpressions[0] == cronSpec {
-		return map[string]interface{}{"message": "Schedule already up to date"}, nil
-	}
-
+// updateSchedule replaces the spec and action of an existing schedule, which
+// also moves schedules created before job workflows onto the job workflow
+func (c *Client) updateSchedule(ctx context.Context, handle client.ScheduleHandle, spec *client.ScheduleSpec, job *models.Job) (map[string]interface{}, error) {
 	err := handle.Update(ctx, client.ScheduleUpdateOptions{
 		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
-			input.Description.Schedule.Spec = &client.ScheduleSpec{
-				CronExpressions: []string{cronSpec},
-			}
+			input.Description.Schedule.Spec = spec
+			input.Description.Schedule.Action = jobScheduleAction(job, spec)
 			return &client.ScheduleUpdate{
 				Schedule: &input.Description.Schedule,
 			}, nil
@@ -235,10 +374,47 @@ func (c *Client) updateSchedule(ctx context.Context, handle client.ScheduleHandl
 	}
 	return map[string]interface{}{
 		"message": "Schedule updated successfully",
-		"cron":    cronSpec,
+		"cron":    spec.CronExpressions,
 	}, nil
 }
 
+// StartBackfill starts a backfill run of some streams of a job and returns its
+// workflow ID, it returns ErrBlackoutWindow inside a blackout window of the job
+// unless overrideBlackout is set
+func (c *Client) StartBackfill(ctx context.Context, job *models.Job, streams []string, filter string, overrideBlackout bool) (string, error) {
+	if !overrideBlackout {
+		if err := checkBlackoutWindows(job, time.Now()); err != nil {
+			return "", err
+		}
+	}
+	params := BackfillParams{
+		JobID:      job.ID,
+		ProjectID:  job.ProjectID,
+		Streams:    streams,
+		Filter:     filter,
+		WorkflowID: backfillRunID(job.ProjectID, job.ID),
+	}
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        params.WorkflowID,
+		TaskQueue: TaskQueue,
+	}
+	_, err := c.temporalClient.ExecuteWorkflow(ctx, workflowOptions, BackfillWorkflow, params)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		return "", fmt.Errorf("%w: %s", ErrBackfillStarted, params.WorkflowID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to start backfill workflow: %v", err)
+	}
+	return params.WorkflowID, nil
+}
+
+// backfillRunID returns the workflow ID of a new backfill run, the ULID keeps the
+// IDs of backfills started within the same second apart and sorts them by start time
+func backfillRunID(projectID string, jobID int) string {
+	return fmt.Sprintf("%s-%s", BackfillWorkflowID(projectID, jobID), utils.ULID())
+}
+
 // ListWorkflow lists workflow executions based on the provided query
 func (c *Client) ListWorkflow(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (*workflowservice.ListWorkflowExecutionsResponse, error) {
 	// Query workflows using the SDK's ListWorkflow method
@@ -249,3 +425,53 @@ func (c *Client) ListWorkflow(ctx context.Context, request *workflowservice.List
 
 	return resp, nil
 }
+
+// GetLatestSyncRun returns the most recent sync run of a job, or nil if it never ran
+func (c *Client) GetLatestSyncRun(ctx context.Context, projectID string, jobID int) (*workflow.WorkflowExecutionInfo, error) {
+	return latestSyncExecution(ctx, c.temporalClient, projectID, jobID)
+}
+
+func latestSyncExecution(ctx context.Context, temporalClient client.Client, projectID string, jobID int) (*workflow.WorkflowExecutionInfo, error) {
+	workflowID := SyncWorkflowID(projectID, jobID)
+	resp, err := temporalClient.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Query:    fmt.Sprintf("WorkflowId between '%s' and '%s-~'", workflowID, workflowID),
+		PageSize: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing sync runs of job[%d]: %v", jobID, err)
+	}
+	if len(resp.Executions) == 0 {
+		return nil, nil
+	}
+	return resp.Executions[0], nil
+}
+
+// IsSyncRunning reports whether a sync of the job is running
+func (c *Client) IsSyncRunning(ctx context.Context, projectID string, jobID int) (bool, error) {
+	workflowID := SyncWorkflowID(projectID, jobID)
+	resp, err := c.temporalClient.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		Query:    fmt.Sprintf("WorkflowId between '%s' and '%s-~' AND ExecutionStatus = 'Running'", workflowID, workflowID),
+		PageSize: 1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error listing running syncs of job[%d]: %v", jobID, err)
+	}
+	return len(resp.Executions) > 0, nil
+}
+
+// GetConcurrencyState returns the running and queued syncs of the concurrency workflow
+func (c *Client) GetConcurrencyState(ctx context.Context) (*ConcurrencyState, error) {
+	state := &ConcurrencyState{}
+	resp, err := c.temporalClient.QueryWorkflow(ctx, ConcurrencyWorkflowID, "", QueryConcurrencyState)
+	if _, notFound := err.(*serviceerror.NotFound); notFound {
+		// the workflow starts with the first limited sync
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query concurrency state: %s", err)
+	}
+	if err := resp.Get(state); err != nil {
+		return nil, fmt.Errorf("failed to decode concurrency state: %s", err)
+	}
+	return state, nil
+}
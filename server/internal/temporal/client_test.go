@@ -0,0 +1,57 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// fakeStarter is a Temporal client recording the workflows it starts, starts fail with err while it is set
+type fakeStarter struct {
+	client.Client
+	started []string
+	err     error
+}
+
+func (f *fakeStarter) ExecuteWorkflow(_ context.Context, options client.StartWorkflowOptions, _ interface{}, _ ...interface{}) (client.WorkflowRun, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.started = append(f.started, options.ID)
+	return nil, nil
+}
+
+func TestStartBackfill(t *testing.T) {
+	fake := &fakeStarter{}
+	c := &Client{temporalClient: fake}
+	job := &models.Job{ID: 7, ProjectID: "p-1", Frequency: "1-days"}
+
+	// backfills started within the same second do not collide
+	for i := 0; i < 3; i++ {
+		workflowID, err := c.StartBackfill(context.Background(), job, []string{"orders"}, "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(workflowID, BackfillWorkflowID("p-1", 7)+"-") {
+			t.Errorf("backfill started as %s, want an ID under %s", workflowID, BackfillWorkflowID("p-1", 7))
+		}
+	}
+	if fake.started[0] == fake.started[1] || fake.started[1] == fake.started[2] {
+		t.Errorf("backfills started as %v, want distinct IDs", fake.started)
+	}
+
+	fake.err = serviceerror.NewWorkflowExecutionAlreadyStarted("workflow execution already started", "", "")
+	if _, err := c.StartBackfill(context.Background(), job, []string{"orders"}, "", true); !errors.Is(err, ErrBackfillStarted) {
+		t.Errorf("backfill with a started ID fails with %v, want ErrBackfillStarted", err)
+	}
+	fake.err = errors.New("temporal is unavailable")
+	if _, err := c.StartBackfill(context.Background(), job, []string{"orders"}, "", true); err == nil || errors.Is(err, ErrBackfillStarted) {
+		t.Errorf("backfill while Temporal fails returns %v", err)
+	}
+}
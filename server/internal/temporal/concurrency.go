@@ -0,0 +1,365 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+)
+
+const (
+	// ConcurrencyWorkflowID is the ID of the single workflow handing out sync slots
+	ConcurrencyWorkflowID = "olake-sync-concurrency"
+	// SignalAcquireSyncSlot queues a ConcurrencyRequest
+	SignalAcquireSyncSlot = "acquire-sync-slot"
+	// SignalReleaseSyncSlot frees the slots of a workflow ID, job workflows started
+	// before syncSlotRunChange release their slot with it
+	SignalReleaseSyncSlot = "release-sync-slot"
+	// SignalReleaseSyncSlotRun frees the slot of the run of a ConcurrencyRequest
+	SignalReleaseSyncSlotRun = "release-sync-slot-run"
+	// SignalSyncSlotGranted is sent to the requesting workflow once its slot is granted
+	SignalSyncSlotGranted = "sync-slot-granted"
+	// QueryConcurrencyState returns the ConcurrencyState of the concurrency workflow
+	QueryConcurrencyState = "state"
+	// ConcurrencyHolderCheckInterval is how often slots of closed workflows are reclaimed
+	ConcurrencyHolderCheckInterval = time.Minute * 5
+	// concurrencyContinueAsNewEvents keeps the history of the concurrency workflow short
+	concurrencyContinueAsNewEvents = 500
+	// syncSlotRunChange keys holders and requests on the workflow and run ID, a
+	// run reusing the workflow ID of a stale holder keeps its own slot
+	syncSlotRunChange = "sync-slot-run"
+)
+
+var (
+	// MaxConcurrentSyncs caps the syncs running at once across all workers, 0 means unlimited
+	MaxConcurrentSyncs int
+)
+
+func init() {
+	MaxConcurrentSyncs = web.AppConfig.DefaultInt("MAX_CONCURRENT_SYNCS", 0)
+}
+
+// ConcurrencyScope is a resource with a limit on the syncs using it at once, e.g. "source:4"
+type ConcurrencyScope struct {
+	Key   string
+	Limit int
+}
+
+// ConcurrencyRequest asks for a sync slot in every scope at once
+type ConcurrencyRequest struct {
+	WorkflowID  string
+	RunID       string
+	ProjectID   string
+	JobID       int
+	Scopes      []ConcurrencyScope
+	RequestedAt time.Time
+	GrantedAt   time.Time
+}
+
+// ConcurrencyState is carried across continue-as-new of the concurrency workflow
+type ConcurrencyState struct {
+	Holders []ConcurrencyRequest
+	Queue   []ConcurrencyRequest
+}
+
+// SyncConcurrencyWorkflow is a semaphore over all concurrency scopes. A request
+// is granted only when every one of its scopes has a free slot, so a sync never
+// holds a slot while waiting for another one. Waiting requests are granted in
+// order, a request that does not fit yet does not block later requests of other scopes.
+func SyncConcurrencyWorkflow(ctx workflow.Context, state ConcurrencyState) error {
+	logger := workflow.GetLogger(ctx)
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	if err := workflow.SetQueryHandler(ctx, QueryConcurrencyState, func() (ConcurrencyState, error) {
+		return state, nil
+	}); err != nil {
+		return err
+	}
+
+	byRun := workflow.GetVersion(ctx, syncSlotRunChange, workflow.DefaultVersion, 1) == 1
+	acquireCh := workflow.GetSignalChannel(ctx, SignalAcquireSyncSlot)
+	releaseCh := workflow.GetSignalChannel(ctx, SignalReleaseSyncSlot)
+	releaseRunCh := workflow.GetSignalChannel(ctx, SignalReleaseSyncSlotRun)
+	acquire := func(request ConcurrencyRequest) {
+		// a retried acquire activity may deliver the same request twice
+		if state.contains(request) {
+			return
+		}
+		state.Queue = append(state.Queue, request)
+	}
+	// release frees the slots of a run, or of every run of the workflow ID when runID is ""
+	release := func(workflowID, runID string) {
+		state.Holders = removeConcurrencyRequest(state.Holders, workflowID, runID)
+		state.Queue = removeConcurrencyRequest(state.Queue, workflowID, runID)
+	}
+
+	var checkTimer workflow.Future
+	for events := 0; ; events++ {
+		state.grant(ctx, logger)
+
+		if events >= concurrencyContinueAsNewEvents {
+			// keep signals that arrived meanwhile
+			var request ConcurrencyRequest
+			for acquireCh.ReceiveAsync(&request) {
+				acquire(request)
+			}
+			var workflowID string
+			for releaseCh.ReceiveAsync(&workflowID) {
+				release(workflowID, "")
+			}
+			for releaseRunCh.ReceiveAsync(&request) {
+				release(request.WorkflowID, request.RunID)
+			}
+			return workflow.NewContinueAsNewError(ctx, SyncConcurrencyWorkflow, state)
+		}
+
+		if checkTimer == nil && len(state.Holders) > 0 {
+			checkTimer = workflow.NewTimer(ctx, ConcurrencyHolderCheckInterval)
+		}
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(acquireCh, func(c workflow.ReceiveChannel, _ bool) {
+			var request ConcurrencyRequest
+			c.Receive(ctx, &request)
+			acquire(request)
+		})
+		selector.AddReceive(releaseCh, func(c workflow.ReceiveChannel, _ bool) {
+			var workflowID string
+			c.Receive(ctx, &workflowID)
+			release(workflowID, "")
+		})
+		selector.AddReceive(releaseRunCh, func(c workflow.ReceiveChannel, _ bool) {
+			var request ConcurrencyRequest
+			c.Receive(ctx, &request)
+			release(request.WorkflowID, request.RunID)
+		})
+		if checkTimer != nil {
+			selector.AddFuture(checkTimer, func(workflow.Future) {
+				checkTimer = nil
+				// reclaim slots of syncs that closed without releasing them
+				if !byRun {
+					var closed []string
+					if err := workflow.ExecuteActivity(ctx, GetClosedWorkflowsActivity, state.Holders).Get(ctx, &closed); err != nil {
+						logger.Error("Failed to check sync slot holders", "error", err)
+						return
+					}
+					for _, workflowID := range closed {
+						logger.Warn("Reclaiming sync slot of closed workflow", "workflowId", workflowID)
+						release(workflowID, "")
+					}
+					return
+				}
+				var closed []ConcurrencyRequest
+				if err := workflow.ExecuteActivity(ctx, GetClosedSyncSlotsActivity, state.Holders).Get(ctx, &closed); err != nil {
+					logger.Error("Failed to check sync slot holders", "error", err)
+					return
+				}
+				for _, holder := range closed {
+					logger.Warn("Reclaiming sync slot of closed workflow", "workflowId", holder.WorkflowID, "runId", holder.RunID)
+					release(holder.WorkflowID, holder.RunID)
+				}
+			})
+		}
+		selector.Select(ctx)
+	}
+}
+
+// grant hands out slots to waiting requests in order while their scopes have capacity
+func (s *ConcurrencyState) grant(ctx workflow.Context, logger log.Logger) {
+	used := map[string]int{}
+	for _, holder := range s.Holders {
+		for _, scope := range holder.Scopes {
+			used[scope.Key]++
+		}
+	}
+
+	var waiting []ConcurrencyRequest
+	for _, request := range s.Queue {
+		fits := true
+		for _, scope := range request.Scopes {
+			if used[scope.Key] >= scope.Limit {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			waiting = append(waiting, request)
+			continue
+		}
+
+		err := workflow.SignalExternalWorkflow(ctx, request.WorkflowID, request.RunID, SignalSyncSlotGranted, nil).Get(ctx, nil)
+		if err != nil {
+			// the requesting workflow is gone, drop its request
+			logger.Warn("Failed to grant sync slot", "workflowId", request.WorkflowID, "error", err)
+			continue
+		}
+		request.GrantedAt = workflow.Now(ctx)
+		s.Holders = append(s.Holders, request)
+		for _, scope := range request.Scopes {
+			used[scope.Key]++
+		}
+	}
+	s.Queue = waiting
+}
+
+// contains reports whether the request is already holding or waiting for a slot
+func (s *ConcurrencyState) contains(request ConcurrencyRequest) bool {
+	for _, requests := range [][]ConcurrencyRequest{s.Holders, s.Queue} {
+		for _, existing := range requests {
+			if existing.WorkflowID == request.WorkflowID && existing.RunID == request.RunID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeConcurrencyRequest removes the requests of a run, or of every run of
+// the workflow ID when runID is ""
+func removeConcurrencyRequest(requests []ConcurrencyRequest, workflowID, runID string) []ConcurrencyRequest {
+	remaining := requests[:0]
+	for _, request := range requests {
+		if request.WorkflowID != workflowID || (runID != "" && request.RunID != runID) {
+			remaining = append(remaining, request)
+		}
+	}
+	return remaining
+}
+
+// acquireSyncSlot blocks until the job may start a sync and returns the function releasing the slot
+func acquireSyncSlot(ctx workflow.Context, params JobWorkflowParams) (func(), error) {
+	jobID := params.JobID
+	var scopes []ConcurrencyScope
+	if err := workflow.ExecuteActivity(ctx, GetConcurrencyScopesActivity, jobID).Get(ctx, &scopes); err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		return func() {}, nil
+	}
+
+	info := workflow.GetInfo(ctx)
+	request := ConcurrencyRequest{
+		WorkflowID:  info.WorkflowExecution.ID,
+		RunID:       info.WorkflowExecution.RunID,
+		ProjectID:   params.ProjectID,
+		JobID:       jobID,
+		Scopes:      scopes,
+		RequestedAt: workflow.Now(ctx),
+	}
+	if err := workflow.ExecuteActivity(ctx, RequestSyncSlotActivity, request).Get(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	workflow.GetLogger(ctx).Info("Waiting for a sync slot", "jobId", jobID, "scopes", scopes)
+	workflow.GetSignalChannel(ctx, SignalSyncSlotGranted).Receive(ctx, nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		// release the slot even when the job workflow is being canceled
+		releaseCtx, _ := workflow.NewDisconnectedContext(ctx)
+		var err error
+		if workflow.GetVersion(releaseCtx, syncSlotRunChange, workflow.DefaultVersion, 1) == 1 {
+			err = workflow.SignalExternalWorkflow(releaseCtx, ConcurrencyWorkflowID, "", SignalReleaseSyncSlotRun, ConcurrencyRequest{WorkflowID: request.WorkflowID, RunID: request.RunID}).Get(releaseCtx, nil)
+		} else {
+			err = workflow.SignalExternalWorkflow(releaseCtx, ConcurrencyWorkflowID, "", SignalReleaseSyncSlot, request.WorkflowID).Get(releaseCtx, nil)
+		}
+		if err != nil {
+			workflow.GetLogger(ctx).Error("Failed to release sync slot", "jobId", jobID, "error", err)
+		}
+	}, nil
+}
+
+// GetConcurrencyScopesActivity returns the limited scopes a sync of the job runs in,
+// always in the same order: source, destination, project, global
+func GetConcurrencyScopesActivity(_ context.Context, jobID int) ([]ConcurrencyScope, error) {
+	job, err := database.NewJobORM().GetByID(jobID, false)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := database.NewProjectSettingsORM().GetByProjectID(job.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scopes []ConcurrencyScope
+	add := func(key string, limit int) {
+		if limit > 0 {
+			scopes = append(scopes, ConcurrencyScope{Key: key, Limit: limit})
+		}
+	}
+	if job.SourceID != nil {
+		add(fmt.Sprintf("source:%d", job.SourceID.ID), job.SourceID.MaxConcurrentSyncs)
+	}
+	if job.DestID != nil {
+		add(fmt.Sprintf("destination:%d", job.DestID.ID), job.DestID.MaxConcurrentSyncs)
+	}
+	add(fmt.Sprintf("project:%s", job.ProjectID), settings.MaxConcurrentSyncs)
+	add("global", MaxConcurrentSyncs)
+	return scopes, nil
+}
+
+// RequestSyncSlotActivity queues a request on the concurrency workflow, starting it if needed
+func RequestSyncSlotActivity(ctx context.Context, request ConcurrencyRequest) error {
+	_, err := activity.GetClient(ctx).SignalWithStartWorkflow(ctx, ConcurrencyWorkflowID, SignalAcquireSyncSlot, request,
+		client.StartWorkflowOptions{
+			ID:        ConcurrencyWorkflowID,
+			TaskQueue: TaskQueue,
+		}, SyncConcurrencyWorkflow, ConcurrencyState{})
+	if err != nil {
+		return fmt.Errorf("failed to request sync slot: %s", err)
+	}
+	return nil
+}
+
+// GetClosedSyncSlotsActivity returns the slot holders whose run is no longer running
+func GetClosedSyncSlotsActivity(ctx context.Context, holders []ConcurrencyRequest) ([]ConcurrencyRequest, error) {
+	temporalClient := activity.GetClient(ctx)
+	var closed []ConcurrencyRequest
+	for _, holder := range holders {
+		resp, err := temporalClient.DescribeWorkflowExecution(ctx, holder.WorkflowID, holder.RunID)
+		if _, notFound := err.(*serviceerror.NotFound); notFound {
+			closed = append(closed, ConcurrencyRequest{WorkflowID: holder.WorkflowID, RunID: holder.RunID})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.WorkflowExecutionInfo.Status != enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+			closed = append(closed, ConcurrencyRequest{WorkflowID: holder.WorkflowID, RunID: holder.RunID})
+		}
+	}
+	return closed, nil
+}
+
+// GetClosedWorkflowsActivity returns the workflow IDs of the slot holders that
+// are no longer running, for concurrency workflows started before syncSlotRunChange
+func GetClosedWorkflowsActivity(ctx context.Context, holders []ConcurrencyRequest) ([]string, error) {
+	temporalClient := activity.GetClient(ctx)
+	var closed []string
+	for _, holder := range holders {
+		resp, err := temporalClient.DescribeWorkflowExecution(ctx, holder.WorkflowID, holder.RunID)
+		if _, notFound := err.(*serviceerror.NotFound); notFound {
+			closed = append(closed, holder.WorkflowID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.WorkflowExecutionInfo.Status != enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+			closed = append(closed, holder.WorkflowID)
+		}
+	}
+	return closed, nil
+}
@@ -0,0 +1,223 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// slotRequest asks for a slot of a run in every scope
+func slotRequest(workflowID, runID string, scopes ...ConcurrencyScope) ConcurrencyRequest {
+	return ConcurrencyRequest{WorkflowID: workflowID, RunID: runID, Scopes: scopes}
+}
+
+// slotKeys returns the "workflowID/runID" of the requests in order
+func slotKeys(requests []ConcurrencyRequest) []string {
+	keys := []string{}
+	for _, request := range requests {
+		keys = append(keys, request.WorkflowID+"/"+request.RunID)
+	}
+	return keys
+}
+
+// concurrencyEnv runs the concurrency workflow recording the runs granted a slot in order
+type concurrencyEnv struct {
+	*testsuite.TestWorkflowEnvironment
+	t       *testing.T
+	granted []string
+}
+
+func newConcurrencyEnv(t *testing.T) *concurrencyEnv {
+	var suite testsuite.WorkflowTestSuite
+	env := &concurrencyEnv{TestWorkflowEnvironment: suite.NewTestWorkflowEnvironment(), t: t}
+	env.OnSignalExternalWorkflow(mock.Anything, mock.Anything, mock.Anything, SignalSyncSlotGranted, mock.Anything).
+		Return(nil).Run(func(args mock.Arguments) {
+		env.granted = append(env.granted, args.String(1)+"/"+args.String(2))
+	})
+	return env
+}
+
+// at runs fn once the workflow handled everything sent before
+func (env *concurrencyEnv) at(minute int, fn func()) {
+	env.RegisterDelayedCallback(fn, time.Duration(minute)*time.Minute+time.Second)
+}
+
+// state queries the state of the running workflow
+func (env *concurrencyEnv) state() ConcurrencyState {
+	env.t.Helper()
+	value, err := env.QueryWorkflow(QueryConcurrencyState)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	var state ConcurrencyState
+	if err := value.Get(&state); err != nil {
+		env.t.Fatal(err)
+	}
+	return state
+}
+
+// expect checks the holders and the queue of the running workflow
+func (env *concurrencyEnv) expect(step string, holders, queue []string) {
+	env.t.Helper()
+	state := env.state()
+	if got := slotKeys(state.Holders); !reflect.DeepEqual(got, holders) {
+		env.t.Errorf("%s: holders = %v, want %v", step, got, holders)
+	}
+	if got := slotKeys(state.Queue); !reflect.DeepEqual(got, queue) {
+		env.t.Errorf("%s: queue = %v, want %v", step, got, queue)
+	}
+}
+
+// run executes the workflow until it continues as new and returns the state it carries
+func (env *concurrencyEnv) run(state ConcurrencyState) ConcurrencyState {
+	env.t.Helper()
+	env.ExecuteWorkflow(SyncConcurrencyWorkflow, state)
+	var continued *workflow.ContinueAsNewError
+	if err := env.GetWorkflowError(); !errors.As(err, &continued) {
+		env.t.Fatalf("workflow ended with %v, want it to continue as new", err)
+	}
+	var carried ConcurrencyState
+	if err := converter.GetDefaultDataConverter().FromPayloads(continued.Input, &carried); err != nil {
+		env.t.Fatal(err)
+	}
+	return carried
+}
+
+// the workflow continues as new once enough holder checks found every holder running
+func noneClosed(env *concurrencyEnv) {
+	env.OnActivity(GetClosedSyncSlotsActivity, mock.Anything, mock.Anything).Return([]ConcurrencyRequest{}, nil)
+}
+
+func TestSyncConcurrencyWorkflowQueue(t *testing.T) {
+	source := ConcurrencyScope{Key: "source:1", Limit: 1}
+	global := ConcurrencyScope{Key: "global", Limit: 2}
+	env := newConcurrencyEnv(t)
+	noneClosed(env)
+
+	env.at(0, func() {
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-1", "r1", source, global))
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-2", "r1", source, global))
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-3", "r1", global))
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-4", "r1", global))
+		// a retried acquire activity delivers the request again
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-4", "r1", global))
+	})
+	env.at(1, func() {
+		// sync-2 waits for the source, sync-3 fits the global limit before it
+		env.expect("acquire", []string{"sync-1/r1", "sync-3/r1"}, []string{"sync-2/r1", "sync-4/r1"})
+		env.SignalWorkflow(SignalReleaseSyncSlotRun, ConcurrencyRequest{WorkflowID: "sync-1", RunID: "r1"})
+	})
+	env.at(2, func() {
+		// the first waiting request that fits is granted
+		env.expect("release", []string{"sync-3/r1", "sync-2/r1"}, []string{"sync-4/r1"})
+		// a queued request leaves the queue on release
+		env.SignalWorkflow(SignalReleaseSyncSlotRun, ConcurrencyRequest{WorkflowID: "sync-4", RunID: "r1"})
+	})
+	env.at(3, func() {
+		env.expect("release queued", []string{"sync-3/r1", "sync-2/r1"}, []string{})
+	})
+
+	state := env.run(ConcurrencyState{})
+	if got := slotKeys(state.Holders); !reflect.DeepEqual(got, []string{"sync-3/r1", "sync-2/r1"}) {
+		t.Errorf("continued as new with holders %v", got)
+	}
+	if want := []string{"sync-1/r1", "sync-3/r1", "sync-2/r1"}; !reflect.DeepEqual(env.granted, want) {
+		t.Errorf("granted %v, want %v", env.granted, want)
+	}
+}
+
+// TestSyncConcurrencyWorkflowLimitChange applies the limit of each request, a
+// raised limit grants more slots while a lowered one waits for holders to leave
+func TestSyncConcurrencyWorkflowLimitChange(t *testing.T) {
+	env := newConcurrencyEnv(t)
+	noneClosed(env)
+
+	env.at(0, func() {
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-1", "r1", ConcurrencyScope{Key: "project:p-1", Limit: 1}))
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-2", "r1", ConcurrencyScope{Key: "project:p-1", Limit: 1}))
+	})
+	env.at(1, func() {
+		env.expect("limit 1", []string{"sync-1/r1"}, []string{"sync-2/r1"})
+		// the limit was raised to 3 before sync-3 asked for a slot
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-3", "r1", ConcurrencyScope{Key: "project:p-1", Limit: 3}))
+	})
+	env.at(2, func() {
+		env.expect("limit raised", []string{"sync-1/r1", "sync-3/r1"}, []string{"sync-2/r1"})
+		// lowered back to 1, sync-4 waits until both holders are gone
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-4", "r1", ConcurrencyScope{Key: "project:p-1", Limit: 1}))
+		env.SignalWorkflow(SignalReleaseSyncSlotRun, ConcurrencyRequest{WorkflowID: "sync-2", RunID: "r1"})
+		env.SignalWorkflow(SignalReleaseSyncSlotRun, ConcurrencyRequest{WorkflowID: "sync-1", RunID: "r1"})
+	})
+	env.at(3, func() {
+		env.expect("limit lowered", []string{"sync-3/r1"}, []string{"sync-4/r1"})
+		env.SignalWorkflow(SignalReleaseSyncSlotRun, ConcurrencyRequest{WorkflowID: "sync-3", RunID: "r1"})
+	})
+	env.at(4, func() {
+		env.expect("holders left", []string{"sync-4/r1"}, []string{})
+	})
+	env.run(ConcurrencyState{})
+}
+
+// TestSyncConcurrencyWorkflowReclaim reclaims the slot of a closed run, a new
+// run of the same workflow ID keeps its slot
+func TestSyncConcurrencyWorkflowReclaim(t *testing.T) {
+	scope := ConcurrencyScope{Key: "destination:2", Limit: 2}
+	env := newConcurrencyEnv(t)
+	checks := 0
+	env.OnActivity(GetClosedSyncSlotsActivity, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, holders []ConcurrencyRequest) ([]ConcurrencyRequest, error) {
+			checks++
+			var closed []ConcurrencyRequest
+			for _, holder := range holders {
+				if holder.RunID == "r1" {
+					closed = append(closed, ConcurrencyRequest{WorkflowID: holder.WorkflowID, RunID: holder.RunID})
+				}
+			}
+			return closed, nil
+		})
+
+	// the first run of sync-1 closed without releasing its slot and the job
+	// workflow was reset, its new run shares the workflow ID
+	state := ConcurrencyState{Holders: []ConcurrencyRequest{slotRequest("sync-1", "r1", scope), slotRequest("sync-1", "r2", scope)}}
+	env.at(0, func() {
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-2", "r2", scope))
+	})
+	env.at(1, func() {
+		env.expect("full", []string{"sync-1/r1", "sync-1/r2"}, []string{"sync-2/r2"})
+	})
+	env.at(int(ConcurrencyHolderCheckInterval/time.Minute), func() {
+		if checks != 1 {
+			t.Errorf("checked the holders %d times, want once", checks)
+		}
+		env.expect("reclaimed", []string{"sync-1/r2", "sync-2/r2"}, []string{})
+	})
+	env.run(state)
+}
+
+// TestSyncConcurrencyWorkflowLegacyRelease frees every run of a workflow ID
+// for job workflows and concurrency workflows started before syncSlotRunChange
+func TestSyncConcurrencyWorkflowLegacyRelease(t *testing.T) {
+	scope := ConcurrencyScope{Key: "global", Limit: 1}
+	env := newConcurrencyEnv(t)
+	env.OnGetVersion(syncSlotRunChange, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+	env.OnActivity(GetClosedWorkflowsActivity, mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	env.at(0, func() {
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-1", "r1", scope))
+		env.SignalWorkflow(SignalAcquireSyncSlot, slotRequest("sync-2", "r1", scope))
+	})
+	env.at(1, func() {
+		env.SignalWorkflow(SignalReleaseSyncSlot, "sync-1")
+	})
+	env.at(2, func() {
+		env.expect("released", []string{"sync-2/r1"}, []string{})
+	})
+	env.run(ConcurrencyState{})
+}
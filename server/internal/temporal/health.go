@@ -0,0 +1,59 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// CheckHealth checks that the Temporal frontend is reachable
+func (c *Client) CheckHealth(ctx context.Context) error {
+	return checkHealth(ctx, c.temporalClient)
+}
+
+// CheckHealth checks that the Temporal frontend the worker polls is reachable
+func (w *Worker) CheckHealth(ctx context.Context) error {
+	return checkHealth(ctx, w.temporalClient)
+}
+
+func checkHealth(ctx context.Context, c client.Client) error {
+	if _, err := c.CheckHealth(ctx, &client.CheckHealthRequest{}); err != nil {
+		return fmt.Errorf("temporal is unreachable: %s", err)
+	}
+	return nil
+}
+
+// TaskQueuePollers lists the workflow and activity pollers of TaskQueue, a
+// task queue without pollers has no worker running syncs
+func (c *Client) TaskQueuePollers(ctx context.Context) ([]models.TaskQueuePoller, error) {
+	queueTypes := []struct {
+		name      string
+		queueType enums.TaskQueueType
+	}{
+		{"workflow", enums.TASK_QUEUE_TYPE_WORKFLOW},
+		{"activity", enums.TASK_QUEUE_TYPE_ACTIVITY},
+	}
+	pollers := []models.TaskQueuePoller{}
+	for _, qt := range queueTypes {
+		resp, err := c.temporalClient.DescribeTaskQueue(ctx, TaskQueue, qt.queueType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe %s task queue %s: %s", qt.name, TaskQueue, err)
+		}
+		for _, poller := range resp.GetPollers() {
+			info := models.TaskQueuePoller{
+				Type:          qt.name,
+				Identity:      poller.GetIdentity(),
+				RatePerSecond: poller.GetRatePerSecond(),
+			}
+			if poller.GetLastAccessTime() != nil {
+				info.LastAccessTime = poller.GetLastAccessTime().AsTime().Format(time.RFC3339)
+			}
+			pollers = append(pollers, info)
+		}
+	}
+	return pollers, nil
+}
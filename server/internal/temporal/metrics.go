@@ -0,0 +1,43 @@
+package temporal
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// countErrors counts the failed calls of a Temporal client by method and gRPC
+// code. NotFound is an expected answer, e.g. when describing a schedule to find
+// out whether it exists, and is not counted.
+func countErrors(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if code := status.Code(err); code != codes.OK && code != codes.NotFound {
+		metrics.TemporalClientErrors.WithLabelValues(path.Base(method), code.String()).Inc()
+	}
+	return err
+}
+
+// trackSync counts a sync run of a job as active until the returned function
+// is called with the result of the run
+func trackSync(ctx context.Context, jobID int, kind string) func(error) {
+	start := time.Now()
+	metrics.ActiveSyncs.Inc()
+	return func(err error) {
+		metrics.ActiveSyncs.Dec()
+		status := metrics.SyncCompleted
+		switch {
+		case ctx.Err() != nil:
+			status = metrics.SyncCanceled
+		case err != nil:
+			status = metrics.SyncFailed
+		}
+		job := metrics.JobLabel(jobID)
+		metrics.SyncRuns.WithLabelValues(job, kind, status).Inc()
+		metrics.SyncDuration.WithLabelValues(job, kind, status).Observe(metrics.Since(start))
+	}
+}
@@ -0,0 +1,38 @@
+package temporal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCountErrors(t *testing.T) {
+	const method = "/temporal.api.workflowservice.v1.WorkflowService/DescribeSchedule"
+	cases := []struct {
+		err  error
+		want float64
+	}{
+		{nil, 0},
+		{status.Error(codes.NotFound, "schedule not found"), 0},
+		{status.Error(codes.Unavailable, "connection refused"), 1},
+		{status.Error(codes.InvalidArgument, "invalid cron"), 1},
+	}
+	for _, tc := range cases {
+		code := status.Code(tc.err).String()
+		before := testutil.ToFloat64(metrics.TemporalClientErrors.WithLabelValues("DescribeSchedule", code))
+		invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+			return tc.err
+		}
+		if err := countErrors(context.Background(), method, nil, nil, nil, invoker); err != tc.err {
+			t.Errorf("countErrors() = %v, want %v", err, tc.err)
+		}
+		if counted := testutil.ToFloat64(metrics.TemporalClientErrors.WithLabelValues("DescribeSchedule", code)) - before; counted != tc.want {
+			t.Errorf("%s counted %v times, want %v", code, counted, tc.want)
+		}
+	}
+}
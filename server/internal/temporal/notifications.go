@@ -0,0 +1,374 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/notify"
+	"github.com/datazip/olake-frontend/server/internal/tracing"
+	"github.com/datazip/olake-frontend/server/utils"
+	"go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// NotificationRetryPolicy retries the delivery of a notification to a channel
+var NotificationRetryPolicy = &temporal.RetryPolicy{
+	InitialInterval:    time.Second * 30,
+	BackoffCoefficient: 2.0,
+	MaximumInterval:    time.Minute * 10,
+	MaximumAttempts:    8,
+}
+
+const (
+	// NotificationMonitorWorkflowID is the ID of the workflow checking long_run and no_success subscriptions
+	NotificationMonitorWorkflowID = "olake-notification-monitor"
+	// NotificationMonitorSchedule is the cron schedule of NotificationMonitorWorkflow
+	NotificationMonitorSchedule = "*/5 * * * *"
+	// notificationWorkflowPrefix prefixes the ID of a sync to get the ID of its notification workflow,
+	// a suffix would put it among the sync runs of the job
+	notificationWorkflowPrefix = "notify-"
+)
+
+// Statuses of finished syncs kept on subscriptions
+const (
+	syncStatusCompleted = "completed"
+	syncStatusFailed    = "failed"
+)
+
+// notifySync starts the workflow notifying the subscribers of the job about a
+// finished sync. It is abandoned so that slow channels do not hold up the job.
+func notifySync(ctx workflow.Context, outcome SyncOutcome) {
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        notificationWorkflowPrefix + outcome.WorkflowID,
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+	child := workflow.ExecuteChildWorkflow(childCtx, SyncNotificationWorkflow, outcome)
+	if err := child.GetChildWorkflowExecution().Get(childCtx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to start sync notifications", "jobId", outcome.JobID, "error", err)
+	}
+}
+
+// SyncNotificationWorkflow works out the notifications of a finished sync and delivers them
+func SyncNotificationWorkflow(ctx workflow.Context, outcome SyncOutcome) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// checking for schema drift runs the discover command of the source
+		StartToCloseTimeout: time.Minute * 30,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	// the drift is checked before evaluating so that a failed discover does not
+	// leave some subscriptions recorded and others not
+	if workflow.GetVersion(ctx, schemaDriftActivityChange, workflow.DefaultVersion, 1) == 1 && outcome.Error == "" {
+		if err := workflow.ExecuteActivity(ctx, DetectSchemaDriftActivity, outcome).Get(ctx, &outcome.SchemaDrift); err != nil {
+			return err
+		}
+		outcome.SchemaDriftChecked = true
+	}
+	var deliveries []NotificationDelivery
+	if err := workflow.ExecuteActivity(ctx, EvaluateSyncNotificationsActivity, outcome).Get(ctx, &deliveries); err != nil {
+		return err
+	}
+	deliverNotifications(ctx, deliveries)
+	return nil
+}
+
+// NotificationMonitorWorkflow reports running syncs that take too long and
+// jobs without a recent successful sync, the worker runs it on NotificationMonitorSchedule
+func NotificationMonitorWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	var deliveries []NotificationDelivery
+	if err := workflow.ExecuteActivity(ctx, CheckNotificationSLAsActivity).Get(ctx, &deliveries); err != nil {
+		return err
+	}
+	deliverNotifications(ctx, deliveries)
+	return nil
+}
+
+// deliverNotifications delivers notifications in parallel, every one retried on its own
+func deliverNotifications(ctx workflow.Context, deliveries []NotificationDelivery) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: notify.SendTimeout + time.Minute,
+		RetryPolicy:         NotificationRetryPolicy,
+	})
+	futures := make([]workflow.Future, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		futures = append(futures, workflow.ExecuteActivity(ctx, DeliverNotificationActivity, delivery))
+	}
+	for i, future := range futures {
+		if err := future.Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Error("Failed to deliver notification", "jobId", deliveries[i].Notification.JobID,
+				"channelId", deliveries[i].ChannelID, "event", deliveries[i].Notification.Event, "error", err)
+		}
+	}
+}
+
+// startNotificationMonitor starts NotificationMonitorWorkflow unless it is already running
+func startNotificationMonitor(ctx context.Context, temporalClient client.Client) error {
+	_, err := temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:           NotificationMonitorWorkflowID,
+		TaskQueue:    TaskQueue,
+		CronSchedule: NotificationMonitorSchedule,
+	}, NotificationMonitorWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to start notification monitor: %s", err)
+	}
+	return nil
+}
+
+// DetectSchemaDriftActivity returns the schema drift of the job of a successful
+// sync, the source is only discovered when a subscription listens for drift
+func DetectSchemaDriftActivity(ctx context.Context, outcome SyncOutcome) ([]string, error) {
+	ctx = tracing.Annotate(ctx, tracing.JobID(outcome.JobID), tracing.WorkflowID(outcome.WorkflowID))
+	subscriptions, err := database.NewJobNotificationORM().GetByJobID(outcome.JobID)
+	if err != nil || !listensForSchemaDrift(subscriptions) {
+		return nil, err
+	}
+	job, err := database.NewJobORM().GetByID(outcome.JobID, false)
+	if err != nil {
+		return nil, err
+	}
+	return detectSchemaDrift(ctx, job, outcome.WorkflowID)
+}
+
+// EvaluateSyncNotificationsActivity returns the notifications the subscriptions
+// of a job get for a finished sync and records them on the subscriptions. The
+// subscriptions are recorded together once every notification is known, so a
+// retried evaluation sees the same subscriptions as the failed one.
+func EvaluateSyncNotificationsActivity(ctx context.Context, outcome SyncOutcome) ([]NotificationDelivery, error) {
+	ctx = tracing.Annotate(ctx, tracing.JobID(outcome.JobID), tracing.WorkflowID(outcome.WorkflowID))
+	notificationORM := database.NewJobNotificationORM()
+	subscriptions, err := notificationORM.GetByJobID(outcome.JobID)
+	if err != nil || len(subscriptions) == 0 {
+		return nil, err
+	}
+	job, err := database.NewJobORM().GetByID(outcome.JobID, false)
+	if err != nil {
+		return nil, err
+	}
+	// notification workflows started by an older worker check the drift here
+	if outcome.Error == "" && !outcome.SchemaDriftChecked && listensForSchemaDrift(subscriptions) {
+		if outcome.SchemaDrift, err = detectSchemaDrift(ctx, job, outcome.WorkflowID); err != nil {
+			return nil, err
+		}
+	}
+
+	deliveries := evaluateSyncNotifications(job, subscriptions, outcome)
+	if err := notificationORM.UpdateTracking(subscriptions...); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// evaluateSyncNotifications returns the notifications of a finished sync and
+// updates what the subscriptions have notified, without saving them
+func evaluateSyncNotifications(job *models.Job, subscriptions []*models.JobNotification, outcome SyncOutcome) []NotificationDelivery {
+	failed := outcome.Error != ""
+	duration := outcome.FinishedAt.Sub(outcome.StartedAt).Round(time.Second)
+	newNotification := func(event, message string) notify.Notification {
+		return notify.Notification{
+			Event:      event,
+			ProjectID:  job.ProjectID,
+			JobID:      job.ID,
+			JobName:    job.Name,
+			WorkflowID: outcome.WorkflowID,
+			Message:    message,
+			Error:      outcome.Error,
+			Time:       outcome.FinishedAt,
+		}
+	}
+	driftFingerprint := ""
+	if len(outcome.SchemaDrift) > 0 {
+		driftFingerprint = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(outcome.SchemaDrift, "\n"))))
+	}
+
+	var deliveries []NotificationDelivery
+	for _, subscription := range subscriptions {
+		events := subscriptionEvents(subscription)
+		add := func(notification notify.Notification) {
+			deliveries = append(deliveries, NotificationDelivery{ChannelID: subscription.ChannelID.ID, Notification: notification})
+		}
+
+		if failed {
+			if events[constants.NotificationEventFailure] {
+				add(newNotification(constants.NotificationEventFailure, fmt.Sprintf("Sync of job %s failed after %s.", job.Name, duration)))
+			}
+		} else {
+			if events[constants.NotificationEventSuccess] {
+				add(newNotification(constants.NotificationEventSuccess, fmt.Sprintf("Sync of job %s completed in %s.", job.Name, duration)))
+			}
+			if events[constants.NotificationEventRecovery] && subscription.LastStatus == syncStatusFailed {
+				add(newNotification(constants.NotificationEventRecovery, fmt.Sprintf("Sync of job %s completed again after failing.", job.Name)))
+			}
+			finishedAt := outcome.FinishedAt
+			subscription.LastSuccessAt = &finishedAt
+			subscription.NoSuccessNotifiedAt = nil
+		}
+
+		// runs the monitor already reported while they ran are not reported again
+		limit := subscriptionDuration(subscription.RunLongerThan)
+		if events[constants.NotificationEventLongRun] && limit > 0 && duration > limit && subscription.LongRunWorkflowID != outcome.WorkflowID {
+			add(newNotification(constants.NotificationEventLongRun, fmt.Sprintf("Sync of job %s ran for %s, longer than %s.", job.Name, duration, limit)))
+			subscription.LongRunWorkflowID = outcome.WorkflowID
+		}
+
+		if events[constants.NotificationEventSchemaDrift] && !failed {
+			if driftFingerprint != "" && driftFingerprint != subscription.SchemaDrift {
+				notification := newNotification(constants.NotificationEventSchemaDrift, fmt.Sprintf("The source schema of job %s no longer matches its streams.", job.Name))
+				notification.Details = outcome.SchemaDrift
+				add(notification)
+			}
+			subscription.SchemaDrift = driftFingerprint
+		}
+
+		subscription.LastStatus = syncStatusCompleted
+		if failed {
+			subscription.LastStatus = syncStatusFailed
+		}
+	}
+	return deliveries
+}
+
+// listensForSchemaDrift reports whether a subscription is for schema_drift
+func listensForSchemaDrift(subscriptions []*models.JobNotification) bool {
+	for _, subscription := range subscriptions {
+		if subscriptionEvents(subscription)[constants.NotificationEventSchemaDrift] {
+			return true
+		}
+	}
+	return false
+}
+
+// detectSchemaDrift discovers the source of a job and compares it with the streams of the job
+func detectSchemaDrift(ctx context.Context, job *models.Job, workflowID string) ([]string, error) {
+	runner := docker.NewRunner(docker.GetDefaultConfigDir())
+	catalog, err := runner.GetCatalog(ctx, job.SourceID.Type, job.SourceID.Version, job.SourceID.Config, "schema-drift-"+workflowID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover source schema of job[%d]: %s", job.ID, err)
+	}
+	discovered, err := json.Marshal(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode discovered catalog: %s", err)
+	}
+	return utils.SchemaDrift(job.StreamsConfig, string(discovered))
+}
+
+// CheckNotificationSLAsActivity returns the long_run notifications of running
+// syncs and the no_success notifications of active jobs, each reported once
+func CheckNotificationSLAsActivity(ctx context.Context) ([]NotificationDelivery, error) {
+	notificationORM := database.NewJobNotificationORM()
+	subscriptions, err := notificationORM.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	temporalClient := activity.GetClient(ctx)
+	latestRuns := map[int]*workflowpb.WorkflowExecutionInfo{}
+	var deliveries []NotificationDelivery
+	var changed []*models.JobNotification
+	for _, subscription := range subscriptions {
+		job := subscription.JobID
+		events := subscriptionEvents(subscription)
+		add := func(event, workflowID, message string) {
+			deliveries = append(deliveries, NotificationDelivery{
+				ChannelID: subscription.ChannelID.ID,
+				Notification: notify.Notification{
+					Event:      event,
+					ProjectID:  job.ProjectID,
+					JobID:      job.ID,
+					JobName:    job.Name,
+					WorkflowID: workflowID,
+					Message:    message,
+					Time:       now,
+				},
+			})
+		}
+		reported := false
+
+		within := subscriptionDuration(subscription.NoSuccessWithin)
+		if events[constants.NotificationEventNoSuccess] && within > 0 && job.Active && subscription.NoSuccessNotifiedAt == nil {
+			since := subscription.CreatedAt
+			if subscription.LastSuccessAt != nil {
+				since = *subscription.LastSuccessAt
+			}
+			if now.Sub(since) > within {
+				add(constants.NotificationEventNoSuccess, "", fmt.Sprintf("Job %s has not synced successfully for more than %s.", job.Name, within))
+				subscription.NoSuccessNotifiedAt = &now
+				reported = true
+			}
+		}
+
+		limit := subscriptionDuration(subscription.RunLongerThan)
+		if events[constants.NotificationEventLongRun] && limit > 0 {
+			latest, ok := latestRuns[job.ID]
+			if !ok {
+				if latest, err = latestSyncExecution(ctx, temporalClient, job.ProjectID, job.ID); err != nil {
+					return nil, err
+				}
+				latestRuns[job.ID] = latest
+			}
+			if latest != nil && latest.Status == enums.WORKFLOW_EXECUTION_STATUS_RUNNING && latest.Execution.GetWorkflowId() != subscription.LongRunWorkflowID {
+				if running := now.Sub(latest.StartTime.AsTime()).Round(time.Second); running > limit {
+					add(constants.NotificationEventLongRun, latest.Execution.GetWorkflowId(), fmt.Sprintf("Sync of job %s has been running for %s, longer than %s.", job.Name, running, limit))
+					subscription.LongRunWorkflowID = latest.Execution.GetWorkflowId()
+					reported = true
+				}
+			}
+		}
+
+		if reported {
+			changed = append(changed, subscription)
+		}
+	}
+	if err := notificationORM.UpdateTracking(changed...); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// DeliverNotificationActivity sends a notification to its channel
+func DeliverNotificationActivity(ctx context.Context, delivery NotificationDelivery) error {
+	ctx = tracing.Annotate(ctx, tracing.JobID(delivery.Notification.JobID))
+	channel, err := database.NewNotificationChannelORM().GetByID(delivery.ChannelID)
+	if err != nil {
+		return err
+	}
+	if err := notify.Send(ctx, channel, &delivery.Notification); err != nil {
+		return fmt.Errorf("failed to notify channel %s: %s", channel.Name, err)
+	}
+	return nil
+}
+
+// subscriptionEvents returns the events a subscription is for
+func subscriptionEvents(subscription *models.JobNotification) map[string]bool {
+	var events []string
+	_ = json.Unmarshal([]byte(subscription.Events), &events)
+	set := make(map[string]bool, len(events))
+	for _, event := range events {
+		set[event] = true
+	}
+	return set
+}
+
+// subscriptionDuration parses a threshold of a subscription, 0 when unset
+func subscriptionDuration(value string) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
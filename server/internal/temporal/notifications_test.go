@@ -0,0 +1,61 @@
+package temporal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestEvaluateSyncNotifications(t *testing.T) {
+	job := &models.Job{ID: 1, Name: "orders", ProjectID: "p1"}
+	subscription := &models.JobNotification{
+		ChannelID:     &models.NotificationChannel{ID: 2},
+		Events:        `["failure", "recovery", "long_run", "schema_drift"]`,
+		RunLongerThan: "1h",
+	}
+	startedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := func(deliveries []NotificationDelivery) []string {
+		var got []string
+		for _, delivery := range deliveries {
+			got = append(got, delivery.Notification.Event)
+		}
+		return got
+	}
+
+	failed := SyncOutcome{JobID: 1, WorkflowID: "sync-1", StartedAt: startedAt, FinishedAt: startedAt.Add(time.Minute), Error: "boom"}
+	deliveries := evaluateSyncNotifications(job, []*models.JobNotification{subscription}, failed)
+	if got := events(deliveries); !reflect.DeepEqual(got, []string{constants.NotificationEventFailure}) {
+		t.Errorf("failed sync notified %v", got)
+	}
+	if subscription.LastStatus != syncStatusFailed || subscription.LastSuccessAt != nil {
+		t.Errorf("failed sync recorded %q, %v", subscription.LastStatus, subscription.LastSuccessAt)
+	}
+
+	// the recovery is reported as long as the evaluation of the recovering sync is not saved
+	recovered := SyncOutcome{JobID: 1, WorkflowID: "sync-2", StartedAt: startedAt, FinishedAt: startedAt.Add(time.Hour * 2), SchemaDrift: []string{"added column"}}
+	want := []string{constants.NotificationEventRecovery, constants.NotificationEventLongRun, constants.NotificationEventSchemaDrift}
+	for i := 0; i < 2; i++ {
+		saved := *subscription
+		deliveries = evaluateSyncNotifications(job, []*models.JobNotification{&saved}, recovered)
+		if got := events(deliveries); !reflect.DeepEqual(got, want) {
+			t.Errorf("evaluation %d of the recovering sync notified %v, want %v", i+1, got, want)
+		}
+		if saved.LastStatus != syncStatusCompleted || saved.LongRunWorkflowID != "sync-2" || saved.SchemaDrift == "" {
+			t.Errorf("recovering sync recorded %+v", saved)
+		}
+		if i == 1 {
+			*subscription = saved
+		}
+	}
+	if got := deliveries[2].Notification.Details; !reflect.DeepEqual(got, []string{"added column"}) {
+		t.Errorf("schema drift details = %v", got)
+	}
+
+	// the same drift is reported once
+	if got := events(evaluateSyncNotifications(job, []*models.JobNotification{subscription}, recovered)); got != nil {
+		t.Errorf("repeated sync notified %v", got)
+	}
+}
@@ -0,0 +1,162 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+const (
+	// MaxSyncAttempts caps the maximum attempts of a job retry policy
+	MaxSyncAttempts = 10
+	// syncAttemptSuffix separates the attempt number from the sync workflow ID of retries
+	syncAttemptSuffix = "-attempt-"
+)
+
+// SyncRetryPolicy is the validated retry policy of the syncs of a job
+type SyncRetryPolicy struct {
+	MaximumAttempts    int
+	InitialInterval    time.Duration
+	BackoffCoefficient float64
+	MaximumInterval    time.Duration
+	NonRetryableErrors []string
+}
+
+// ParseRetryPolicy decodes the retry policy stored on a job
+func ParseRetryPolicy(raw string) (*models.RetryPolicy, error) {
+	policy := &models.RetryPolicy{}
+	if strings.TrimSpace(raw) == "" {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(raw), policy); err != nil {
+		return nil, fmt.Errorf("invalid retry policy: %s", err)
+	}
+	return policy, nil
+}
+
+// BuildSyncRetryPolicy validates a job retry policy, unset fields default to DefaultRetryPolicy
+func BuildSyncRetryPolicy(policy *models.RetryPolicy) (*SyncRetryPolicy, error) {
+	syncPolicy := &SyncRetryPolicy{
+		MaximumAttempts:    int(DefaultRetryPolicy.MaximumAttempts),
+		InitialInterval:    DefaultRetryPolicy.InitialInterval,
+		BackoffCoefficient: DefaultRetryPolicy.BackoffCoefficient,
+		MaximumInterval:    DefaultRetryPolicy.MaximumInterval,
+	}
+	if policy == nil {
+		return syncPolicy, nil
+	}
+
+	if policy.MaximumAttempts != 0 {
+		if policy.MaximumAttempts < 1 || policy.MaximumAttempts > MaxSyncAttempts {
+			return nil, fmt.Errorf("maximum_attempts must be between 1 and %d", MaxSyncAttempts)
+		}
+		syncPolicy.MaximumAttempts = policy.MaximumAttempts
+	}
+	if policy.InitialInterval != "" {
+		interval, err := time.ParseDuration(policy.InitialInterval)
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("initial_interval must be a duration of at least 1s")
+		}
+		syncPolicy.InitialInterval = interval
+	}
+	if policy.BackoffCoefficient != 0 {
+		if policy.BackoffCoefficient < 1 {
+			return nil, fmt.Errorf("backoff_coefficient must be at least 1")
+		}
+		syncPolicy.BackoffCoefficient = policy.BackoffCoefficient
+	}
+	if policy.MaximumInterval != "" {
+		interval, err := time.ParseDuration(policy.MaximumInterval)
+		if err != nil || interval < syncPolicy.InitialInterval {
+			return nil, fmt.Errorf("maximum_interval must be a duration of at least initial_interval")
+		}
+		syncPolicy.MaximumInterval = interval
+	} else {
+		syncPolicy.MaximumInterval = max(syncPolicy.MaximumInterval, syncPolicy.InitialInterval)
+	}
+	for _, class := range policy.NonRetryableErrors {
+		if !slices.Contains(docker.ErrorClasses, class) {
+			return nil, fmt.Errorf("unknown error class %q in non_retryable_errors, must be one of %s", class, strings.Join(docker.ErrorClasses, ", "))
+		}
+	}
+	syncPolicy.NonRetryableErrors = policy.NonRetryableErrors
+	return syncPolicy, nil
+}
+
+// GetSyncRetryPolicyActivity loads the retry policy of the syncs of a job
+func GetSyncRetryPolicyActivity(_ context.Context, jobID int) (*SyncRetryPolicy, error) {
+	job, err := database.NewJobORM().GetByID(jobID, false)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := ParseRetryPolicy(job.RetryPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return BuildSyncRetryPolicy(policy)
+}
+
+// shouldRetry reports whether a failed sync attempt is retried
+func (p *SyncRetryPolicy) shouldRetry(err error, attempt int) bool {
+	if attempt >= p.MaximumAttempts || temporal.IsCanceledError(err) || temporal.IsTerminatedError(err) {
+		return false
+	}
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		return !appErr.NonRetryable() && !slices.Contains(p.NonRetryableErrors, appErr.Type())
+	}
+	return true
+}
+
+// backoff returns the delay before the attempt following the given one
+func (p *SyncRetryPolicy) backoff(attempt int) time.Duration {
+	interval := float64(p.InitialInterval) * math.Pow(p.BackoffCoefficient, float64(attempt-1))
+	return min(time.Duration(interval), p.MaximumInterval)
+}
+
+// syncAttemptWorkflowID returns the workflow ID of an attempt, the first attempt keeps the plain ID
+func syncAttemptWorkflowID(workflowID string, attempt int) string {
+	if attempt == 1 {
+		return workflowID
+	}
+	return fmt.Sprintf("%s%s%d", workflowID, syncAttemptSuffix, attempt)
+}
+
+// SyncAttempt returns the attempt number of a sync workflow ID
+func SyncAttempt(workflowID string) int {
+	idx := strings.LastIndex(workflowID, syncAttemptSuffix)
+	if idx == -1 {
+		return 1
+	}
+	attempt, err := strconv.Atoi(workflowID[idx+len(syncAttemptSuffix):])
+	if err != nil {
+		return 1
+	}
+	return attempt
+}
+
+// toSyncError turns a failed docker command into a Temporal application error
+// typed by its error class, fatal classes are not retryable
+func toSyncError(err error) error {
+	var cmdErr *docker.CommandError
+	if !errors.As(err, &cmdErr) {
+		return fmt.Errorf("sync command failed: %v", err)
+	}
+	message := fmt.Sprintf("sync command failed: %s", cmdErr)
+	if !cmdErr.Retryable {
+		return temporal.NewNonRetryableApplicationError(message, cmdErr.Class, err, cmdErr.ExitCode)
+	}
+	return temporal.NewApplicationErrorWithCause(message, cmdErr.Class, err, cmdErr.ExitCode)
+}
@@ -0,0 +1,382 @@
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+const (
+	// weekSkipHorizon is the number of weeks ahead a schedule of every N weeks
+	// skips the weeks outside its cycle for, BlackoutShiftWorkflow moves it along weekly
+	weekSkipHorizon = 8
+	// temporal only evaluates calendar years within this range
+	minCalendarYear = 2000
+	maxCalendarYear = 2100
+	// DefaultSchedulePreviewCount is the number of fire times returned when a preview does not ask for a count
+	DefaultSchedulePreviewCount = 5
+	// MaxSchedulePreviewCount caps the number of fire times returned by a preview
+	MaxSchedulePreviewCount = 100
+	// maxPreviewCandidates caps the fire times a preview looks at, most of them
+	// can fall inside blackout windows
+	maxPreviewCandidates = 100000
+)
+
+// epochSunday is the first sunday after the unix epoch, the weeks of schedules
+// running every N weeks count from it
+var epochSunday = time.Date(1970, 1, 4, 0, 0, 0, 0, time.UTC)
+
+// ErrScheduleBlackedOut is returned when the fire times of a schedule keep falling inside its blackout windows
+var ErrScheduleBlackedOut = errors.New("schedule does not fire outside its blackout windows")
+
+// compiledSchedule is a validated schedule that can be turned into a Temporal
+// ScheduleSpec or evaluated locally for a preview
+type compiledSchedule struct {
+	cronExpressions []string
+	calendars       []*utils.CalendarSchedule
+	calendarSpecs   []client.ScheduleCalendarSpec
+	intervals       []client.ScheduleIntervalSpec
+	blackouts       []*blackoutWindow
+	location        *time.Location
+	jitter          time.Duration
+	startAt         time.Time
+	endAt           time.Time
+
+	// everyWeeks is set for the shorthand "N-weeks" with N above 1, which
+	// fires weekly and skips the weeks outside its cycle
+	everyWeeks int
+}
+
+// ParseScheduleConfig decodes the schedule config stored on a job
+func ParseScheduleConfig(raw string) (*models.ScheduleConfig, error) {
+	config := &models.ScheduleConfig{}
+	if strings.TrimSpace(raw) == "" {
+		return config, nil
+	}
+	if err := json.Unmarshal([]byte(raw), config); err != nil {
+		return nil, fmt.Errorf("invalid schedule config: %s", err)
+	}
+	return config, nil
+}
+
+// BuildScheduleSpec validates the job frequency and schedule config and builds
+// the Temporal spec, blackout windows of the job and its project become skip specs
+func BuildScheduleSpec(frequency string, config *models.ScheduleConfig, projectWindows []models.BlackoutWindow) (*client.ScheduleSpec, error) {
+	schedule, err := compileSchedule(frequency, config, projectWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var skip []client.ScheduleCalendarSpec
+	for _, blackout := range schedule.blackouts {
+		skip = append(skip, blackout.skipSpecs(schedule.location, now)...)
+	}
+	skip = append(skip, schedule.weekSkipSpecs(now)...)
+
+	return &client.ScheduleSpec{
+		CronExpressions: schedule.cronExpressions,
+		Calendars:       schedule.calendarSpecs,
+		Intervals:       schedule.intervals,
+		Skip:            skip,
+		StartAt:         schedule.startAt,
+		EndAt:           schedule.endAt,
+		Jitter:          schedule.jitter,
+		TimeZoneName:    schedule.location.String(),
+	}, nil
+}
+
+// ValidateSchedule checks that a schedule builds and still fires outside of
+// its blackout windows and those of its project
+func ValidateSchedule(frequency string, config *models.ScheduleConfig, projectWindows []models.BlackoutWindow, now time.Time) error {
+	if _, err := BuildScheduleSpec(frequency, config, projectWindows); err != nil {
+		return err
+	}
+	fireTimes, _, err := PreviewSchedule(frequency, config, projectWindows, now, 1)
+	if err != nil {
+		return err
+	}
+	// schedules that ended already are kept, they are paused by Temporal
+	if len(fireTimes) == 0 && (config == nil || config.EndAt == nil || config.EndAt.After(now)) {
+		return fmt.Errorf("schedule never fires")
+	}
+	return nil
+}
+
+// PreviewSchedule returns the next count fire times after from outside of
+// blackout windows, before jitter is applied. It fails with ErrScheduleBlackedOut
+// when maxPreviewCandidates fire times do not hold count outside the windows.
+func PreviewSchedule(frequency string, config *models.ScheduleConfig, projectWindows []models.BlackoutWindow, from time.Time, count int) ([]time.Time, *time.Location, error) {
+	schedule, err := compileSchedule(frequency, config, projectWindows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if count <= 0 || count > MaxSchedulePreviewCount {
+		return nil, nil, fmt.Errorf("count must be between 1 and %d", MaxSchedulePreviewCount)
+	}
+
+	if from.Before(schedule.startAt) {
+		// startAt is inclusive
+		from = schedule.startAt.Add(-time.Nanosecond)
+	}
+
+	fireTimes := make([]time.Time, 0, count)
+	for candidates := 0; len(fireTimes) < count; candidates++ {
+		if candidates == maxPreviewCandidates {
+			return nil, nil, fmt.Errorf("%w: %d of the next %d fire times are outside of them", ErrScheduleBlackedOut, len(fireTimes), maxPreviewCandidates)
+		}
+		next := schedule.next(from)
+		if next.IsZero() || (!schedule.endAt.IsZero() && next.After(schedule.endAt)) {
+			break
+		}
+		if schedule.activeBlackout(next) == nil && schedule.inWeekCycle(next) {
+			fireTimes = append(fireTimes, next)
+		}
+		from = next
+	}
+	return fireTimes, schedule.location, nil
+}
+
+// next returns the earliest fire time strictly after from across all specs
+func (s *compiledSchedule) next(from time.Time) time.Time {
+	candidates := make([]time.Time, 0, len(s.calendars)+len(s.intervals))
+	for _, calendar := range s.calendars {
+		if next := calendar.Next(from, s.location); !next.IsZero() {
+			candidates = append(candidates, next)
+		}
+	}
+	for _, interval := range s.intervals {
+		// fire times are epoch + n*every + offset
+		elapsed := from.Add(-interval.Offset).Sub(time.Unix(0, 0))
+		n := elapsed/interval.Every + 1
+		if elapsed < 0 {
+			n = 0
+		}
+		candidates = append(candidates, time.Unix(0, 0).Add(n*interval.Every+interval.Offset).In(s.location))
+	}
+	if len(candidates) == 0 {
+		return time.Time{}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return candidates[0]
+}
+
+// activeBlackout returns the blackout window containing t, if any
+func (s *compiledSchedule) activeBlackout(t time.Time) *blackoutWindow {
+	for _, blackout := range s.blackouts {
+		if blackout.contains(t) {
+			return blackout
+		}
+	}
+	return nil
+}
+
+func compileSchedule(frequency string, config *models.ScheduleConfig, projectWindows []models.BlackoutWindow) (*compiledSchedule, error) {
+	if config == nil {
+		config = &models.ScheduleConfig{}
+	}
+
+	timeZone := config.TimeZone
+	if timeZone == "" {
+		timeZone = constants.DefaultTimeZone
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %s", timeZone, err)
+	}
+	schedule := &compiledSchedule{location: location}
+
+	if frequency = strings.TrimSpace(frequency); frequency != "" {
+		if err := schedule.addFrequency(frequency); err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, spec := range config.Calendars {
+		calendar, err := utils.ParseCalendarFields(spec.Minute, spec.Hour, spec.DayOfMonth, spec.Month, spec.DayOfWeek)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar spec at index %d: %s", idx, err)
+		}
+		schedule.addCalendar(calendar, spec.Comment)
+	}
+
+	for idx, spec := range config.Intervals {
+		every, err := time.ParseDuration(spec.Every)
+		if err != nil || every < time.Minute {
+			return nil, fmt.Errorf("invalid interval spec at index %d: every must be a duration of at least 1m", idx)
+		}
+		var offset time.Duration
+		if spec.Offset != "" {
+			offset, err = time.ParseDuration(spec.Offset)
+			if err != nil || offset < 0 || offset >= every {
+				return nil, fmt.Errorf("invalid interval spec at index %d: offset must be a duration between 0 and every", idx)
+			}
+		}
+		schedule.intervals = append(schedule.intervals, client.ScheduleIntervalSpec{Every: every, Offset: offset})
+	}
+
+	if len(schedule.calendars) == 0 && len(schedule.intervals) == 0 {
+		return nil, fmt.Errorf("schedule requires a frequency, a calendar or an interval")
+	}
+
+	if config.Jitter != "" {
+		schedule.jitter, err = time.ParseDuration(config.Jitter)
+		if err != nil || schedule.jitter < 0 {
+			return nil, fmt.Errorf("invalid jitter %q: must be a non-negative duration", config.Jitter)
+		}
+	}
+	if config.StartAt != nil {
+		schedule.startAt = *config.StartAt
+	}
+	if config.EndAt != nil {
+		schedule.endAt = *config.EndAt
+	}
+	if !schedule.startAt.IsZero() && !schedule.endAt.IsZero() && !schedule.endAt.After(schedule.startAt) {
+		return nil, fmt.Errorf("schedule end_at must be after start_at")
+	}
+
+	// windows without a time zone use the time zone of the schedule
+	if schedule.blackouts, err = compileBlackoutWindows(config.BlackoutWindows, location); err != nil {
+		return nil, err
+	}
+	projectBlackouts, err := compileBlackoutWindows(projectWindows, location)
+	if err != nil {
+		return nil, fmt.Errorf("project settings: %s", err)
+	}
+	schedule.blackouts = append(schedule.blackouts, projectBlackouts...)
+
+	return schedule, nil
+}
+
+// addFrequency adds either the shorthand or a raw cron expression to the schedule
+func (s *compiledSchedule) addFrequency(frequency string) error {
+	if len(strings.Fields(frequency)) == 1 {
+		value, unit, err := utils.ParseFrequency(frequency)
+		if err != nil {
+			return err
+		}
+		switch {
+		case unit == "weeks" && value > 1:
+			// intervals ignore time zones and would move an hour with DST, the
+			// weekly calendar keeps to sunday midnight
+			s.everyWeeks = value
+			frequency = "1-weeks"
+		case unit == "years" && value > 1:
+			calendar, _ := utils.ParseCalendarFields("0", "0", "1", "1", "*")
+			calendar.Year = utils.CronField{{Start: minCalendarYear, End: maxCalendarYear, Step: value}}
+			s.addCalendar(calendar, fmt.Sprintf("every %d years", value))
+			return nil
+		}
+	}
+
+	cronSpec, err := utils.ToCron(frequency)
+	if err != nil {
+		return err
+	}
+	calendar, err := utils.ParseCron(cronSpec)
+	if err != nil {
+		return err
+	}
+	s.cronExpressions = append(s.cronExpressions, cronSpec)
+	s.calendars = append(s.calendars, calendar)
+	return nil
+}
+
+// week returns the number of the week holding t in the schedule time zone,
+// counting sunday to saturday weeks from epochSunday
+func (s *compiledSchedule) week(t time.Time) int {
+	year, month, day := t.In(s.location).Date()
+	days := int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Sub(epochSunday).Hours()) / 24
+	if days < 0 {
+		days -= 6
+	}
+	return days / 7
+}
+
+// inWeekCycle reports whether t falls in a week the schedule runs in. The
+// cycle of every N weeks starts with the week of start_at, or with epochSunday
+// without it.
+func (s *compiledSchedule) inWeekCycle(t time.Time) bool {
+	if s.everyWeeks <= 1 {
+		return true
+	}
+	weeks := s.week(t)
+	if !s.startAt.IsZero() {
+		weeks -= s.week(s.startAt)
+	}
+	return (weeks%s.everyWeeks+s.everyWeeks)%s.everyWeeks == 0
+}
+
+// weekSkipSpecs skips the weeks outside the cycle of the schedule within
+// weekSkipHorizon weeks from the week holding at. Skip specs can only match
+// calendar fields, so every week to skip is its own spec.
+func (s *compiledSchedule) weekSkipSpecs(at time.Time) []client.ScheduleCalendarSpec {
+	if s.everyWeeks <= 1 {
+		return nil
+	}
+	var specs []client.ScheduleCalendarSpec
+	current := s.week(at)
+	for week := current; week < current+weekSkipHorizon; week++ {
+		sunday := epochSunday.AddDate(0, 0, week*7)
+		if s.inWeekCycle(time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 12, 0, 0, 0, s.location)) {
+			continue
+		}
+		// the frequency only fires on sunday, the spec covers the whole day
+		specs = append(specs, client.ScheduleCalendarSpec{
+			Second:     []client.ScheduleRange{{Start: 0, End: 59}},
+			Minute:     []client.ScheduleRange{{Start: 0, End: 59}},
+			Hour:       []client.ScheduleRange{{Start: 0, End: 23}},
+			DayOfMonth: []client.ScheduleRange{{Start: sunday.Day()}},
+			Month:      []client.ScheduleRange{{Start: int(sunday.Month())}},
+			Year:       []client.ScheduleRange{{Start: sunday.Year()}},
+			Comment:    fmt.Sprintf("week outside the cycle of every %d weeks", s.everyWeeks),
+		})
+	}
+	return specs
+}
+
+// weekSkipsChanged reports whether the weeks skipped by a schedule of every
+// N weeks moved along between from and to
+func (s *compiledSchedule) weekSkipsChanged(from, to time.Time) bool {
+	return s.everyWeeks > 1 && s.week(from) != s.week(to)
+}
+
+// addCalendar registers a calendar both for local evaluation and as a Temporal calendar spec
+func (s *compiledSchedule) addCalendar(calendar *utils.CalendarSchedule, comment string) {
+	s.calendars = append(s.calendars, calendar)
+	s.calendarSpecs = append(s.calendarSpecs, toCalendarSpec(calendar, comment))
+}
+
+// toCalendarSpec converts a calendar to a Temporal calendar spec firing on the minute
+func toCalendarSpec(calendar *utils.CalendarSchedule, comment string) client.ScheduleCalendarSpec {
+	return client.ScheduleCalendarSpec{
+		Second:     []client.ScheduleRange{{Start: 0}},
+		Minute:     toScheduleRanges(calendar.Minute),
+		Hour:       toScheduleRanges(calendar.Hour),
+		DayOfMonth: toScheduleRanges(calendar.DayOfMonth),
+		Month:      toScheduleRanges(calendar.Month),
+		DayOfWeek:  toScheduleRanges(calendar.DayOfWeek),
+		Year:       toScheduleRanges(calendar.Year),
+		Comment:    comment,
+	}
+}
+
+func toScheduleRanges(field utils.CronField) []client.ScheduleRange {
+	if len(field) == 0 {
+		return nil
+	}
+	ranges := make([]client.ScheduleRange, 0, len(field))
+	for _, r := range field {
+		ranges = append(ranges, client.ScheduleRange{Start: r.Start, End: r.End, Step: r.Step})
+	}
+	return ranges
+}
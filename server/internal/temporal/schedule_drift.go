@@ -0,0 +1,382 @@
+package temporal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/server/web"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+const (
+	// ScheduleDriftWorkflowID is the ID of the workflow comparing jobs with their schedules
+	ScheduleDriftWorkflowID = "olake-schedule-drift"
+	// ScheduleDriftSchedule is the cron schedule of ScheduleDriftWorkflow
+	ScheduleDriftSchedule = "*/15 * * * *"
+	// jobWorkflowType is the workflow type of RunJobWorkflow, which job schedules start
+	jobWorkflowType = "RunJobWorkflow"
+	// memo keys of the schedule action recording what the schedule was built from
+	scheduleMemoFrequency   = "olake_frequency"
+	scheduleMemoFingerprint = "olake_schedule_fingerprint"
+)
+
+// ScheduleDriftRepair is whether ScheduleDriftWorkflow repairs the differences it
+// finds, it only reports them by default
+var ScheduleDriftRepair bool
+
+func init() {
+	ScheduleDriftRepair = web.AppConfig.DefaultBool("SCHEDULE_DRIFT_REPAIR", false)
+}
+
+// scheduleFingerprint identifies the spec a schedule was built with
+func scheduleFingerprint(spec *client.ScheduleSpec) string {
+	data, _ := json.Marshal(spec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// actionMemo returns a memo value of the action of a described schedule, "" if it is not set
+func actionMemo(desc *client.ScheduleDescription, key string) string {
+	action, ok := desc.Schedule.Action.(*client.ScheduleWorkflowAction)
+	if !ok || action.Memo[key] == nil {
+		return ""
+	}
+	var value string
+	switch memo := action.Memo[key].(type) {
+	case string:
+		value = memo
+	case *commonpb.Payload:
+		// Describe returns the memo encoded
+		if err := converter.GetDefaultDataConverter().FromPayload(memo, &value); err != nil {
+			return ""
+		}
+	}
+	return value
+}
+
+// scheduleWorkflowType returns the workflow type the action of a described schedule starts
+func scheduleWorkflowType(desc *client.ScheduleDescription) string {
+	action, ok := desc.Schedule.Action.(*client.ScheduleWorkflowAction)
+	if !ok {
+		return ""
+	}
+	// Describe returns the workflow type name
+	name, _ := action.Workflow.(string)
+	return name
+}
+
+// CheckScheduleDrift compares the jobs of all projects with the job schedules
+// in Temporal and returns the differences: orphaned schedules, missing
+// schedules, schedules built from another frequency or schedule config,
+// schedules starting another workflow than RunJobWorkflow and schedules in the
+// wrong pause state. With repair it also brings the schedules
+// in line with the jobs and records the outcome on every difference.
+func (c *Client) CheckScheduleDrift(ctx context.Context, repair bool) ([]*models.ScheduleDrift, error) {
+	jobs, err := database.NewJobORM().GetAllForSchedules()
+	if err != nil {
+		return nil, err
+	}
+	return c.checkScheduleDrift(ctx, jobs, repair)
+}
+
+// checkScheduleDrift is CheckScheduleDrift for the jobs of all projects
+func (c *Client) checkScheduleDrift(ctx context.Context, jobs []*models.Job, repair bool) ([]*models.ScheduleDrift, error) {
+	expected := make(map[string]*models.Job, len(jobs))
+	for _, job := range jobs {
+		expected[ScheduleID(job.ProjectID, job.ID)] = job
+	}
+
+	iter, err := c.temporalClient.ScheduleClient().List(ctx, client.ScheduleListOptions{PageSize: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %s", err)
+	}
+	var orphans []string
+	for iter.HasNext() {
+		entry, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schedules: %s", err)
+		}
+		if _, ok := expected[entry.ID]; !ok && strings.HasPrefix(entry.ID, ScheduleIDPrefix) {
+			orphans = append(orphans, entry.ID)
+		}
+	}
+
+	var drifts []*models.ScheduleDrift
+	check := func(scheduleID string, job *models.Job) {
+		found, err := c.jobScheduleDrift(ctx, scheduleID, job)
+		if err != nil {
+			found = []*models.ScheduleDrift{newScheduleDrift(scheduleID, job, constants.ScheduleDriftUnchecked)}
+			found[0].Error = err.Error()
+		}
+		if repair {
+			for _, drift := range found {
+				c.repairScheduleDrift(ctx, drift, job)
+			}
+		}
+		drifts = append(drifts, found...)
+	}
+	for _, scheduleID := range orphans {
+		check(scheduleID, nil)
+	}
+	for _, job := range jobs {
+		check(ScheduleID(job.ProjectID, job.ID), job)
+	}
+	sort.SliceStable(drifts, func(i, j int) bool { return drifts[i].ScheduleID < drifts[j].ScheduleID })
+	return drifts, nil
+}
+
+// jobScheduleDrift compares a schedule with its job, job is nil when the job is in the trash or gone
+func (c *Client) jobScheduleDrift(ctx context.Context, scheduleID string, job *models.Job) ([]*models.ScheduleDrift, error) {
+	desc, err := c.temporalClient.ScheduleClient().GetHandle(ctx, scheduleID).Describe(ctx)
+	if _, notFound := err.(*serviceerror.NotFound); err != nil && !notFound {
+		return nil, fmt.Errorf("failed to describe schedule: %s", err)
+	}
+	exists := err == nil
+	switch {
+	case job == nil && !exists:
+		return nil, nil
+	case job == nil:
+		return []*models.ScheduleDrift{newScheduleDrift(scheduleID, nil, constants.ScheduleDriftOrphaned)}, nil
+	case !exists:
+		drift := newScheduleDrift(scheduleID, job, constants.ScheduleDriftMissing)
+		drift.Expected = job.Frequency
+		return []*models.ScheduleDrift{drift}, nil
+	}
+
+	spec, err := buildJobScheduleSpec(job)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule of job: %s", err)
+	}
+	var drifts []*models.ScheduleDrift
+	builtFromJob := actionMemo(desc, scheduleMemoFingerprint) == scheduleFingerprint(spec)
+	if !builtFromJob || !sameScheduleSpec(desc.Schedule.Spec, spec) {
+		drift := newScheduleDrift(scheduleID, job, constants.ScheduleDriftWrongCron)
+		drift.Expected, drift.Actual = job.Frequency, actionMemo(desc, scheduleMemoFrequency)
+		if builtFromJob {
+			// built from the job and changed in Temporal since
+			drift.Actual += " (spec changed in Temporal)"
+		}
+		drifts = append(drifts, drift)
+	}
+	if workflowType := scheduleWorkflowType(desc); workflowType != jobWorkflowType {
+		drift := newScheduleDrift(scheduleID, job, constants.ScheduleDriftWrongAction)
+		drift.Expected, drift.Actual = jobWorkflowType, workflowType
+		drifts = append(drifts, drift)
+	}
+	paused := desc.Schedule.State != nil && desc.Schedule.State.Paused
+	if paused == job.Active {
+		drift := newScheduleDrift(scheduleID, job, constants.ScheduleDriftWrongPause)
+		drift.Expected, drift.Actual = pauseState(!job.Active), pauseState(paused)
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// sameScheduleSpec reports whether the spec of a described schedule fires like
+// spec. Temporal returns cron expressions as calendars, so cron expressions and
+// calendars are compared by the values they match rather than as written.
+func sameScheduleSpec(actual, spec *client.ScheduleSpec) bool {
+	if actual == nil {
+		return false
+	}
+	return scheduleTimeZone(actual.TimeZoneName) == scheduleTimeZone(spec.TimeZoneName) &&
+		actual.Jitter == spec.Jitter && actual.StartAt.Equal(spec.StartAt) && actual.EndAt.Equal(spec.EndAt) &&
+		slices.Equal(intervalKeys(actual.Intervals), intervalKeys(spec.Intervals)) &&
+		slices.Equal(calendarKeys(actual.CronExpressions, actual.Calendars), calendarKeys(spec.CronExpressions, spec.Calendars)) &&
+		slices.Equal(calendarKeys(nil, actual.Skip), calendarKeys(nil, spec.Skip))
+}
+
+// scheduleTimeZone names the time zone of a spec, Temporal evaluates specs without one in UTC
+func scheduleTimeZone(name string) string {
+	if name == "" {
+		return "UTC"
+	}
+	return name
+}
+
+// intervalKeys describes intervals in order
+func intervalKeys(intervals []client.ScheduleIntervalSpec) []string {
+	keys := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		keys = append(keys, fmt.Sprintf("%s+%s", interval.Every, interval.Offset))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// calendarKeys describes the values the cron expressions and calendars match in order
+func calendarKeys(cronExpressions []string, calendars []client.ScheduleCalendarSpec) []string {
+	keys := make([]string, 0, len(cronExpressions)+len(calendars))
+	for _, expr := range cronExpressions {
+		calendar, err := utils.ParseCron(expr)
+		if err != nil {
+			// never equal to a valid calendar
+			keys = append(keys, expr)
+			continue
+		}
+		keys = append(keys, calendarKey(toCalendarSpec(calendar, "")))
+	}
+	for _, calendar := range calendars {
+		keys = append(keys, calendarKey(calendar))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// calendarKey describes the values a calendar matches. Empty fields match
+// like in Temporal: second, minute and hour 0 and every value of the others.
+func calendarKey(calendar client.ScheduleCalendarSpec) string {
+	return strings.Join([]string{
+		rangeValues(calendar.Second, 0, 59, "0"),
+		rangeValues(calendar.Minute, 0, 59, "0"),
+		rangeValues(calendar.Hour, 0, 23, "0"),
+		rangeValues(calendar.DayOfMonth, 1, 31, "*"),
+		rangeValues(calendar.Month, 1, 12, "*"),
+		rangeValues(calendar.DayOfWeek, 0, 7, "*"),
+		rangeValues(calendar.Year, minCalendarYear, maxCalendarYear, "*"),
+	}, " ")
+}
+
+// rangeValues lists the values between minValue and maxValue that ranges match,
+// "*" when they match all of them and empty when there are no ranges
+func rangeValues(ranges []client.ScheduleRange, minValue, maxValue int, empty string) string {
+	if len(ranges) == 0 {
+		return empty
+	}
+	matched := make(map[int]bool)
+	for _, r := range ranges {
+		// an unset end is the start and an unset step is 1
+		end, step := max(r.End, r.Start), max(r.Step, 1)
+		for value := max(r.Start, minValue); value <= min(end, maxValue); value++ {
+			if (value-r.Start)%step == 0 {
+				matched[value] = true
+			}
+		}
+	}
+	if maxValue == 7 {
+		// sunday is 0 and 7
+		if matched[7] {
+			matched[0] = true
+			delete(matched, 7)
+		}
+		maxValue = 6
+	}
+	if len(matched) == maxValue-minValue+1 {
+		return "*"
+	}
+	values := make([]string, 0, len(matched))
+	for value := minValue; value <= maxValue; value++ {
+		if matched[value] {
+			values = append(values, strconv.Itoa(value))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// repairScheduleDrift brings a schedule in line with its job and records the outcome on the drift
+func (c *Client) repairScheduleDrift(ctx context.Context, drift *models.ScheduleDrift, job *models.Job) {
+	var err error
+	switch drift.Kind {
+	case constants.ScheduleDriftOrphaned:
+		err = c.temporalClient.ScheduleClient().GetHandle(ctx, drift.ScheduleID).Delete(ctx)
+	case constants.ScheduleDriftMissing:
+		// created paused when the job is inactive
+		_, err = c.ManageSync(ctx, job, ActionCreate)
+	case constants.ScheduleDriftWrongCron, constants.ScheduleDriftWrongAction:
+		_, err = c.ManageSync(ctx, job, ActionUpdate)
+	case constants.ScheduleDriftWrongPause:
+		action := ActionUnpause
+		if !job.Active {
+			action = ActionPause
+		}
+		_, err = c.ManageSync(ctx, job, action)
+	default:
+		return
+	}
+	if err != nil {
+		drift.Error = err.Error()
+		return
+	}
+	drift.Repaired = true
+}
+
+// newScheduleDrift describes a difference of a schedule, the job is parsed from
+// the schedule ID of orphaned schedules
+func newScheduleDrift(scheduleID string, job *models.Job, kind string) *models.ScheduleDrift {
+	drift := &models.ScheduleDrift{ScheduleID: scheduleID, Kind: kind}
+	if job != nil {
+		drift.ProjectID, drift.JobID, drift.JobName = job.ProjectID, job.ID, job.Name
+		return drift
+	}
+	// schedule-sync-<project id>-<job id>, project IDs may contain dashes
+	rest := strings.TrimPrefix(scheduleID, ScheduleIDPrefix)
+	if i := strings.LastIndex(rest, "-"); i > 0 {
+		if jobID, err := strconv.Atoi(rest[i+1:]); err == nil {
+			drift.ProjectID, drift.JobID = rest[:i], jobID
+		}
+	}
+	return drift
+}
+
+func pauseState(paused bool) string {
+	if paused {
+		return "paused"
+	}
+	return "active"
+}
+
+// ScheduleDriftWorkflow compares the jobs with their schedules and repairs the
+// differences when ScheduleDriftRepair is set, the worker runs it on ScheduleDriftSchedule
+func ScheduleDriftWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 10,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	return workflow.ExecuteActivity(ctx, CheckScheduleDriftActivity).Get(ctx, nil)
+}
+
+// CheckScheduleDriftActivity compares the jobs with their schedules and logs the differences
+func CheckScheduleDriftActivity(ctx context.Context) error {
+	logger := activity.GetLogger(ctx)
+	c := &Client{temporalClient: activity.GetClient(ctx)}
+	drifts, err := c.CheckScheduleDrift(ctx, ScheduleDriftRepair)
+	if err != nil {
+		return err
+	}
+	for _, drift := range drifts {
+		logger.Warn("Schedule drift", "scheduleId", drift.ScheduleID, "jobId", drift.JobID, "kind", drift.Kind,
+			"expected", drift.Expected, "actual", drift.Actual, "repaired", drift.Repaired, "error", drift.Error)
+	}
+	return nil
+}
+
+// startScheduleDriftCheck starts ScheduleDriftWorkflow unless it is already running
+func startScheduleDriftCheck(ctx context.Context, temporalClient client.Client) error {
+	_, err := temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:           ScheduleDriftWorkflowID,
+		TaskQueue:    TaskQueue,
+		CronSchedule: ScheduleDriftSchedule,
+	}, ScheduleDriftWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to start schedule drift check: %s", err)
+	}
+	return nil
+}
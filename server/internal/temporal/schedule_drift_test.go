@@ -0,0 +1,217 @@
+package temporal
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// dailyCalendar is the calendar Temporal describes the cron "0 <hour> * * *" as
+func dailyCalendar(hour int) client.ScheduleCalendarSpec {
+	return client.ScheduleCalendarSpec{
+		Second:     []client.ScheduleRange{{Start: 0}},
+		Minute:     []client.ScheduleRange{{Start: 0}},
+		Hour:       []client.ScheduleRange{{Start: hour}},
+		DayOfMonth: []client.ScheduleRange{{Start: 1, End: 31, Step: 1}},
+		Month:      []client.ScheduleRange{{Start: 1, End: 12, Step: 1}},
+		DayOfWeek:  []client.ScheduleRange{{Start: 0, End: 6, Step: 1}},
+	}
+}
+
+func TestSameScheduleSpec(t *testing.T) {
+	spec := &client.ScheduleSpec{CronExpressions: []string{"0 0 * * *"}, TimeZoneName: "Asia/Kolkata"}
+	cases := []struct {
+		name   string
+		actual *client.ScheduleSpec
+		want   bool
+	}{
+		{"as created", &client.ScheduleSpec{CronExpressions: []string{"0 0 * * *"}, TimeZoneName: "Asia/Kolkata"}, true},
+		{"cron described as calendar", &client.ScheduleSpec{Calendars: []client.ScheduleCalendarSpec{dailyCalendar(0)}, TimeZoneName: "Asia/Kolkata"}, true},
+		{"sunday as 7", &client.ScheduleSpec{CronExpressions: []string{"0 0 * * 0-7"}, TimeZoneName: "Asia/Kolkata"}, true},
+		{"other hour", &client.ScheduleSpec{Calendars: []client.ScheduleCalendarSpec{dailyCalendar(5)}, TimeZoneName: "Asia/Kolkata"}, false},
+		{"other time zone", &client.ScheduleSpec{CronExpressions: []string{"0 0 * * *"}, TimeZoneName: "UTC"}, false},
+		{"extra interval", &client.ScheduleSpec{
+			CronExpressions: []string{"0 0 * * *"},
+			Intervals:       []client.ScheduleIntervalSpec{{Every: time.Hour}},
+			TimeZoneName:    "Asia/Kolkata",
+		}, false},
+		{"skip removed", nil, false},
+	}
+	for _, tc := range cases {
+		if got := sameScheduleSpec(tc.actual, spec); got != tc.want {
+			t.Errorf("%s: sameScheduleSpec = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+
+	withSkip := *spec
+	withSkip.Skip = []client.ScheduleCalendarSpec{dailyCalendar(0)}
+	if sameScheduleSpec(spec, &withSkip) {
+		t.Error("spec without the skip of a blackout window matches")
+	}
+	if sameScheduleSpec(&client.ScheduleSpec{CronExpressions: []string{"0 0 * *"}, TimeZoneName: "Asia/Kolkata"}, spec) {
+		t.Error("invalid cron expression matches")
+	}
+}
+
+// driftJobs has a schedule for every kind of drift and one without drift
+func driftJobs(t *testing.T, fake *fakeSchedules) []*models.Job {
+	t.Helper()
+	inSync := &models.Job{ID: 1, ProjectID: "p-1", Name: "in-sync", Frequency: "1-days", Active: true}
+	missing := &models.Job{ID: 2, ProjectID: "p-1", Name: "missing", Frequency: "1-days", Active: true}
+	paused := &models.Job{ID: 3, ProjectID: "p-1", Name: "paused", Frequency: "1-days", Active: false}
+	changed := &models.Job{ID: 4, ProjectID: "p-1", Name: "changed", Frequency: "1-days", Active: true}
+	rebuilt := &models.Job{ID: 5, ProjectID: "p-1", Name: "rebuilt", Frequency: "1-days", Active: true}
+	legacy := &models.Job{ID: 6, ProjectID: "p-1", Name: "legacy", Frequency: "1-days", Active: true}
+
+	fake.put(t, inSync, false)
+	fake.put(t, paused, false)
+	// changed in Temporal after OLake built it
+	fake.put(t, changed, false)
+	fake.schedules[ScheduleID("p-1", 4)].Schedule.Spec = &client.ScheduleSpec{
+		Calendars:    []client.ScheduleCalendarSpec{dailyCalendar(5)},
+		TimeZoneName: constants.DefaultTimeZone,
+	}
+	// built from the frequency the job had before
+	fake.put(t, &models.Job{ID: 5, ProjectID: "p-1", Frequency: "1-hours"}, false)
+	fake.put(t, &models.Job{ID: 99, ProjectID: "p-1", Frequency: "1-days"}, false)
+	// created before job workflows
+	fake.put(t, legacy, false)
+	fake.schedules[ScheduleID("p-1", 6)].Schedule.Action.(*client.ScheduleWorkflowAction).Workflow = "RunSyncWorkflow"
+	// schedules of other applications are left alone
+	fake.schedules["nightly-report"] = &client.ScheduleDescription{}
+	return []*models.Job{inSync, missing, paused, changed, rebuilt, legacy}
+}
+
+func TestCheckScheduleDrift(t *testing.T) {
+	fake := newFakeSchedules(t)
+	jobs := driftJobs(t, fake)
+	c := &Client{temporalClient: fake}
+
+	drifts, err := c.checkScheduleDrift(context.Background(), jobs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		ScheduleID("p-1", 2):  constants.ScheduleDriftMissing,
+		ScheduleID("p-1", 3):  constants.ScheduleDriftWrongPause,
+		ScheduleID("p-1", 4):  constants.ScheduleDriftWrongCron,
+		ScheduleID("p-1", 5):  constants.ScheduleDriftWrongCron,
+		ScheduleID("p-1", 6):  constants.ScheduleDriftWrongAction,
+		ScheduleID("p-1", 99): constants.ScheduleDriftOrphaned,
+	}
+	if len(drifts) != len(want) {
+		t.Fatalf("found %d drifts, want %d: %+v", len(drifts), len(want), drifts)
+	}
+	for _, drift := range drifts {
+		if want[drift.ScheduleID] != drift.Kind {
+			t.Errorf("%s drifted as %s, want %s", drift.ScheduleID, drift.Kind, want[drift.ScheduleID])
+		}
+		if drift.Repaired || drift.Error != "" {
+			t.Errorf("%s is repaired or failed by a report: %+v", drift.ScheduleID, drift)
+		}
+		switch drift.JobID {
+		case 4:
+			if !strings.Contains(drift.Actual, "spec changed in Temporal") {
+				t.Errorf("schedule changed in Temporal reports %q", drift.Actual)
+			}
+		case 5:
+			if drift.Expected != "1-days" || drift.Actual != "1-hours" {
+				t.Errorf("rebuilt schedule reports %q for %q", drift.Actual, drift.Expected)
+			}
+		case 6:
+			if drift.Expected != "RunJobWorkflow" || drift.Actual != "RunSyncWorkflow" {
+				t.Errorf("legacy schedule reports %q for %q", drift.Actual, drift.Expected)
+			}
+		case 99:
+			if drift.ProjectID != "p-1" {
+				t.Errorf("orphaned schedule of project %q, want p-1", drift.ProjectID)
+			}
+		}
+	}
+	if _, ok := fake.schedules[ScheduleID("p-1", 2)]; ok || len(fake.schedules) != 7 {
+		t.Error("a report changed the schedules")
+	}
+
+	drifts, err = c.checkScheduleDrift(context.Background(), jobs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, drift := range drifts {
+		if !drift.Repaired {
+			t.Errorf("%s drift %s is not repaired: %s", drift.ScheduleID, drift.Kind, drift.Error)
+		}
+	}
+	if drifts, err = c.checkScheduleDrift(context.Background(), jobs, false); err != nil || len(drifts) != 0 {
+		t.Errorf("found %d drifts after the repair, want none: %v", len(drifts), err)
+	}
+	if _, ok := fake.schedules["nightly-report"]; !ok {
+		t.Error("repair removed a schedule of another application")
+	}
+}
+
+// TestApplyScheduleOperations converges every kind of drift of a job, whatever the recorded action
+func TestApplyScheduleOperations(t *testing.T) {
+	fake := newFakeSchedules(t)
+	jobs := driftJobs(t, fake)
+	delete(fake.schedules, "nightly-report")
+	c := &Client{temporalClient: fake}
+
+	ops := []*models.ScheduleOperation{scheduleOp(&models.Job{ID: 99, ProjectID: "p-1"}, ActionDelete)}
+	for _, job := range jobs {
+		ops = append(ops, scheduleOp(job, ActionUpdate))
+	}
+	outbox := &fakeOutbox{}
+	if err := c.applyScheduleOperations(context.Background(), ops, jobGetter(jobs...), outbox); err != nil {
+		t.Fatal(err)
+	}
+	if len(outbox.completed) != len(ops) || len(outbox.failed) != 0 {
+		t.Errorf("completed %d and failed %d operations, want all %d completed", len(outbox.completed), len(outbox.failed), len(ops))
+	}
+	if drifts, err := c.checkScheduleDrift(context.Background(), jobs, false); err != nil || len(drifts) != 0 {
+		t.Errorf("found %d drifts after applying the operations, want none: %v", len(drifts), err)
+	}
+}
+
+func TestStaleScheduleActions(t *testing.T) {
+	fake := newFakeSchedules(t)
+	jobs := driftJobs(t, fake)
+	// built by older servers as an interval, which moves with DST
+	fortnightly := &models.Job{ID: 7, ProjectID: "p-1", Name: "fortnightly", Frequency: "2-weeks", Active: true}
+	fake.put(t, fortnightly, false)
+	fake.schedules[ScheduleID("p-1", 7)].Schedule.Spec = &client.ScheduleSpec{
+		Intervals:    []client.ScheduleIntervalSpec{{Every: 14 * 24 * time.Hour, Offset: 3 * 24 * time.Hour}},
+		TimeZoneName: constants.DefaultTimeZone,
+	}
+	jobs = append(jobs, fortnightly, &models.Job{ID: 8, ProjectID: "p-1", Name: "current", Frequency: "2-weeks", Active: true})
+	fake.put(t, jobs[len(jobs)-1], false)
+	c := &Client{temporalClient: fake}
+
+	stale, err := c.staleScheduleActions(context.Background(), jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 2 || stale[0].Name != "legacy" || stale[1].Name != "fortnightly" {
+		t.Fatalf("stale schedules = %+v, want the legacy and the fortnightly job", stale)
+	}
+
+	outbox := &fakeOutbox{}
+	ops := []*models.ScheduleOperation{scheduleOp(stale[0], ActionUpdate), scheduleOp(stale[1], ActionUpdate)}
+	if err := c.applyScheduleOperations(context.Background(), ops, jobGetter(jobs...), outbox); err != nil {
+		t.Fatal(err)
+	}
+	if got := scheduleWorkflowType(fake.schedules[ScheduleID("p-1", 6)]); got != jobWorkflowType {
+		t.Errorf("migrated schedule starts %s, want %s", got, jobWorkflowType)
+	}
+	if spec := fake.schedules[ScheduleID("p-1", 7)].Schedule.Spec; len(spec.Intervals) != 0 || len(spec.CronExpressions) != 1 {
+		t.Errorf("migrated fortnightly schedule = %+v, want the weekly cron", spec)
+	}
+	if stale, err = c.staleScheduleActions(context.Background(), jobs); err != nil || len(stale) != 0 {
+		t.Errorf("stale schedules after the migration = %+v, %v, want none", stale, err)
+	}
+}
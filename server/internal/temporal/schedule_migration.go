@@ -0,0 +1,114 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/utils"
+)
+
+// ScheduleActionMigrationWorkflowID is the ID of the workflow moving schedules
+// created by older servers onto the current schedule. It runs once, a closed run
+// keeps the worker from starting it again, so the ID changes with every new migration.
+const ScheduleActionMigrationWorkflowID = "olake-schedule-action-migration-2"
+
+// ScheduleActionMigrationWorkflow records an update in the schedule outbox for
+// every job whose schedule still starts RunSyncWorkflow directly, which skips
+// dependencies, concurrency limits, retries and blackout checks, or runs every
+// N weeks on an interval, which moves with DST, and applies them
+func ScheduleActionMigrationWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 10,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	return workflow.ExecuteActivity(ctx, MigrateScheduleActionsActivity).Get(ctx, nil)
+}
+
+// MigrateScheduleActionsActivity moves the stale schedules onto the current
+// schedule through the schedule outbox
+func MigrateScheduleActionsActivity(ctx context.Context) error {
+	logger := activity.GetLogger(ctx)
+	jobs, err := database.NewJobORM().GetAllForSchedules()
+	if err != nil {
+		return err
+	}
+	c := &Client{temporalClient: activity.GetClient(ctx)}
+	stale, err := c.staleScheduleActions(ctx, jobs)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	ops, err := database.NewScheduleOutboxORM().Enqueue(stale, string(ActionUpdate))
+	if err != nil {
+		return err
+	}
+	logger.Info("Moving schedules onto the current schedule", "count", len(ops))
+	// failed operations stay pending for ScheduleOutboxWorkflow
+	if err := c.ApplyScheduleOperations(ctx, ops); err != nil {
+		logger.Warn("Failed to apply schedule operations", "error", err)
+	}
+	return nil
+}
+
+// staleScheduleActions returns the jobs whose schedule starts another workflow
+// than RunJobWorkflow or runs every N weeks on an interval, jobs without a
+// schedule are left to the drift check
+func (c *Client) staleScheduleActions(ctx context.Context, jobs []*models.Job) ([]*models.Job, error) {
+	var stale []*models.Job
+	for _, job := range jobs {
+		desc, err := c.temporalClient.ScheduleClient().GetHandle(ctx, ScheduleID(job.ProjectID, job.ID)).Describe(ctx)
+		if _, notFound := err.(*serviceerror.NotFound); notFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe schedule of job[%d]: %s", job.ID, err)
+		}
+		if scheduleWorkflowType(desc) != jobWorkflowType || weekIntervalSchedule(job, desc) {
+			stale = append(stale, job)
+		}
+	}
+	return stale, nil
+}
+
+// weekIntervalSchedule reports whether the schedule of a job running every N
+// weeks still fires on the interval older servers built for it
+func weekIntervalSchedule(job *models.Job, desc *client.ScheduleDescription) bool {
+	value, unit, err := utils.ParseFrequency(job.Frequency)
+	if err != nil || unit != "weeks" || value <= 1 || desc.Schedule.Spec == nil {
+		return false
+	}
+	for _, interval := range desc.Schedule.Spec.Intervals {
+		if interval.Every == time.Duration(value)*7*24*time.Hour {
+			return true
+		}
+	}
+	return false
+}
+
+// startScheduleActionMigration starts ScheduleActionMigrationWorkflow unless it
+// is running or already ran
+func startScheduleActionMigration(ctx context.Context, temporalClient client.Client) error {
+	_, err := temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:                    ScheduleActionMigrationWorkflowID,
+		TaskQueue:             TaskQueue,
+		WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+	}, ScheduleActionMigrationWorkflow)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if err != nil && !errors.As(err, &alreadyStarted) {
+		return fmt.Errorf("failed to start schedule action migration: %s", err)
+	}
+	return nil
+}
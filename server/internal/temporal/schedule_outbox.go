@@ -0,0 +1,143 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+const (
+	// ScheduleOutboxWorkflowID is the ID of the workflow applying the schedule outbox
+	ScheduleOutboxWorkflowID = "olake-schedule-outbox"
+	// ScheduleOutboxSchedule is the cron schedule of ScheduleOutboxWorkflow
+	ScheduleOutboxSchedule = "* * * * *"
+	// ScheduleOutboxMaxAttempts is the number of attempts after which an operation is marked failed
+	ScheduleOutboxMaxAttempts = 20
+	// scheduleOutboxDelay leaves new operations to the request that recorded them
+	scheduleOutboxDelay = 30 * time.Second
+	// scheduleOutboxBatch is the number of operations applied per run
+	scheduleOutboxBatch = 100
+	// scheduleOutboxRetention is how long applied and failed operations are kept
+	scheduleOutboxRetention = 7 * 24 * time.Hour
+)
+
+// scheduleOutbox marks applied and failed schedule operations, it is the
+// database.ScheduleOutboxORM outside of tests
+type scheduleOutbox interface {
+	Complete(ops []*models.ScheduleOperation) error
+	Fail(ops []*models.ScheduleOperation, cause error, maxAttempts int) error
+}
+
+// ApplyScheduleOperations brings the schedules of the jobs of operations in
+// line with the jobs and marks the operations done. The schedule converges to
+// the job row rather than replaying the action, so applying an operation twice
+// or out of order is harmless and the operations of a job are applied together.
+func (c *Client) ApplyScheduleOperations(ctx context.Context, ops []*models.ScheduleOperation) error {
+	return c.applyScheduleOperations(ctx, ops, database.NewJobORM().GetForSchedule, database.NewScheduleOutboxORM())
+}
+
+// applyScheduleOperations is ApplyScheduleOperations reading the jobs with
+// getJob, which returns nil for a job in the trash or gone, and marking the
+// operations in outbox. Operations that fail stay pending for the next run.
+func (c *Client) applyScheduleOperations(ctx context.Context, ops []*models.ScheduleOperation, getJob func(id int) (*models.Job, error), outbox scheduleOutbox) error {
+	byJob := make(map[int][]*models.ScheduleOperation)
+	var jobIDs []int
+	for _, op := range ops {
+		if _, ok := byJob[op.JobID]; !ok {
+			jobIDs = append(jobIDs, op.JobID)
+		}
+		byJob[op.JobID] = append(byJob[op.JobID], op)
+	}
+
+	var errs []error
+	for _, jobID := range jobIDs {
+		jobOps := byJob[jobID]
+		err := func() error {
+			job, err := getJob(jobID)
+			if err != nil {
+				return err
+			}
+			return c.convergeSchedule(ctx, ScheduleID(jobOps[0].ProjectID, jobID), job)
+		}()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job[%d]: %s", jobID, err))
+			if err := outbox.Fail(jobOps, err, ScheduleOutboxMaxAttempts); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := outbox.Complete(jobOps); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// convergeSchedule repairs every difference between a job and its schedule,
+// job is nil when the job is in the trash or gone
+func (c *Client) convergeSchedule(ctx context.Context, scheduleID string, job *models.Job) error {
+	drifts, err := c.jobScheduleDrift(ctx, scheduleID, job)
+	if err != nil {
+		return err
+	}
+	for _, drift := range drifts {
+		if c.repairScheduleDrift(ctx, drift, job); drift.Error != "" {
+			return fmt.Errorf("%s schedule: %s", drift.Kind, drift.Error)
+		}
+	}
+	return nil
+}
+
+// ScheduleOutboxWorkflow applies the schedule operations their request did not
+// apply, the worker runs it on ScheduleOutboxSchedule
+func ScheduleOutboxWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	return workflow.ExecuteActivity(ctx, ProcessScheduleOutboxActivity).Get(ctx, nil)
+}
+
+// ProcessScheduleOutboxActivity applies a batch of pending schedule operations
+// and deletes the operations processed before the retention period
+func ProcessScheduleOutboxActivity(ctx context.Context) error {
+	logger := activity.GetLogger(ctx)
+	outbox := database.NewScheduleOutboxORM()
+	if deleted, err := outbox.DeleteProcessedBefore(time.Now().Add(-scheduleOutboxRetention)); err != nil {
+		logger.Warn("Failed to delete processed schedule operations", "error", err)
+	} else if deleted > 0 {
+		logger.Info("Deleted processed schedule operations", "count", deleted)
+	}
+
+	ops, err := outbox.GetPending(time.Now().Add(-scheduleOutboxDelay), scheduleOutboxBatch)
+	if err != nil || len(ops) == 0 {
+		return err
+	}
+	c := &Client{temporalClient: activity.GetClient(ctx)}
+	// failed operations stay pending for the next run, the activity is not retried for them
+	if err := c.ApplyScheduleOperations(ctx, ops); err != nil {
+		logger.Warn("Failed to apply schedule operations", "error", err)
+	}
+	return nil
+}
+
+// startScheduleOutbox starts ScheduleOutboxWorkflow unless it is already running
+func startScheduleOutbox(ctx context.Context, temporalClient client.Client) error {
+	_, err := temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:           ScheduleOutboxWorkflowID,
+		TaskQueue:    TaskQueue,
+		CronSchedule: ScheduleOutboxSchedule,
+	}, ScheduleOutboxWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to start schedule outbox: %s", err)
+	}
+	return nil
+}
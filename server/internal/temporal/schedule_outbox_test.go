@@ -0,0 +1,304 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// fakeSchedules is a Temporal client keeping its schedules in memory, every
+// schedule change fails with err while it is set
+type fakeSchedules struct {
+	client.Client
+	schedules map[string]*client.ScheduleDescription
+	err       error
+}
+
+func newFakeSchedules(t *testing.T) *fakeSchedules {
+	t.Helper()
+	// the schedules are built without project blackout windows
+	load := projectBlackoutWindows
+	projectBlackoutWindows = func(string) ([]models.BlackoutWindow, error) { return nil, nil }
+	t.Cleanup(func() { projectBlackoutWindows = load })
+	return &fakeSchedules{schedules: map[string]*client.ScheduleDescription{}}
+}
+
+// put adds the schedule ManageSync creates for job
+func (f *fakeSchedules) put(t *testing.T, job *models.Job, paused bool) {
+	t.Helper()
+	spec, err := buildJobScheduleSpec(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.schedules[ScheduleID(job.ProjectID, job.ID)] = &client.ScheduleDescription{Schedule: client.Schedule{
+		Spec:   spec,
+		Action: described(jobScheduleAction(job, spec)),
+		State:  &client.ScheduleState{Paused: paused},
+	}}
+}
+
+// described returns a schedule action as Describe returns it, naming the workflow type
+func described(action client.ScheduleAction) client.ScheduleAction {
+	workflowAction, ok := action.(*client.ScheduleWorkflowAction)
+	if !ok {
+		return action
+	}
+	if _, named := workflowAction.Workflow.(string); named {
+		return action
+	}
+	copied := *workflowAction
+	name := runtime.FuncForPC(reflect.ValueOf(workflowAction.Workflow).Pointer()).Name()
+	copied.Workflow = name[strings.LastIndex(name, ".")+1:]
+	return &copied
+}
+
+func (f *fakeSchedules) ScheduleClient() client.ScheduleClient {
+	return fakeScheduleClient{f}
+}
+
+type fakeScheduleClient struct {
+	fake *fakeSchedules
+}
+
+func (c fakeScheduleClient) Create(_ context.Context, options client.ScheduleOptions) (client.ScheduleHandle, error) {
+	if c.fake.err != nil {
+		return nil, c.fake.err
+	}
+	spec := options.Spec
+	c.fake.schedules[options.ID] = &client.ScheduleDescription{Schedule: client.Schedule{
+		Spec:   &spec,
+		Action: described(options.Action),
+		State:  &client.ScheduleState{Paused: options.Paused},
+	}}
+	return fakeScheduleHandle{id: options.ID, fake: c.fake}, nil
+}
+
+func (c fakeScheduleClient) GetHandle(_ context.Context, scheduleID string) client.ScheduleHandle {
+	return fakeScheduleHandle{id: scheduleID, fake: c.fake}
+}
+
+func (c fakeScheduleClient) List(context.Context, client.ScheduleListOptions) (client.ScheduleListIterator, error) {
+	ids := make([]string, 0, len(c.fake.schedules))
+	for id := range c.fake.schedules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return &fakeScheduleIterator{ids: ids}, nil
+}
+
+type fakeScheduleIterator struct {
+	ids []string
+}
+
+func (i *fakeScheduleIterator) HasNext() bool {
+	return len(i.ids) > 0
+}
+
+func (i *fakeScheduleIterator) Next() (*client.ScheduleListEntry, error) {
+	entry := &client.ScheduleListEntry{ID: i.ids[0]}
+	i.ids = i.ids[1:]
+	return entry, nil
+}
+
+type fakeScheduleHandle struct {
+	client.ScheduleHandle
+	id   string
+	fake *fakeSchedules
+}
+
+func (h fakeScheduleHandle) GetID() string {
+	return h.id
+}
+
+// schedule returns the described schedule, it fails while err is set when change is
+func (h fakeScheduleHandle) schedule(change bool) (*client.ScheduleDescription, error) {
+	if change && h.fake.err != nil {
+		return nil, h.fake.err
+	}
+	desc, ok := h.fake.schedules[h.id]
+	if !ok {
+		return nil, serviceerror.NewNotFound("schedule not found")
+	}
+	return desc, nil
+}
+
+func (h fakeScheduleHandle) Describe(context.Context) (*client.ScheduleDescription, error) {
+	return h.schedule(false)
+}
+
+func (h fakeScheduleHandle) Delete(context.Context) error {
+	if _, err := h.schedule(true); err != nil {
+		return err
+	}
+	delete(h.fake.schedules, h.id)
+	return nil
+}
+
+func (h fakeScheduleHandle) Update(_ context.Context, options client.ScheduleUpdateOptions) error {
+	desc, err := h.schedule(true)
+	if err != nil {
+		return err
+	}
+	update, err := options.DoUpdate(client.ScheduleUpdateInput{Description: *desc})
+	if err != nil {
+		return err
+	}
+	desc.Schedule = *update.Schedule
+	desc.Schedule.Action = described(desc.Schedule.Action)
+	return nil
+}
+
+func (h fakeScheduleHandle) Pause(context.Context, client.SchedulePauseOptions) error {
+	desc, err := h.schedule(true)
+	if err != nil {
+		return err
+	}
+	desc.Schedule.State.Paused = true
+	return nil
+}
+
+func (h fakeScheduleHandle) Unpause(context.Context, client.ScheduleUnpauseOptions) error {
+	desc, err := h.schedule(true)
+	if err != nil {
+		return err
+	}
+	desc.Schedule.State.Paused = false
+	return nil
+}
+
+// fakeOutbox records the operations marked applied and failed
+type fakeOutbox struct {
+	completed, failed []*models.ScheduleOperation
+}
+
+func (o *fakeOutbox) Complete(ops []*models.ScheduleOperation) error {
+	o.completed = append(o.completed, ops...)
+	return nil
+}
+
+func (o *fakeOutbox) Fail(ops []*models.ScheduleOperation, _ error, _ int) error {
+	o.failed = append(o.failed, ops...)
+	return nil
+}
+
+// jobGetter returns the jobs by ID, the missing ones are in the trash
+func jobGetter(jobs ...*models.Job) func(id int) (*models.Job, error) {
+	return func(id int) (*models.Job, error) {
+		for _, job := range jobs {
+			if job.ID == id {
+				return job, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func scheduleOp(job *models.Job, action SyncAction) *models.ScheduleOperation {
+	return &models.ScheduleOperation{ProjectID: job.ProjectID, JobID: job.ID, Action: string(action)}
+}
+
+// TestApplyConnectorScheduleOperations applies the operations a source or
+// destination delete and restore record for its jobs
+func TestApplyConnectorScheduleOperations(t *testing.T) {
+	cases := []struct {
+		name    string
+		action  SyncAction
+		exists  bool
+		trashed bool
+		// removed is whether the schedule is gone after the operation, it is paused otherwise
+		removed bool
+	}{
+		{"cascade removes the schedule", ActionDelete, true, true, true},
+		{"cascade of a job without schedule", ActionDelete, false, true, true},
+		{"detach pauses the schedule", ActionPause, true, false, false},
+		{"restore creates the schedule paused", ActionCreate, false, false, false},
+		{"restore pauses a schedule left behind", ActionCreate, true, false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeSchedules(t)
+			// cascaded and detached jobs are inactive once the delete commits
+			job := &models.Job{ID: 7, ProjectID: "p-1", Name: "orders", Frequency: "1-days", Active: false}
+			if tc.exists {
+				fake.put(t, job, false)
+			}
+			getJob := jobGetter(job)
+			if tc.trashed {
+				getJob = jobGetter()
+			}
+			outbox := &fakeOutbox{}
+			c := &Client{temporalClient: fake}
+			if err := c.applyScheduleOperations(context.Background(), []*models.ScheduleOperation{scheduleOp(job, tc.action)}, getJob, outbox); err != nil {
+				t.Fatal(err)
+			}
+			if len(outbox.completed) != 1 || len(outbox.failed) != 0 {
+				t.Errorf("completed %d and failed %d operations, want the operation completed", len(outbox.completed), len(outbox.failed))
+			}
+			desc, exists := fake.schedules[ScheduleID(job.ProjectID, job.ID)]
+			switch {
+			case tc.removed && exists:
+				t.Error("schedule exists, want it removed")
+			case !tc.removed && !exists:
+				t.Error("schedule is missing")
+			case !tc.removed && !desc.Schedule.State.Paused:
+				t.Error("schedule is active, want it paused")
+			}
+		})
+	}
+}
+
+// TestApplyScheduleOperationsRetry leaves failed operations pending and
+// converges the schedule once Temporal takes the change
+func TestApplyScheduleOperationsRetry(t *testing.T) {
+	fake := newFakeSchedules(t)
+	deleted := &models.Job{ID: 1, ProjectID: "p-1", Frequency: "1-days"}
+	detached := &models.Job{ID: 2, ProjectID: "p-1", Frequency: "1-days"}
+	fake.put(t, deleted, false)
+	fake.put(t, detached, false)
+	// both operations of the detached job are applied together
+	ops := []*models.ScheduleOperation{scheduleOp(deleted, ActionDelete), scheduleOp(detached, ActionPause), scheduleOp(detached, ActionPause)}
+	c := &Client{temporalClient: fake}
+
+	fake.err = errors.New("temporal is unavailable")
+	outbox := &fakeOutbox{}
+	if err := c.applyScheduleOperations(context.Background(), ops, jobGetter(detached), outbox); err == nil {
+		t.Fatal("applied the operations while Temporal fails")
+	}
+	if len(outbox.failed) != 3 || len(outbox.completed) != 0 {
+		t.Fatalf("failed %d and completed %d operations, want all 3 failed", len(outbox.failed), len(outbox.completed))
+	}
+	if len(fake.schedules) != 2 || fake.schedules[ScheduleID("p-1", 2)].Schedule.State.Paused {
+		t.Fatal("schedules changed while Temporal fails")
+	}
+
+	fake.err = nil
+	outbox = &fakeOutbox{}
+	if err := c.applyScheduleOperations(context.Background(), ops, jobGetter(detached), outbox); err != nil {
+		t.Fatal(err)
+	}
+	if len(outbox.completed) != 3 {
+		t.Errorf("completed %d operations on retry, want 3", len(outbox.completed))
+	}
+	if _, ok := fake.schedules[ScheduleID("p-1", 1)]; ok {
+		t.Error("schedule of the deleted job exists after retry")
+	}
+	if !fake.schedules[ScheduleID("p-1", 2)].Schedule.State.Paused {
+		t.Error("schedule of the detached job is active after retry")
+	}
+
+	// a job that can not be read is retried too
+	outbox = &fakeOutbox{}
+	failingGet := func(int) (*models.Job, error) { return nil, errors.New("database is unavailable") }
+	if err := c.applyScheduleOperations(context.Background(), ops[1:2], failingGet, outbox); err == nil || len(outbox.failed) != 1 {
+		t.Errorf("failed %d operations without the job, want 1 and an error", len(outbox.failed))
+	}
+}
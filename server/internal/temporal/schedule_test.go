@@ -0,0 +1,250 @@
+package temporal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestBuildScheduleSpec(t *testing.T) {
+	spec, err := BuildScheduleSpec("2-weeks", &models.ScheduleConfig{TimeZone: "Asia/Kolkata"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// intervals would move with DST, so every 2 weeks fires weekly and skips every other week
+	if len(spec.CronExpressions) != 1 || spec.CronExpressions[0] != "0 0 * * 0" || len(spec.Intervals) != 0 {
+		t.Fatalf("2-weeks builds %v and %v, want the weekly cron", spec.CronExpressions, spec.Intervals)
+	}
+	if len(spec.Skip) != weekSkipHorizon/2 {
+		t.Errorf("2-weeks skips %d weeks, want every other week of %d", len(spec.Skip), weekSkipHorizon)
+	}
+	if spec.TimeZoneName != "Asia/Kolkata" {
+		t.Errorf("time zone = %q, want Asia/Kolkata", spec.TimeZoneName)
+	}
+
+	spec, err = BuildScheduleSpec("1-weeks", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.CronExpressions) != 1 || spec.CronExpressions[0] != "0 0 * * 0" || spec.TimeZoneName != constants.DefaultTimeZone {
+		t.Errorf("1-weeks builds %v in %q, want a sunday cron in the default time zone", spec.CronExpressions, spec.TimeZoneName)
+	}
+
+	spec, err = BuildScheduleSpec("3-years", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Calendars) != 1 || len(spec.Calendars[0].Year) != 1 || spec.Calendars[0].Year[0].Step != 3 {
+		t.Errorf("3-years builds %+v, want a calendar every 3 years", spec.Calendars)
+	}
+}
+
+func TestWeekSkipSpecs(t *testing.T) {
+	// a friday in the week of sunday 2026-03-01, which is in the cycle of every 2 weeks
+	at := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		frequency string
+		config    *models.ScheduleConfig
+		// skipped are the sundays of the weeks skipped from at
+		skipped []string
+	}{
+		{"every 2 weeks", "2-weeks", &models.ScheduleConfig{TimeZone: "America/New_York"},
+			[]string{"2026-03-08", "2026-03-22", "2026-04-05", "2026-04-19"}},
+		{"every 3 weeks", "3-weeks", &models.ScheduleConfig{TimeZone: "UTC"},
+			[]string{"2026-03-01", "2026-03-15", "2026-03-22", "2026-04-05", "2026-04-12"}},
+		{"every 2 weeks from start_at", "2-weeks", &models.ScheduleConfig{TimeZone: "UTC", StartAt: timePtr(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))},
+			[]string{"2026-03-01", "2026-03-15", "2026-03-29", "2026-04-12"}},
+		{"weekly", "1-weeks", nil, nil},
+	}
+	for _, tc := range cases {
+		schedule, err := compileSchedule(tc.frequency, tc.config, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var skipped []string
+		for _, spec := range schedule.weekSkipSpecs(at) {
+			day := time.Date(spec.Year[0].Start, time.Month(spec.Month[0].Start), spec.DayOfMonth[0].Start, 0, 0, 0, 0, time.UTC)
+			if day.Weekday() != time.Sunday || spec.Hour[0].End != 23 || spec.Second[0].End != 59 {
+				t.Errorf("%s: skip spec %+v does not cover a sunday", tc.name, spec)
+			}
+			skipped = append(skipped, day.Format("2006-01-02"))
+		}
+		if !reflect.DeepEqual(skipped, tc.skipped) {
+			t.Errorf("%s: skipped weeks = %v, want %v", tc.name, skipped, tc.skipped)
+		}
+	}
+}
+
+func TestBuildScheduleSpecInvalid(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		frequency string
+		config    *models.ScheduleConfig
+	}{
+		{"nothing to run", "", &models.ScheduleConfig{}},
+		{"invalid frequency", "0-hours", nil},
+		{"invalid cron", "* * * *", nil},
+		{"invalid time zone", "1-days", &models.ScheduleConfig{TimeZone: "Mars/Olympus"}},
+		{"invalid calendar", "", &models.ScheduleConfig{Calendars: []models.CalendarSpec{{Hour: "25"}}}},
+		{"interval below a minute", "", &models.ScheduleConfig{Intervals: []models.IntervalSpec{{Every: "30s"}}}},
+		{"offset not below every", "", &models.ScheduleConfig{Intervals: []models.IntervalSpec{{Every: "1h", Offset: "1h"}}}},
+		{"negative jitter", "1-hours", &models.ScheduleConfig{Jitter: "-1m"}},
+		{"end before start", "1-hours", &models.ScheduleConfig{StartAt: &start, EndAt: &start}},
+	}
+	for _, tc := range cases {
+		if _, err := BuildScheduleSpec(tc.frequency, tc.config, nil); err == nil {
+			t.Errorf("%s: BuildScheduleSpec succeeded, want an error", tc.name)
+		}
+	}
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	from := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC) // a wednesday
+	for _, timeZone := range []string{"UTC", "Asia/Kolkata", "America/Los_Angeles", "Asia/Tokyo"} {
+		// from is in the standard time of all four zones
+		fireTimes, location, err := PreviewSchedule("3-weeks", &models.ScheduleConfig{TimeZone: timeZone}, nil, from, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fireAt := fireTimes[0].In(location); fireAt.Weekday() != time.Sunday || fireAt.Hour() != 0 || fireAt.Minute() != 0 {
+			t.Errorf("3-weeks in %s fires at %s, want sundays at midnight", timeZone, fireAt)
+		}
+	}
+
+	fireTimes, _, err := PreviewSchedule("2-weeks", &models.ScheduleConfig{TimeZone: "UTC"}, nil, from, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fireAt := range fireTimes {
+		if fireAt.Weekday() != time.Sunday || fireAt.Hour() != 0 {
+			t.Errorf("2-weeks fires at %s, want sundays at midnight", fireAt)
+		}
+		if i > 0 && fireAt.Sub(fireTimes[i-1]) != 14*24*time.Hour {
+			t.Errorf("2-weeks fires at %s after %s, want 14 days apart", fireAt, fireTimes[i-1])
+		}
+	}
+
+	config := &models.ScheduleConfig{
+		TimeZone:  "America/New_York",
+		Calendars: []models.CalendarSpec{{Minute: "30", Hour: "9", DayOfWeek: "MON-FRI"}},
+	}
+	fireTimes, location, err := PreviewSchedule("", config, nil, from, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.String() != "America/New_York" {
+		t.Errorf("location = %s, want America/New_York", location)
+	}
+	want := []time.Time{
+		time.Date(2026, 3, 4, 14, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 5, 14, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 6, 14, 30, 0, 0, time.UTC),
+	}
+	for i := range want {
+		if i >= len(fireTimes) || !fireTimes[i].Equal(want[i]) {
+			t.Fatalf("fire times = %v, want %v", fireTimes, want)
+		}
+	}
+}
+
+func TestPreviewScheduleCases(t *testing.T) {
+	// a friday, two days before daylight saving time starts in New York
+	from := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	newYork := &models.ScheduleConfig{TimeZone: "America/New_York"}
+	cases := []struct {
+		name      string
+		frequency string
+		config    *models.ScheduleConfig
+		windows   []models.BlackoutWindow
+		from      time.Time
+		count     int
+		// want are the fire times in the time zone of the schedule
+		want []string
+	}{
+		{"daily across the start of dst", "1-days", newYork, nil, from, 3,
+			[]string{"2026-03-07 00:00 EST", "2026-03-08 00:00 EST", "2026-03-09 00:00 EDT"}},
+		{"weekly across the start of dst", "1-weeks", newYork, nil, from, 2,
+			[]string{"2026-03-08 00:00 EST", "2026-03-15 00:00 EDT"}},
+		{"cron across the end of dst fires once in the repeated hour", "30 1 * * *", newYork, nil, time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC), 3,
+			[]string{"2026-11-01 01:30 EDT", "2026-11-02 01:30 EST", "2026-11-03 01:30 EST"}},
+		{"every 2 weeks", "2-weeks", &models.ScheduleConfig{TimeZone: "UTC"}, nil, from, 3,
+			[]string{"2026-03-15 00:00 UTC", "2026-03-29 00:00 UTC", "2026-04-12 00:00 UTC"}},
+		{"every 2 weeks across the start of dst", "2-weeks", newYork, nil, time.Date(2026, 2, 26, 12, 0, 0, 0, time.UTC), 3,
+			[]string{"2026-03-01 00:00 EST", "2026-03-15 00:00 EDT", "2026-03-29 00:00 EDT"}},
+		{"every 2 weeks across the end of dst", "2-weeks", newYork, nil, time.Date(2026, 10, 22, 12, 0, 0, 0, time.UTC), 3,
+			[]string{"2026-10-25 00:00 EDT", "2026-11-08 00:00 EST", "2026-11-22 00:00 EST"}},
+		{"every 2 weeks from start_at", "2-weeks", &models.ScheduleConfig{TimeZone: "UTC", StartAt: timePtr(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))}, nil, from, 2,
+			[]string{"2026-03-22 00:00 UTC", "2026-04-05 00:00 UTC"}},
+		{"every 2 years", "2-years", newYork, nil, from, 2,
+			[]string{"2028-01-01 00:00 EST", "2030-01-01 00:00 EST"}},
+		{"every 3 years from the start", "3-years", &models.ScheduleConfig{TimeZone: "UTC"}, nil, from, 2,
+			[]string{"2027-01-01 00:00 UTC", "2030-01-01 00:00 UTC"}},
+		{"job blackout window skips the night", "6-hours", &models.ScheduleConfig{
+			TimeZone:        "UTC",
+			BlackoutWindows: []models.BlackoutWindow{{StartTime: "22:00", EndTime: "07:00"}},
+		}, nil, from, 3,
+			[]string{"2026-03-06 18:00 UTC", "2026-03-07 12:00 UTC", "2026-03-07 18:00 UTC"}},
+		{"project blackout window skips the weekend", "1-days", &models.ScheduleConfig{TimeZone: "UTC"},
+			[]models.BlackoutWindow{{DaysOfWeek: "SAT,SUN", StartTime: "00:00", EndTime: "06:00"}}, from, 2,
+			[]string{"2026-03-09 00:00 UTC", "2026-03-10 00:00 UTC"}},
+		// 08:00 to 23:00 in Kolkata is 02:30 to 17:30 UTC
+		{"blackout window in its own time zone", "1-hours", &models.ScheduleConfig{TimeZone: "UTC"},
+			[]models.BlackoutWindow{{StartTime: "08:00", EndTime: "23:00", TimeZone: "Asia/Kolkata"}}, from, 2,
+			[]string{"2026-03-06 18:00 UTC", "2026-03-06 19:00 UTC"}},
+		{"ends before count", "1-days", &models.ScheduleConfig{TimeZone: "UTC", EndAt: timePtr(time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC))}, nil, from, 5,
+			[]string{"2026-03-07 00:00 UTC", "2026-03-08 00:00 UTC"}},
+	}
+	for _, tc := range cases {
+		fireTimes, location, err := PreviewSchedule(tc.frequency, tc.config, tc.windows, tc.from, tc.count)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		got := make([]string, 0, len(fireTimes))
+		for _, fireAt := range fireTimes {
+			got = append(got, fireAt.In(location).Format("2006-01-02 15:04 MST"))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: fire times = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPreviewScheduleLimits(t *testing.T) {
+	from := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	utc := &models.ScheduleConfig{TimeZone: "UTC"}
+	fireTimes, _, err := PreviewSchedule("1-minutes", utc, nil, from, MaxSchedulePreviewCount)
+	if err != nil || len(fireTimes) != MaxSchedulePreviewCount {
+		t.Errorf("preview of %d fire times returned %d: %v", MaxSchedulePreviewCount, len(fireTimes), err)
+	}
+	for _, count := range []int{0, -1, MaxSchedulePreviewCount + 1} {
+		if _, _, err := PreviewSchedule("1-minutes", utc, nil, from, count); err == nil {
+			t.Errorf("preview of %d fire times succeeded, want an error", count)
+		}
+	}
+
+	// a minutely schedule only fires in the last minute of the day, maxPreviewCandidates
+	// minutes are 69 days and hold 69 fire times
+	windows := []models.BlackoutWindow{{StartTime: "00:00", EndTime: "23:59"}}
+	if fireTimes, _, err = PreviewSchedule("* * * * *", utc, windows, from, 69); err != nil || len(fireTimes) != 69 {
+		t.Errorf("preview of 69 fire times within the candidates returned %d: %v", len(fireTimes), err)
+	}
+	if _, _, err = PreviewSchedule("* * * * *", utc, windows, from, 70); !errors.Is(err, ErrScheduleBlackedOut) {
+		t.Errorf("preview past the candidates fails with %v, want ErrScheduleBlackedOut", err)
+	}
+	// a schedule always inside its windows is rejected
+	always := []models.BlackoutWindow{{StartTime: "00:00", EndTime: "12:00"}, {StartTime: "12:00", EndTime: "24:00"}}
+	if err = ValidateSchedule("1-hours", utc, always, from); !errors.Is(err, ErrScheduleBlackedOut) {
+		t.Errorf("schedule always blacked out validates with %v, want ErrScheduleBlackedOut", err)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,114 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/datazip/olake-frontend/server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/interceptor"
+	"google.golang.org/grpc"
+)
+
+// tracingHeader is the Temporal header carrying the trace context of workflows and activities
+const tracingHeader = "_tracer-data"
+
+// spanContextKey is the key of the span on workflow contexts
+type spanContextKey struct{}
+
+// tracer propagates OpenTelemetry spans through Temporal headers, so the
+// workflows and activities of a request are traced as its children
+type tracer struct {
+	interceptor.BaseTracer
+}
+
+// tracerSpan is a span started by the tracer
+type tracerSpan struct {
+	trace.Span
+}
+
+func (s *tracerSpan) Finish(opts *interceptor.TracerFinishSpanOptions) {
+	tracing.End(s.Span, opts.Error)
+}
+
+// tracerSpanRef is a parent span read from a Temporal header
+type tracerSpanRef struct {
+	trace.SpanContext
+}
+
+func newTracingInterceptor() interceptor.Interceptor {
+	return interceptor.NewTracingInterceptor(tracer{})
+}
+
+func (tracer) Options() interceptor.TracerOptions {
+	return interceptor.TracerOptions{
+		SpanContextKey: spanContextKey{},
+		HeaderKey:      tracingHeader,
+		// workflows started before tracing was enabled carry no parent
+		AllowInvalidParentSpans: true,
+	}
+}
+
+func (tracer) UnmarshalSpan(header map[string]string) (interceptor.TracerSpanRef, error) {
+	spanContext := trace.SpanContextFromContext(tracing.Extract(context.Background(), propagation.MapCarrier(header)))
+	if !spanContext.IsValid() {
+		return nil, fmt.Errorf("invalid span context in header")
+	}
+	return &tracerSpanRef{spanContext}, nil
+}
+
+func (tracer) MarshalSpan(span interceptor.TracerSpan) (map[string]string, error) {
+	carrier := propagation.MapCarrier{}
+	tracing.Inject(trace.ContextWithSpan(context.Background(), span.(*tracerSpan).Span), carrier)
+	return carrier, nil
+}
+
+func (tracer) SpanFromContext(ctx context.Context) interceptor.TracerSpan {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil
+	}
+	return &tracerSpan{span}
+}
+
+func (tracer) ContextWithSpan(ctx context.Context, span interceptor.TracerSpan) context.Context {
+	return trace.ContextWithSpan(ctx, span.(*tracerSpan).Span)
+}
+
+func (t tracer) StartSpan(opts *interceptor.TracerStartSpanOptions) (interceptor.TracerSpan, error) {
+	ctx := context.Background()
+	switch parent := opts.Parent.(type) {
+	case *tracerSpan:
+		ctx = trace.ContextWithSpan(ctx, parent.Span)
+	case *tracerSpanRef:
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent.SpanContext)
+	}
+	attrs := make([]attribute.KeyValue, 0, len(opts.Tags)+1)
+	for key, value := range opts.Tags {
+		attrs = append(attrs, attribute.String(key, value))
+		if key == "temporalWorkflowID" {
+			attrs = append(attrs, tracing.WorkflowID(value))
+		}
+	}
+	_, span := tracing.Tracer().Start(ctx, t.SpanName(opts), trace.WithTimestamp(opts.Time), trace.WithAttributes(attrs...))
+	return &tracerSpan{span}, nil
+}
+
+// traceCalls traces the calls of a Temporal client made within a trace,
+// including the list and schedule calls the tracing interceptor does not see.
+// Calls without a parent span, such as the task polls of workers, would each
+// start a trace of their own and are not traced, nor are activity heartbeats.
+func traceCalls(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	name := path.Base(method)
+	if !trace.SpanContextFromContext(ctx).IsValid() || strings.HasPrefix(name, "Poll") || strings.HasPrefix(name, "RecordActivityTaskHeartbeat") {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "temporal."+name, trace.WithSpanKind(trace.SpanKindClient))
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	tracing.End(span, err)
+	return err
+}
@@ -0,0 +1,48 @@
+package temporal
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+)
+
+func TestTraceCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	parent, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return nil
+	}
+	cases := []struct {
+		ctx    context.Context
+		method string
+		traced bool
+	}{
+		{parent, "/temporal.api.workflowservice.v1.WorkflowService/DescribeSchedule", true},
+		{context.Background(), "/temporal.api.workflowservice.v1.WorkflowService/DescribeSchedule", false},
+		{parent, "/temporal.api.workflowservice.v1.WorkflowService/PollActivityTaskQueue", false},
+		{context.Background(), "/temporal.api.workflowservice.v1.WorkflowService/PollWorkflowTaskQueue", false},
+		{parent, "/temporal.api.workflowservice.v1.WorkflowService/RecordActivityTaskHeartbeat", false},
+	}
+	for _, tc := range cases {
+		before := len(recorder.Ended())
+		if err := traceCalls(tc.ctx, tc.method, nil, nil, nil, invoker); err != nil {
+			t.Fatal(err)
+		}
+		if traced := len(recorder.Ended()) > before; traced != tc.traced {
+			t.Errorf("%s with parent %t traced = %t, want %t", tc.method, tc.ctx == parent, traced, tc.traced)
+		}
+	}
+	if ended := recorder.Ended(); len(ended) != 1 || ended[0].Parent().SpanID() != span.SpanContext().SpanID() || ended[0].Name() != "temporal.DescribeSchedule" {
+		t.Errorf("traced spans = %v, want DescribeSchedule as a child of the request", ended)
+	}
+}
@@ -0,0 +1,54 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// TrashPurgeWorkflowID is the ID of the workflow purging the trash
+	TrashPurgeWorkflowID = "olake-trash-purge"
+	// TrashPurgeSchedule is the cron schedule of TrashPurgeWorkflow
+	TrashPurgeSchedule = "0 3 * * *"
+)
+
+// TrashPurgeWorkflow deletes the rows kept in the trash for longer than
+// TRASH_RETENTION_DAYS, the worker runs it on TrashPurgeSchedule
+func TrashPurgeWorkflow(ctx workflow.Context) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 10,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	return workflow.ExecuteActivity(ctx, PurgeTrashActivity).Get(ctx, nil)
+}
+
+// PurgeTrashActivity deletes the rows trashed before the retention period
+func PurgeTrashActivity(ctx context.Context) error {
+	if database.TrashRetentionDays <= 0 {
+		return nil
+	}
+	purged, err := database.NewTrashORM().Purge(ctx, time.Now().AddDate(0, 0, -database.TrashRetentionDays))
+	if purged > 0 {
+		activity.GetLogger(ctx).Info("Purged trash", "rows", purged)
+	}
+	return err
+}
+
+// startTrashPurge starts TrashPurgeWorkflow unless it is already running
+func startTrashPurge(ctx context.Context, temporalClient client.Client) error {
+	_, err := temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:           TrashPurgeWorkflowID,
+		TaskQueue:    TaskQueue,
+		CronSchedule: TrashPurgeSchedule,
+	}, TrashPurgeWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to start trash purge: %s", err)
+	}
+	return nil
+}
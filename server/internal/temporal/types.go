@@ -1,6 +1,11 @@
 package temporal
 
-import "github.com/datazip/olake-frontend/server/internal/docker"
+import (
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/notify"
+)
 
 // DockerCommandParams contains parameters for Docker commands (legacy)
 type DockerCommandParams struct {
@@ -30,3 +35,64 @@ type SyncParams struct {
 	JobID      int
 	WorkflowID string
 }
+
+// BackfillParams contains parameters for backfill workflows and activities
+type BackfillParams struct {
+	JobID     int
+	ProjectID string
+	// Streams are the "namespace.name" or "name" of the streams to load from scratch
+	Streams []string
+	// Filter limits the rows read from each stream, empty reads all rows
+	Filter     string
+	WorkflowID string
+}
+
+// PreviewParams contains parameters for preview workflows and activities
+type PreviewParams struct {
+	SourceType    string
+	Version       string
+	Config        string
+	StreamsConfig string
+	RowLimit      int
+	SampleSize    int
+	WorkflowID    string
+}
+
+// JobWorkflowParams contains parameters for the job workflow
+type JobWorkflowParams struct {
+	JobID     int
+	ProjectID string
+	// TriggeredBy is the upstream job that started this run, 0 for scheduled and manual runs
+	TriggeredBy int
+	// OverrideBlackout is set by manual runs forced into a blackout window
+	OverrideBlackout bool
+}
+
+// DependencyCheck is the outcome of checking the upstream jobs of a job
+type DependencyCheck struct {
+	// WaitingFor lists running upstream jobs the run has to wait for
+	WaitingFor []int
+	// SkipReason is set when the run must not happen
+	SkipReason string
+}
+
+// SyncOutcome is how a sync run ended, for notifying the subscribers of the job
+type SyncOutcome struct {
+	JobID      int
+	WorkflowID string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Error is empty for a successful sync
+	Error string
+	// SchemaDrift lists the differences DetectSchemaDriftActivity found, it is
+	// only set when SchemaDriftChecked is
+	SchemaDrift        []string
+	SchemaDriftChecked bool
+}
+
+// NotificationDelivery is a notification for a channel, the channel is loaded
+// by the delivering activity so that its credentials stay out of the history
+type NotificationDelivery struct {
+	ChannelID    int
+	Notification notify.Notification
+}
@@ -0,0 +1,218 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/database"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/webhooks"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// WebhookRetryPolicy retries the delivery of an event to a webhook for about
+// 3 hours, deliveries that run out of attempts go to the dead-letter list
+var WebhookRetryPolicy = &temporal.RetryPolicy{
+	InitialInterval:    time.Second * 30,
+	BackoffCoefficient: 2.0,
+	MaximumInterval:    time.Hour,
+	MaximumAttempts:    10,
+}
+
+const (
+	// webhookEventWorkflowPrefix prefixes the ID of an event to get the ID of its WebhookEventWorkflow
+	webhookEventWorkflowPrefix = "webhook-event-"
+	// webhookDeliveryWorkflowPrefix prefixes the ID of a delivery to get the ID of the workflow replaying it
+	webhookDeliveryWorkflowPrefix = "webhook-delivery-"
+)
+
+// EmitWebhookEvent starts the workflow delivering an event to the subscribed webhooks of its project
+func (c *Client) EmitWebhookEvent(ctx context.Context, event *webhooks.Event) error {
+	_, err := c.temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        webhookEventWorkflowPrefix + event.ID,
+		TaskQueue: TaskQueue,
+	}, WebhookEventWorkflow, *event)
+	if err != nil {
+		return fmt.Errorf("failed to emit webhook event %s: %s", event.Type, err)
+	}
+	return nil
+}
+
+// ReplayWebhookDelivery starts sending a recorded delivery again, with the retries of a new delivery
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, deliveryID int) error {
+	_, err := c.temporalClient.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:                                       fmt.Sprintf("%s%d", webhookDeliveryWorkflowPrefix, deliveryID),
+		TaskQueue:                                TaskQueue,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}, WebhookDeliveryWorkflow, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to replay webhook delivery %d: %s", deliveryID, err)
+	}
+	return nil
+}
+
+// emitSyncEvent starts WebhookEventWorkflow for an event of a sync, the job is
+// loaded by the workflow. It is abandoned so that slow webhooks do not hold up the sync.
+func emitSyncEvent(ctx workflow.Context, jobID int, eventType string, sync webhooks.Sync) {
+	event := webhooks.Event{
+		SchemaVersion: constants.WebhookSchemaVersion,
+		// retries of the sync are syncs of their own
+		ID:        fmt.Sprintf("%s/%s", sync.WorkflowID, eventType),
+		Type:      eventType,
+		CreatedAt: workflow.Now(ctx).UTC(),
+		Data:      webhooks.EventData{Job: webhooks.Job{ID: jobID}, Sync: &sync},
+	}
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        webhookEventWorkflowPrefix + event.ID,
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+	child := workflow.ExecuteChildWorkflow(childCtx, WebhookEventWorkflow, event)
+	if err := child.GetChildWorkflowExecution().Get(childCtx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to emit webhook event", "jobId", jobID, "event", eventType, "error", err)
+	}
+}
+
+// WebhookEventWorkflow records the deliveries of an event to the subscribed
+// webhooks of its project and delivers them
+func WebhookEventWorkflow(ctx workflow.Context, event webhooks.Event) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	var deliveryIDs []int
+	if err := workflow.ExecuteActivity(ctx, RecordWebhookDeliveriesActivity, event).Get(ctx, &deliveryIDs); err != nil {
+		return err
+	}
+	deliverWebhooks(ctx, deliveryIDs)
+	return nil
+}
+
+// WebhookDeliveryWorkflow replays a recorded delivery
+func WebhookDeliveryWorkflow(ctx workflow.Context, deliveryID int) error {
+	deliverWebhooks(ctx, []int{deliveryID})
+	return nil
+}
+
+// deliverWebhooks sends deliveries in parallel with WebhookRetryPolicy and
+// moves the ones that keep failing to the dead-letter list
+func deliverWebhooks(ctx workflow.Context, deliveryIDs []int) {
+	deliverCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: webhooks.DeliveryTimeout + time.Minute,
+		RetryPolicy:         WebhookRetryPolicy,
+	})
+	futures := make([]workflow.Future, 0, len(deliveryIDs))
+	for _, deliveryID := range deliveryIDs {
+		futures = append(futures, workflow.ExecuteActivity(deliverCtx, DeliverWebhookActivity, deliveryID))
+	}
+
+	deadCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	for i, future := range futures {
+		err := future.Get(ctx, nil)
+		if err == nil {
+			continue
+		}
+		workflow.GetLogger(ctx).Error("Webhook delivery failed, moving it to the dead-letter list", "deliveryId", deliveryIDs[i], "error", err)
+		if err := workflow.ExecuteActivity(deadCtx, MarkWebhookDeliveryDeadActivity, deliveryIDs[i]).Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Error("Failed to move webhook delivery to the dead-letter list", "deliveryId", deliveryIDs[i], "error", err)
+		}
+	}
+}
+
+// RecordWebhookDeliveriesActivity records a pending delivery of an event for
+// every subscribed webhook and returns their IDs. Events of syncs only carry the
+// ID of the job, the job and its project are loaded here.
+func RecordWebhookDeliveriesActivity(ctx context.Context, event webhooks.Event) ([]int, error) {
+	if event.ProjectID == "" {
+		job, err := database.NewJobORM().GetByID(event.Data.Job.ID, false)
+		if err != nil {
+			return nil, err
+		}
+		event.ProjectID = job.ProjectID
+		event.Data.Job = webhooks.JobData(job)
+	}
+
+	subscribed, err := database.NewWebhookORM().GetSubscribed(event.ProjectID, event.Type)
+	if err != nil || len(subscribed) == 0 {
+		return nil, err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("failed to encode webhook event: %s", err), "encode", err)
+	}
+	deliveryORM := database.NewWebhookDeliveryORM()
+	var deliveryIDs []int
+	for _, webhook := range subscribed {
+		delivery, err := deliveryORM.GetOrCreate(&models.WebhookDelivery{
+			ProjectID: event.ProjectID,
+			WebhookID: webhook,
+			EventID:   event.ID,
+			Event:     event.Type,
+			Payload:   string(payload),
+			Status:    constants.WebhookDeliveryPending,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// deliveries recorded by an earlier attempt of the activity may be done already
+		if delivery.Status == constants.WebhookDeliveryPending {
+			deliveryIDs = append(deliveryIDs, delivery.ID)
+		}
+	}
+	return deliveryIDs, nil
+}
+
+// DeliverWebhookActivity sends a delivery to its webhook and records the attempt
+func DeliverWebhookActivity(ctx context.Context, deliveryID int) error {
+	deliveryORM := database.NewWebhookDeliveryORM()
+	delivery, err := deliveryORM.GetByID(deliveryID)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError(err.Error(), "not_found", err)
+	}
+	webhook, err := database.NewWebhookORM().GetByID(delivery.WebhookID.ID)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError(err.Error(), "not_found", err)
+	}
+	if !webhook.Active {
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("webhook %s is inactive", webhook.Name), "inactive", nil)
+	}
+
+	status, deliverErr := webhooks.Deliver(ctx, webhook, delivery)
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.LastAttemptAt = &now
+	delivery.UpdatedAt = now
+	delivery.LastError = ""
+	if deliverErr != nil {
+		delivery.LastError = deliverErr.Error()
+	} else {
+		delivery.Status = constants.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	}
+	// the attempt itself decides whether the delivery is retried
+	if err := deliveryORM.UpdateAttempt(delivery); err != nil {
+		activity.GetLogger(ctx).Error("Failed to record webhook delivery attempt", "deliveryId", deliveryID, "error", err)
+	}
+
+	var statusErr *webhooks.StatusError
+	if errors.As(deliverErr, &statusErr) && !statusErr.Retryable() {
+		return temporal.NewNonRetryableApplicationError(deliverErr.Error(), "rejected", deliverErr)
+	}
+	return deliverErr
+}
+
+// MarkWebhookDeliveryDeadActivity moves a delivery that ran out of retries to the dead-letter list
+func MarkWebhookDeliveryDeadActivity(_ context.Context, deliveryID int) error {
+	return database.NewWebhookDeliveryORM().UpdateStatus(deliveryID, constants.WebhookDeliveryDead)
+}
@@ -1,6 +1,7 @@
 package temporal
 
 import (
+	"context"
 	"fmt"
 
 	"go.temporal.io/sdk/client"
@@ -15,9 +16,7 @@ type Worker struct {
 
 // NewWorker creates a new Temporal worker
 func NewWorker() (*Worker, error) {
-	c, err := client.Dial(client.Options{
-		HostPort: TemporalAddress,
-	})
+	c, err := client.Dial(clientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Temporal client: %v", err)
 	}
@@ -29,11 +28,46 @@ func NewWorker() (*Worker, error) {
 	w.RegisterWorkflow(DiscoverCatalogWorkflow)
 	w.RegisterWorkflow(TestConnectionWorkflow)
 	w.RegisterWorkflow(RunSyncWorkflow)
+	w.RegisterWorkflow(RunJobWorkflow)
+	w.RegisterWorkflow(SyncConcurrencyWorkflow)
+	w.RegisterWorkflow(BackfillWorkflow)
+	w.RegisterWorkflow(PreviewWorkflow)
+	w.RegisterWorkflow(SyncNotificationWorkflow)
+	w.RegisterWorkflow(NotificationMonitorWorkflow)
+	w.RegisterWorkflow(WebhookEventWorkflow)
+	w.RegisterWorkflow(WebhookDeliveryWorkflow)
+	w.RegisterWorkflow(TrashPurgeWorkflow)
+	w.RegisterWorkflow(ScheduleOutboxWorkflow)
+	w.RegisterWorkflow(ScheduleDriftWorkflow)
+	w.RegisterWorkflow(BlackoutShiftWorkflow)
+	w.RegisterWorkflow(ScheduleActionMigrationWorkflow)
 
 	// Register activities
 	w.RegisterActivity(DiscoverCatalogActivity)
 	w.RegisterActivity(TestConnectionActivity)
 	w.RegisterActivity(SyncActivity)
+	w.RegisterActivity(BackfillActivity)
+	w.RegisterActivity(PreviewActivity)
+	w.RegisterActivity(CheckDependenciesActivity)
+	w.RegisterActivity(CheckBlackoutActivity)
+	w.RegisterActivity(GetTriggeredJobsActivity)
+	w.RegisterActivity(GetSyncRetryPolicyActivity)
+	w.RegisterActivity(GetConcurrencyScopesActivity)
+	w.RegisterActivity(RequestSyncSlotActivity)
+	w.RegisterActivity(GetClosedWorkflowsActivity)
+	w.RegisterActivity(GetClosedSyncSlotsActivity)
+	w.RegisterActivity(DetectSchemaDriftActivity)
+	w.RegisterActivity(EvaluateSyncNotificationsActivity)
+	w.RegisterActivity(CheckNotificationSLAsActivity)
+	w.RegisterActivity(DeliverNotificationActivity)
+	w.RegisterActivity(RecordWebhookDeliveriesActivity)
+	w.RegisterActivity(DeliverWebhookActivity)
+	w.RegisterActivity(MarkWebhookDeliveryDeadActivity)
+	w.RegisterActivity(PurgeTrashActivity)
+	w.RegisterActivity(ProcessScheduleOutboxActivity)
+	w.RegisterActivity(CheckScheduleDriftActivity)
+	w.RegisterActivity(RefreshBlackoutShiftsActivity)
+	w.RegisterActivity(MigrateScheduleActionsActivity)
 
 	return &Worker{
 		temporalClient: c,
@@ -41,9 +75,27 @@ func NewWorker() (*Worker, error) {
 	}, nil
 }
 
-// Start starts the worker
+// Start starts the worker, the notification monitor and the trash purge if they are not running yet
 func (w *Worker) Start() error {
-	return w.worker.Start()
+	if err := w.worker.Start(); err != nil {
+		return err
+	}
+	if err := startNotificationMonitor(context.Background(), w.temporalClient); err != nil {
+		return err
+	}
+	if err := startTrashPurge(context.Background(), w.temporalClient); err != nil {
+		return err
+	}
+	if err := startScheduleOutbox(context.Background(), w.temporalClient); err != nil {
+		return err
+	}
+	if err := startScheduleDriftCheck(context.Background(), w.temporalClient); err != nil {
+		return err
+	}
+	if err := startScheduleActionMigration(context.Background(), w.temporalClient); err != nil {
+		return err
+	}
+	return startBlackoutShiftCheck(context.Background(), w.temporalClient)
 }
 
 // Stop stops the worker
@@ -1,8 +1,16 @@
 package temporal
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/webhooks"
+	"go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
@@ -16,6 +24,38 @@ var (
 		MaximumInterval:    time.Minute * 10,
 		MaximumAttempts:    1,
 	}
+
+	// DependencyRetryPolicy is used for the database and visibility lookups of the job workflow
+	DependencyRetryPolicy = &temporal.RetryPolicy{
+		InitialInterval:    time.Second * 5,
+		BackoffCoefficient: 2.0,
+		MaximumInterval:    time.Minute,
+		MaximumAttempts:    5,
+	}
+)
+
+const (
+	// DependencyPollInterval is how often a run waiting for upstream jobs checks them again
+	DependencyPollInterval = time.Minute
+	// DependencyWaitTimeout fails a run that waited this long for upstream jobs
+	DependencyWaitTimeout = time.Hour * 6
+	// SyncHeartbeatTimeout fails a sync activity whose worker stopped heartbeating,
+	// the retried run resumes from the last checkpoint
+	SyncHeartbeatTimeout = time.Minute * 5
+)
+
+// Change IDs of workflow.GetVersion, syncs started by an older worker replay
+// without the change they gate
+const (
+	syncHeartbeatChange     = "sync-heartbeat"
+	syncNotificationsChange = "sync-notifications"
+	syncWebhooksChange      = "sync-webhooks"
+	// jobNotificationsChange moves the sync notifications from every attempt to the final one
+	jobNotificationsChange = "job-notifications"
+	// schemaDriftActivityChange checks the schema drift before evaluating the sync notifications
+	schemaDriftActivityChange = "schema-drift-activity"
+	// blackoutCheckChange checks the blackout windows right before a sync starts
+	blackoutCheckChange = "blackout-check"
 )
 
 // DiscoverCatalogWorkflow is a workflow for discovering catalogs
@@ -54,22 +94,242 @@ func TestConnectionWorkflow(ctx workflow.Context, params *ActivityParams) (map[s
 	return result, nil
 }
 
-// RunSyncWorkflow is a workflow for running data synchronization
-func RunSyncWorkflow(ctx workflow.Context, jobID int) (map[string]interface{}, error) {
+// RunSyncWorkflow is a workflow for running data synchronization, failed syncs
+// are retried by RunJobWorkflow with the retry policy of the job. The start and
+// end of every attempt are sent to the webhooks of the project. The subscribers
+// of the job are notified by RunJobWorkflow about the final attempt, attempts
+// started by an older job workflow are passed on to SyncNotificationWorkflow here.
+func RunSyncWorkflow(ctx workflow.Context, jobID int, notifiedByJob bool) (map[string]interface{}, error) {
 	options := workflow.ActivityOptions{
 		// Using large duration (e.g., 10 years)
 		StartToCloseTimeout: time.Hour * 24 * 30, // 30 days
 		RetryPolicy:         DefaultRetryPolicy,
 	}
+	if workflow.GetVersion(ctx, syncHeartbeatChange, workflow.DefaultVersion, 1) == 1 {
+		// the sync activity heartbeats on every checkpoint check
+		options.HeartbeatTimeout = SyncHeartbeatTimeout
+	}
+	notify := workflow.GetVersion(ctx, syncNotificationsChange, workflow.DefaultVersion, 1) == 1 && !notifiedByJob
+	emit := workflow.GetVersion(ctx, syncWebhooksChange, workflow.DefaultVersion, 1) == 1
 	params := SyncParams{
 		JobID:      jobID,
 		WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
 	}
 	ctx = workflow.WithActivityOptions(ctx, options)
+	sync := webhooks.Sync{
+		WorkflowID: params.WorkflowID,
+		Attempt:    SyncAttempt(params.WorkflowID),
+		StartedAt:  workflow.Now(ctx).UTC(),
+	}
+	if emit {
+		emitSyncEvent(ctx, jobID, constants.WebhookEventSyncStarted, sync)
+	}
 	var result map[string]interface{}
 	err := workflow.ExecuteActivity(ctx, SyncActivity, params).Get(ctx, &result)
+	// canceled syncs are neither failures nor successes to subscribers
+	if (notify || emit) && !temporal.IsCanceledError(err) {
+		finishedAt := workflow.Now(ctx).UTC()
+		outcome := SyncOutcome{
+			JobID:      jobID,
+			WorkflowID: params.WorkflowID,
+			StartedAt:  sync.StartedAt,
+			FinishedAt: finishedAt,
+		}
+		sync.FinishedAt = &finishedAt
+		event := constants.WebhookEventSyncSucceeded
+		if err != nil {
+			outcome.Error = err.Error()
+			sync.Error = outcome.Error
+			event = constants.WebhookEventSyncFailed
+		}
+		if notify {
+			notifySync(ctx, outcome)
+		}
+		if emit {
+			emitSyncEvent(ctx, jobID, event, sync)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RunJobWorkflow runs a job: it waits for or checks the upstream jobs, runs
+// RunSyncWorkflow as a child and then starts the downstream jobs that are
+// triggered by this job. Scheduled and manual runs of every job start here.
+func RunJobWorkflow(ctx workflow.Context, params JobWorkflowParams) (map[string]interface{}, error) {
+	logger := workflow.GetLogger(ctx)
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+
+	deadline := workflow.Now(ctx).Add(DependencyWaitTimeout)
+	for {
+		var check DependencyCheck
+		if err := workflow.ExecuteActivity(ctx, CheckDependenciesActivity, params).Get(ctx, &check); err != nil {
+			return nil, err
+		}
+		if check.SkipReason != "" {
+			logger.Info("Skipping job run", "jobId", params.JobID, "reason", check.SkipReason)
+			return map[string]interface{}{"skipped": true, "reason": check.SkipReason}, nil
+		}
+		if len(check.WaitingFor) == 0 {
+			break
+		}
+		if workflow.Now(ctx).After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for upstream jobs %v", DependencyWaitTimeout, check.WaitingFor)
+		}
+		logger.Info("Waiting for upstream jobs", "jobId", params.JobID, "upstreamJobIds", check.WaitingFor)
+		if err := workflow.Sleep(ctx, DependencyPollInterval); err != nil {
+			return nil, err
+		}
+	}
+
+	var retryPolicy SyncRetryPolicy
+	if err := workflow.ExecuteActivity(ctx, GetSyncRetryPolicyActivity, params.JobID).Get(ctx, &retryPolicy); err != nil {
+		return nil, err
+	}
+	notify := workflow.GetVersion(ctx, jobNotificationsChange, workflow.DefaultVersion, 1) == 1
+	checkBlackout := workflow.GetVersion(ctx, blackoutCheckChange, workflow.DefaultVersion, 1) == 1 && !params.OverrideBlackout
+
+	// the sync keeps the workflow ID format used by the tasks and logs of the job,
+	// every attempt is its own child workflow so that it shows up as a task
+	syncWorkflowID := strings.TrimPrefix(workflow.GetInfo(ctx).WorkflowExecution.ID, JobWorkflowIDPrefix)
+	var result map[string]interface{}
+	// failed is the outcome of the failed attempt a retry follows
+	var failed *SyncOutcome
+	var failedErr error
+	for attempt := 1; ; attempt++ {
+		// syncs queue for a slot of every concurrency scope, the slot is not held between attempts
+		release, err := acquireSyncSlot(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		// the waits for upstream jobs, the slot and the backoff may end inside a blackout window
+		if checkBlackout {
+			var blackout string
+			if err := workflow.ExecuteActivity(ctx, CheckBlackoutActivity, params.JobID).Get(ctx, &blackout); err != nil {
+				release()
+				return nil, err
+			}
+			if blackout != "" {
+				release()
+				if failed == nil {
+					logger.Info("Skipping job run", "jobId", params.JobID, "reason", blackout)
+					return map[string]interface{}{"skipped": true, "reason": blackout}, nil
+				}
+				// no more retries, the failed attempt is the final one
+				logger.Warn("Not retrying the sync inside a blackout window", "jobId", params.JobID, "attempt", attempt, "reason", blackout)
+				if notify && !temporal.IsCanceledError(failedErr) && !temporal.IsTerminatedError(failedErr) {
+					notifySync(ctx, *failed)
+				}
+				return nil, failedErr
+			}
+		}
+		syncCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID: syncAttemptWorkflowID(syncWorkflowID, attempt),
+		})
+		startedAt := workflow.Now(ctx).UTC()
+		err = workflow.ExecuteChildWorkflow(syncCtx, RunSyncWorkflow, params.JobID, notify).Get(syncCtx, &result)
+		release()
+		final := err == nil || !retryPolicy.shouldRetry(err, attempt)
+		outcome := SyncOutcome{
+			JobID:      params.JobID,
+			WorkflowID: syncAttemptWorkflowID(syncWorkflowID, attempt),
+			StartedAt:  startedAt,
+			FinishedAt: workflow.Now(ctx).UTC(),
+			Error:      syncError(err),
+		}
+		// canceled and terminated syncs are neither failures nor successes to subscribers
+		if notify && final && !temporal.IsCanceledError(err) && !temporal.IsTerminatedError(err) {
+			notifySync(ctx, outcome)
+		}
+		if err == nil {
+			break
+		}
+		if final {
+			return nil, err
+		}
+		failed, failedErr = &outcome, err
+		backoff := retryPolicy.backoff(attempt)
+		logger.Warn("Sync attempt failed, retrying", "jobId", params.JobID, "attempt", attempt, "backoff", backoff, "error", err)
+		if err := workflow.Sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+	}
+
+	var downstreams []int
+	if err := workflow.ExecuteActivity(ctx, GetTriggeredJobsActivity, params.JobID).Get(ctx, &downstreams); err != nil {
+		return nil, fmt.Errorf("sync completed but failed to get downstream jobs: %v", err)
+	}
+	for _, jobID := range downstreams {
+		// downstream runs outlive this workflow, runs triggered in the same second share an ID and start once
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID:        fmt.Sprintf("%s-%s", JobWorkflowID(params.ProjectID, jobID), workflow.Now(ctx).UTC().Format(time.RFC3339)),
+			ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+		})
+		child := workflow.ExecuteChildWorkflow(childCtx, RunJobWorkflow, JobWorkflowParams{
+			JobID:       jobID,
+			ProjectID:   params.ProjectID,
+			TriggeredBy: params.JobID,
+		})
+		if err := child.GetChildWorkflowExecution().Get(childCtx, nil); err != nil && !temporal.IsWorkflowExecutionAlreadyStartedError(err) {
+			logger.Error("Failed to trigger downstream job", "jobId", jobID, "error", err)
+		}
+	}
+	return result, nil
+}
+
+// syncError returns the message a sync attempt failed with, without the child
+// workflow and activity errors wrapping it, "" for a successful attempt
+func syncError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		return appErr.Message()
+	}
+	return err.Error()
+}
+
+// BackfillWorkflow runs a one-off sync of some streams of a job from scratch
+// that leaves the job state untouched. It shares the concurrency limits of the job.
+func BackfillWorkflow(ctx workflow.Context, params BackfillParams) (map[string]interface{}, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+		RetryPolicy:         DependencyRetryPolicy,
+	})
+	release, err := acquireSyncSlot(ctx, JobWorkflowParams{JobID: params.JobID, ProjectID: params.ProjectID})
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour * 24 * 30, // 30 days
+		RetryPolicy:         DefaultRetryPolicy,
+	})
+	var result map[string]interface{}
+	if err := workflow.ExecuteActivity(ctx, BackfillActivity, params).Get(ctx, &result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+// PreviewWorkflow runs the streams of a job that is not created yet into a
+// throwaway local sink
+func PreviewWorkflow(ctx workflow.Context, params PreviewParams) (*models.SyncPreviewResponse, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// pulling the connector image comes on top of the preview run itself
+		StartToCloseTimeout: docker.PreviewTimeout + time.Minute*10,
+		RetryPolicy:         DefaultRetryPolicy,
+	})
+	var result models.SyncPreviewResponse
+	if err := workflow.ExecuteActivity(ctx, PreviewActivity, params).Get(ctx, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
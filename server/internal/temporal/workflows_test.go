@@ -0,0 +1,269 @@
+package temporal
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/datazip/olake-frontend/server/internal/docker"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+func TestRunSyncWorkflowVersions(t *testing.T) {
+	cases := []struct {
+		name          string
+		version       workflow.Version
+		notifiedByJob bool
+		heartbeat     time.Duration
+		notifications int
+		webhookEvents int
+	}{
+		{"started by an older worker", workflow.DefaultVersion, false, 0, 0, 0},
+		{"started by an older job workflow", 1, false, SyncHeartbeatTimeout, 1, 2},
+		{"current", 1, true, SyncHeartbeatTimeout, 0, 2},
+	}
+	for _, tc := range cases {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+		env.OnGetVersion(syncHeartbeatChange, workflow.DefaultVersion, 1).Return(tc.version)
+		env.OnGetVersion(syncNotificationsChange, workflow.DefaultVersion, 1).Return(tc.version)
+		env.OnGetVersion(syncWebhooksChange, workflow.DefaultVersion, 1).Return(tc.version)
+		env.RegisterWorkflow(SyncNotificationWorkflow)
+		env.RegisterWorkflow(WebhookEventWorkflow)
+
+		var heartbeat time.Duration
+		env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+			heartbeat = info.HeartbeatTimeout
+		})
+		var notifications, webhookEvents int
+		env.OnActivity(SyncActivity, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil)
+		env.OnWorkflow(SyncNotificationWorkflow, mock.Anything, mock.Anything).Return(func(workflow.Context, SyncOutcome) error {
+			notifications++
+			return nil
+		})
+		env.OnWorkflow(WebhookEventWorkflow, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) { webhookEvents++ })
+
+		env.ExecuteWorkflow(RunSyncWorkflow, 1, tc.notifiedByJob)
+		if err := env.GetWorkflowError(); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if heartbeat != tc.heartbeat {
+			t.Errorf("%s: heartbeat timeout = %s, want %s", tc.name, heartbeat, tc.heartbeat)
+		}
+		if notifications != tc.notifications || webhookEvents != tc.webhookEvents {
+			t.Errorf("%s: %d notifications and %d webhook events, want %d and %d", tc.name, notifications, webhookEvents, tc.notifications, tc.webhookEvents)
+		}
+	}
+}
+
+func TestRunJobWorkflowNotifications(t *testing.T) {
+	cases := []struct {
+		name     string
+		version  workflow.Version
+		failures int
+		want     []SyncOutcome
+	}{
+		{"succeeds after retries", 1, 2, []SyncOutcome{{WorkflowID: "sync-1-attempt-3"}}},
+		{"fails every attempt", 1, 3, []SyncOutcome{{WorkflowID: "sync-1-attempt-3", Error: "sync failed"}}},
+		{"succeeds at once", 1, 0, []SyncOutcome{{WorkflowID: "sync-1"}}},
+		{"started by an older worker", workflow.DefaultVersion, 2, nil},
+	}
+	for _, tc := range cases {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+		env.OnGetVersion(jobNotificationsChange, workflow.DefaultVersion, 1).Return(tc.version)
+		env.RegisterWorkflow(RunSyncWorkflow)
+		env.RegisterWorkflow(SyncNotificationWorkflow)
+		env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: JobWorkflowIDPrefix + "sync-1"})
+
+		env.OnActivity(CheckDependenciesActivity, mock.Anything, mock.Anything).Return(&DependencyCheck{}, nil)
+		env.OnActivity(CheckBlackoutActivity, mock.Anything, mock.Anything).Return("", nil)
+		env.OnActivity(GetSyncRetryPolicyActivity, mock.Anything, mock.Anything).Return(&SyncRetryPolicy{
+			MaximumAttempts: 3, InitialInterval: time.Second, BackoffCoefficient: 1, MaximumInterval: time.Second,
+		}, nil)
+		env.OnActivity(GetConcurrencyScopesActivity, mock.Anything, mock.Anything).Return(nil, nil)
+		env.OnActivity(GetTriggeredJobsActivity, mock.Anything, mock.Anything).Return(nil, nil)
+		attempts := 0
+		env.OnWorkflow(RunSyncWorkflow, mock.Anything, mock.Anything, mock.Anything).Return(
+			func(_ workflow.Context, _ int, notifiedByJob bool) (map[string]interface{}, error) {
+				if notifiedByJob != (tc.version == 1) {
+					t.Errorf("%s: sync notified by job = %t", tc.name, notifiedByJob)
+				}
+				if attempts++; attempts <= tc.failures {
+					return nil, temporal.NewApplicationError("sync failed", "sync")
+				}
+				return map[string]interface{}{}, nil
+			})
+		var outcomes []SyncOutcome
+		env.OnWorkflow(SyncNotificationWorkflow, mock.Anything, mock.Anything).Return(func(_ workflow.Context, outcome SyncOutcome) error {
+			outcomes = append(outcomes, SyncOutcome{WorkflowID: outcome.WorkflowID, Error: outcome.Error})
+			return nil
+		})
+
+		env.ExecuteWorkflow(RunJobWorkflow, JobWorkflowParams{JobID: 1, ProjectID: "p1"})
+		if !env.IsWorkflowCompleted() {
+			t.Fatalf("%s: workflow did not complete", tc.name)
+		}
+		if !reflect.DeepEqual(outcomes, tc.want) {
+			t.Errorf("%s: notified %+v, want %+v", tc.name, outcomes, tc.want)
+		}
+	}
+}
+
+func TestRunJobWorkflowBlackout(t *testing.T) {
+	cases := []struct {
+		name     string
+		params   JobWorkflowParams
+		blackout string
+		syncs    int
+		skipped  bool
+	}{
+		{"outside of blackout windows", JobWorkflowParams{JobID: 1, ProjectID: "p1", TriggeredBy: 2}, "", 1, false},
+		{"triggered inside a blackout window", JobWorkflowParams{JobID: 1, ProjectID: "p1", TriggeredBy: 2}, "blackout window: [sat] 02:00-04:00 UTC", 0, true},
+		{"forced into a blackout window", JobWorkflowParams{JobID: 1, ProjectID: "p1", OverrideBlackout: true}, "blackout window: [sat] 02:00-04:00 UTC", 1, false},
+	}
+	for _, tc := range cases {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+		env.RegisterWorkflow(RunSyncWorkflow)
+		env.RegisterWorkflow(SyncNotificationWorkflow)
+		env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: JobWorkflowIDPrefix + "sync-1"})
+
+		env.OnActivity(CheckDependenciesActivity, mock.Anything, mock.Anything).Return(&DependencyCheck{}, nil)
+		checks := 0
+		env.OnActivity(CheckBlackoutActivity, mock.Anything, mock.Anything).Return(tc.blackout, nil).Run(func(mock.Arguments) { checks++ })
+		env.OnActivity(GetSyncRetryPolicyActivity, mock.Anything, mock.Anything).Return(&SyncRetryPolicy{MaximumAttempts: 1}, nil)
+		env.OnActivity(GetConcurrencyScopesActivity, mock.Anything, mock.Anything).Return(nil, nil)
+		env.OnActivity(GetTriggeredJobsActivity, mock.Anything, mock.Anything).Return(nil, nil)
+		syncs := 0
+		env.OnWorkflow(RunSyncWorkflow, mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{}, nil).Run(func(mock.Arguments) { syncs++ })
+		env.OnWorkflow(SyncNotificationWorkflow, mock.Anything, mock.Anything).Return(nil)
+
+		env.ExecuteWorkflow(RunJobWorkflow, tc.params)
+		if err := env.GetWorkflowError(); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		var result map[string]interface{}
+		if err := env.GetWorkflowResult(&result); err != nil {
+			t.Fatal(err)
+		}
+		if syncs != tc.syncs || (result["skipped"] == true) != tc.skipped {
+			t.Errorf("%s: %d syncs and result %v, want %d syncs and skipped %t", tc.name, syncs, result, tc.syncs, tc.skipped)
+		}
+		if tc.params.OverrideBlackout && checks != 0 {
+			t.Errorf("%s: forced run checked the blackout windows", tc.name)
+		}
+	}
+}
+
+// TestRunJobWorkflowRetryBlackout stops retrying when the backoff ends inside a blackout window
+func TestRunJobWorkflowRetryBlackout(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(RunSyncWorkflow)
+	env.RegisterWorkflow(SyncNotificationWorkflow)
+	env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: JobWorkflowIDPrefix + "sync-1"})
+
+	env.OnActivity(CheckDependenciesActivity, mock.Anything, mock.Anything).Return(&DependencyCheck{}, nil)
+	checks := 0
+	env.OnActivity(CheckBlackoutActivity, mock.Anything, mock.Anything).Return(func(context.Context, int) (string, error) {
+		// the window starts during the backoff of the first attempt
+		if checks++; checks > 1 {
+			return "blackout window: [sat] 02:00-04:00 UTC", nil
+		}
+		return "", nil
+	})
+	env.OnActivity(GetSyncRetryPolicyActivity, mock.Anything, mock.Anything).Return(&SyncRetryPolicy{
+		MaximumAttempts: 3, InitialInterval: 10 * time.Minute, BackoffCoefficient: 1, MaximumInterval: 10 * time.Minute,
+	}, nil)
+	env.OnActivity(GetConcurrencyScopesActivity, mock.Anything, mock.Anything).Return(nil, nil)
+	syncs := 0
+	env.OnWorkflow(RunSyncWorkflow, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(workflow.Context, int, bool) (map[string]interface{}, error) {
+			syncs++
+			return nil, temporal.NewApplicationError("sync failed", "sync")
+		})
+	var outcomes []SyncOutcome
+	env.OnWorkflow(SyncNotificationWorkflow, mock.Anything, mock.Anything).Return(func(_ workflow.Context, outcome SyncOutcome) error {
+		outcomes = append(outcomes, SyncOutcome{WorkflowID: outcome.WorkflowID, Error: outcome.Error})
+		return nil
+	})
+
+	env.ExecuteWorkflow(RunJobWorkflow, JobWorkflowParams{JobID: 1, ProjectID: "p1"})
+	if env.GetWorkflowError() == nil {
+		t.Error("run succeeded, want the failure of the first attempt")
+	}
+	if syncs != 1 || checks != 2 {
+		t.Errorf("%d syncs and %d blackout checks, want 1 sync and a check before the retry", syncs, checks)
+	}
+	want := []SyncOutcome{{WorkflowID: "sync-1", Error: "sync failed"}}
+	if !reflect.DeepEqual(outcomes, want) {
+		t.Errorf("notified %+v, want %+v", outcomes, want)
+	}
+}
+
+func TestPreviewWorkflow(t *testing.T) {
+	preview := &models.SyncPreviewResponse{WorkflowID: "preview-1", Streams: []models.StreamPreview{{
+		Stream:           "public.orders",
+		Columns:          []models.PreviewColumn{{Name: "id", Types: []string{"integer"}}},
+		RowCountEstimate: 2,
+		Complete:         true,
+		SampleRows:       []map[string]interface{}{{"id": float64(1)}, {"id": float64(2)}},
+	}}}
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"returns the preview", nil},
+		{"fails with the connector", temporal.NewApplicationError("connector exited with code 1", "preview")},
+	}
+	for _, tc := range cases {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+		var timeout time.Duration
+		env.SetOnActivityStartedListener(func(info *activity.Info, _ context.Context, _ converter.EncodedValues) {
+			timeout = info.Deadline.Sub(info.StartedTime)
+		})
+		attempts := 0
+		params := PreviewParams{SourceType: "postgres", Version: "latest", RowLimit: 2, SampleSize: 2, WorkflowID: "preview-1"}
+		env.OnActivity(PreviewActivity, mock.Anything, params).Return(func(context.Context, PreviewParams) (*models.SyncPreviewResponse, error) {
+			attempts++
+			if tc.err != nil {
+				return nil, tc.err
+			}
+			return preview, nil
+		})
+
+		env.ExecuteWorkflow(PreviewWorkflow, params)
+		if attempts != 1 {
+			t.Errorf("%s: %d preview runs, want 1", tc.name, attempts)
+		}
+		// the image pull of the connector comes on top of the preview run
+		if want := docker.PreviewTimeout + 10*time.Minute; timeout != want {
+			t.Errorf("%s: preview activity times out after %s, want %s", tc.name, timeout, want)
+		}
+		if tc.err != nil {
+			if err := env.GetWorkflowError(); err == nil || !strings.Contains(err.Error(), "connector exited with code 1") {
+				t.Errorf("%s: workflow error = %v, want the connector failure", tc.name, err)
+			}
+			continue
+		}
+		var got models.SyncPreviewResponse
+		if err := env.GetWorkflowResult(&got); err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if !reflect.DeepEqual(&got, preview) {
+			t.Errorf("%s: preview = %+v, want %+v", tc.name, got, preview)
+		}
+	}
+}
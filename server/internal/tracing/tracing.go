@@ -0,0 +1,167 @@
+// Package tracing sets up OpenTelemetry tracing of the server and the worker,
+// exporting spans over OTLP to the collector configured by otlpendpoint.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/datazip/olake-frontend/server"
+
+// Span attributes of OLake resources
+const (
+	AttrJobID            = attribute.Key("olake.job.id")
+	AttrWorkflowID       = attribute.Key("olake.workflow.id")
+	AttrConnectorType    = attribute.Key("olake.connector.type")
+	AttrConnectorVersion = attribute.Key("olake.connector.version")
+)
+
+// Environment variables carrying the trace context into connector containers
+const (
+	EnvTraceParent  = "TRACEPARENT"
+	EnvTraceState   = "TRACESTATE"
+	EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// collectorURL is the URL of the configured collector, empty when tracing is disabled
+var collectorURL string
+
+// flushTimeout bounds how long Flush waits for the collector
+const flushTimeout = 5 * time.Second
+
+// Init installs the tracer provider of a service. Tracing is disabled unless
+// otlpendpoint (host:port of an OTLP gRPC collector) is set in the config,
+// otlpinsecure disables TLS to the collector. The returned function flushes
+// the spans not exported yet, it is a no-op when tracing is disabled or
+// failed to start, so services can run on without tracing.
+func Init(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+	noop := func(context.Context) error { return nil }
+	endpoint := strings.TrimSpace(web.AppConfig.DefaultString("otlpendpoint", ""))
+	if endpoint == "" {
+		return noop, nil
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	collectorURL = "https://" + endpoint
+	if web.AppConfig.DefaultBool("otlpinsecure", false) {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+		collectorURL = "http://" + endpoint
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		collectorURL = ""
+		return noop, fmt.Errorf("failed to create OTLP exporter: %s", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		collectorURL = ""
+		return noop, fmt.Errorf("failed to create trace resource: %s", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	logs.Info("Exporting traces of %s to %s", serviceName, endpoint)
+	return provider.Shutdown, nil
+}
+
+// Flush exports the spans not exported yet with the shutdown function returned by Init
+func Flush(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		logs.Error("Failed to flush traces: %s", err)
+	}
+}
+
+// Tracer returns the tracer of the server and the worker
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// attributesKey is the context key of the attributes added by Annotate
+type attributesKey struct{}
+
+// Annotate adds attributes to the span in ctx and to the spans later started
+// from the returned context with Start, e.g. the job of an activity to the
+// Docker commands it runs
+func Annotate(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	inherited, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	return context.WithValue(ctx, attributesKey{}, append(append([]attribute.KeyValue{}, inherited...), attrs...))
+}
+
+// Start starts a span, a child of the span in ctx if any, with the attributes
+// added to ctx by Annotate
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	inherited, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	return Tracer().Start(ctx, name, trace.WithAttributes(inherited...), trace.WithAttributes(attrs...))
+}
+
+// End ends a span, recording err if not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject writes the trace context of ctx to carrier
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
+
+// Extract returns ctx with the trace context read from carrier
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return propagator.Extract(ctx, carrier)
+}
+
+// ContainerEnv returns the environment variables passing the trace context of
+// ctx, and the collector when configured, to a connector container
+func ContainerEnv(ctx context.Context) []string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	var env []string
+	if parent := carrier.Get("traceparent"); parent != "" {
+		env = append(env, EnvTraceParent+"="+parent)
+	}
+	if state := carrier.Get("tracestate"); state != "" {
+		env = append(env, EnvTraceState+"="+state)
+	}
+	if collectorURL != "" {
+		env = append(env, EnvOTLPEndpoint+"="+collectorURL)
+	}
+	return env
+}
+
+// JobID returns the job attribute of a span
+func JobID(id int) attribute.KeyValue {
+	return AttrJobID.Int(id)
+}
+
+// WorkflowID returns the workflow attribute of a span
+func WorkflowID(id string) attribute.KeyValue {
+	return AttrWorkflowID.String(id)
+}
+
+// Connector returns the connector attributes of a span
+func Connector(connectorType, version string) []attribute.KeyValue {
+	return []attribute.KeyValue{AttrConnectorType.String(connectorType), AttrConnectorVersion.String(version)}
+}
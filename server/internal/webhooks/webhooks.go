@@ -0,0 +1,214 @@
+// Package webhooks builds the versioned payloads of job and sync events and
+// posts them, signed with HMAC-SHA256, to the webhooks of a project.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// DeliveryTimeout bounds a single delivery attempt
+const DeliveryTimeout = 30 * time.Second
+
+// Headers of a delivery
+const (
+	HeaderEvent         = "X-OLake-Event"
+	HeaderEventID       = "X-OLake-Event-ID"
+	HeaderDelivery      = "X-OLake-Delivery"
+	HeaderSchemaVersion = "X-OLake-Schema-Version"
+	// HeaderSignature is "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">"
+	HeaderSignature = "X-OLake-Signature"
+)
+
+// Event is the payload of a delivery, see schema/v1.json
+type Event struct {
+	SchemaVersion string    `json:"schema_version"`
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	ProjectID     string    `json:"project_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Data          EventData `json:"data"`
+}
+
+// EventData is what an event is about, every event has the job
+type EventData struct {
+	Job  Job   `json:"job"`
+	Sync *Sync `json:"sync,omitempty"`
+	// Streams are the reset streams of a job.state_reset, empty when the whole state was reset
+	Streams []string `json:"streams,omitempty"`
+}
+
+type Job struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Active      bool       `json:"active"`
+	Frequency   string     `json:"frequency"`
+	Source      *Connector `json:"source,omitempty"`
+	Destination *Connector `json:"destination,omitempty"`
+}
+
+type Connector struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type Sync struct {
+	WorkflowID string     `json:"workflow_id"`
+	Attempt    int        `json:"attempt"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// NewEvent creates an event of a job with the current schema version
+func NewEvent(id, eventType string, job *models.Job, at time.Time) *Event {
+	return &Event{
+		SchemaVersion: constants.WebhookSchemaVersion,
+		ID:            id,
+		Type:          eventType,
+		ProjectID:     job.ProjectID,
+		CreatedAt:     at.UTC(),
+		Data:          EventData{Job: JobData(job)},
+	}
+}
+
+// JobData describes a job in an event, the source and destination are left out when not loaded
+func JobData(job *models.Job) Job {
+	data := Job{
+		ID:        job.ID,
+		Name:      job.Name,
+		Active:    job.Active,
+		Frequency: job.Frequency,
+	}
+	if job.SourceID != nil && job.SourceID.Name != "" {
+		data.Source = &Connector{ID: job.SourceID.ID, Name: job.SourceID.Name, Type: job.SourceID.Type}
+	}
+	if job.DestID != nil && job.DestID.Name != "" {
+		data.Destination = &Connector{ID: job.DestID.ID, Name: job.DestID.Name, Type: job.DestID.DestType}
+	}
+	return data
+}
+
+// GenerateSecret returns a random signing secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %s", err)
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// ValidateURL checks that a webhook URL is an absolute http or https URL
+func ValidateURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	return nil
+}
+
+// Sign returns the signature header of a payload sent at t
+func Sign(secret string, t time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, signature(secret, timestamp, payload))
+}
+
+// Verify checks the signature header of a payload and that it was signed
+// within tolerance of now, receivers written in Go can use it as is
+func Verify(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("malformed signature header")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp is outside the tolerance")
+	}
+	expected := signature(secret, timestamp, payload)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match")
+}
+
+func signature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StatusError is a delivery the endpoint answered with a status other than 2xx
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// Retryable tells if the endpoint may accept the delivery later, client
+// errors other than timeouts and rate limits will not go away by retrying
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Deliver posts the payload of a delivery to its webhook and returns the
+// status of the response, 0 if there was none
+func Deliver(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, DeliveryTimeout)
+	defer cancel()
+
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "olake-webhooks")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(HeaderSchemaVersion, constants.WebhookSchemaVersion)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, time.Now(), payload))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %s", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return resp.StatusCode, nil
+}
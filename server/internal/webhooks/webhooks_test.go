@@ -0,0 +1,200 @@
+package webhooks
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+//go:embed schema/v1.json
+var schemaV1 []byte
+
+var job = &models.Job{
+	ID:        3,
+	Name:      "orders",
+	Active:    true,
+	Frequency: "0 * * * *",
+	ProjectID: "123",
+	SourceID:  &models.Source{ID: 1, Name: "shop", Type: "postgres"},
+	DestID:    &models.Destination{ID: 2, Name: "lake", DestType: "iceberg"},
+}
+
+func syncFailed() *Event {
+	started := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+	finished := started.Add(90 * time.Second)
+	event := NewEvent("sync-123-3-2026-10-19T02:00:00Z/sync.failed", constants.WebhookEventSyncFailed, job, finished)
+	event.Data.Sync = &Sync{
+		WorkflowID: "sync-123-3-2026-10-19T02:00:00Z",
+		Attempt:    1,
+		StartedAt:  started,
+		FinishedAt: &finished,
+		Error:      "connection refused",
+	}
+	return event
+}
+
+func TestSignVerify(t *testing.T) {
+	payload := []byte(`{"type":"job.created"}`)
+	now := time.Unix(1760839200, 0)
+	header := Sign("whsec_test", now, payload)
+
+	if err := Verify("whsec_test", header, payload, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Errorf("Verify of a fresh signature: %s", err)
+	}
+	if err := Verify("whsec_other", header, payload, 5*time.Minute, now); err == nil {
+		t.Error("Verify with another secret succeeded")
+	}
+	if err := Verify("whsec_test", header, []byte(`{"type":"job.deleted"}`), 5*time.Minute, now); err == nil {
+		t.Error("Verify of a changed payload succeeded")
+	}
+	if err := Verify("whsec_test", header, payload, 5*time.Minute, now.Add(time.Hour)); err == nil {
+		t.Error("Verify of a stale signature succeeded")
+	}
+	if err := Verify("whsec_test", "v1=abc", payload, 5*time.Minute, now); err == nil {
+		t.Error("Verify of a header without timestamp succeeded")
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	payload, err := json.Marshal(syncFailed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := &models.Webhook{ID: 1, URL: server.URL + "/olake", Secret: "whsec_test"}
+	delivery := &models.WebhookDelivery{ID: 7, EventID: "sync-123-3-2026-10-19T02:00:00Z/sync.failed", Event: constants.WebhookEventSyncFailed, Payload: string(payload)}
+	status, err := Deliver(context.Background(), webhook, delivery)
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("Deliver = %d, %v, want 202", status, err)
+	}
+
+	req, body := <-received, <-bodies
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want the stored payload", body)
+	}
+	want := map[string]string{
+		HeaderEvent:         constants.WebhookEventSyncFailed,
+		HeaderEventID:       delivery.EventID,
+		HeaderDelivery:      "7",
+		HeaderSchemaVersion: constants.WebhookSchemaVersion,
+		"Content-Type":      "application/json",
+	}
+	for header, value := range want {
+		if got := req.Header.Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+	if err := Verify("whsec_test", req.Header.Get(HeaderSignature), body, time.Minute, time.Now()); err != nil {
+		t.Errorf("signature of the delivery: %s", err)
+	}
+}
+
+func TestDeliverStatus(t *testing.T) {
+	cases := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusInternalServerError, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusRequestTimeout, true},
+		{http.StatusGone, false},
+		{http.StatusUnauthorized, false},
+	}
+	for _, tc := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		status, err := Deliver(context.Background(), &models.Webhook{URL: server.URL, Secret: "whsec_test"}, &models.WebhookDelivery{Payload: "{}"})
+		server.Close()
+
+		var statusErr *StatusError
+		if status != tc.status || !errors.As(err, &statusErr) {
+			t.Errorf("Deliver to a %d endpoint = %d, %v", tc.status, status, err)
+			continue
+		}
+		if statusErr.Retryable() != tc.retryable {
+			t.Errorf("%d retryable = %v, want %v", tc.status, statusErr.Retryable(), tc.retryable)
+		}
+	}
+}
+
+// TestSchema keeps the published JSON schema in line with the payloads
+func TestSchema(t *testing.T) {
+	type object struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	var schema struct {
+		object
+		Properties struct {
+			SchemaVersion struct {
+				Const string `json:"const"`
+			} `json:"schema_version"`
+			Type struct {
+				Enum []string `json:"enum"`
+			} `json:"type"`
+			Data struct {
+				object
+				Properties struct {
+					Job  object `json:"job"`
+					Sync object `json:"sync"`
+				} `json:"properties"`
+			} `json:"data"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(schemaV1, &schema); err != nil {
+		t.Fatalf("schema/v1.json: %s", err)
+	}
+	if schema.Properties.SchemaVersion.Const != constants.WebhookSchemaVersion {
+		t.Errorf("schema version %q, payloads have %q", schema.Properties.SchemaVersion.Const, constants.WebhookSchemaVersion)
+	}
+	if !slices.Equal(schema.Properties.Type.Enum, constants.WebhookEvents) {
+		t.Errorf("schema event types %v, want %v", schema.Properties.Type.Enum, constants.WebhookEvents)
+	}
+
+	payload, err := json.Marshal(syncFailed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		name     string
+		required []string
+		value    interface{}
+	}{
+		{"event", schema.Required, event},
+		{"data", schema.Properties.Data.Required, event["data"]},
+		{"data.job", schema.Properties.Data.Properties.Job.Required, event["data"].(map[string]interface{})["job"]},
+		{"data.sync", schema.Properties.Data.Properties.Sync.Required, event["data"].(map[string]interface{})["sync"]},
+	}
+	for _, check := range checks {
+		fields, _ := check.value.(map[string]interface{})
+		for _, field := range check.required {
+			if _, ok := fields[field]; !ok {
+				t.Errorf("%s lacks required field %s", check.name, field)
+			}
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/core/config"
@@ -10,6 +14,7 @@ import (
 	"github.com/datazip/olake-frontend/server/internal/constants"
 	"github.com/datazip/olake-frontend/server/internal/database"
 	"github.com/datazip/olake-frontend/server/internal/logger"
+	"github.com/datazip/olake-frontend/server/internal/tracing"
 	"github.com/datazip/olake-frontend/server/routes"
 )
 
@@ -21,13 +26,38 @@ func main() {
 	// check constants
 	constants.Init()
 
+	// maintenance commands run instead of the server
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// init logger
 	logsdir, _ := config.String("logsdir")
 	logger.InitLogger(logsdir)
 
+	// init tracing, spans are exported in batches while the server runs and the
+	// server runs on without it if the collector can not be set up
+	shutdownTracing, err := tracing.Init(context.Background(), "olake-server")
+	if err != nil {
+		logs.Error("Failed to initialize tracing, continuing without it: %s", err)
+	}
+	// web.Run does not return, the spans not exported yet are flushed on termination
+	go func() {
+		signalChan := make(chan os.Signal, 1)
+		signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+		sig := <-signalChan
+		logs.Info("Received signal %v, shutting down server...", sig)
+		tracing.Flush(shutdownTracing)
+		os.Exit(0)
+	}()
+
 	// init database
 	postgresDB, _ := config.String("postgresdb")
-	err := database.Init(postgresDB)
+	err = database.Init(postgresDB)
 	if err != nil {
 		logs.Critical("Failed to initialize database: %s", err)
 	}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Login starts a session, which the client keeps for later calls, and returns the username
+func (c *Client) Login(ctx context.Context, username, password string) (string, error) {
+	var out struct {
+		Username string `json:"username"`
+	}
+	err := c.call(ctx, http.MethodPost, "/login", nil, LoginRequest{Username: username, Password: password}, &out)
+	return out.Username, err
+}
+
+// Logout ends the session
+func (c *Client) Logout(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, "/logout", nil, nil, nil)
+}
+
+// Signup registers a user
+func (c *Client) Signup(ctx context.Context, user *User) error {
+	return c.call(ctx, http.MethodPost, "/signup", nil, user, nil)
+}
+
+// CheckAuth returns an error matching ErrUnauthorized when the session is not valid
+func (c *Client) CheckAuth(ctx context.Context) error {
+	return c.call(ctx, http.MethodGet, "/auth/check", nil, nil, nil)
+}
+
+// OpenAPISpec returns the OpenAPI document of the server
+func (c *Client) OpenAPISpec(ctx context.Context) ([]byte, error) {
+	return c.raw(ctx, http.MethodGet, "/api/v1/openapi.json", nil, "", nil)
+}
@@ -0,0 +1,232 @@
+// Package client is a typed Go client for the OLake server API.
+//
+// A client authenticates with the session cookie of the server, either by
+// calling Login, which keeps the cookie for later calls, or by passing the
+// session token of an earlier login with WithToken:
+//
+//	c, err := client.New("http://localhost:8080", client.WithProject("123"))
+//	if err != nil {
+//		return err
+//	}
+//	if _, err := c.Login(ctx, "admin", "password"); err != nil {
+//		return err
+//	}
+//	jobs, err := c.ListJobs(ctx)
+//
+// Errors returned by the server are *APIError values, which match ErrNotFound
+// and the other sentinel errors with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SessionCookie is the cookie the server keeps its session in, its value is the session token
+const SessionCookie = "beegosessionID"
+
+// DefaultProject is the project the UI uses
+const DefaultProject = "123"
+
+const defaultTimeout = 5 * time.Minute
+
+// Client calls the API of an OLake server. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	project    string
+	token      string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithProject sets the project of the project scoped endpoints, DefaultProject by default
+func WithProject(project string) Option {
+	return func(c *Client) {
+		c.project = project
+	}
+}
+
+// WithToken authenticates with the session token of an earlier login
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests. Login only keeps the
+// session when the client has a cookie jar.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New returns a client for the server at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %s", err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %s", err)
+	}
+	c := &Client{
+		baseURL:    parsed,
+		project:    DefaultProject,
+		httpClient: &http.Client{Jar: jar, Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Token returns the session token of the client, from WithToken or the last Login
+func (c *Client) Token() string {
+	if c.token != "" {
+		return c.token
+	}
+	if c.httpClient.Jar == nil {
+		return ""
+	}
+	for _, cookie := range c.httpClient.Jar.Cookies(c.baseURL) {
+		if cookie.Name == SessionCookie {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// Sentinel errors matched by *APIError with errors.Is
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrServer       = errors.New("server error")
+)
+
+// APIError is an error response of the server
+type APIError struct {
+	StatusCode int
+	// Message is the message of the JSONResponse envelope, or the body when the response is not one
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// Is matches the sentinel error of the status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// projectPath returns the path of a project scoped endpoint
+func (c *Client) projectPath(format string, args ...interface{}) string {
+	return "/api/v1/project/" + url.PathEscape(c.project) + "/" + fmt.Sprintf(format, args...)
+}
+
+// call sends body as JSON, when not nil, and decodes the data of the response into out, when not nil
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %s", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	return c.send(ctx, method, path, query, "application/json", reader, out)
+}
+
+// send sends a body as is and decodes the data of the response into out, when not nil
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader, out interface{}) error {
+	data, err := c.raw(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	envelope := APIResponse[json.RawMessage]{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("invalid response: %s", err)
+	}
+	if !envelope.Success {
+		return &APIError{StatusCode: http.StatusOK, Message: envelope.Message}
+	}
+	if out == nil || len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("invalid response data: %s", err)
+	}
+	return nil
+}
+
+// raw sends a request and returns the body of a successful response
+func (c *Client) raw(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader) ([]byte, error) {
+	target := *c.baseURL
+	target.Path += path
+	target.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %s", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.AddCookie(&http.Cookie{Name: SessionCookie, Value: c.token})
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %s", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var envelope JSONResponse
+		if json.Unmarshal(data, &envelope) == nil && envelope.Message != "" {
+			apiErr.Message = envelope.Message
+		}
+		return nil, apiErr
+	}
+	return data, nil
+}
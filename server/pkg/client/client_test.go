@@ -0,0 +1,555 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/datazip/olake-frontend/server/internal/openapi"
+	"github.com/datazip/olake-frontend/server/routes"
+)
+
+// request is a request the fake server received
+type request struct {
+	Method  string
+	Path    string
+	Query   string
+	Body    string
+	Token   string
+	Handler string
+}
+
+// reply is what the fake server answers the next request with
+type reply struct {
+	status int
+	data   interface{}
+	raw    string
+	// session is set as the session cookie
+	session string
+}
+
+// fakeServer serves the real routes, but answers in a BeforeExec filter so the
+// handlers, which need a database, never run
+type fakeServer struct {
+	mu       sync.Mutex
+	handlers map[string]string
+	next     reply
+	last     request
+	hit      map[string]bool
+}
+
+var fake = &fakeServer{hit: map[string]bool{}}
+
+var baseURL string
+
+func TestMain(m *testing.M) {
+	web.BConfig.CopyRequestBody = true
+	routes.Init()
+	fake.handlers = map[string]string{}
+	for _, route := range openapi.RegisteredRoutes() {
+		fake.handlers[route.Method+" "+route.Path] = route.Handler
+	}
+	web.InsertFilter("*", web.BeforeExec, fake.serve)
+
+	server := httptest.NewServer(web.BeeApp.Handlers)
+	baseURL = server.URL
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func (f *fakeServer) serve(ctx *beecontext.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pattern, _ := ctx.Input.GetData("RouterPattern").(string)
+	handler := f.handlers[ctx.Request.Method+" "+pattern]
+	f.hit[handler] = true
+	f.last = request{
+		Method:  ctx.Request.Method,
+		Path:    ctx.Request.URL.Path,
+		Query:   ctx.Request.URL.RawQuery,
+		Body:    string(ctx.Input.RequestBody),
+		Handler: handler,
+	}
+	if cookie, err := ctx.Request.Cookie(SessionCookie); err == nil {
+		f.last.Token = cookie.Value
+	}
+
+	next := f.next
+	f.next = reply{}
+	if next.session != "" {
+		ctx.SetCookie(SessionCookie, next.session, 0, "/")
+	}
+	if next.status == 0 {
+		next.status = http.StatusOK
+	}
+	if next.raw != "" {
+		ctx.Output.SetStatus(next.status)
+		_ = ctx.Output.Body([]byte(next.raw))
+		return
+	}
+	resp := JSONResponse{Success: next.status < http.StatusBadRequest, Message: http.StatusText(next.status), Data: next.data}
+	ctx.Output.SetStatus(next.status)
+	_ = ctx.Output.JSON(resp, false, false)
+}
+
+// expect sets the reply of the next request
+func (f *fakeServer) expect(next reply) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next = next
+}
+
+func (f *fakeServer) request() request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.last
+}
+
+func newClient(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(baseURL, append([]Option{WithProject("7")}, opts...)...)
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+	return c
+}
+
+func TestEndpoints(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+	job := &CreateJobRequest{Name: "orders"}
+	state := &JobStateResponse{JobID: 3, State: `{"cursor":1}`, Revision: 2}
+	template := &JobTemplateResponse{ID: 5, Name: "per-tenant"}
+	channel := &NotificationChannelResponse{ID: 2, Name: "ops", Type: "slack", Config: NotificationChannelConfig{URL: "https://hooks.example.com"}}
+	subscriptions := &JobNotificationsResponse{JobID: 3, Subscriptions: []JobNotificationConfig{{ChannelID: 2, Events: []string{"failure"}}}}
+	webhook := &WebhookResponse{ID: 8, Name: "airflow", URL: "https://airflow.example.com/olake", Events: []string{"sync.succeeded"}, Active: true}
+	delivery := &WebhookDeliveryResponse{ID: 9, WebhookID: 8, Event: "sync.succeeded", Status: "dead", Attempts: 10}
+	readiness := &ReadinessResponse{Ready: true, Checks: []HealthCheck{{Name: "database", OK: true, DurationMs: 2}}}
+	diagnostics := &DiagnosticsResponse{Build: "v0.1.0", CommitSHA: "a671e05", Readiness: *readiness,
+		TaskQueue: TaskQueueResponse{Name: "OLAKE_DOCKER_TASK_QUEUE", Pollers: []TaskQueuePoller{{Type: "activity", Identity: "1@worker"}}}}
+	drift := &ScheduleDrift{ScheduleID: "schedule-sync-7-3", ProjectID: "7", JobID: 3, Kind: "wrong_pause", Expected: "paused", Actual: "active", Repaired: true}
+
+	cases := []struct {
+		handler string
+		path    string
+		data    interface{}
+		raw     string
+		want    interface{}
+		call    func() (interface{}, error)
+	}{
+		{"Login", "/login", map[string]string{"username": "admin"}, "", "admin",
+			func() (interface{}, error) { return c.Login(ctx, "admin", "secret") }},
+		{"Logout", "/logout", nil, "", nil,
+			func() (interface{}, error) { return nil, c.Logout(ctx) }},
+		{"Signup", "/signup", nil, "", nil,
+			func() (interface{}, error) { return nil, c.Signup(ctx, &User{Username: "admin"}) }},
+		{"CheckAuth", "/auth/check", nil, "", nil,
+			func() (interface{}, error) { return nil, c.CheckAuth(ctx) }},
+		{"Liveness", "/healthz", map[string]string{"status": "ok"}, "", nil,
+			func() (interface{}, error) { return nil, c.Healthz(ctx) }},
+		{"Readiness", "/readyz", readiness, "", readiness,
+			func() (interface{}, error) { return c.Readyz(ctx) }},
+		{"GetDiagnostics", "/api/v1/diagnostics", diagnostics, "", diagnostics,
+			func() (interface{}, error) { return c.Diagnostics(ctx) }},
+		{"ReconcileSchedules", "/api/v1/schedules/reconcile", []ScheduleDrift{*drift}, "", []ScheduleDrift{*drift},
+			func() (interface{}, error) { return c.ReconcileSchedules(ctx, true) }},
+		{"GetOpenAPISpec", "/api/v1/openapi.json", nil, `{"openapi":"3.0.3"}`, []byte(`{"openapi":"3.0.3"}`),
+			func() (interface{}, error) { return c.OpenAPISpec(ctx) }},
+		{"GetSwaggerUI", "/api/v1/docs", nil, "<html></html>", []byte("<html></html>"),
+			func() (interface{}, error) { return c.raw(ctx, http.MethodGet, "/api/v1/docs", nil, "", nil) }},
+
+		{"GetAllUsers", "/api/v1/users", []User{{Username: "admin"}}, "", []User{{Username: "admin"}},
+			func() (interface{}, error) { return c.ListUsers(ctx) }},
+		{"CreateUser", "/api/v1/users", User{Username: "bob"}, "", &User{Username: "bob"},
+			func() (interface{}, error) { return c.CreateUser(ctx, &User{Username: "bob"}) }},
+		{"UpdateUser", "/api/v1/users/4", User{Username: "bob"}, "", &User{Username: "bob"},
+			func() (interface{}, error) { return c.UpdateUser(ctx, 4, &User{Username: "bob"}) }},
+		{"DeleteUser", "/api/v1/users/4", nil, "", nil,
+			func() (interface{}, error) { return nil, c.DeleteUser(ctx, 4) }},
+		{"RestoreUser", "/api/v1/users/4/restore", RestoreResponse{Name: "bob"}, "", &RestoreResponse{Name: "bob"},
+			func() (interface{}, error) { return c.RestoreUser(ctx, 4) }},
+
+		{"GetProjectSettings", "/api/v1/project/7/settings", ProjectSettingsResponse{ProjectID: "7"}, "", &ProjectSettingsResponse{ProjectID: "7"},
+			func() (interface{}, error) { return c.GetProjectSettings(ctx) }},
+		{"UpdateProjectSettings", "/api/v1/project/7/settings", ProjectSettingsResponse{MaxConcurrentSyncs: 2}, "", &ProjectSettingsResponse{MaxConcurrentSyncs: 2},
+			func() (interface{}, error) {
+				return c.UpdateProjectSettings(ctx, &ProjectSettingsRequest{MaxConcurrentSyncs: 2})
+			}},
+		{"GetSyncConcurrency", "/api/v1/project/7/concurrency", ConcurrencyResponse{}, "", &ConcurrencyResponse{},
+			func() (interface{}, error) { return c.GetSyncConcurrency(ctx) }},
+		{"ExportProjectConfig", "/api/v1/project/7/config/export", nil, "version: 1\n", []byte("version: 1\n"),
+			func() (interface{}, error) { return c.ExportProjectConfig(ctx, ExportYAML, SecretsOmit) }},
+		{"PlanProjectConfig", "/api/v1/project/7/config/plan", ProjectConfigPlanResponse{}, "", &ProjectConfigPlanResponse{},
+			func() (interface{}, error) { return c.PlanProjectConfig(ctx, []byte("version: 1\n")) }},
+		{"ApplyProjectConfig", "/api/v1/project/7/config/apply", ProjectConfigApplyResponse{}, "", &ProjectConfigApplyResponse{},
+			func() (interface{}, error) { return c.ApplyProjectConfig(ctx, []byte("version: 1\n")) }},
+		{"GetTrash", "/api/v1/project/7/trash", []TrashItem{{Kind: "source", ID: 1, Name: "pg"}}, "", []TrashItem{{Kind: "source", ID: 1, Name: "pg"}},
+			func() (interface{}, error) { return c.ListTrash(ctx) }},
+
+		{"GetNotificationChannels", "/api/v1/project/7/notification-channels", []NotificationChannelResponse{*channel}, "", []NotificationChannelResponse{*channel},
+			func() (interface{}, error) { return c.ListNotificationChannels(ctx) }},
+		{"CreateNotificationChannel", "/api/v1/project/7/notification-channels", channel, "", channel,
+			func() (interface{}, error) {
+				return c.CreateNotificationChannel(ctx, &NotificationChannelRequest{Name: "ops", Type: "slack"})
+			}},
+		{"UpdateNotificationChannel", "/api/v1/project/7/notification-channels/2", channel, "", channel,
+			func() (interface{}, error) {
+				return c.UpdateNotificationChannel(ctx, 2, &NotificationChannelRequest{Name: "ops", Type: "slack"})
+			}},
+		{"DeleteNotificationChannel", "/api/v1/project/7/notification-channels/2", map[string]string{"name": "ops"}, "", "ops",
+			func() (interface{}, error) { return c.DeleteNotificationChannel(ctx, 2) }},
+		{"RestoreNotificationChannel", "/api/v1/project/7/notification-channels/2/restore", RestoreResponse{Name: "ops"}, "", &RestoreResponse{Name: "ops"},
+			func() (interface{}, error) { return c.RestoreNotificationChannel(ctx, 2) }},
+		{"TestNotificationChannel", "/api/v1/project/7/notification-channels/2/test", map[string]string{"name": "ops"}, "", nil,
+			func() (interface{}, error) { return nil, c.TestNotificationChannel(ctx, 2) }},
+		{"GetWebhooks", "/api/v1/project/7/webhooks", []WebhookResponse{*webhook}, "", []WebhookResponse{*webhook},
+			func() (interface{}, error) { return c.ListWebhooks(ctx) }},
+		{"CreateWebhook", "/api/v1/project/7/webhooks", webhook, "", webhook,
+			func() (interface{}, error) {
+				return c.CreateWebhook(ctx, &WebhookRequest{Name: "airflow", URL: webhook.URL, Events: webhook.Events})
+			}},
+		{"UpdateWebhook", "/api/v1/project/7/webhooks/8", webhook, "", webhook,
+			func() (interface{}, error) {
+				return c.UpdateWebhook(ctx, 8, &WebhookRequest{Name: "airflow", URL: webhook.URL, Events: webhook.Events})
+			}},
+		{"DeleteWebhook", "/api/v1/project/7/webhooks/8", map[string]string{"name": "airflow"}, "", "airflow",
+			func() (interface{}, error) { return c.DeleteWebhook(ctx, 8) }},
+		{"RestoreWebhook", "/api/v1/project/7/webhooks/8/restore", RestoreResponse{Name: "airflow"}, "", &RestoreResponse{Name: "airflow"},
+			func() (interface{}, error) { return c.RestoreWebhook(ctx, 8) }},
+		{"GetWebhookDeliveries", "/api/v1/project/7/webhook-deliveries", []WebhookDeliveryResponse{*delivery}, "", []WebhookDeliveryResponse{*delivery},
+			func() (interface{}, error) { return c.ListWebhookDeliveries(ctx, WebhookDeliveryQuery{Status: "dead"}) }},
+		{"GetWebhookDelivery", "/api/v1/project/7/webhook-deliveries/9", delivery, "", delivery,
+			func() (interface{}, error) { return c.GetWebhookDelivery(ctx, 9) }},
+		{"ReplayWebhookDelivery", "/api/v1/project/7/webhook-deliveries/9/replay", delivery, "", delivery,
+			func() (interface{}, error) { return c.ReplayWebhookDelivery(ctx, 9) }},
+
+		{"GetAllSources", "/api/v1/project/7/sources", []SourceDataItem{{ID: 1, Name: "pg"}}, "", []SourceDataItem{{ID: 1, Name: "pg"}},
+			func() (interface{}, error) { return c.ListSources(ctx) }},
+		{"CreateSource", "/api/v1/project/7/sources", CreateSourceRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}}, "", &CreateSourceRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}},
+			func() (interface{}, error) {
+				return c.CreateSource(ctx, &CreateSourceRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}})
+			}},
+		{"UpdateSource", "/api/v1/project/7/sources/1", UpdateSourceRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}}, "", &UpdateSourceRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}},
+			func() (interface{}, error) {
+				return c.UpdateSource(ctx, 1, &UpdateSourceRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}})
+			}},
+		{"DeleteSource", "/api/v1/project/7/sources/1", DeleteSourceResponse{Name: "pg"}, "", "pg",
+			func() (interface{}, error) { return c.DeleteSource(ctx, 1, "") }},
+		{"RestoreSource", "/api/v1/project/7/sources/1/restore", RestoreResponse{Name: "pg", Jobs: []string{"orders"}}, "", &RestoreResponse{Name: "pg", Jobs: []string{"orders"}},
+			func() (interface{}, error) { return c.RestoreSource(ctx, 1) }},
+		{"GetSourceJobs", "/api/v1/project/7/sources/1/jobs", map[string]interface{}{"jobs": []Job{{ID: 3}}}, "", []Job{{ID: 3}},
+			func() (interface{}, error) { return c.ListSourceJobs(ctx, 1) }},
+		{"TestConnection", "/api/v1/project/7/sources/test", map[string]interface{}{"status": "SUCCEEDED"}, "", map[string]interface{}{"status": "SUCCEEDED"},
+			func() (interface{}, error) {
+				return c.TestSource(ctx, &SourceTestConnectionRequest{ConnectorConfig: ConnectorConfig{Type: "postgres"}})
+			}},
+		{"GetSourceCatalog", "/api/v1/project/7/sources/streams", map[string]interface{}{"streams": []interface{}{}}, "", map[string]interface{}{"streams": []interface{}{}},
+			func() (interface{}, error) {
+				return c.DiscoverStreams(ctx, &StreamsRequest{ConnectorConfig: ConnectorConfig{Name: "pg"}})
+			}},
+		{"GetSourceVersions", "/api/v1/project/7/sources/versions", map[string]interface{}{"version": []string{"v0.1.0"}}, "", []string{"v0.1.0"},
+			func() (interface{}, error) { return c.ListSourceVersions(ctx, "postgres") }},
+		{"GetProjectSourceSpec", "/api/v1/project/7/sources/spec", SpecResponse{Type: "postgres"}, "", &SpecResponse{Type: "postgres"},
+			func() (interface{}, error) { return c.GetSourceSpec(ctx, &SpecRequest{Type: "postgres"}) }},
+
+		{"GetAllDestinations", "/api/v1/project/7/destinations", []DestinationDataItem{{ID: 2, Name: "s3"}}, "", []DestinationDataItem{{ID: 2, Name: "s3"}},
+			func() (interface{}, error) { return c.ListDestinations(ctx) }},
+		{"CreateDestination", "/api/v1/project/7/destinations", CreateDestinationRequest{ConnectorConfig: ConnectorConfig{Name: "s3"}}, "", &CreateDestinationRequest{ConnectorConfig: ConnectorConfig{Name: "s3"}},
+			func() (interface{}, error) {
+				return c.CreateDestination(ctx, &CreateDestinationRequest{ConnectorConfig: ConnectorConfig{Name: "s3"}})
+			}},
+		{"UpdateDestination", "/api/v1/project/7/destinations/2", UpdateDestinationRequest{ConnectorConfig: ConnectorConfig{Name: "s3"}}, "", &UpdateDestinationRequest{ConnectorConfig: ConnectorConfig{Name: "s3"}},
+			func() (interface{}, error) {
+				return c.UpdateDestination(ctx, 2, &UpdateDestinationRequest{ConnectorConfig: ConnectorConfig{Name: "s3"}})
+			}},
+		{"DeleteDestination", "/api/v1/project/7/destinations/2", DeleteDestinationResponse{Name: "s3"}, "", "s3",
+			func() (interface{}, error) { return c.DeleteDestination(ctx, 2, "") }},
+		{"RestoreDestination", "/api/v1/project/7/destinations/2/restore", RestoreResponse{Name: "s3"}, "", &RestoreResponse{Name: "s3"},
+			func() (interface{}, error) { return c.RestoreDestination(ctx, 2) }},
+		{"GetDestinationJobs", "/api/v1/project/7/destinations/2/jobs", map[string]interface{}{"jobs": []Job{{ID: 3}}}, "", []Job{{ID: 3}},
+			func() (interface{}, error) { return c.ListDestinationJobs(ctx, 2) }},
+		{"TestConnection", "/api/v1/project/7/destinations/test", map[string]interface{}{"status": "SUCCEEDED"}, "", map[string]interface{}{"status": "SUCCEEDED"},
+			func() (interface{}, error) {
+				return c.TestDestination(ctx, &DestinationTestConnectionRequest{ConnectorConfig: ConnectorConfig{Type: "s3"}})
+			}},
+		{"GetDestinationVersions", "/api/v1/project/7/destinations/versions", map[string]interface{}{"version": []string{"v0.1.0"}}, "", []string{"v0.1.0"},
+			func() (interface{}, error) { return c.ListDestinationVersions(ctx, "s3") }},
+		{"GetDestinationSpec", "/api/v1/project/7/destinations/spec", DestinationSpec{Type: "s3"}, "", &DestinationSpec{Type: "s3"},
+			func() (interface{}, error) { return c.GetDestinationSpec(ctx, &SpecRequest{Type: "s3"}) }},
+
+		{"GetAllJobs", "/api/v1/project/7/jobs", []JobResponse{{ID: 3, Name: "orders"}}, "", []JobResponse{{ID: 3, Name: "orders"}},
+			func() (interface{}, error) { return c.ListJobs(ctx) }},
+		{"CreateJob", "/api/v1/project/7/jobs", job, "", job,
+			func() (interface{}, error) { return c.CreateJob(ctx, job) }},
+		{"UpdateJob", "/api/v1/project/7/jobs/3", UpdateJobRequest{Name: "orders"}, "", &UpdateJobRequest{Name: "orders"},
+			func() (interface{}, error) { return c.UpdateJob(ctx, 3, &UpdateJobRequest{Name: "orders"}) }},
+		{"DeleteJob", "/api/v1/project/7/jobs/3", DeleteDestinationResponse{Name: "orders"}, "", "orders",
+			func() (interface{}, error) { return c.DeleteJob(ctx, 3) }},
+		{"RestoreJob", "/api/v1/project/7/jobs/3/restore", RestoreResponse{Name: "orders"}, "", &RestoreResponse{Name: "orders"},
+			func() (interface{}, error) { return c.RestoreJob(ctx, 3) }},
+		{"PreviewSchedule", "/api/v1/project/7/jobs/schedule/preview", SchedulePreviewResponse{TimeZone: "UTC"}, "", &SchedulePreviewResponse{TimeZone: "UTC"},
+			func() (interface{}, error) {
+				return c.PreviewSchedule(ctx, &SchedulePreviewRequest{Frequency: "@daily"})
+			}},
+		{"PreviewSync", "/api/v1/project/7/jobs/preview", SyncPreviewResponse{WorkflowID: "preview-1"}, "", &SyncPreviewResponse{WorkflowID: "preview-1"},
+			func() (interface{}, error) { return c.PreviewSync(ctx, &SyncPreviewRequest{}) }},
+		{"GetJobDAG", "/api/v1/project/7/jobs/dag", JobDAGResponse{}, "", &JobDAGResponse{},
+			func() (interface{}, error) { return c.GetJobDAG(ctx) }},
+		{"GetJobDependencies", "/api/v1/project/7/jobs/3/dependencies", JobDependenciesResponse{JobID: 3}, "", &JobDependenciesResponse{JobID: 3},
+			func() (interface{}, error) { return c.GetJobDependencies(ctx, 3) }},
+		{"UpdateJobDependencies", "/api/v1/project/7/jobs/3/dependencies", JobDependenciesResponse{JobID: 3}, "", &JobDependenciesResponse{JobID: 3},
+			func() (interface{}, error) {
+				return c.UpdateJobDependencies(ctx, 3, &JobDependenciesRequest{})
+			}},
+		{"GetJobNotifications", "/api/v1/project/7/jobs/3/notifications", subscriptions, "", subscriptions,
+			func() (interface{}, error) { return c.GetJobNotifications(ctx, 3) }},
+		{"UpdateJobNotifications", "/api/v1/project/7/jobs/3/notifications", subscriptions, "", subscriptions,
+			func() (interface{}, error) {
+				return c.UpdateJobNotifications(ctx, 3, &JobNotificationsRequest{Subscriptions: subscriptions.Subscriptions})
+			}},
+		{"GetJobState", "/api/v1/project/7/jobs/3/state", state, "", state,
+			func() (interface{}, error) { return c.GetJobState(ctx, 3) }},
+		{"UpdateJobState", "/api/v1/project/7/jobs/3/state", state, "", state,
+			func() (interface{}, error) { return c.UpdateJobState(ctx, 3, `{"cursor":1}`) }},
+		{"ResetJobState", "/api/v1/project/7/jobs/3/state/reset", state, "", state,
+			func() (interface{}, error) { return c.ResetJobState(ctx, 3, []string{"public.orders"}) }},
+		{"GetJobStateHistory", "/api/v1/project/7/jobs/3/states", []JobStateRevisionResponse{{Revision: 2}}, "", []JobStateRevisionResponse{{Revision: 2}},
+			func() (interface{}, error) { return c.ListJobStateHistory(ctx, 3, 10) }},
+		{"RestoreJobState", "/api/v1/project/7/jobs/3/states/1/restore", state, "", state,
+			func() (interface{}, error) { return c.RestoreJobState(ctx, 3, 1) }},
+		{"SyncJob", "/api/v1/project/7/jobs/3/sync", nil, "", nil,
+			func() (interface{}, error) { return nil, c.SyncJob(ctx, 3, true) }},
+		{"BackfillJob", "/api/v1/project/7/jobs/3/backfill", BackfillResponse{WorkflowID: "backfill-1"}, "", &BackfillResponse{WorkflowID: "backfill-1"},
+			func() (interface{}, error) {
+				return c.BackfillJob(ctx, 3, &BackfillRequest{Streams: []string{"public.orders"}}, false)
+			}},
+		{"CloneJob", "/api/v1/project/7/jobs/3/clone", CreatedJobResponse{ID: 4, Name: "orders (copy)"}, "", &CreatedJobResponse{ID: 4, Name: "orders (copy)"},
+			func() (interface{}, error) { return c.CloneJob(ctx, 3, nil) }},
+		{"ActivateJob", "/api/v1/project/7/jobs/3/activate", nil, "", nil,
+			func() (interface{}, error) { return nil, c.ActivateJob(ctx, 3, false) }},
+		{"GetJobTasks", "/api/v1/project/7/jobs/3/tasks", []JobTask{{Runtime: "1m"}}, "", []JobTask{{Runtime: "1m"}},
+			func() (interface{}, error) { return c.ListJobTasks(ctx, 3) }},
+		{"GetTaskLogs", "/api/v1/project/7/jobs/3/tasks/run-1/logs", []TaskLogEntry{{Level: "info", Message: "done"}}, "", []TaskLogEntry{{Level: "info", Message: "done"}},
+			func() (interface{}, error) { return c.GetTaskLogs(ctx, 3, "run-1", "run-1/olake.log") }},
+
+		{"GetJobTemplates", "/api/v1/project/7/job-templates", []JobTemplateResponse{*template}, "", []JobTemplateResponse{*template},
+			func() (interface{}, error) { return c.ListJobTemplates(ctx) }},
+		{"CreateJobTemplate", "/api/v1/project/7/job-templates", template, "", template,
+			func() (interface{}, error) { return c.CreateJobTemplate(ctx, &JobTemplateRequest{Name: "per-tenant"}) }},
+		{"UpdateJobTemplate", "/api/v1/project/7/job-templates/5", template, "", template,
+			func() (interface{}, error) {
+				return c.UpdateJobTemplate(ctx, 5, &JobTemplateRequest{Name: "per-tenant"})
+			}},
+		{"DeleteJobTemplate", "/api/v1/project/7/job-templates/5", map[string]string{"name": "per-tenant"}, "", "per-tenant",
+			func() (interface{}, error) { return c.DeleteJobTemplate(ctx, 5) }},
+		{"RestoreJobTemplate", "/api/v1/project/7/job-templates/5/restore", RestoreResponse{Name: "per-tenant"}, "", &RestoreResponse{Name: "per-tenant"},
+			func() (interface{}, error) { return c.RestoreJobTemplate(ctx, 5) }},
+		{"InstantiateJobTemplate", "/api/v1/project/7/job-templates/5/instantiate",
+			[]JobTemplateInstanceResponse{{Variables: map[string]string{"tenant": "a"}, Job: &CreatedJobResponse{ID: 6}}}, "",
+			[]JobTemplateInstanceResponse{{Variables: map[string]string{"tenant": "a"}, Job: &CreatedJobResponse{ID: 6}}},
+			func() (interface{}, error) {
+				return c.InstantiateJobTemplate(ctx, 5, []map[string]string{{"tenant": "a"}})
+			}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.handler+" "+tc.path, func(t *testing.T) {
+			fake.expect(reply{data: tc.data, raw: tc.raw})
+			got, err := tc.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			req := fake.request()
+			if req.Handler != tc.handler {
+				t.Errorf("routed to %q, want %q", req.Handler, tc.handler)
+			}
+			if req.Path != tc.path {
+				t.Errorf("path %q, want %q", req.Path, tc.path)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+
+	// every route is reachable through the client
+	for _, route := range openapi.RegisteredRoutes() {
+		if !fake.hit[route.Handler] {
+			t.Errorf("%s %s (%s) has no client method", route.Method, route.Path, route.Handler)
+		}
+	}
+}
+
+func TestRequestEncoding(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	fake.expect(reply{})
+	if err := c.SyncJob(ctx, 3, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "override_blackout=true" {
+		t.Errorf("query %q, want override_blackout=true", req.Query)
+	}
+
+	fake.expect(reply{data: BackfillResponse{}})
+	if _, err := c.BackfillJob(ctx, 3, &BackfillRequest{Streams: []string{"public.orders"}}, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "override_blackout=true" {
+		t.Errorf("backfill query %q, want override_blackout=true", req.Query)
+	}
+
+	fake.expect(reply{data: []JobStateRevisionResponse{}})
+	if _, err := c.ListJobStateHistory(ctx, 3, 10); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "limit=10" {
+		t.Errorf("query %q, want limit=10", req.Query)
+	}
+
+	fake.expect(reply{data: []ScheduleDrift{}})
+	if _, err := c.ReconcileSchedules(ctx, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "apply=true" {
+		t.Errorf("query %q, want apply=true", req.Query)
+	}
+
+	fake.expect(reply{data: []ScheduleDrift{}})
+	if _, err := c.ReconcileSchedules(ctx, false); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "" {
+		t.Errorf("report query %q, want none", req.Query)
+	}
+
+	fake.expect(reply{status: http.StatusForbidden})
+	if _, err := c.ReconcileSchedules(ctx, true); !errors.Is(err, ErrForbidden) {
+		t.Errorf("reconcile as a non-admin = %v, want ErrForbidden", err)
+	}
+
+	fake.expect(reply{data: DeleteSourceResponse{Name: "pg"}})
+	if _, err := c.DeleteSource(ctx, 1, "reject"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "mode=reject" {
+		t.Errorf("query %q, want mode=reject", req.Query)
+	}
+
+	fake.expect(reply{})
+	if err := c.ActivateJob(ctx, 3, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var status JobStatus
+	if err := json.Unmarshal([]byte(fake.request().Body), &status); err != nil || !status.Activate {
+		t.Errorf("body %q does not activate the job", fake.request().Body)
+	}
+
+	fake.expect(reply{raw: "{}"})
+	if _, err := c.ExportProjectConfig(ctx, ExportJSON, SecretsReference); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req := fake.request(); req.Query != "format=json&secrets=reference" {
+		t.Errorf("query %q, want format=json&secrets=reference", req.Query)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	for status, sentinel := range map[int]error{
+		http.StatusBadRequest:          ErrBadRequest,
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusForbidden:           ErrForbidden,
+		http.StatusNotFound:            ErrNotFound,
+		http.StatusConflict:            ErrConflict,
+		http.StatusInternalServerError: ErrServer,
+	} {
+		fake.expect(reply{status: status})
+		_, err := c.GetJobState(ctx, 3)
+		if !errors.Is(err, sentinel) {
+			t.Errorf("HTTP %d: got %v, want %v", status, err, sentinel)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status || apiErr.Message != http.StatusText(status) {
+			t.Errorf("HTTP %d: got %#v, want the envelope message", status, err)
+		}
+	}
+
+	// raw endpoints report the envelope message too
+	fake.expect(reply{status: http.StatusBadRequest})
+	if _, err := c.ExportProjectConfig(ctx, "xml", ""); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("got %v, want %v", err, ErrBadRequest)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.ListJobs(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+
+	c := newClient(t, WithToken("abc"))
+	fake.expect(reply{data: []JobResponse{}})
+	if _, err := c.ListJobs(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if token := fake.request().Token; token != "abc" {
+		t.Errorf("sent token %q, want abc", token)
+	}
+
+	// the session cookie set by Login is sent with later calls
+	c = newClient(t)
+	fake.expect(reply{data: map[string]string{"username": "admin"}, session: "session-1"})
+	if _, err := c.Login(ctx, "admin", "secret"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(fake.request().Body, `"username":"admin"`) {
+		t.Errorf("login body %q does not hold the username", fake.request().Body)
+	}
+	if c.Token() != "session-1" {
+		t.Errorf("token %q, want session-1", c.Token())
+	}
+	fake.expect(reply{})
+	if err := c.CheckAuth(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if token := fake.request().Token; token != "session-1" {
+		t.Errorf("sent token %q, want session-1", token)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	if got := Paginate(items, 1, 2); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("Paginate(1, 2) = %v", got)
+	}
+	if got := Paginate(items, 4, 10); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("Paginate(4, 10) = %v", got)
+	}
+	if got := Paginate(items, 9, 2); len(got) != 0 {
+		t.Errorf("Paginate(9, 2) = %v", got)
+	}
+	var pages [][]int
+	for page := range Pages(items, 2) {
+		pages = append(pages, page)
+	}
+	if !reflect.DeepEqual(pages, [][]int{{1, 2}, {3, 4}, {5}}) {
+		t.Errorf("Pages(2) = %v", pages)
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListSources returns the sources of the project
+func (c *Client) ListSources(ctx context.Context) ([]SourceDataItem, error) {
+	var out []SourceDataItem
+	err := c.call(ctx, http.MethodGet, c.projectPath("sources"), nil, nil, &out)
+	return out, err
+}
+
+// CreateSource creates a source
+func (c *Client) CreateSource(ctx context.Context, req *CreateSourceRequest) (*CreateSourceRequest, error) {
+	out := &CreateSourceRequest{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("sources"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateSource updates a source
+func (c *Client) UpdateSource(ctx context.Context, id int, req *UpdateSourceRequest) (*UpdateSourceRequest, error) {
+	out := &UpdateSourceRequest{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("sources/%d", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteSource moves a source to the trash and returns its name. mode, one of
+// reject, cascade and detach, says what happens to the jobs using it; "" is
+// the server default, detach.
+func (c *Client) DeleteSource(ctx context.Context, id int, mode string) (string, error) {
+	var query url.Values
+	if mode != "" {
+		query = url.Values{"mode": {mode}}
+	}
+	var out DeleteSourceResponse
+	err := c.call(ctx, http.MethodDelete, c.projectPath("sources/%d", id), query, nil, &out)
+	return out.Name, err
+}
+
+// RestoreSource restores a deleted source and the jobs deleted with it
+func (c *Client) RestoreSource(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("sources/%d/restore", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListSourceJobs returns the jobs reading from a source
+func (c *Client) ListSourceJobs(ctx context.Context, id int) ([]Job, error) {
+	var out struct {
+		Jobs []Job `json:"jobs"`
+	}
+	err := c.call(ctx, http.MethodGet, c.projectPath("sources/%d/jobs", id), nil, nil, &out)
+	return out.Jobs, err
+}
+
+// TestSource checks that a source config can connect
+func (c *Client) TestSource(ctx context.Context, req *SourceTestConnectionRequest) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.call(ctx, http.MethodPost, c.projectPath("sources/test"), nil, req, &out)
+	return out, err
+}
+
+// DiscoverStreams returns the catalog of the streams of a source config
+func (c *Client) DiscoverStreams(ctx context.Context, req *StreamsRequest) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.call(ctx, http.MethodPost, c.projectPath("sources/streams"), nil, req, &out)
+	return out, err
+}
+
+// ListSourceVersions returns the released versions of a source type
+func (c *Client) ListSourceVersions(ctx context.Context, sourceType string) ([]string, error) {
+	var out struct {
+		Version []string `json:"version"`
+	}
+	err := c.call(ctx, http.MethodGet, c.projectPath("sources/versions"), url.Values{"type": {sourceType}}, nil, &out)
+	return out.Version, err
+}
+
+// GetSourceSpec returns the config spec of a source type
+func (c *Client) GetSourceSpec(ctx context.Context, req *SpecRequest) (*SpecResponse, error) {
+	out := &SpecResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("sources/spec"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListDestinations returns the destinations of the project
+func (c *Client) ListDestinations(ctx context.Context) ([]DestinationDataItem, error) {
+	var out []DestinationDataItem
+	err := c.call(ctx, http.MethodGet, c.projectPath("destinations"), nil, nil, &out)
+	return out, err
+}
+
+// CreateDestination creates a destination
+func (c *Client) CreateDestination(ctx context.Context, req *CreateDestinationRequest) (*CreateDestinationRequest, error) {
+	out := &CreateDestinationRequest{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("destinations"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateDestination updates a destination
+func (c *Client) UpdateDestination(ctx context.Context, id int, req *UpdateDestinationRequest) (*UpdateDestinationRequest, error) {
+	out := &UpdateDestinationRequest{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("destinations/%d", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteDestination moves a destination to the trash and returns its name. mode, one of
+// reject, cascade and detach, says what happens to the jobs using it; "" is
+// the server default, detach.
+func (c *Client) DeleteDestination(ctx context.Context, id int, mode string) (string, error) {
+	var query url.Values
+	if mode != "" {
+		query = url.Values{"mode": {mode}}
+	}
+	var out DeleteDestinationResponse
+	err := c.call(ctx, http.MethodDelete, c.projectPath("destinations/%d", id), query, nil, &out)
+	return out.Name, err
+}
+
+// RestoreDestination restores a deleted destination and the jobs deleted with it
+func (c *Client) RestoreDestination(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("destinations/%d/restore", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListDestinationJobs returns the jobs writing to a destination
+func (c *Client) ListDestinationJobs(ctx context.Context, id int) ([]Job, error) {
+	var out struct {
+		Jobs []Job `json:"jobs"`
+	}
+	err := c.call(ctx, http.MethodGet, c.projectPath("destinations/%d/jobs", id), nil, nil, &out)
+	return out.Jobs, err
+}
+
+// TestDestination checks that a destination config can connect
+func (c *Client) TestDestination(ctx context.Context, req *DestinationTestConnectionRequest) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.call(ctx, http.MethodPost, c.projectPath("destinations/test"), nil, req, &out)
+	return out, err
+}
+
+// ListDestinationVersions returns the released versions of a destination type
+func (c *Client) ListDestinationVersions(ctx context.Context, destinationType string) ([]string, error) {
+	var out struct {
+		Version []string `json:"version"`
+	}
+	err := c.call(ctx, http.MethodGet, c.projectPath("destinations/versions"), url.Values{"type": {destinationType}}, nil, &out)
+	return out.Version, err
+}
+
+// GetDestinationSpec returns the config spec of a destination type
+func (c *Client) GetDestinationSpec(ctx context.Context, req *SpecRequest) (*DestinationSpec, error) {
+	out := &DestinationSpec{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("destinations/spec"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
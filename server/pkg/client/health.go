@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Healthz returns nil while the server process answers
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.call(ctx, http.MethodGet, "/healthz", nil, nil, nil)
+}
+
+// Readyz returns the readiness checks of the server, a server that is not
+// ready fails with an error matching ErrServer
+func (c *Client) Readyz(ctx context.Context) (*ReadinessResponse, error) {
+	out := &ReadinessResponse{}
+	if err := c.call(ctx, http.MethodGet, "/readyz", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Diagnostics returns the versions, config summary, readiness and task queue pollers of the server
+func (c *Client) Diagnostics(ctx context.Context) (*DiagnosticsResponse, error) {
+	out := &DiagnosticsResponse{}
+	if err := c.call(ctx, http.MethodGet, "/api/v1/diagnostics", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReconcileSchedules compares the jobs of all projects with their Temporal
+// schedules and returns the differences, apply also repairs them
+func (c *Client) ReconcileSchedules(ctx context.Context, apply bool) ([]ScheduleDrift, error) {
+	var out []ScheduleDrift
+	var query url.Values
+	if apply {
+		query = url.Values{"apply": {"true"}}
+	}
+	if err := c.call(ctx, http.MethodPost, "/api/v1/schedules/reconcile", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListJobs returns the jobs of the project
+func (c *Client) ListJobs(ctx context.Context) ([]JobResponse, error) {
+	var out []JobResponse
+	err := c.call(ctx, http.MethodGet, c.projectPath("jobs"), nil, nil, &out)
+	return out, err
+}
+
+// CreateJob creates a job, and its source and destination when they do not exist
+func (c *Client) CreateJob(ctx context.Context, req *CreateJobRequest) (*CreateJobRequest, error) {
+	out := &CreateJobRequest{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateJob updates a job
+func (c *Client) UpdateJob(ctx context.Context, id int, req *UpdateJobRequest) (*UpdateJobRequest, error) {
+	out := &UpdateJobRequest{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("jobs/%d", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteJob moves a job to the trash, deletes its schedule and returns its name
+func (c *Client) DeleteJob(ctx context.Context, id int) (string, error) {
+	var out DeleteDestinationResponse
+	err := c.call(ctx, http.MethodDelete, c.projectPath("jobs/%d", id), nil, nil, &out)
+	return out.Name, err
+}
+
+// RestoreJob restores a deleted job and recreates its schedule
+func (c *Client) RestoreJob(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/restore", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PreviewSchedule returns the next run times of a schedule
+func (c *Client) PreviewSchedule(ctx context.Context, req *SchedulePreviewRequest) (*SchedulePreviewResponse, error) {
+	out := &SchedulePreviewResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/schedule/preview"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PreviewSync samples the streams of a job config into a local sink
+func (c *Client) PreviewSync(ctx context.Context, req *SyncPreviewRequest) (*SyncPreviewResponse, error) {
+	out := &SyncPreviewResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/preview"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetJobDAG returns the dependency graph of the jobs of the project
+func (c *Client) GetJobDAG(ctx context.Context) (*JobDAGResponse, error) {
+	out := &JobDAGResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("jobs/dag"), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetJobDependencies returns the upstream and downstream jobs of a job
+func (c *Client) GetJobDependencies(ctx context.Context, id int) (*JobDependenciesResponse, error) {
+	out := &JobDependenciesResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("jobs/%d/dependencies", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateJobDependencies replaces the upstream jobs of a job
+func (c *Client) UpdateJobDependencies(ctx context.Context, id int, req *JobDependenciesRequest) (*JobDependenciesResponse, error) {
+	out := &JobDependenciesResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("jobs/%d/dependencies", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetJobState returns the current state of a job
+func (c *Client) GetJobState(ctx context.Context, id int) (*JobStateResponse, error) {
+	out := &JobStateResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("jobs/%d/state", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateJobState replaces the state of a job
+func (c *Client) UpdateJobState(ctx context.Context, id int, state string) (*JobStateResponse, error) {
+	out := &JobStateResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("jobs/%d/state", id), nil, JobStateRequest{State: state}, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResetJobState clears the given streams of the state of a job, or all of it when none are given
+func (c *Client) ResetJobState(ctx context.Context, id int, streams []string) (*JobStateResponse, error) {
+	out := &JobStateResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/state/reset", id), nil, JobStateResetRequest{Streams: streams}, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListJobStateHistory returns the latest state revisions of a job, newest first,
+// limit is the server default when not positive
+func (c *Client) ListJobStateHistory(ctx context.Context, id, limit int) ([]JobStateRevisionResponse, error) {
+	var query url.Values
+	if limit > 0 {
+		query = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+	var out []JobStateRevisionResponse
+	err := c.call(ctx, http.MethodGet, c.projectPath("jobs/%d/states", id), query, nil, &out)
+	return out, err
+}
+
+// RestoreJobState makes an earlier state revision of a job its current state
+func (c *Client) RestoreJobState(ctx context.Context, id, revision int) (*JobStateResponse, error) {
+	out := &JobStateResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/states/%d/restore", id, revision), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SyncJob starts a run of a job, overrideBlackout runs it inside a blackout window
+func (c *Client) SyncJob(ctx context.Context, id int, overrideBlackout bool) error {
+	var query url.Values
+	if overrideBlackout {
+		query = url.Values{"override_blackout": {"true"}}
+	}
+	return c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/sync", id), query, nil, nil)
+}
+
+// BackfillJob starts a one-off run of some streams of a job, overrideBlackout
+// runs it even inside a blackout window
+func (c *Client) BackfillJob(ctx context.Context, id int, req *BackfillRequest, overrideBlackout bool) (*BackfillResponse, error) {
+	var query url.Values
+	if overrideBlackout {
+		query = url.Values{"override_blackout": {"true"}}
+	}
+	out := &BackfillResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/backfill", id), query, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CloneJob copies a job, req may be nil to use the default name
+func (c *Client) CloneJob(ctx context.Context, id int, req *JobCloneRequest) (*CreatedJobResponse, error) {
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	out := &CreatedJobResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/clone", id), nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ActivateJob resumes or pauses the schedule of a job
+func (c *Client) ActivateJob(ctx context.Context, id int, activate bool) error {
+	return c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/activate", id), nil, JobStatus{Activate: activate}, nil)
+}
+
+// ListJobTasks returns the runs of a job
+func (c *Client) ListJobTasks(ctx context.Context, id int) ([]JobTask, error) {
+	var out []JobTask
+	err := c.call(ctx, http.MethodGet, c.projectPath("jobs/%d/tasks", id), nil, nil, &out)
+	return out, err
+}
+
+// GetTaskLogs returns the logs of a run of a job, filePath is the file path of the JobTask
+func (c *Client) GetTaskLogs(ctx context.Context, id int, taskID, filePath string) ([]TaskLogEntry, error) {
+	body := struct {
+		FilePath string `json:"file_path"`
+	}{FilePath: filePath}
+	var out []TaskLogEntry
+	err := c.call(ctx, http.MethodPost, c.projectPath("jobs/%d/tasks/%s/logs", id, url.PathEscape(taskID)), nil, body, &out)
+	return out, err
+}
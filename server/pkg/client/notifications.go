@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListNotificationChannels returns the notification channels of the project
+func (c *Client) ListNotificationChannels(ctx context.Context) ([]NotificationChannelResponse, error) {
+	var out []NotificationChannelResponse
+	err := c.call(ctx, http.MethodGet, c.projectPath("notification-channels"), nil, nil, &out)
+	return out, err
+}
+
+// CreateNotificationChannel creates a webhook, slack or email notification channel
+func (c *Client) CreateNotificationChannel(ctx context.Context, req *NotificationChannelRequest) (*NotificationChannelResponse, error) {
+	out := &NotificationChannelResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("notification-channels"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateNotificationChannel replaces a notification channel
+func (c *Client) UpdateNotificationChannel(ctx context.Context, id int, req *NotificationChannelRequest) (*NotificationChannelResponse, error) {
+	out := &NotificationChannelResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("notification-channels/%d", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteNotificationChannel moves a notification channel to the trash, its
+// subscribed jobs are not notified until it is restored, and returns its name
+func (c *Client) DeleteNotificationChannel(ctx context.Context, id int) (string, error) {
+	var out struct {
+		Name string `json:"name"`
+	}
+	err := c.call(ctx, http.MethodDelete, c.projectPath("notification-channels/%d", id), nil, nil, &out)
+	return out.Name, err
+}
+
+// RestoreNotificationChannel restores a deleted notification channel and its subscriptions
+func (c *Client) RestoreNotificationChannel(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("notification-channels/%d/restore", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TestNotificationChannel sends a test notification to a channel
+func (c *Client) TestNotificationChannel(ctx context.Context, id int) error {
+	return c.call(ctx, http.MethodPost, c.projectPath("notification-channels/%d/test", id), nil, nil, nil)
+}
+
+// GetJobNotifications returns the notification subscriptions of a job
+func (c *Client) GetJobNotifications(ctx context.Context, id int) (*JobNotificationsResponse, error) {
+	out := &JobNotificationsResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("jobs/%d/notifications", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateJobNotifications replaces the notification subscriptions of a job
+func (c *Client) UpdateJobNotifications(ctx context.Context, id int, req *JobNotificationsRequest) (*JobNotificationsResponse, error) {
+	out := &JobNotificationsResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("jobs/%d/notifications", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import "iter"
+
+// The list endpoints return every item at once, these helpers page through a
+// returned list for callers that show or process it in pages.
+
+// Paginate returns the page of items starting at offset with at most limit
+// items, all remaining items when limit is not positive
+func Paginate[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
+
+// Pages yields the items in pages of size items, the last page may be shorter.
+// A size that is not positive yields all items as one page.
+func Pages[T any](items []T, size int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if size <= 0 {
+			size = len(items)
+		}
+		for offset := 0; offset < len(items); offset += size {
+			if !yield(Paginate(items, offset, size)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetProjectSettings returns the settings of the project
+func (c *Client) GetProjectSettings(ctx context.Context) (*ProjectSettingsResponse, error) {
+	out := &ProjectSettingsResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("settings"), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateProjectSettings updates the settings of the project
+func (c *Client) UpdateProjectSettings(ctx context.Context, req *ProjectSettingsRequest) (*ProjectSettingsResponse, error) {
+	out := &ProjectSettingsResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("settings"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetSyncConcurrency returns the sync slots in use in the project
+func (c *Client) GetSyncConcurrency(ctx context.Context) (*ConcurrencyResponse, error) {
+	out := &ConcurrencyResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("concurrency"), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportProjectConfig returns the project config document of the project
+func (c *Client) ExportProjectConfig(ctx context.Context, format ExportFormat, secrets ExportSecrets) ([]byte, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", string(format))
+	}
+	if secrets != "" {
+		query.Set("secrets", string(secrets))
+	}
+	return c.raw(ctx, http.MethodGet, c.projectPath("config/export"), query, "", nil)
+}
+
+// PlanProjectConfig returns the changes importing a YAML or JSON project config document makes
+func (c *Client) PlanProjectConfig(ctx context.Context, doc []byte) (*ProjectConfigPlanResponse, error) {
+	out := &ProjectConfigPlanResponse{}
+	if err := c.send(ctx, http.MethodPost, c.projectPath("config/plan"), nil, "application/yaml", bytes.NewReader(doc), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ApplyProjectConfig imports a YAML or JSON project config document
+func (c *Client) ApplyProjectConfig(ctx context.Context, doc []byte) (*ProjectConfigApplyResponse, error) {
+	out := &ProjectConfigApplyResponse{}
+	if err := c.send(ctx, http.MethodPost, c.projectPath("config/apply"), nil, "application/yaml", bytes.NewReader(doc), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListTrash returns the deleted rows of the project that can still be restored, most recently deleted first
+func (c *Client) ListTrash(ctx context.Context) ([]TrashItem, error) {
+	var out []TrashItem
+	err := c.call(ctx, http.MethodGet, c.projectPath("trash"), nil, nil, &out)
+	return out, err
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListJobTemplates returns the job templates of the project
+func (c *Client) ListJobTemplates(ctx context.Context) ([]JobTemplateResponse, error) {
+	var out []JobTemplateResponse
+	err := c.call(ctx, http.MethodGet, c.projectPath("job-templates"), nil, nil, &out)
+	return out, err
+}
+
+// CreateJobTemplate creates a job template
+func (c *Client) CreateJobTemplate(ctx context.Context, req *JobTemplateRequest) (*JobTemplateResponse, error) {
+	out := &JobTemplateResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("job-templates"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateJobTemplate updates a job template
+func (c *Client) UpdateJobTemplate(ctx context.Context, id int, req *JobTemplateRequest) (*JobTemplateResponse, error) {
+	out := &JobTemplateResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("job-templates/%d", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteJobTemplate moves a job template to the trash and returns its name
+func (c *Client) DeleteJobTemplate(ctx context.Context, id int) (string, error) {
+	var out struct {
+		Name string `json:"name"`
+	}
+	err := c.call(ctx, http.MethodDelete, c.projectPath("job-templates/%d", id), nil, nil, &out)
+	return out.Name, err
+}
+
+// RestoreJobTemplate restores a deleted job template
+func (c *Client) RestoreJobTemplate(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("job-templates/%d/restore", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InstantiateJobTemplate creates a job for each variable set, a failed set does
+// not stop the others and is reported in its result
+func (c *Client) InstantiateJobTemplate(ctx context.Context, id int, variableSets []map[string]string) ([]JobTemplateInstanceResponse, error) {
+	var out []JobTemplateInstanceResponse
+	err := c.call(ctx, http.MethodPost, c.projectPath("job-templates/%d/instantiate", id), nil, JobTemplateInstantiateRequest{VariableSets: variableSets}, &out)
+	return out, err
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"github.com/datazip/olake-frontend/server/internal/models"
+	"github.com/datazip/olake-frontend/server/internal/webhooks"
+)
+
+// The request and response types are the server's own, so they can not drift
+// from what the handlers decode and encode.
+type (
+	APIResponse[T any] = models.APIResponse[T]
+	JSONResponse       = models.JSONResponse
+
+	User          = models.User
+	LoginRequest  = models.LoginRequest
+	LoginResponse = models.LoginResponse
+
+	ProjectSettingsRequest     = models.ProjectSettingsRequest
+	ProjectSettingsResponse    = models.ProjectSettingsResponse
+	ConcurrencyResponse        = models.ConcurrencyResponse
+	ProjectConfig              = models.ProjectConfig
+	ProjectConfigPlanResponse  = models.ProjectConfigPlanResponse
+	ProjectConfigApplyResponse = models.ProjectConfigApplyResponse
+	TrashItem                  = models.TrashItem
+	RestoreResponse            = models.RestoreResponse
+
+	ConnectorConfig                  = models.ConnectorConfig
+	Source                           = models.Source
+	Destination                      = models.Destination
+	SourceDataItem                   = models.SourceDataItem
+	DestinationDataItem              = models.DestinationDataItem
+	CreateSourceRequest              = models.CreateSourceRequest
+	UpdateSourceRequest              = models.UpdateSourceRequest
+	CreateDestinationRequest         = models.CreateDestinationRequest
+	UpdateDestinationRequest         = models.UpdateDestinationRequest
+	DeleteSourceResponse             = models.DeleteSourceResponse
+	DeleteDestinationResponse        = models.DeleteDestinationResponse
+	SourceTestConnectionRequest      = models.SourceTestConnectionRequest
+	DestinationTestConnectionRequest = models.DestinationTestConnectionRequest
+	StreamsRequest                   = models.StreamsRequest
+	SpecRequest                      = models.SpecRequest
+	SpecResponse                     = models.SpecResponse
+
+	Job                           = models.Job
+	JobResponse                   = models.JobResponse
+	CreateJobRequest              = models.CreateJobRequest
+	UpdateJobRequest              = models.UpdateJobRequest
+	JobStatus                     = models.JobStatus
+	JobTask                       = models.JobTask
+	ScheduleConfig                = models.ScheduleConfig
+	RetryPolicy                   = models.RetryPolicy
+	SchedulePreviewRequest        = models.SchedulePreviewRequest
+	SchedulePreviewResponse       = models.SchedulePreviewResponse
+	SyncPreviewRequest            = models.SyncPreviewRequest
+	SyncPreviewResponse           = models.SyncPreviewResponse
+	JobDAGResponse                = models.JobDAGResponse
+	JobDependenciesRequest        = models.JobDependenciesRequest
+	JobDependenciesResponse       = models.JobDependenciesResponse
+	JobStateRequest               = models.JobStateRequest
+	JobStateResetRequest          = models.JobStateResetRequest
+	JobStateResponse              = models.JobStateResponse
+	JobStateRevisionResponse      = models.JobStateRevisionResponse
+	BackfillRequest               = models.BackfillRequest
+	BackfillResponse              = models.BackfillResponse
+	JobCloneRequest               = models.JobCloneRequest
+	CreatedJobResponse            = models.CreatedJobResponse
+	JobTemplateRequest            = models.JobTemplateRequest
+	JobTemplateResponse           = models.JobTemplateResponse
+	JobTemplateInstantiateRequest = models.JobTemplateInstantiateRequest
+	JobTemplateInstanceResponse   = models.JobTemplateInstanceResponse
+
+	NotificationChannelRequest  = models.NotificationChannelRequest
+	NotificationChannelConfig   = models.NotificationChannelConfig
+	NotificationChannelResponse = models.NotificationChannelResponse
+	JobNotificationConfig       = models.JobNotificationConfig
+	JobNotificationsRequest     = models.JobNotificationsRequest
+	JobNotificationsResponse    = models.JobNotificationsResponse
+
+	WebhookRequest          = models.WebhookRequest
+	WebhookResponse         = models.WebhookResponse
+	WebhookDeliveryResponse = models.WebhookDeliveryResponse
+	// WebhookEvent is the payload webhooks receive
+	WebhookEvent = webhooks.Event
+
+	HealthCheck         = models.HealthCheck
+	ReadinessResponse   = models.ReadinessResponse
+	TaskQueuePoller     = models.TaskQueuePoller
+	TaskQueueResponse   = models.TaskQueueResponse
+	DiagnosticsResponse = models.DiagnosticsResponse
+
+	ScheduleDrift = models.ScheduleDrift
+)
+
+// DestinationSpec is the config spec of a destination type
+type DestinationSpec struct {
+	Version  string      `json:"version"`
+	Type     string      `json:"type"`
+	Spec     interface{} `json:"spec"`
+	UISchema interface{} `json:"uiSchema"`
+}
+
+// TaskLogEntry is a line of the logs of a job run
+type TaskLogEntry struct {
+	Level   string `json:"level"`
+	Time    string `json:"time"`
+	Message string `json:"message"`
+}
+
+// ExportFormat is the format of an exported project config
+type ExportFormat string
+
+const (
+	ExportYAML ExportFormat = "yaml"
+	ExportJSON ExportFormat = "json"
+)
+
+// ExportSecrets is how secrets are written in an exported project config
+type ExportSecrets string
+
+const (
+	// SecretsReference writes secrets as ${secret:...} references to the stored values
+	SecretsReference ExportSecrets = "reference"
+	// SecretsOmit leaves secrets out
+	SecretsOmit ExportSecrets = "omit"
+)
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// ListUsers returns all users
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var out []User
+	err := c.call(ctx, http.MethodGet, "/api/v1/users", nil, nil, &out)
+	return out, err
+}
+
+// CreateUser creates a user
+func (c *Client) CreateUser(ctx context.Context, user *User) (*User, error) {
+	out := &User{}
+	if err := c.call(ctx, http.MethodPost, "/api/v1/users", nil, user, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateUser updates a user
+func (c *Client) UpdateUser(ctx context.Context, id int, user *User) (*User, error) {
+	out := &User{}
+	if err := c.call(ctx, http.MethodPut, "/api/v1/users/"+strconv.Itoa(id), nil, user, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteUser moves a user to the trash
+func (c *Client) DeleteUser(ctx context.Context, id int) error {
+	return c.call(ctx, http.MethodDelete, "/api/v1/users/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+// RestoreUser restores a deleted user
+func (c *Client) RestoreUser(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, "/api/v1/users/"+strconv.Itoa(id)+"/restore", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
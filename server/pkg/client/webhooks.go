@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/webhooks"
+)
+
+// WebhookDeliveryQuery narrows down the delivery log, zero values match everything
+type WebhookDeliveryQuery struct {
+	WebhookID int
+	// Status is pending, succeeded or dead, dead lists the dead letters
+	Status string
+	Event  string
+	// Limit defaults to 50 on the server
+	Limit int
+}
+
+// ListWebhooks returns the webhooks of the project, without their secrets
+func (c *Client) ListWebhooks(ctx context.Context) ([]WebhookResponse, error) {
+	var out []WebhookResponse
+	err := c.call(ctx, http.MethodGet, c.projectPath("webhooks"), nil, nil, &out)
+	return out, err
+}
+
+// CreateWebhook creates a webhook, the response holds its signing secret
+func (c *Client) CreateWebhook(ctx context.Context, req *WebhookRequest) (*WebhookResponse, error) {
+	out := &WebhookResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("webhooks"), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateWebhook replaces a webhook, its secret is kept unless the request sets a new one
+func (c *Client) UpdateWebhook(ctx context.Context, id int, req *WebhookRequest) (*WebhookResponse, error) {
+	out := &WebhookResponse{}
+	if err := c.call(ctx, http.MethodPut, c.projectPath("webhooks/%d", id), nil, req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteWebhook moves a webhook to the trash and returns its name
+func (c *Client) DeleteWebhook(ctx context.Context, id int) (string, error) {
+	var out struct {
+		Name string `json:"name"`
+	}
+	err := c.call(ctx, http.MethodDelete, c.projectPath("webhooks/%d", id), nil, nil, &out)
+	return out.Name, err
+}
+
+// RestoreWebhook restores a deleted webhook
+func (c *Client) RestoreWebhook(ctx context.Context, id int) (*RestoreResponse, error) {
+	out := &RestoreResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("webhooks/%d/restore", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListWebhookDeliveries returns the newest deliveries of the project matching the query
+func (c *Client) ListWebhookDeliveries(ctx context.Context, q WebhookDeliveryQuery) ([]WebhookDeliveryResponse, error) {
+	query := url.Values{}
+	if q.WebhookID != 0 {
+		query.Set("webhook_id", strconv.Itoa(q.WebhookID))
+	}
+	if q.Status != "" {
+		query.Set("status", q.Status)
+	}
+	if q.Event != "" {
+		query.Set("event", q.Event)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var out []WebhookDeliveryResponse
+	err := c.call(ctx, http.MethodGet, c.projectPath("webhook-deliveries"), query, nil, &out)
+	return out, err
+}
+
+// GetWebhookDelivery returns a delivery with its payload
+func (c *Client) GetWebhookDelivery(ctx context.Context, id int) (*WebhookDeliveryResponse, error) {
+	out := &WebhookDeliveryResponse{}
+	if err := c.call(ctx, http.MethodGet, c.projectPath("webhook-deliveries/%d", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReplayWebhookDelivery sends a succeeded or dead delivery again
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, id int) (*WebhookDeliveryResponse, error) {
+	out := &WebhookDeliveryResponse{}
+	if err := c.call(ctx, http.MethodPost, c.projectPath("webhook-deliveries/%d/replay", id), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VerifyWebhookSignature checks the X-OLake-Signature header of a delivery
+// received now against the secret of the webhook
+func VerifyWebhookSignature(secret, header string, payload []byte, tolerance time.Duration) error {
+	return webhooks.Verify(secret, header, payload, tolerance, time.Now())
+}
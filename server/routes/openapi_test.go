@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/datazip/olake-frontend/server/internal/openapi"
+)
+
+// TestOpenAPIContract fails when a route is added, removed or renamed without
+// updating the operations documented in internal/openapi, or the other way round
+func TestOpenAPIContract(t *testing.T) {
+	Init()
+	routes := openapi.RegisteredRoutes()
+	if len(routes) == 0 {
+		t.Fatal("no routes registered")
+	}
+	for _, problem := range openapi.Check(routes) {
+		t.Error(problem)
+	}
+
+	doc := openapi.Build(routes)
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("failed to encode OpenAPI document: %s", err)
+	}
+	operationIDs := map[string]bool{}
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			if operationIDs[op.OperationID] {
+				t.Errorf("%s %s: duplicate operation ID %s", method, path, op.OperationID)
+			}
+			operationIDs[op.OperationID] = true
+		}
+	}
+}
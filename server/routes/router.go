@@ -2,10 +2,18 @@ package routes
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/beego/beego/v2/server/web"
 	"github.com/beego/beego/v2/server/web/context"
 	"github.com/datazip/olake-frontend/server/internal/handlers"
+	"github.com/datazip/olake-frontend/server/internal/metrics"
+	"github.com/datazip/olake-frontend/server/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // writeDefaultCorsHeaders sets common CORS headers
@@ -31,29 +39,125 @@ func CustomCorsFilter(ctx *context.Context) {
 	}
 }
 
+// MetricsFilterChain records the count and latency of every request by route pattern
+func MetricsFilterChain(next web.FilterFunc) web.FilterFunc {
+	return func(ctx *context.Context) {
+		start := time.Now()
+		next(ctx)
+
+		// unmatched paths share one label so scanners can not blow up the cardinality
+		route, _ := ctx.Input.GetData("RouterPattern").(string)
+		if route == "" {
+			route = "unmatched"
+		}
+		status := ctx.ResponseWriter.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.HTTPRequests.WithLabelValues(route, ctx.Input.Method(), strconv.Itoa(status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, ctx.Input.Method()).Observe(metrics.Since(start))
+	}
+}
+
+// TracingFilterChain traces every request, continuing the trace of the caller
+// when it sends a traceparent header
+func TracingFilterChain(next web.FilterFunc) web.FilterFunc {
+	return func(ctx *context.Context) {
+		spanCtx := tracing.Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+		spanCtx, span := tracing.Tracer().Start(spanCtx, "HTTP "+ctx.Input.Method(), trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		// handlers pass the request context on, so their Temporal calls join the trace
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+		next(ctx)
+
+		status := ctx.ResponseWriter.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPRequestMethodKey.String(ctx.Input.Method()), semconv.HTTPResponseStatusCode(status))
+		if route, ok := ctx.Input.GetData("RouterPattern").(string); ok && route != "" {
+			span.SetName(ctx.Input.Method() + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
 func Init() {
+	web.InsertFilterChain("*", MetricsFilterChain)
+	web.InsertFilterChain("*", TracingFilterChain)
+
 	// Apply CORS filter first
 	web.InsertFilter("*", web.BeforeRouter, CustomCorsFilter)
 
 	// Apply auth middleware to protected routes
 	web.InsertFilter("/api/v1/*", web.BeforeRouter, handlers.AuthMiddleware)
 
+	// Prometheus metrics, outside /api/v1 so scrapers need no session. The API
+	// port is public, so they are only served with a token to scrape them with.
+	if token := web.AppConfig.DefaultString("metricstoken", ""); token != "" {
+		web.Handler("/metrics", metrics.Handler(token))
+	}
+
+	// Probes, outside /api/v1 so orchestrators need no session
+	web.Router("/healthz", &handlers.HealthHandler{}, "get:Liveness")
+	web.Router("/readyz", &handlers.HealthHandler{}, "get:Readiness")
+
+	// Diagnostics, behind the session unlike the probes and only for admins
+	web.Router("/api/v1/diagnostics", &handlers.HealthHandler{}, "get:GetDiagnostics")
+
+	// Schedule reconciliation across all projects
+	web.Router("/api/v1/schedules/reconcile", &handlers.ScheduleHandler{}, "post:ReconcileSchedules")
+
 	// Auth routes
 	web.Router("/login", &handlers.AuthHandler{}, "post:Login")
+	web.Router("/logout", &handlers.AuthHandler{}, "post:Logout")
 	web.Router("/signup", &handlers.AuthHandler{}, "post:Signup")
 	web.Router("/auth/check", &handlers.AuthHandler{}, "get:CheckAuth")
 
+	// API documentation
+	web.Router("/api/v1/openapi.json", &handlers.DocsHandler{}, "get:GetOpenAPISpec")
+	web.Router("/api/v1/docs", &handlers.DocsHandler{}, "get:GetSwaggerUI")
+
 	// User routes
 	web.Router("/api/v1/users", &handlers.UserHandler{}, "post:CreateUser")
 	web.Router("/api/v1/users", &handlers.UserHandler{}, "get:GetAllUsers")
 	web.Router("/api/v1/users/:id", &handlers.UserHandler{}, "put:UpdateUser")
 	web.Router("/api/v1/users/:id", &handlers.UserHandler{}, "delete:DeleteUser")
+	web.Router("/api/v1/users/:id/restore", &handlers.UserHandler{}, "post:RestoreUser")
+
+	// Project routes
+	web.Router("/api/v1/project/:projectid/settings", &handlers.ProjectHandler{}, "get:GetProjectSettings")
+	web.Router("/api/v1/project/:projectid/settings", &handlers.ProjectHandler{}, "put:UpdateProjectSettings")
+	web.Router("/api/v1/project/:projectid/concurrency", &handlers.ProjectHandler{}, "get:GetSyncConcurrency")
+	web.Router("/api/v1/project/:projectid/config/export", &handlers.ProjectHandler{}, "get:ExportProjectConfig")
+	web.Router("/api/v1/project/:projectid/config/plan", &handlers.ProjectHandler{}, "post:PlanProjectConfig")
+	web.Router("/api/v1/project/:projectid/config/apply", &handlers.ProjectHandler{}, "post:ApplyProjectConfig")
+	web.Router("/api/v1/project/:projectid/trash", &handlers.ProjectHandler{}, "get:GetTrash")
+	web.Router("/api/v1/project/:projectid/notification-channels", &handlers.ProjectHandler{}, "get:GetNotificationChannels")
+	web.Router("/api/v1/project/:projectid/notification-channels", &handlers.ProjectHandler{}, "post:CreateNotificationChannel")
+	web.Router("/api/v1/project/:projectid/notification-channels/:id", &handlers.ProjectHandler{}, "put:UpdateNotificationChannel")
+	web.Router("/api/v1/project/:projectid/notification-channels/:id", &handlers.ProjectHandler{}, "delete:DeleteNotificationChannel")
+	web.Router("/api/v1/project/:projectid/notification-channels/:id/restore", &handlers.ProjectHandler{}, "post:RestoreNotificationChannel")
+	web.Router("/api/v1/project/:projectid/notification-channels/:id/test", &handlers.ProjectHandler{}, "post:TestNotificationChannel")
+	web.Router("/api/v1/project/:projectid/webhooks", &handlers.ProjectHandler{}, "get:GetWebhooks")
+	web.Router("/api/v1/project/:projectid/webhooks", &handlers.ProjectHandler{}, "post:CreateWebhook")
+	web.Router("/api/v1/project/:projectid/webhooks/:id", &handlers.ProjectHandler{}, "put:UpdateWebhook")
+	web.Router("/api/v1/project/:projectid/webhooks/:id", &handlers.ProjectHandler{}, "delete:DeleteWebhook")
+	web.Router("/api/v1/project/:projectid/webhooks/:id/restore", &handlers.ProjectHandler{}, "post:RestoreWebhook")
+	web.Router("/api/v1/project/:projectid/webhook-deliveries", &handlers.ProjectHandler{}, "get:GetWebhookDeliveries")
+	web.Router("/api/v1/project/:projectid/webhook-deliveries/:id", &handlers.ProjectHandler{}, "get:GetWebhookDelivery")
+	web.Router("/api/v1/project/:projectid/webhook-deliveries/:id/replay", &handlers.ProjectHandler{}, "post:ReplayWebhookDelivery")
 
 	// Source routes
 	web.Router("/api/v1/project/:projectid/sources", &handlers.SourceHandler{}, "get:GetAllSources")
 	web.Router("/api/v1/project/:projectid/sources", &handlers.SourceHandler{}, "post:CreateSource")
 	web.Router("/api/v1/project/:projectid/sources/:id", &handlers.SourceHandler{}, "put:UpdateSource")
 	web.Router("/api/v1/project/:projectid/sources/:id", &handlers.SourceHandler{}, "delete:DeleteSource")
+	web.Router("/api/v1/project/:projectid/sources/:id/restore", &handlers.SourceHandler{}, "post:RestoreSource")
+	web.Router("/api/v1/project/:projectid/sources/:id/jobs", &handlers.SourceHandler{}, "get:GetSourceJobs")
 	web.Router("/api/v1/project/:projectid/sources/test", &handlers.SourceHandler{}, "post:TestConnection")
 	web.Router("/api/v1/project/:projectid/sources/streams", &handlers.SourceHandler{}, "post:GetSourceCatalog")
 	web.Router("/api/v1/project/:projectid/sources/versions", &handlers.SourceHandler{}, "get:GetSourceVersions")
@@ -64,6 +168,8 @@ func Init() {
 	web.Router("/api/v1/project/:projectid/destinations", &handlers.DestHandler{}, "post:CreateDestination")
 	web.Router("/api/v1/project/:projectid/destinations/:id", &handlers.DestHandler{}, "put:UpdateDestination")
 	web.Router("/api/v1/project/:projectid/destinations/:id", &handlers.DestHandler{}, "delete:DeleteDestination")
+	web.Router("/api/v1/project/:projectid/destinations/:id/restore", &handlers.DestHandler{}, "post:RestoreDestination")
+	web.Router("/api/v1/project/:projectid/destinations/:id/jobs", &handlers.DestHandler{}, "get:GetDestinationJobs")
 	web.Router("/api/v1/project/:projectid/destinations/test", &handlers.DestHandler{}, "post:TestConnection")
 	web.Router("/api/v1/project/:projectid/destinations/versions", &handlers.DestHandler{}, "get:GetDestinationVersions")
 	web.Router("/api/v1/project/:projectid/destinations/spec", &handlers.DestHandler{}, "post:GetDestinationSpec")
@@ -73,7 +179,28 @@ func Init() {
 	web.Router("/api/v1/project/:projectid/jobs", &handlers.JobHandler{}, "post:CreateJob")
 	web.Router("/api/v1/project/:projectid/jobs/:id", &handlers.JobHandler{}, "put:UpdateJob")
 	web.Router("/api/v1/project/:projectid/jobs/:id", &handlers.JobHandler{}, "delete:DeleteJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/restore", &handlers.JobHandler{}, "post:RestoreJob")
+	web.Router("/api/v1/project/:projectid/jobs/schedule/preview", &handlers.JobHandler{}, "post:PreviewSchedule")
+	web.Router("/api/v1/project/:projectid/jobs/preview", &handlers.JobHandler{}, "post:PreviewSync")
+	web.Router("/api/v1/project/:projectid/jobs/dag", &handlers.JobHandler{}, "get:GetJobDAG")
+	web.Router("/api/v1/project/:projectid/jobs/:id/dependencies", &handlers.JobHandler{}, "get:GetJobDependencies")
+	web.Router("/api/v1/project/:projectid/jobs/:id/dependencies", &handlers.JobHandler{}, "put:UpdateJobDependencies")
+	web.Router("/api/v1/project/:projectid/jobs/:id/notifications", &handlers.JobHandler{}, "get:GetJobNotifications")
+	web.Router("/api/v1/project/:projectid/jobs/:id/notifications", &handlers.JobHandler{}, "put:UpdateJobNotifications")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state", &handlers.JobHandler{}, "get:GetJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state", &handlers.JobHandler{}, "put:UpdateJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/state/reset", &handlers.JobHandler{}, "post:ResetJobState")
+	web.Router("/api/v1/project/:projectid/jobs/:id/states", &handlers.JobHandler{}, "get:GetJobStateHistory")
+	web.Router("/api/v1/project/:projectid/jobs/:id/states/:rev/restore", &handlers.JobHandler{}, "post:RestoreJobState")
 	web.Router("/api/v1/project/:projectid/jobs/:id/sync", &handlers.JobHandler{}, "post:SyncJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/backfill", &handlers.JobHandler{}, "post:BackfillJob")
+	web.Router("/api/v1/project/:projectid/jobs/:id/clone", &handlers.JobHandler{}, "post:CloneJob")
+	web.Router("/api/v1/project/:projectid/job-templates", &handlers.JobHandler{}, "get:GetJobTemplates")
+	web.Router("/api/v1/project/:projectid/job-templates", &handlers.JobHandler{}, "post:CreateJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id", &handlers.JobHandler{}, "put:UpdateJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id", &handlers.JobHandler{}, "delete:DeleteJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id/restore", &handlers.JobHandler{}, "post:RestoreJobTemplate")
+	web.Router("/api/v1/project/:projectid/job-templates/:id/instantiate", &handlers.JobHandler{}, "post:InstantiateJobTemplate")
 	web.Router("/api/v1/project/:projectid/jobs/:id/activate", &handlers.JobHandler{}, "post:ActivateJob")
 	web.Router("/api/v1/project/:projectid/jobs/:id/tasks", &handlers.JobHandler{}, "get:GetJobTasks")
 	web.Router("/api/v1/project/:projectid/jobs/:id/tasks/:taskid/logs", &handlers.JobHandler{}, "post:GetTaskLogs")
@@ -13,10 +13,12 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/datazip/olake-frontend/server/internal/constants"
+	"github.com/datazip/olake-frontend/server/internal/metrics"
 )
 
 // utility provides encryption and decryption functionality using either AWS KMS or local AES-256-GCM.
@@ -49,15 +51,26 @@ func getSecretKey() ([]byte, *kms.Client, error) {
 	return hash[:], nil, nil
 }
 
+// observeEncryption records the latency of an encryption operation, including loading the KMS config
+func observeEncryption(operation string, kmsClient *kms.Client, start time.Time) {
+	backend := "aes"
+	if kmsClient != nil {
+		backend = "kms"
+	}
+	metrics.EncryptionDuration.WithLabelValues(operation, backend).Observe(metrics.Since(start))
+}
+
 func Encrypt(plaintext string) (string, error) {
 	if strings.TrimSpace(plaintext) == "" {
 		return plaintext, nil
 	}
 
+	start := time.Now()
 	key, kmsClient, err := getSecretKey()
 	if err != nil || key == nil || len(key) == 0 {
 		return plaintext, err
 	}
+	defer observeEncryption("encrypt", kmsClient, start)
 
 	// Use KMS if client is provided
 	if kmsClient != nil {
@@ -97,10 +110,12 @@ func Decrypt(encryptedText string) (string, error) {
 		return "", fmt.Errorf("cannot decrypt empty or whitespace-only input")
 	}
 
+	start := time.Now()
 	key, kmsClient, err := getSecretKey()
 	if err != nil || key == nil || len(key) == 0 {
 		return encryptedText, err
 	}
+	defer observeEncryption("decrypt", kmsClient, start)
 
 	var config string
 	err = json.Unmarshal([]byte(encryptedText), &config)
@@ -145,3 +160,16 @@ func Decrypt(encryptedText string) (string, error) {
 	}
 	return string(plaintext), nil
 }
+
+// EncryptionMode reports how secrets are encrypted: "kms", "aes" or "disabled"
+func EncryptionMode() string {
+	envKey := strings.TrimSpace(os.Getenv(constants.EncryptionKey))
+	switch {
+	case envKey == "":
+		return "disabled"
+	case strings.HasPrefix(envKey, "arn:aws:kms:"):
+		return "kms"
+	default:
+		return "aes"
+	}
+}
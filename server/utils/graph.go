@@ -0,0 +1,88 @@
+package utils
+
+import "sort"
+
+// FindCycle returns the nodes of a cycle in the directed graph, starting and
+// ending with the same node, or nil if the graph is acyclic. The graph maps
+// each node to the nodes its edges point to.
+func FindCycle(graph map[int][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[int]int{}
+	var path []int
+
+	var visit func(node int) []int
+	visit = func(node int) []int {
+		state[node] = visiting
+		path = append(path, node)
+		for _, next := range graph[node] {
+			switch state[next] {
+			case visiting:
+				// the cycle is the part of the path starting at next
+				for idx, n := range path {
+					if n == next {
+						return append(append([]int{}, path[idx:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		return nil
+	}
+
+	// visit nodes in order so the reported cycle is stable
+	nodes := make([]int, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+	for _, node := range nodes {
+		if state[node] != unvisited {
+			continue
+		}
+		if cycle := visit(node); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// GraphLevels returns the length of the longest path reaching each node of an
+// acyclic directed graph, nodes without incoming edges are at level 0
+func GraphLevels(graph map[int][]int) map[int]int {
+	levels := map[int]int{}
+	var level func(node int, parents map[int][]int) int
+	level = func(node int, parents map[int][]int) int {
+		if value, ok := levels[node]; ok {
+			return value
+		}
+		value := 0
+		for _, parent := range parents[node] {
+			value = max(value, level(parent, parents)+1)
+		}
+		levels[node] = value
+		return value
+	}
+
+	parents := map[int][]int{}
+	for node, children := range graph {
+		for _, child := range children {
+			parents[child] = append(parents[child], node)
+		}
+	}
+	for node := range graph {
+		level(node, parents)
+	}
+	for node := range parents {
+		level(node, parents)
+	}
+	return levels
+}
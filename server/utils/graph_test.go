@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindCycle(t *testing.T) {
+	cases := []struct {
+		name  string
+		graph map[int][]int
+		want  []int
+	}{
+		{"empty", map[int][]int{}, nil},
+		{"chain", map[int][]int{1: {2}, 2: {3}}, nil},
+		{"diamond", map[int][]int{1: {2, 3}, 2: {4}, 3: {4}}, nil},
+		{"self loop", map[int][]int{1: {1}}, []int{1, 1}},
+		{"two nodes", map[int][]int{1: {2}, 2: {1}}, []int{1, 2, 1}},
+		{"cycle behind a chain", map[int][]int{1: {2}, 2: {3}, 3: {4}, 4: {2}}, []int{2, 3, 4, 2}},
+		{"cycle among acyclic nodes", map[int][]int{1: {5}, 3: {4}, 4: {3}, 5: nil}, []int{3, 4, 3}},
+	}
+	for _, tc := range cases {
+		if got := FindCycle(tc.graph); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: FindCycle = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGraphLevels(t *testing.T) {
+	cases := []struct {
+		name  string
+		graph map[int][]int
+		want  map[int]int
+	}{
+		{"empty", map[int][]int{}, map[int]int{}},
+		{"single node", map[int][]int{1: nil}, map[int]int{1: 0}},
+		{"chain", map[int][]int{1: {2}, 2: {3}}, map[int]int{1: 0, 2: 1, 3: 2}},
+		// 4 is reached by 1-4 and by 1-2-3-4, the longest path wins
+		{"longest path", map[int][]int{1: {2, 4}, 2: {3}, 3: {4}}, map[int]int{1: 0, 2: 1, 3: 2, 4: 3}},
+		{"separate graphs", map[int][]int{1: {2}, 5: {6}, 7: nil}, map[int]int{1: 0, 2: 1, 5: 0, 6: 1, 7: 0}},
+	}
+	for _, tc := range cases {
+		if got := GraphLevels(tc.graph); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: GraphLevels = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleSearchDays bounds how far ahead CalendarSchedule.Next looks for a match
+const maxScheduleSearchDays = 366 * 100
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dayOfWeekNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// CronRange is an inclusive range of values with a step, mirroring Temporal's ScheduleRange
+type CronRange struct {
+	Start int
+	End   int
+	Step  int
+}
+
+// CronField is a parsed cron field made of one or more ranges
+type CronField []CronRange
+
+// Matches reports whether value is covered by any range of the field
+func (f CronField) Matches(value int) bool {
+	for _, r := range f {
+		if value < r.Start || value > r.End {
+			continue
+		}
+		if (value-r.Start)%r.Step == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarSchedule is a parsed calendar specification. All fields must match
+// for a time to fire, which is how Temporal evaluates both calendar specs and
+// cron expressions (day-of-month and day-of-week are ANDed, not ORed).
+type CalendarSchedule struct {
+	Minute     CronField
+	Hour       CronField
+	DayOfMonth CronField
+	Month      CronField
+	DayOfWeek  CronField
+	// Year is optional, an empty field matches every year
+	Year CronField
+}
+
+// ParseCronField parses a single cron field such as "*", "*/15", "1-5", "MON-FRI" or "0,30"
+func ParseCronField(expr string, minValue, maxValue int, names map[string]int) (CronField, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if expr == "" {
+		return nil, fmt.Errorf("empty field")
+	}
+
+	var field CronField
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepExpr)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangeExpr == "*":
+			start, end = minValue, maxValue
+		case strings.Contains(rangeExpr, "-"):
+			startExpr, endExpr, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if start, err = parseCronValue(startExpr, names); err != nil {
+				return nil, err
+			}
+			if end, err = parseCronValue(endExpr, names); err != nil {
+				return nil, err
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(rangeExpr, names); err != nil {
+				return nil, err
+			}
+			end = start
+			// "5/10" means starting at 5 every 10 until the maximum
+			if hasStep {
+				end = maxValue
+			}
+		}
+
+		if start < minValue || end > maxValue {
+			return nil, fmt.Errorf("value out of range [%d-%d] in %q", minValue, maxValue, part)
+		}
+		if start > end {
+			return nil, fmt.Errorf("range start is greater than end in %q", part)
+		}
+		field = append(field, CronRange{Start: start, End: end, Step: step})
+	}
+
+	return field, nil
+}
+
+func parseCronValue(expr string, names map[string]int) (int, error) {
+	if value, ok := names[expr]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", expr)
+	}
+	return value, nil
+}
+
+// ParseCalendarFields parses calendar fields written in cron syntax. Empty
+// minute and hour default to 0, all other empty fields default to "*", which
+// matches the defaults of Temporal calendar specs.
+func ParseCalendarFields(minute, hour, dayOfMonth, month, dayOfWeek string) (*CalendarSchedule, error) {
+	var err error
+	schedule := &CalendarSchedule{}
+	if schedule.Minute, err = parseCalendarField("minute", minute, "0", 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if schedule.Hour, err = parseCalendarField("hour", hour, "0", 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if schedule.DayOfMonth, err = parseCalendarField("day of month", dayOfMonth, "*", 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if schedule.Month, err = parseCalendarField("month", month, "*", 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	// day of week accepts 7 as an alias for sunday
+	if schedule.DayOfWeek, err = parseCalendarField("day of week", dayOfWeek, "*", 0, 7, dayOfWeekNames); err != nil {
+		return nil, err
+	}
+	schedule.DayOfWeek = normalizeDayOfWeek(schedule.DayOfWeek)
+	return schedule, nil
+}
+
+func parseCalendarField(name, expr, fallback string, minValue, maxValue int, names map[string]int) (CronField, error) {
+	if strings.TrimSpace(expr) == "" {
+		expr = fallback
+	}
+	field, err := ParseCronField(expr, minValue, maxValue, names)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s field: %s", name, err)
+	}
+	return field, nil
+}
+
+// normalizeDayOfWeek folds the value 7 into 0 so that ranges stay within 0-6
+func normalizeDayOfWeek(field CronField) CronField {
+	normalized := make(CronField, 0, len(field))
+	for _, r := range field {
+		if r.End == 7 && (7-r.Start)%r.Step == 0 {
+			normalized = append(normalized, CronRange{Start: 0, End: 0, Step: 1})
+		}
+		if r.Start == 7 {
+			continue
+		}
+		if r.End == 7 {
+			r.End = 6
+		}
+		normalized = append(normalized, r)
+	}
+	return normalized
+}
+
+// ParseCron parses a standard 5-field cron expression (minute hour day-of-month month day-of-week)
+func ParseCron(expr string) (*CalendarSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have exactly 5 fields, found %d", expr, len(fields))
+	}
+	schedule, err := ParseCalendarFields(fields[0], fields[1], fields[2], fields[3], fields[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %s", expr, err)
+	}
+	return schedule, nil
+}
+
+// Next returns the first time strictly after the given time matching the
+// schedule in loc, or the zero time when nothing matches within the search window
+func (c *CalendarSchedule) Next(after time.Time, loc *time.Location) time.Time {
+	after = after.In(loc)
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i < maxScheduleSearchDays; i++ {
+		candidate := day.AddDate(0, 0, i)
+		if !c.matchesDay(candidate) {
+			continue
+		}
+		// wall clock hours and minutes before after on its own day fire before it
+		firstHour := 0
+		if i == 0 {
+			firstHour = after.Hour()
+		}
+		for hour := firstHour; hour < 24; hour++ {
+			if !c.Hour.Matches(hour) {
+				continue
+			}
+			firstMinute := 0
+			if i == 0 && hour == after.Hour() {
+				firstMinute = after.Minute()
+			}
+			for minute := firstMinute; minute < 60; minute++ {
+				if !c.Minute.Matches(minute) {
+					continue
+				}
+				fireAt := time.Date(candidate.Year(), candidate.Month(), candidate.Day(), hour, minute, 0, 0, loc)
+				if fireAt.After(after) {
+					return fireAt
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (c *CalendarSchedule) matchesDay(day time.Time) bool {
+	if len(c.Year) > 0 && !c.Year.Matches(day.Year()) {
+		return false
+	}
+	return c.Month.Matches(int(day.Month())) &&
+		c.DayOfMonth.Matches(day.Day()) &&
+		c.DayOfWeek.Matches(int(day.Weekday()))
+}
+
+// ParseFrequency splits the "N-unit" shorthand (e.g. "15-minutes") into its value and unit
+func ParseFrequency(frequency string) (int, string, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(frequency)), "-")
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("invalid frequency %q, expected format N-unit", frequency)
+	}
+
+	value, err := strconv.Atoi(parts[0])
+	if err != nil || value <= 0 {
+		return 0, "", fmt.Errorf("invalid frequency value %q, must be a positive integer", parts[0])
+	}
+
+	switch parts[1] {
+	case "minutes", "hours", "days", "weeks", "months", "years":
+		return value, parts[1], nil
+	default:
+		return 0, "", fmt.Errorf("unsupported frequency unit %q", parts[1])
+	}
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	cases := []struct {
+		expr string
+		min  int
+		max  int
+		want CronField
+	}{
+		{"*", 0, 59, CronField{{0, 59, 1}}},
+		{"*/15", 0, 59, CronField{{0, 59, 15}}},
+		{"5/10", 0, 59, CronField{{5, 59, 10}}},
+		{"1-5", 0, 6, CronField{{1, 5, 1}}},
+		{"10-20/5", 0, 59, CronField{{10, 20, 5}}},
+		{"0,30", 0, 59, CronField{{0, 0, 1}, {30, 30, 1}}},
+		{" 7 ", 0, 23, CronField{{7, 7, 1}}},
+	}
+	for _, tc := range cases {
+		got, err := ParseCronField(tc.expr, tc.min, tc.max, nil)
+		if err != nil {
+			t.Errorf("ParseCronField(%q): %s", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseCronField(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+
+	names, err := ParseCronField("MON-fri", 0, 7, dayOfWeekNames)
+	if err != nil || !reflect.DeepEqual(names, CronField{{1, 5, 1}}) {
+		t.Errorf("ParseCronField(MON-fri) = %v, %v, want 1-5", names, err)
+	}
+}
+
+func TestParseCronFieldInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"", " ", "60", "-1", "a", "5-", "-5", "20-10", "*/0", "*/-1", "*/x", "1-5/0", "1,,2", "0-60", "1/2/3",
+	} {
+		if field, err := ParseCronField(expr, 0, 59, nil); err == nil {
+			t.Errorf("ParseCronField(%q) = %v, want an error", expr, field)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 * ", "* * * * 8", "* * * foo *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", expr)
+		}
+	}
+
+	schedule, err := ParseCron("30 2 * JAN,jul 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(schedule.Month, CronField{{1, 1, 1}, {7, 7, 1}}) {
+		t.Errorf("month = %v, want jan and jul", schedule.Month)
+	}
+	// 7 is sunday
+	if !reflect.DeepEqual(schedule.DayOfWeek, CronField{{0, 0, 1}}) {
+		t.Errorf("day of week = %v, want sunday", schedule.DayOfWeek)
+	}
+}
+
+func TestNormalizeDayOfWeek(t *testing.T) {
+	cases := []struct {
+		expr string
+		want []int
+	}{
+		{"*", []int{0, 1, 2, 3, 4, 5, 6}},
+		{"5-7", []int{0, 5, 6}},
+		{"1-7/2", []int{0, 1, 3, 5}},
+		{"0-7/2", []int{0, 2, 4, 6}},
+		{"7", []int{0}},
+	}
+	for _, tc := range cases {
+		schedule, err := ParseCalendarFields("", "", "", "", tc.expr)
+		if err != nil {
+			t.Errorf("%s: %s", tc.expr, err)
+			continue
+		}
+		var days []int
+		for day := 0; day <= 7; day++ {
+			if schedule.DayOfWeek.Matches(day) {
+				days = append(days, day)
+			}
+		}
+		if !reflect.DeepEqual(days, tc.want) {
+			t.Errorf("day of week %q matches %v, want %v", tc.expr, days, tc.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		cron  string
+		after time.Time
+		loc   *time.Location
+		want  time.Time
+	}{
+		{"every 15 minutes", "*/15 * * * *", time.Date(2026, 3, 2, 10, 7, 0, 0, time.UTC), time.UTC,
+			time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC)},
+		{"strictly after", "*/15 * * * *", time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC), time.UTC,
+			time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)},
+		{"later in the same hour", "45 10 * * *", time.Date(2026, 3, 2, 10, 44, 59, 0, time.UTC), time.UTC,
+			time.Date(2026, 3, 2, 10, 45, 0, 0, time.UTC)},
+		{"day of month and day of week are anded", "0 0 13 * 5", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC,
+			time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 12 29 2 *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC,
+			time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"time zone", "0 9 * * *", time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC), newYork,
+			time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC)},
+		{"time zone after the dst change", "0 9 * * *", time.Date(2026, 11, 2, 12, 0, 0, 0, time.UTC), newYork,
+			time.Date(2026, 11, 2, 14, 0, 0, 0, time.UTC)},
+		{"next day in the time zone", "0 1 * * *", time.Date(2026, 7, 1, 4, 30, 0, 0, time.UTC), newYork,
+			time.Date(2026, 7, 1, 5, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		schedule, err := ParseCron(tc.cron)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if got := schedule.Next(tc.after, tc.loc); !got.Equal(tc.want) {
+			t.Errorf("%s: Next = %s, want %s", tc.name, got.UTC(), tc.want)
+		}
+	}
+
+	never, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := never.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC); !got.IsZero() {
+		t.Errorf("february 31st fires at %s, want never", got)
+	}
+}
+
+func TestParseFrequency(t *testing.T) {
+	value, unit, err := ParseFrequency(" 15-Minutes ")
+	if err != nil || value != 15 || unit != "minutes" {
+		t.Errorf("ParseFrequency = %d, %q, %v, want 15 minutes", value, unit, err)
+	}
+	for _, frequency := range []string{"", "15", "minutes", "0-hours", "-1-hours", "2-fortnights", "1-2-days", "x-days"} {
+		if _, _, err := ParseFrequency(frequency); err == nil {
+			t.Errorf("ParseFrequency(%q) succeeded, want an error", frequency)
+		}
+	}
+}
+
+func TestToCron(t *testing.T) {
+	cases := []struct {
+		frequency string
+		want      string
+	}{
+		{"15-minutes", "*/15 * * * *"},
+		{"2-hours", "0 */2 * * *"},
+		{"3-days", "0 0 */3 * *"},
+		{"1-weeks", "0 0 * * 0"},
+		{"6-months", "0 0 1 */6 *"},
+		{"1-years", "0 0 1 1 *"},
+		{"  5 4 * * 1-5 ", "5 4 * * 1-5"},
+	}
+	for _, tc := range cases {
+		got, err := ToCron(tc.frequency)
+		if err != nil || got != tc.want {
+			t.Errorf("ToCron(%q) = %q, %v, want %q", tc.frequency, got, err, tc.want)
+		}
+	}
+	// every N weeks and years need an interval or a calendar with a year
+	for _, frequency := range []string{"2-weeks", "5-years", "61 * * * *"} {
+		if got, err := ToCron(frequency); err == nil {
+			t.Errorf("ToCron(%q) = %q, want an error", frequency, got)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func decodeConfig(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestHideSecrets(t *testing.T) {
+	config := decodeConfig(t, `{
+		"host": "db.internal",
+		"port": 5432,
+		"username": "olake",
+		"password": "p",
+		"conn_string": "host=db password=p",
+		"dsn": "postgres://olake:p@db/orders",
+		"jdbc_url": "jdbc:postgresql://db/orders?password=p",
+		"rest_catalog_url": "https://user:p@catalog.internal",
+		"hosts": ["a:27017", "b:27017"],
+		"seeds": ["mongodb://olake:p@a"],
+		"headers": {"Authorization": "Bearer x"},
+		"ssl": {"mode": "require", "client_key": "k"},
+		"max_threads": 4,
+		"srv": true,
+		"api_key": 12345,
+		"empty_value": "",
+		"null_value": null
+	}`)
+	HideSecrets(config, "source", "pg", false)
+	want := decodeConfig(t, `{
+		"host": "db.internal",
+		"port": 5432,
+		"username": "olake",
+		"password": "${secret:source/pg/password}",
+		"conn_string": "${secret:source/pg/conn_string}",
+		"dsn": "${secret:source/pg/dsn}",
+		"jdbc_url": "${secret:source/pg/jdbc_url}",
+		"rest_catalog_url": "${secret:source/pg/rest_catalog_url}",
+		"hosts": ["a:27017", "b:27017"],
+		"seeds": "${secret:source/pg/seeds}",
+		"headers": {"Authorization": "${secret:source/pg/headers.Authorization}"},
+		"ssl": {"mode": "require", "client_key": "${secret:source/pg/ssl.client_key}"},
+		"max_threads": 4,
+		"srv": true,
+		"api_key": "${secret:source/pg/api_key}",
+		"empty_value": "",
+		"null_value": null
+	}`)
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("HideSecrets() = %v, want %v", config, want)
+	}
+
+	config = decodeConfig(t, `{"host": "db", "password": "p", "ssl": {"client_key": "k"}, "hosts": ["mongodb://u:p@a"]}`)
+	HideSecrets(config, "source", "pg", true)
+	if want := decodeConfig(t, `{"host": "db", "ssl": {}}`); !reflect.DeepEqual(config, want) {
+		t.Errorf("HideSecrets() with omit = %v, want %v", config, want)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	stored := decodeConfig(t, `{"password": "p", "ssl": {"client_key": "k"}, "seeds": ["a", "b"]}`)
+	lookup := func(ref SecretReference) (interface{}, error) {
+		if ref.Kind != "source" || ref.Name != "pg" {
+			return nil, fmt.Errorf("%s %q does not exist", ref.Kind, ref.Name)
+		}
+		value, ok := LookupPath(stored, ref.Path)
+		if !ok {
+			return nil, fmt.Errorf("no %s", ref.Path)
+		}
+		return value, nil
+	}
+
+	config := decodeConfig(t, `{
+		"host": "db",
+		"password": "${secret:source/pg/password}",
+		"ssl": {"client_key": "${secret:source/pg/ssl.client_key}"},
+		"seeds": "${secret:source/pg/seeds}",
+		"keys": ["${secret:source/pg/password}", "plain"]
+	}`)
+	if err := ResolveSecrets(config, lookup); err != nil {
+		t.Fatal(err)
+	}
+	want := decodeConfig(t, `{"host": "db", "password": "p", "ssl": {"client_key": "k"}, "seeds": ["a", "b"], "keys": ["p", "plain"]}`)
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("ResolveSecrets() = %v, want %v", config, want)
+	}
+
+	for _, ref := range []string{"${secret:source/pg/missing}", "${secret:destination/pg/password}"} {
+		if err := ResolveSecrets(map[string]interface{}{"password": ref}, lookup); err == nil {
+			t.Errorf("ResolveSecrets(%s) resolved", ref)
+		}
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	config := decodeConfig(t, `{"a": {"b": {"c": 1}}, "d": "x"}`)
+	if value, ok := LookupPath(config, "a.b.c"); !ok || value != float64(1) {
+		t.Errorf("LookupPath(a.b.c) = %v, %t", value, ok)
+	}
+	for _, path := range []string{"a.x", "d.e", "x"} {
+		if value, ok := LookupPath(config, path); ok {
+			t.Errorf("LookupPath(%s) = %v, want not found", path, value)
+		}
+	}
+}
+
+func TestKeepOmittedSecrets(t *testing.T) {
+	current := decodeConfig(t, `{"host": "a", "password": "p", "dsn": "postgres://u:p@a", "ssl": {"client_key": "k", "mode": "require"}}`)
+	updated := decodeConfig(t, `{"host": "b", "ssl": {}}`)
+	KeepOmittedSecrets(current, updated)
+	want := decodeConfig(t, `{"host": "b", "password": "p", "dsn": "postgres://u:p@a", "ssl": {"client_key": "k"}}`)
+	if !reflect.DeepEqual(updated, want) {
+		t.Errorf("KeepOmittedSecrets() = %v, want %v", updated, want)
+	}
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// job state types written by the connectors
+var stateTypes = []string{"STREAM", "GLOBAL", "MIXED"}
+
+// ValidateJobState checks that a job state is a JSON object in the format the
+// connectors read, e.g. {"type": "STREAM", "streams": [{"stream": "users", "namespace": "public", "state": {}}]}
+func ValidateJobState(state string) error {
+	parsed, err := parseJobState(state)
+	if err != nil {
+		return err
+	}
+
+	if stateType, ok := parsed["type"]; ok {
+		typ, isString := stateType.(string)
+		if !isString || !slices.Contains(stateTypes, typ) {
+			return fmt.Errorf("state type must be one of %s", strings.Join(stateTypes, ", "))
+		}
+	}
+	if global, ok := parsed["global"]; ok && global != nil {
+		globalState, isObject := global.(map[string]interface{})
+		if !isObject {
+			return fmt.Errorf("state global must be an object")
+		}
+		if streams, ok := globalState["streams"]; ok && streams != nil {
+			ids, isArray := streams.([]interface{})
+			if !isArray {
+				return fmt.Errorf("state global.streams must be an array of stream IDs")
+			}
+			for _, id := range ids {
+				if _, isString := id.(string); !isString {
+					return fmt.Errorf("state global.streams must be an array of stream IDs")
+				}
+			}
+		}
+	}
+	if streams, ok := parsed["streams"]; ok && streams != nil {
+		entries, isArray := streams.([]interface{})
+		if !isArray {
+			return fmt.Errorf("state streams must be an array")
+		}
+		for idx, entry := range entries {
+			stream, isObject := entry.(map[string]interface{})
+			if !isObject {
+				return fmt.Errorf("state streams[%d] must be an object", idx)
+			}
+			if name, _ := stream["stream"].(string); name == "" {
+				return fmt.Errorf("state streams[%d].stream must be a non-empty string", idx)
+			}
+			if namespace, ok := stream["namespace"]; ok && namespace != nil {
+				if _, isString := namespace.(string); !isString {
+					return fmt.Errorf("state streams[%d].namespace must be a string", idx)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ResetStreams removes streams from a job state so the next sync loads them
+// from scratch. Streams are given as "namespace.name", or "name" to match the
+// stream in every namespace.
+func ResetStreams(state string, streams []string) (string, error) {
+	reset, found, err := removeStreams(state, streams)
+	if err != nil {
+		return "", err
+	}
+	for _, stream := range streams {
+		if !found[stream] {
+			return "", fmt.Errorf("stream %q not found in job state", stream)
+		}
+	}
+	return reset, nil
+}
+
+// WithoutStreams is ResetStreams ignoring streams that are not in the state
+func WithoutStreams(state string, streams []string) (string, error) {
+	reset, _, err := removeStreams(state, streams)
+	return reset, err
+}
+
+// removeStreams removes streams from a job state and reports which streams were found
+func removeStreams(state string, streams []string) (string, map[string]bool, error) {
+	parsed, err := parseJobState(state)
+	if err != nil {
+		return "", nil, err
+	}
+
+	found := map[string]bool{}
+	matches := func(id, name string) bool {
+		for _, stream := range streams {
+			if stream == id || (!strings.Contains(stream, ".") && stream == name) {
+				found[stream] = true
+				return true
+			}
+		}
+		return false
+	}
+
+	if entries, ok := parsed["streams"].([]interface{}); ok {
+		remaining := make([]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			stream, _ := entry.(map[string]interface{})
+			name, _ := stream["stream"].(string)
+			namespace, _ := stream["namespace"].(string)
+			if !matches(fmt.Sprintf("%s.%s", namespace, name), name) {
+				remaining = append(remaining, entry)
+			}
+		}
+		parsed["streams"] = remaining
+	}
+	if global, ok := parsed["global"].(map[string]interface{}); ok {
+		// streams missing from the global state are backfilled before joining CDC
+		if ids, ok := global["streams"].([]interface{}); ok {
+			remaining := make([]interface{}, 0, len(ids))
+			for _, id := range ids {
+				streamID, _ := id.(string)
+				_, name, _ := strings.Cut(streamID, ".")
+				if !matches(streamID, name) {
+					remaining = append(remaining, id)
+				}
+			}
+			global["streams"] = remaining
+		}
+	}
+
+	reset, err := json.Marshal(parsed)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode job state: %s", err)
+	}
+	return string(reset), found, nil
+}
+
+func parseJobState(state string) (map[string]interface{}, error) {
+	parsed := map[string]interface{}{}
+	if strings.TrimSpace(state) == "" {
+		return parsed, nil
+	}
+	if err := json.Unmarshal([]byte(state), &parsed); err != nil {
+		return nil, fmt.Errorf("state must be a JSON object: %s", err)
+	}
+	if parsed == nil {
+		// "null" decodes without error
+		return nil, fmt.Errorf("state must be a JSON object")
+	}
+	return parsed, nil
+}
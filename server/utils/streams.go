@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datazip/olake-frontend/server/internal/models"
+)
+
+// SelectStreams narrows a job streams config to the given streams, forcing a
+// full refresh of them and setting filter on each of them when not empty.
+// Streams are given as "namespace.name", or "name" to match the stream in
+// every namespace, and must be selected in the config.
+func SelectStreams(streamsConfig string, streams []string, filter string) (string, error) {
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(streamsConfig), &config); err != nil {
+		return "", fmt.Errorf("invalid streams config: %s", err)
+	}
+	selected, _ := config["selected_streams"].(map[string]interface{})
+
+	found := map[string]bool{}
+	matches := func(namespace, name string) bool {
+		for _, stream := range streams {
+			if stream == fmt.Sprintf("%s.%s", namespace, name) || (!strings.Contains(stream, ".") && stream == name) {
+				found[stream] = true
+				return true
+			}
+		}
+		return false
+	}
+
+	chosen := map[string]interface{}{}
+	chosenIDs := map[string]bool{}
+	for namespace, entries := range selected {
+		list, _ := entries.([]interface{})
+		for _, entry := range list {
+			stream, _ := entry.(map[string]interface{})
+			name, _ := stream["stream_name"].(string)
+			if stream == nil || !matches(namespace, name) {
+				continue
+			}
+			if filter != "" {
+				stream["filter"] = filter
+			}
+			chosen[namespace] = append(asList(chosen[namespace]), stream)
+			chosenIDs[fmt.Sprintf("%s.%s", namespace, name)] = true
+		}
+	}
+	for _, stream := range streams {
+		if !found[stream] {
+			return "", fmt.Errorf("stream %q is not selected in the job", stream)
+		}
+	}
+	config["selected_streams"] = chosen
+
+	// a one-off run reads the chosen streams from scratch instead of resuming from a cursor or CDC position
+	if entries, ok := config["streams"].([]interface{}); ok {
+		for _, entry := range entries {
+			stream, _ := entry.(map[string]interface{})
+			details, _ := stream["stream"].(map[string]interface{})
+			name, _ := details["name"].(string)
+			namespace, _ := details["namespace"].(string)
+			if chosenIDs[fmt.Sprintf("%s.%s", namespace, name)] {
+				stream["sync_mode"] = "full_refresh"
+			}
+		}
+	}
+
+	narrowed, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode streams config: %s", err)
+	}
+	return string(narrowed), nil
+}
+
+// TimeRangeFilter builds a connector stream filter selecting rows of column
+// within [from, to), either bound may be zero. Bounds are written as double
+// quoted RFC3339 timestamps, which hold no quotes or escapes.
+func TimeRangeFilter(column string, from, to time.Time) string {
+	var conditions []string
+	if !from.IsZero() {
+		conditions = append(conditions, fmt.Sprintf(`%s >= "%s"`, column, from.Format(time.RFC3339)))
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, fmt.Sprintf(`%s < "%s"`, column, to.Format(time.RFC3339)))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// SelectedStreams returns the "namespace.name" of the streams selected in a job streams config
+func SelectedStreams(streamsConfig string) ([]string, error) {
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(streamsConfig), &config); err != nil {
+		return nil, fmt.Errorf("invalid streams config: %s", err)
+	}
+	selected, _ := config["selected_streams"].(map[string]interface{})
+
+	var streams []string
+	for namespace, entries := range selected {
+		for _, entry := range asList(entries) {
+			stream, _ := entry.(map[string]interface{})
+			if name, _ := stream["stream_name"].(string); name != "" {
+				streams = append(streams, fmt.Sprintf("%s.%s", namespace, name))
+			}
+		}
+	}
+	sort.Strings(streams)
+	return streams, nil
+}
+
+// StreamColumns returns the columns the catalog of a job streams config
+// declares for each stream, keyed by "namespace.name" and sorted by name
+func StreamColumns(streamsConfig string) (map[string][]models.PreviewColumn, error) {
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(streamsConfig), &config); err != nil {
+		return nil, fmt.Errorf("invalid streams config: %s", err)
+	}
+
+	columns := map[string][]models.PreviewColumn{}
+	for _, entry := range asList(config["streams"]) {
+		stream, _ := entry.(map[string]interface{})
+		details, _ := stream["stream"].(map[string]interface{})
+		name, _ := details["name"].(string)
+		namespace, _ := details["namespace"].(string)
+		schema, _ := details["type_schema"].(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		streamColumns := make([]models.PreviewColumn, 0, len(properties))
+		for column, property := range properties {
+			property, _ := property.(map[string]interface{})
+			var types []string
+			switch value := property["type"].(type) {
+			case string:
+				types = []string{value}
+			case []interface{}:
+				for _, t := range value {
+					if t, ok := t.(string); ok {
+						types = append(types, t)
+					}
+				}
+			}
+			streamColumns = append(streamColumns, models.PreviewColumn{Name: column, Types: types})
+		}
+		sort.Slice(streamColumns, func(i, j int) bool { return streamColumns[i].Name < streamColumns[j].Name })
+		columns[fmt.Sprintf("%s.%s", namespace, name)] = streamColumns
+	}
+	return columns, nil
+}
+
+// SchemaDrift compares the catalog of a job streams config with a newly
+// discovered catalog and describes, sorted, how the selected streams changed
+func SchemaDrift(streamsConfig, discovered string) ([]string, error) {
+	selected, err := SelectedStreams(streamsConfig)
+	if err != nil {
+		return nil, err
+	}
+	configured, err := StreamColumns(streamsConfig)
+	if err != nil {
+		return nil, err
+	}
+	current, err := StreamColumns(discovered)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovered catalog: %s", err)
+	}
+
+	var changes []string
+	for _, stream := range selected {
+		columns, ok := current[stream]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: stream no longer exists", stream))
+			continue
+		}
+		before := map[string][]string{}
+		for _, column := range configured[stream] {
+			before[column.Name] = column.Types
+		}
+		for _, column := range columns {
+			types, known := before[column.Name]
+			delete(before, column.Name)
+			switch {
+			case !known:
+				changes = append(changes, fmt.Sprintf("%s: column %s added as %s", stream, column.Name, strings.Join(column.Types, "|")))
+			case !sameTypes(types, column.Types):
+				changes = append(changes, fmt.Sprintf("%s: column %s changed from %s to %s", stream, column.Name, strings.Join(types, "|"), strings.Join(column.Types, "|")))
+			}
+		}
+		for column := range before {
+			changes = append(changes, fmt.Sprintf("%s: column %s removed", stream, column))
+		}
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// sameTypes reports whether two column type lists hold the same types in any order
+func sameTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func asList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
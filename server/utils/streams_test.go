@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeRangeFilter(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 5, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	cases := []struct {
+		from, to time.Time
+		want     string
+	}{
+		{from, to, `updated_at >= "2026-01-01T00:00:00Z" and updated_at < "2026-02-01T05:30:00+05:30"`},
+		{from, time.Time{}, `updated_at >= "2026-01-01T00:00:00Z"`},
+		{time.Time{}, to, `updated_at < "2026-02-01T05:30:00+05:30"`},
+		{time.Time{}, time.Time{}, ""},
+	}
+	for _, tc := range cases {
+		if got := TimeRangeFilter("updated_at", tc.from, tc.to); got != tc.want {
+			t.Errorf("TimeRangeFilter(%s, %s) = %s, want %s", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// templateVariablePattern matches a {{variable}} placeholder of a job template
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVariables returns the sorted names of the placeholders used in a template
+func TemplateVariables(template string) []string {
+	seen := map[string]bool{}
+	variables := []string{}
+	for _, match := range templateVariablePattern.FindAllStringSubmatch(template, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			variables = append(variables, match[1])
+		}
+	}
+	sort.Strings(variables)
+	return variables
+}
+
+// RenderTemplate replaces the placeholders in the keys and string values of a
+// JSON document. String values holding a JSON object or array, such as
+// connector configs, are rendered the same way so values are always escaped.
+func RenderTemplate(template string, variables map[string]string) (string, error) {
+	doc, err := decodeJSON(template)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %s", err)
+	}
+	for _, name := range TemplateVariables(template) {
+		if _, ok := variables[name]; !ok {
+			return "", fmt.Errorf("no value for variable %q", name)
+		}
+	}
+
+	rendered, err := renderValue(doc, variables)
+	if err != nil {
+		return "", err
+	}
+	return encodeJSON(rendered)
+}
+
+func renderValue(value interface{}, variables map[string]string) (interface{}, error) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			item, err := renderValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			rendered[renderString(key, variables)] = item
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			item, err := renderValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, item)
+		}
+		return rendered, nil
+	case string:
+		trimmed := strings.TrimSpace(value)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			if doc, err := decodeJSON(trimmed); err == nil {
+				rendered, err := renderValue(doc, variables)
+				if err != nil {
+					return nil, err
+				}
+				return encodeJSON(rendered)
+			}
+		}
+		return renderString(value, variables), nil
+	default:
+		return value, nil
+	}
+}
+
+func renderString(value string, variables map[string]string) string {
+	return templateVariablePattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+		return variables[templateVariablePattern.FindStringSubmatch(placeholder)[1]]
+	})
+}
+
+// decodeJSON decodes a JSON document keeping numbers as written
+func decodeJSON(data string) (interface{}, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after JSON document")
+	}
+	return doc, nil
+}
+
+// encodeJSON encodes a value without escaping HTML characters, which connector configs may contain
+func encodeJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to encode rendered template: %s", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
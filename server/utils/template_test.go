@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateVariables(t *testing.T) {
+	template := `{"name": "{{ db }}-{{env}}", "config": "{\"host\": \"{{host}}\", \"db\": \"{{db}}\"}", "other": "{{1bad}} {{}}"}`
+	want := []string{"db", "env", "host"}
+	if got := TemplateVariables(template); !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateVariables() = %v, want %v", got, want)
+	}
+	if got := TemplateVariables(`{"name": "plain"}`); len(got) != 0 {
+		t.Errorf("TemplateVariables() of a template without placeholders = %v, want none", got)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	cases := []struct {
+		name      string
+		template  string
+		variables map[string]string
+		want      string
+	}{
+		{
+			name:      "values and keys",
+			template:  `{"name": "{{db}}-sync", "{{key}}": "x"}`,
+			variables: map[string]string{"db": "orders", "key": "label"},
+			want:      `{"label": "x", "name": "orders-sync"}`,
+		},
+		{
+			name:      "nested JSON string",
+			template:  `{"config": "{\"host\": \"{{host}}\", \"port\": 5432}"}`,
+			variables: map[string]string{"host": "db.internal"},
+			want:      `{"config": "{\"host\":\"db.internal\",\"port\":5432}"}`,
+		},
+		{
+			name:      "values are escaped",
+			template:  `{"config": "{\"password\": \"{{password}}\"}", "name": "{{name}}"}`,
+			variables: map[string]string{"password": `p"a\ss<&>`, "name": `a"b`},
+			want:      `{"config": "{\"password\":\"p\\\"a\\\\ss<&>\"}", "name": "a\"b"}`,
+		},
+		{
+			name:      "arrays and numbers",
+			template:  `{"streams": ["{{schema}}.users", 12345678901234567890, true, null]}`,
+			variables: map[string]string{"schema": "public"},
+			want:      `{"streams": ["public.users", 12345678901234567890, true, null]}`,
+		},
+		{
+			name:      "unused variables are ignored",
+			template:  `{"name": "{{db}}"}`,
+			variables: map[string]string{"db": "orders", "other": "x"},
+			want:      `{"name": "orders"}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderTemplate(tc.template, tc.variables)
+			if err != nil {
+				t.Fatalf("RenderTemplate() error = %s", err)
+			}
+			var gotDoc, wantDoc interface{}
+			if err := json.Unmarshal([]byte(got), &gotDoc); err != nil {
+				t.Fatalf("RenderTemplate() = %s, not JSON: %s", got, err)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &wantDoc); err != nil {
+				t.Fatalf("invalid want %s: %s", tc.want, err)
+			}
+			if !reflect.DeepEqual(gotDoc, wantDoc) {
+				t.Errorf("RenderTemplate() = %s, want %s", got, tc.want)
+			}
+			if strings.Contains(got, "{{") {
+				t.Errorf("RenderTemplate() = %s, left a placeholder", got)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		template  string
+		variables map[string]string
+		want      string
+	}{
+		{"missing variable", `{"name": "{{db}}-{{env}}"}`, map[string]string{"db": "orders"}, `no value for variable "env"`},
+		{"missing variable in nested JSON", `{"config": "{\"host\": \"{{host}}\"}"}`, nil, `no value for variable "host"`},
+		{"invalid JSON", `{"name": `, nil, "invalid template"},
+		{"trailing data", `{"name": "a"} {}`, nil, "invalid template"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := RenderTemplate(tc.template, tc.variables)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("RenderTemplate() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -253,35 +252,43 @@ func ParseJSONFile(filePath string) (map[string]interface{}, error) {
 	return result, nil
 }
 
-// ToCron converts a frequency string to a cron expression
-func ToCron(frequency string) string {
-	parts := strings.Split(strings.ToLower(frequency), "-")
-	if len(parts) != 2 {
-		return ""
+// ToCron converts a frequency to a cron expression. The frequency is either
+// the "N-unit" shorthand or a standard 5-field cron expression, which is
+// validated and returned unchanged.
+func ToCron(frequency string) (string, error) {
+	frequency = strings.TrimSpace(frequency)
+	if len(strings.Fields(frequency)) > 1 {
+		if _, err := ParseCron(frequency); err != nil {
+			return "", err
+		}
+		return frequency, nil
 	}
 
-	valueStr, unit := parts[0], parts[1]
-	value, err := strconv.Atoi(valueStr)
-	if err != nil || value <= 0 {
-		return ""
+	value, unit, err := ParseFrequency(frequency)
+	if err != nil {
+		return "", err
 	}
 
 	switch unit {
 	case "minutes":
-		return fmt.Sprintf("*/%d * * * *", value) // Every N minutes
+		return fmt.Sprintf("*/%d * * * *", value), nil // Every N minutes
 	case "hours":
-		return fmt.Sprintf("0 */%d * * *", value) // Every N hours at minute 0
+		return fmt.Sprintf("0 */%d * * *", value), nil // Every N hours at minute 0
 	case "days":
-		return fmt.Sprintf("0 0 */%d * *", value) // Every N days at midnight
+		return fmt.Sprintf("0 0 */%d * *", value), nil // Every N days at midnight
 	case "weeks":
-		// Every N weeks on Sunday (0), cron doesn't support "every N weeks" directly,
-		// so simulate with day-of-week field (best-effort)
-		return fmt.Sprintf("0 0 * * */%d", value)
+		// cron has no "every N weeks", the day-of-week field only selects weekdays
+		if value != 1 {
+			return "", fmt.Errorf("frequency %q cannot be expressed as a cron expression", frequency)
+		}
+		return "0 0 * * 0", nil // Every Sunday at midnight
 	case "months":
-		return fmt.Sprintf("0 0 1 */%d *", value) // Every N months on the 1st at midnight
-	case "years":
-		return fmt.Sprintf("0 0 1 1 */%d", value) // Every N years on the 1st of January at midnight
+		return fmt.Sprintf("0 0 1 */%d *", value), nil // Every N months on the 1st at midnight
 	default:
-		return ""
+		// years, a 5-field cron has no year field
+		if value != 1 {
+			return "", fmt.Errorf("frequency %q cannot be expressed as a cron expression", frequency)
+		}
+		return "0 0 1 1 *", nil // Every 1st of January at midnight
 	}
 }
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONToYAML(t *testing.T) {
+	data := `{"version": 1, "jobs": [{"name": "orders", "active": false, "ratio": 0.5, "schedule_config": null, "streams_config": {}}], "sources": []}`
+	got, err := JSONToYAML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `version: 1
+jobs:
+  - name: orders
+    active: false
+    ratio: 0.5
+    schedule_config: null
+    streams_config: {}
+sources: []
+`
+	if string(got) != want {
+		t.Errorf("JSONToYAML() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := JSONToYAML([]byte(`{"a": `)); err == nil {
+		t.Error("JSONToYAML() of invalid JSON succeeded")
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	data := `{"name":"a: b","port":"5432","on":"yes","values":[1,"2",true,null],"nested":{"password":"${secret:source/pg/password}"}}`
+	yamlData, err := JSONToYAML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := YAMLToJSON(yamlData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want interface{}
+	if err := json.Unmarshal(jsonData, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(data), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %s, want %s", jsonData, data)
+	}
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	got, err := YAMLToJSON([]byte("version: 1\nsources:\n  - name: pg\n    config:\n      port: 5432\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"sources":[{"config":{"port":5432},"name":"pg"}],"version":1}`; string(got) != want {
+		t.Errorf("YAMLToJSON() = %s, want %s", got, want)
+	}
+	if got, err := YAMLToJSON([]byte(`{"version": 1}`)); err != nil || string(got) != `{"version":1}` {
+		t.Errorf("YAMLToJSON() of JSON = %s, %v", got, err)
+	}
+	if _, err := YAMLToJSON([]byte("a: [")); err == nil {
+		t.Error("YAMLToJSON() of invalid YAML succeeded")
+	}
+}